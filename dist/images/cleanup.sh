@@ -55,6 +55,11 @@ kubectl delete --ignore-not-found clusterrole system:ovn
 kubectl delete --ignore-not-found clusterrolebinding ovn
 
 # delete CRD
+kubectl delete --ignore-not-found crd dns-records.kubeovn.io
+kubectl delete --ignore-not-found crd subnet-templates.kubeovn.io
+kubectl delete --ignore-not-found crd mirror-sessions.kubeovn.io
+kubectl delete --ignore-not-found crd ipblocks.kubeovn.io
+kubectl delete --ignore-not-found crd ippools.kubeovn.io
 kubectl delete --ignore-not-found crd htbqoses.kubeovn.io
 kubectl delete --ignore-not-found crd security-groups.kubeovn.io
 kubectl delete --ignore-not-found crd ips.kubeovn.io
@@ -13,6 +13,7 @@ HW_OFFLOAD=${HW_OFFLOAD:-false}
 ENABLE_LB=${ENABLE_LB:-true}
 ENABLE_NP=${ENABLE_NP:-true}
 ENABLE_EXTERNAL_VPC=${ENABLE_EXTERNAL_VPC:-true}
+ENABLE_GATEWAY_BFD=${ENABLE_GATEWAY_BFD:-false}
 # The nic to support container network can be a nic name or a group of regex
 # separated by comma, if empty will use the nic that the default route use
 IFACE=${IFACE:-}
@@ -290,6 +291,10 @@ spec:
                         type: string
                       nextHopIP:
                         type: string
+                      routeTable:
+                        type: string
+                      enableBfd:
+                        type: boolean
                     type: object
                   type: array
                 policyRoutes:
@@ -312,8 +317,34 @@ spec:
                         type: string
                       localConnectIP:
                         type: string
+                      remoteAz:
+                        type: string
                     type: object
                   type: array
+                quota:
+                  properties:
+                    maxSubnets:
+                      type: integer
+                      minimum: 0
+                    maxIPs:
+                      type: integer
+                      minimum: 0
+                    maxEips:
+                      type: integer
+                      minimum: 0
+                    maxDnatRules:
+                      type: integer
+                      minimum: 0
+                    maxSnatRules:
+                      type: integer
+                      minimum: 0
+                    maxFloatingIpRules:
+                      type: integer
+                      minimum: 0
+                    maxStaticRoutes:
+                      type: integer
+                      minimum: 0
+                  type: object
               type: object
             status:
               properties:
@@ -350,6 +381,61 @@ spec:
                   items:
                     type: string
                   type: array
+                staticRoutes:
+                  items:
+                    properties:
+                      policy:
+                        type: string
+                      cidr:
+                        type: string
+                      routeTable:
+                        type: string
+                      nextHopIP:
+                        type: string
+                      activeNextHopIP:
+                        type: string
+                    type: object
+                  type: array
+                peeringStatus:
+                  items:
+                    properties:
+                      remoteVpc:
+                        type: string
+                      remoteAz:
+                        type: string
+                      localConnectIP:
+                        type: string
+                      transitSwitch:
+                        type: string
+                      remoteConnectIP:
+                        type: string
+                      remoteCIDRs:
+                        items:
+                          type: string
+                        type: array
+                      state:
+                        type: string
+                      reason:
+                        type: string
+                    type: object
+                  type: array
+                quotaUsage:
+                  properties:
+                    subnets:
+                      type: integer
+                    ips:
+                      type: integer
+                    eips:
+                      type: integer
+                    dnatRules:
+                      type: integer
+                    snatRules:
+                      type: integer
+                    floatingIpRules:
+                      type: integer
+                    staticRoutes:
+                      type: integer
+                  type: object
                 tcpLoadBalancer:
                   type: string
                 tcpSessionLoadBalancer:
@@ -436,6 +522,14 @@ spec:
                     type: string
                 containerID:
                   type: string
+                dhcpV4Options:
+                  type: object
+                  additionalProperties:
+                    type: string
+                dhcpV6Options:
+                  type: object
+                  additionalProperties:
+                    type: string
   scope: Cluster
   names:
     plural: ips
@@ -517,6 +611,74 @@ spec:
                   type: string
                 dhcpV6OptionsUUID:
                   type: string
+                migration:
+                  type: object
+                  properties:
+                    phase:
+                      type: string
+                    sourceCIDR:
+                      type: string
+                    targetCIDR:
+                      type: string
+                    pods:
+                      type: array
+                      items:
+                        type: string
+                    manualPods:
+                      type: array
+                      items:
+                        type: string
+                    ips:
+                      type: array
+                      items:
+                        type: string
+                    vips:
+                      type: array
+                      items:
+                        type: string
+                    staticRoutes:
+                      type: array
+                      items:
+                        type: string
+                    nats:
+                      type: array
+                      items:
+                        type: string
+                    migratedPods:
+                      type: integer
+                    message:
+                      type: string
+                    lastBatchTime:
+                      type: string
+                aclRules:
+                  type: object
+                  properties:
+                    acls:
+                      type: array
+                      items:
+                        type: object
+                        properties:
+                          direction:
+                            type: string
+                          priority:
+                            type: integer
+                          match:
+                            type: string
+                          action:
+                            type: string
+                    message:
+                      type: string
+                namespaceIPQuotas:
+                  type: array
+                  items:
+                    type: object
+                    properties:
+                      namespace:
+                        type: string
+                      quota:
+                        type: integer
+                      used:
+                        type: integer
                 conditions:
                   type: array
                   items:
@@ -539,12 +701,18 @@ spec:
               properties:
                 vpc:
                   type: string
+                routeTable:
+                  type: string
                 default:
                   type: boolean
                 protocol:
                   type: string
                 cidrBlock:
                   type: string
+                extraCIDRBlocks:
+                  type: array
+                  items:
+                    type: string
                 namespaces:
                   type: array
                   items:
@@ -561,6 +729,67 @@ spec:
                   type: array
                   items:
                     type: string
+                ipReuseTTL:
+                  type: integer
+                  minimum: 0
+                ipQuarantine:
+                  type: integer
+                  minimum: 0
+                allocationStrategy:
+                  type: string
+                  enum:
+                    - sequential
+                    - random
+                    - leastRecentlyReleased
+                nodeBlockSize:
+                  type: integer
+                  minimum: 0
+                macPrefix:
+                  type: string
+                  pattern: '^[0-9a-fA-F]{2}:[0-9a-fA-F]{2}:[0-9a-fA-F]{2}$'
+                macPolicy:
+                  type: string
+                  enum:
+                    - random
+                    - ipDerived
+                utilizationThreshold:
+                  type: integer
+                  minimum: 0
+                  maximum: 100
+                migration:
+                  type: object
+                  properties:
+                    cidrBlock:
+                      type: string
+                    dryRun:
+                      type: boolean
+                    batchSize:
+                      type: integer
+                      minimum: 0
+                    batchInterval:
+                      type: integer
+                      minimum: 0
+                  required:
+                    - cidrBlock
+                qos:
+                  type: object
+                  properties:
+                    ingressRate:
+                      type: integer
+                      minimum: 0
+                    ingressBurst:
+                      type: integer
+                      minimum: 0
+                    egressRate:
+                      type: integer
+                      minimum: 0
+                    egressBurst:
+                      type: integer
+                      minimum: 0
+                    egressDSCP:
+                      type: integer
+                      minimum: 0
+                      maximum: 63
                 gatewayType:
                   type: string
                 allowSubnets:
@@ -605,6 +834,14 @@ spec:
                   type: string
                 dhcpV6Options:
                   type: string
+                dhcpV4ExtraOptions:
+                  type: object
+                  additionalProperties:
+                    type: string
+                dhcpV6ExtraOptions:
+                  type: object
+                  additionalProperties:
+                    type: string
                 enableIPv6RA:
                   type: boolean
                 ipv6RAConfigs:
@@ -633,6 +870,49 @@ spec:
                           - allow
                           - drop
                           - reject
+                aclRules:
+                  type: array
+                  items:
+                    type: object
+                    required:
+                      - direction
+                      - action
+                    properties:
+                      direction:
+                        type: string
+                        enum:
+                          - ingress
+                          - egress
+                      priority:
+                        type: integer
+                        minimum: 0
+                        maximum: 899
+                      action:
+                        type: string
+                        enum:
+                          - allow
+                          - drop
+                          - reject
+                      cidrs:
+                        type: array
+                        items:
+                          type: string
+                      protocol:
+                        type: string
+                        enum:
+                          - tcp
+                          - udp
+                          - sctp
+                          - icmp
+                      ports:
+                        type: array
+                        items:
+                          type: string
+                namespaceIPQuotas:
+                  type: object
+                  additionalProperties:
+                    type: integer
+                    minimum: 0
   scope: Cluster
   names:
     plural: subnets
@@ -919,6 +1199,423 @@ spec:
     kind: HtbQos
     shortNames:
       - htbqos
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: ippools.kubeovn.io
+spec:
+  group: kubeovn.io
+  versions:
+    - name: v1
+      served: true
+      storage: true
+      subresources:
+        status: {}
+      additionalPrinterColumns:
+      - name: Subnet
+        type: string
+        jsonPath: .spec.subnet
+      - name: V4Used
+        type: number
+        jsonPath: .status.v4UsingIPs
+      - name: V4Available
+        type: number
+        jsonPath: .status.v4AvailableIPs
+      - name: V6Used
+        type: number
+        jsonPath: .status.v6UsingIPs
+      - name: V6Available
+        type: number
+        jsonPath: .status.v6AvailableIPs
+      schema:
+        openAPIV3Schema:
+          type: object
+          properties:
+            spec:
+              type: object
+              properties:
+                subnet:
+                  type: string
+                ips:
+                  type: array
+                  minItems: 1
+                  items:
+                    type: string
+                namespaces:
+                  type: array
+                  items:
+                    type: string
+                selector:
+                  type: object
+                  properties:
+                    matchLabels:
+                      type: object
+                      additionalProperties:
+                        type: string
+                    matchExpressions:
+                      type: array
+                      items:
+                        type: object
+                        properties:
+                          key:
+                            type: string
+                          operator:
+                            type: string
+                          values:
+                            type: array
+                            items:
+                              type: string
+              required:
+                - subnet
+                - ips
+            status:
+              type: object
+              properties:
+                v4AvailableIPs:
+                  type: number
+                v4UsingIPs:
+                  type: number
+                v6AvailableIPs:
+                  type: number
+                v6UsingIPs:
+                  type: number
+  scope: Cluster
+  names:
+    plural: ippools
+    singular: ippool
+    kind: IPPool
+    listKind: IPPoolList
+    shortNames:
+      - ippool
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: ipblocks.kubeovn.io
+spec:
+  group: kubeovn.io
+  versions:
+    - name: v1
+      served: true
+      storage: true
+      subresources:
+        status: {}
+      additionalPrinterColumns:
+      - name: Subnet
+        type: string
+        jsonPath: .spec.subnet
+      - name: Node
+        type: string
+        jsonPath: .spec.nodeName
+      - name: V4Used
+        type: number
+        jsonPath: .status.v4UsingIPs
+      - name: V4Available
+        type: number
+        jsonPath: .status.v4AvailableIPs
+      - name: V6Used
+        type: number
+        jsonPath: .status.v6UsingIPs
+      - name: V6Available
+        type: number
+        jsonPath: .status.v6AvailableIPs
+      schema:
+        openAPIV3Schema:
+          type: object
+          properties:
+            spec:
+              type: object
+              properties:
+                subnet:
+                  type: string
+                nodeName:
+                  type: string
+                ips:
+                  type: array
+                  minItems: 1
+                  items:
+                    type: string
+              required:
+                - subnet
+                - nodeName
+                - ips
+            status:
+              type: object
+              properties:
+                v4AvailableIPs:
+                  type: number
+                v4UsingIPs:
+                  type: number
+                v6AvailableIPs:
+                  type: number
+                v6UsingIPs:
+                  type: number
+  scope: Cluster
+  names:
+    plural: ipblocks
+    singular: ipblock
+    kind: IPBlock
+    listKind: IPBlockList
+    shortNames:
+      - ipblock
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: mirror-sessions.kubeovn.io
+spec:
+  group: kubeovn.io
+  versions:
+    - name: v1
+      served: true
+      storage: true
+      subresources:
+        status: {}
+      additionalPrinterColumns:
+      - name: Type
+        type: string
+        jsonPath: .spec.destination.type
+      - name: RemoteIP
+        type: string
+        jsonPath: .spec.destination.remoteIP
+      - name: Direction
+        type: string
+        jsonPath: .spec.direction
+      schema:
+        openAPIV3Schema:
+          type: object
+          properties:
+            spec:
+              type: object
+              properties:
+                subnets:
+                  type: array
+                  items:
+                    type: string
+                namespaces:
+                  type: array
+                  items:
+                    type: string
+                podSelector:
+                  type: object
+                  properties:
+                    matchLabels:
+                      type: object
+                      additionalProperties:
+                        type: string
+                    matchExpressions:
+                      type: array
+                      items:
+                        type: object
+                        properties:
+                          key:
+                            type: string
+                          operator:
+                            type: string
+                          values:
+                            type: array
+                            items:
+                              type: string
+                direction:
+                  type: string
+                  enum:
+                    - ingress
+                    - egress
+                    - both
+                snaplen:
+                  type: integer
+                  minimum: 0
+                  maximum: 65535
+                destination:
+                  type: object
+                  properties:
+                    type:
+                      type: string
+                      enum:
+                        - erspan
+                        - gre
+                    remoteIP:
+                      type: string
+                    key:
+                      type: integer
+                      minimum: 0
+                    erspanVersion:
+                      type: integer
+                      enum:
+                        - 1
+                        - 2
+                  required:
+                    - type
+                    - remoteIP
+              required:
+                - destination
+            status:
+              type: object
+              properties:
+                nodes:
+                  type: object
+                  additionalProperties:
+                    type: object
+                    properties:
+                      ready:
+                        type: boolean
+                      ports:
+                        type: integer
+                      message:
+                        type: string
+                      lastUpdateTime:
+                        type: string
+                        format: date-time
+  scope: Cluster
+  names:
+    plural: mirror-sessions
+    singular: mirror-session
+    kind: MirrorSession
+    listKind: MirrorSessionList
+    shortNames:
+      - ms
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: subnet-templates.kubeovn.io
+spec:
+  group: kubeovn.io
+  versions:
+    - name: v1
+      served: true
+      storage: true
+      subresources:
+        status: {}
+      additionalPrinterColumns:
+      - name: Supernet
+        type: string
+        jsonPath: .spec.supernet
+      - name: PrefixLength
+        type: integer
+        jsonPath: .spec.prefixLength
+      schema:
+        openAPIV3Schema:
+          type: object
+          properties:
+            spec:
+              type: object
+              properties:
+                supernet:
+                  type: string
+                prefixLength:
+                  type: integer
+                  minimum: 1
+                  maximum: 128
+                namespaceSelector:
+                  type: object
+                  properties:
+                    matchLabels:
+                      type: object
+                      additionalProperties:
+                        type: string
+                    matchExpressions:
+                      type: array
+                      items:
+                        type: object
+                        properties:
+                          key:
+                            type: string
+                          operator:
+                            type: string
+                          values:
+                            type: array
+                            items:
+                              type: string
+                template:
+                  type: object
+                  x-kubernetes-preserve-unknown-fields: true
+              required:
+                - supernet
+                - prefixLength
+                - namespaceSelector
+            status:
+              type: object
+              properties:
+                allocations:
+                  type: array
+                  items:
+                    type: object
+                    properties:
+                      namespace:
+                        type: string
+                      subnet:
+                        type: string
+                      cidrBlock:
+                        type: string
+  scope: Cluster
+  names:
+    plural: subnet-templates
+    singular: subnet-template
+    kind: SubnetTemplate
+    listKind: SubnetTemplateList
+    shortNames:
+      - st
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: dns-records.kubeovn.io
+spec:
+  group: kubeovn.io
+  versions:
+    - name: v1
+      served: true
+      storage: true
+      subresources:
+        status: {}
+      additionalPrinterColumns:
+      - name: Vpc
+        type: string
+        jsonPath: .spec.vpc
+      - name: Hostname
+        type: string
+        jsonPath: .spec.hostname
+      - name: IPs
+        type: string
+        jsonPath: .spec.ips
+      schema:
+        openAPIV3Schema:
+          type: object
+          properties:
+            spec:
+              type: object
+              properties:
+                vpc:
+                  type: string
+                hostname:
+                  type: string
+                ips:
+                  type: array
+                  minItems: 1
+                  items:
+                    type: string
+                enablePtr:
+                  type: boolean
+              required:
+                - hostname
+                - ips
+            status:
+              type: object
+              properties:
+                logicalSwitches:
+                  type: array
+                  items:
+                    type: string
+  scope: Cluster
+  names:
+    plural: dns-records
+    singular: dns-record
+    kind: DNSRecord
+    listKind: DNSRecordList
+    shortNames:
+      - dnsr
 EOF
 
 if $DPDK; then
@@ -996,6 +1693,16 @@ rules:
       - security-groups
       - security-groups/status
       - htbqoses
+      - ippools
+      - ippools/status
+      - ipblocks
+      - ipblocks/status
+      - mirror-sessions
+      - mirror-sessions/status
+      - subnet-templates
+      - subnet-templates/status
+      - dns-records
+      - dns-records/status
     verbs:
       - "*"
   - apiGroups:
@@ -1003,6 +1710,7 @@ rules:
     resources:
       - pods
       - pods/exec
+      - pods/eviction
       - namespaces
       - nodes
       - configmaps
@@ -1051,6 +1759,24 @@ rules:
       - create
       - patch
       - update
+  - apiGroups:
+      - kubevirt.io
+    resources:
+      - virtualmachines
+    verbs:
+      - get
+  - apiGroups:
+      - authentication.k8s.io
+    resources:
+      - tokenreviews
+    verbs:
+      - create
+  - apiGroups:
+      - authorization.k8s.io
+    resources:
+      - subjectaccessreviews
+    verbs:
+      - create
 
 ---
 apiVersion: rbac.authorization.k8s.io/v1
@@ -1492,6 +2218,16 @@ rules:
       - security-groups
       - security-groups/status
       - htbqoses
+      - ippools
+      - ippools/status
+      - ipblocks
+      - ipblocks/status
+      - mirror-sessions
+      - mirror-sessions/status
+      - subnet-templates
+      - subnet-templates/status
+      - dns-records
+      - dns-records/status
     verbs:
       - "*"
   - apiGroups:
@@ -1499,6 +2235,7 @@ rules:
     resources:
       - pods
       - pods/exec
+      - pods/eviction
       - namespaces
       - nodes
       - configmaps
@@ -1537,6 +2274,24 @@ rules:
       - create
       - patch
       - update
+  - apiGroups:
+      - kubevirt.io
+    resources:
+      - virtualmachines
+    verbs:
+      - get
+  - apiGroups:
+      - authentication.k8s.io
+    resources:
+      - tokenreviews
+    verbs:
+      - create
+  - apiGroups:
+      - authorization.k8s.io
+    resources:
+      - subjectaccessreviews
+    verbs:
+      - create
   - apiGroups:
       - "k8s.cni.cncf.io"
     resources:
@@ -1975,6 +2730,7 @@ spec:
           - --enable-lb=$ENABLE_LB
           - --enable-np=$ENABLE_NP
           - --enable-external-vpc=$ENABLE_EXTERNAL_VPC
+          - --enable-gateway-bfd=$ENABLE_GATEWAY_BFD
           - --logtostderr=false
           - --alsologtostderr=true
           - --log_file=/var/log/kube-ovn/kube-ovn-controller.log
@@ -2089,6 +2845,7 @@ spec:
           - /kube-ovn/start-cniserver.sh
         args:
           - --enable-mirror=$ENABLE_MIRROR
+          - --enable-gateway-bfd=$ENABLE_GATEWAY_BFD
           - --encap-checksum=true
           - --service-cluster-ip-range=$SVC_CIDR
           - --iface=${IFACE}
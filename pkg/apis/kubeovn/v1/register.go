@@ -47,6 +47,16 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&SecurityGroupList{},
 		&HtbQos{},
 		&HtbQosList{},
+		&IPPool{},
+		&IPPoolList{},
+		&IPBlock{},
+		&IPBlockList{},
+		&MirrorSession{},
+		&MirrorSessionList{},
+		&SubnetTemplate{},
+		&SubnetTemplateList{},
+		&DNSRecord{},
+		&DNSRecordList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
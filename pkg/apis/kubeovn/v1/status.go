@@ -57,3 +57,53 @@ func (sgs *SecurityGroupStatus) Bytes() ([]byte, error) {
 	klog.V(5).Info("status body", newStr)
 	return []byte(newStr), nil
 }
+
+func (ips *IPPoolStatus) Bytes() ([]byte, error) {
+	bytes, err := json.Marshal(ips)
+	if err != nil {
+		return nil, err
+	}
+	newStr := fmt.Sprintf(`{"status": %s}`, string(bytes))
+	klog.V(5).Info("status body", newStr)
+	return []byte(newStr), nil
+}
+
+func (ibs *IPBlockStatus) Bytes() ([]byte, error) {
+	bytes, err := json.Marshal(ibs)
+	if err != nil {
+		return nil, err
+	}
+	newStr := fmt.Sprintf(`{"status": %s}`, string(bytes))
+	klog.V(5).Info("status body", newStr)
+	return []byte(newStr), nil
+}
+
+func (mss *MirrorSessionStatus) Bytes() ([]byte, error) {
+	bytes, err := json.Marshal(mss)
+	if err != nil {
+		return nil, err
+	}
+	newStr := fmt.Sprintf(`{"status": %s}`, string(bytes))
+	klog.V(5).Info("status body", newStr)
+	return []byte(newStr), nil
+}
+
+func (sts *SubnetTemplateStatus) Bytes() ([]byte, error) {
+	bytes, err := json.Marshal(sts)
+	if err != nil {
+		return nil, err
+	}
+	newStr := fmt.Sprintf(`{"status": %s}`, string(bytes))
+	klog.V(5).Info("status body", newStr)
+	return []byte(newStr), nil
+}
+
+func (drs *DNSRecordStatus) Bytes() ([]byte, error) {
+	bytes, err := json.Marshal(drs)
+	if err != nil {
+		return nil, err
+	}
+	newStr := fmt.Sprintf(`{"status": %s}`, string(bytes))
+	klog.V(5).Info("status body", newStr)
+	return []byte(newStr), nil
+}
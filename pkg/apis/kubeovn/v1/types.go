@@ -12,6 +12,32 @@ const (
 
 	GWDistributedType = "distributed"
 	GWCentralizedType = "centralized"
+
+	AllocationStrategySequential            = "sequential"
+	AllocationStrategyRandom                = "random"
+	AllocationStrategyLeastRecentlyReleased = "leastRecentlyReleased"
+
+	MacPolicyRandom    = "random"
+	MacPolicyIPDerived = "ipDerived"
+
+	MigrationPlanned    = "Planned"
+	MigrationInProgress = "InProgress"
+	MigrationCompleted  = "Completed"
+	MigrationFailed     = "Failed"
+
+	AclRuleIngress = "ingress"
+	AclRuleEgress  = "egress"
+
+	AclRuleActionAllow  = "allow"
+	AclRuleActionDrop   = "drop"
+	AclRuleActionReject = "reject"
+
+	MirrorDirectionIngress = "ingress"
+	MirrorDirectionEgress  = "egress"
+	MirrorDirectionBoth    = "both"
+
+	MirrorTypeERSPAN = "erspan"
+	MirrorTypeGRE    = "gre"
 )
 
 type SgRemoteType string
@@ -45,6 +71,18 @@ const (
 	Validated = "Validated"
 	// Error => last recorded error
 	Error = "Error"
+	// NearlyExhausted => address utilization of the subnet reaches the threshold
+	NearlyExhausted = "NearlyExhausted"
+
+	// Conditions of the reconcile steps of subnets
+	LogicalSwitchReady = "LogicalSwitchReady"
+	DHCPReady          = "DHCPReady"
+	NamespaceReady     = "NamespaceReady"
+	GatewayReady       = "GatewayReady"
+	VlanReady          = "VlanReady"
+	VIPReady           = "VIPReady"
+	ACLReady           = "ACLReady"
+	QoSReady           = "QoSReady"
 
 	ReasonInit = "Init"
 )
@@ -74,6 +112,10 @@ type IPSpec struct {
 	MacAddress    string   `json:"macAddress"`
 	AttachMacs    []string `json:"attachMacs"`
 	ContainerID   string   `json:"containerID"`
+
+	// dhcp options of the ip overriding the ones of the subnet, keyed by ovn dhcp option name
+	DHCPv4Options map[string]string `json:"dhcpV4Options,omitempty"`
+	DHCPv6Options map[string]string `json:"dhcpV6Options,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -100,6 +142,7 @@ type Subnet struct {
 type SubnetSpec struct {
 	Default    bool     `json:"default"`
 	Vpc        string   `json:"vpc,omitempty"`
+	RouteTable string   `json:"routeTable,omitempty"`
 	Protocol   string   `json:"protocol"`
 	Namespaces []string `json:"namespaces,omitempty"`
 	CIDRBlock  string   `json:"cidrBlock"`
@@ -107,6 +150,9 @@ type SubnetSpec struct {
 	ExcludeIps []string `json:"excludeIps,omitempty"`
 	Provider   string   `json:"provider,omitempty"`
 
+	// additional cidr blocks of the subnet, the first address of each block is used as its gateway
+	ExtraCIDRBlocks []string `json:"extraCIDRBlocks,omitempty"`
+
 	GatewayType string `json:"gatewayType"`
 	GatewayNode string `json:"gatewayNode"`
 	NatOutgoing bool   `json:"natOutgoing"`
@@ -123,6 +169,31 @@ type SubnetSpec struct {
 
 	Vips []string `json:"vips,omitempty"`
 
+	// seconds a released address is held for pods of the same owner
+	IPReuseTTL int `json:"ipReuseTTL,omitempty"`
+	// seconds a released address is not allocated to pods of other owners
+	IPQuarantine int `json:"ipQuarantine,omitempty"`
+
+	// strategy to choose addresses from the free ranges, defaults to sequential
+	AllocationStrategy string `json:"allocationStrategy,omitempty"`
+
+	// num of addresses of each protocol leased to a node at a time, 0 to disable per-node blocks
+	NodeBlockSize int `json:"nodeBlockSize,omitempty"`
+
+	// first 3 bytes of generated mac addresses, such as the oui of a vendor
+	MacPrefix string `json:"macPrefix,omitempty"`
+	// random or ipDerived, defaults to random
+	MacPolicy string `json:"macPolicy,omitempty"`
+
+	// percentage of used addresses to mark the subnet as nearly exhausted, 0 to disable
+	UtilizationThreshold int `json:"utilizationThreshold,omitempty"`
+
+	// move the subnet to new cidr blocks and re-address its pods
+	Migration *SubnetMigration `json:"migration,omitempty"`
+
+	// aggregate bandwidth limits and dscp marking of traffic entering and leaving the subnet
+	QoS *SubnetQoS `json:"qos,omitempty"`
+
 	LogicalGateway         bool `json:"logicalGateway"`
 	DisableGatewayCheck    bool `json:"disableGatewayCheck"`
 	DisableInterConnection bool `json:"disableInterConnection"`
@@ -130,11 +201,42 @@ type SubnetSpec struct {
 	EnableDHCP    bool   `json:"enableDHCP"`
 	DHCPv4Options string `json:"dhcpV4Options"`
 	DHCPv6Options string `json:"dhcpV6Options"`
+	// options merged into dhcpV4Options/dhcpV6Options or the default options, keyed by ovn dhcp option name
+	DHCPv4ExtraOptions map[string]string `json:"dhcpV4ExtraOptions,omitempty"`
+	DHCPv6ExtraOptions map[string]string `json:"dhcpV6ExtraOptions,omitempty"`
 
 	EnableIPv6RA  bool   `json:"enableIPv6RA"`
 	IPv6RAConfigs string `json:"ipv6RAConfigs"`
 
 	Acls []Acl `json:"acls,omitempty"`
+	// structured acl rules compiled into acls of the logical switch
+	AclRules []AclRule `json:"aclRules,omitempty"`
+
+	// max num of addresses allocated to pods of the namespace, keyed by namespace
+	NamespaceIPQuotas map[string]int `json:"namespaceIPQuotas,omitempty"`
+}
+
+type SubnetMigration struct {
+	// cidr blocks the subnet is migrated to
+	CIDRBlock string `json:"cidrBlock"`
+	// only compute the migration plan
+	DryRun bool `json:"dryRun,omitempty"`
+	// num of pods recreated in each batch, defaults to 10
+	BatchSize int `json:"batchSize,omitempty"`
+	// seconds between two batches, defaults to 30
+	BatchInterval int `json:"batchInterval,omitempty"`
+}
+
+type SubnetQoS struct {
+	// Mbit/s of traffic entering the subnet, 0 for unlimited
+	IngressRate int `json:"ingressRate,omitempty"`
+	// Mbit, 0 for the default burst of ovn
+	IngressBurst int `json:"ingressBurst,omitempty"`
+	// Mbit/s of traffic leaving the subnet, 0 for unlimited
+	EgressRate  int `json:"egressRate,omitempty"`
+	EgressBurst int `json:"egressBurst,omitempty"`
+	// dscp value marked on traffic leaving the subnet, 0 to disable
+	EgressDSCP int `json:"egressDSCP,omitempty"`
 }
 
 type Acl struct {
@@ -144,6 +246,28 @@ type Acl struct {
 	Action    string `json:"action,omitempty"`
 }
 
+type AclRule struct {
+	// ingress for traffic entering the subnet, egress for traffic leaving the subnet
+	Direction string `json:"direction"`
+	// 0-899, rules with higher priority take precedence
+	Priority int `json:"priority,omitempty"`
+	// allow, drop or reject
+	Action string `json:"action"`
+	// cidrs of the remote side, empty for any address
+	CIDRs []string `json:"cidrs,omitempty"`
+	// tcp, udp, sctp or icmp, empty for any protocol
+	Protocol string `json:"protocol,omitempty"`
+	// destination ports or port ranges like 8000-8080, only for tcp, udp and sctp
+	Ports []string `json:"ports,omitempty"`
+}
+
+type SubnetAclRulesStatus struct {
+	// acls compiled from aclRules
+	Acls []Acl `json:"acls"`
+	// reason why aclRules are rejected
+	Message string `json:"message"`
+}
+
 // ConditionType encodes information on the condition
 type ConditionType string
 
@@ -184,6 +308,39 @@ type SubnetStatus struct {
 	ActivateGateway   string  `json:"activateGateway"`
 	DHCPv4OptionsUUID string  `json:"dhcpV4OptionsUUID"`
 	DHCPv6OptionsUUID string  `json:"dhcpV6OptionsUUID"`
+
+	Migration *SubnetMigrationStatus `json:"migration,omitempty"`
+
+	AclRules *SubnetAclRulesStatus `json:"aclRules"`
+
+	NamespaceIPQuotas []NamespaceIPQuotaStatus `json:"namespaceIPQuotas"`
+}
+
+type NamespaceIPQuotaStatus struct {
+	Namespace string `json:"namespace"`
+	Quota     int    `json:"quota"`
+	Used      int    `json:"used"`
+}
+
+type SubnetMigrationStatus struct {
+	// Planned, InProgress, Completed or Failed
+	Phase      string `json:"phase"`
+	SourceCIDR string `json:"sourceCIDR"`
+	TargetCIDR string `json:"targetCIDR"`
+
+	// pods recreated by their owners during the migration
+	Pods []string `json:"pods,omitempty"`
+	// pods with static addresses or without owners, which have to be migrated manually
+	ManualPods []string `json:"manualPods,omitempty"`
+	// ip crs not belonging to pods, such as node addresses and reservations
+	IPs          []string `json:"ips,omitempty"`
+	Vips         []string `json:"vips,omitempty"`
+	StaticRoutes []string `json:"staticRoutes,omitempty"`
+	Nats         []string `json:"nats,omitempty"`
+
+	MigratedPods  int         `json:"migratedPods"`
+	Message       string      `json:"message,omitempty"`
+	LastBatchTime metav1.Time `json:"lastBatchTime,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -348,11 +505,61 @@ type VpcSpec struct {
 	StaticRoutes []*StaticRoute `json:"staticRoutes,omitempty"`
 	PolicyRoutes []*PolicyRoute `json:"policyRoutes,omitempty"`
 	VpcPeerings  []*VpcPeering  `json:"vpcPeerings,omitempty"`
+	// Quota limits the resources created in the vpc
+	// +optional
+	Quota *VpcQuota `json:"quota,omitempty"`
+}
+
+// VpcQuota is the max number of resources in the vpc, zero means no limit
+type VpcQuota struct {
+	MaxSubnets int `json:"maxSubnets,omitempty"`
+	// MaxIPs is the max number of addresses allocated in the subnets of the vpc
+	MaxIPs             int `json:"maxIPs,omitempty"`
+	MaxEIPs            int `json:"maxEips,omitempty"`
+	MaxDnatRules       int `json:"maxDnatRules,omitempty"`
+	MaxSnatRules       int `json:"maxSnatRules,omitempty"`
+	MaxFloatingIPRules int `json:"maxFloatingIpRules,omitempty"`
+	MaxStaticRoutes    int `json:"maxStaticRoutes,omitempty"`
+}
+
+type VpcQuotaUsage struct {
+	Subnets         int `json:"subnets"`
+	IPs             int `json:"ips"`
+	EIPs            int `json:"eips"`
+	DnatRules       int `json:"dnatRules"`
+	SnatRules       int `json:"snatRules"`
+	FloatingIPRules int `json:"floatingIpRules"`
+	StaticRoutes    int `json:"staticRoutes"`
 }
 
 type VpcPeering struct {
 	RemoteVpc      string `json:"remoteVpc,omitempty"`
 	LocalConnectIP string `json:"localConnectIP,omitempty"`
+	// RemoteAZ is the ovn-ic availability zone of the remote vpc, the vpcs are peered across azs
+	// through a transit switch dedicated to them if it is set
+	// +optional
+	RemoteAZ string `json:"remoteAz,omitempty"`
+}
+
+type VpcPeeringState string
+
+const (
+	VpcPeeringStateActive  VpcPeeringState = "Active"
+	VpcPeeringStatePending VpcPeeringState = "Pending"
+)
+
+type VpcPeeringStatus struct {
+	RemoteVpc      string `json:"remoteVpc"`
+	RemoteAZ       string `json:"remoteAz,omitempty"`
+	LocalConnectIP string `json:"localConnectIP"`
+	// TransitSwitch is the transit switch connecting the vpcs peered across azs
+	TransitSwitch string `json:"transitSwitch,omitempty"`
+	// RemoteConnectIP and RemoteCIDRs are the address of the remote vpc on the transit switch and
+	// the cidrs of the remote vpc, which are routed through the transit switch
+	RemoteConnectIP string          `json:"remoteConnectIP,omitempty"`
+	RemoteCIDRs     []string        `json:"remoteCIDRs,omitempty"`
+	State           VpcPeeringState `json:"state"`
+	Reason          string          `json:"reason,omitempty"`
 }
 
 type RoutePolicy string
@@ -363,9 +570,23 @@ const (
 )
 
 type StaticRoute struct {
-	Policy    RoutePolicy `json:"policy,omitempty"`
-	CIDR      string      `json:"cidr"`
-	NextHopIP string      `json:"nextHopIP"`
+	Policy RoutePolicy `json:"policy,omitempty"`
+	CIDR   string      `json:"cidr"`
+	// NextHopIP is a comma separated list of next hops, traffic is balanced among them by ecmp
+	NextHopIP  string `json:"nextHopIP"`
+	RouteTable string `json:"routeTable,omitempty"`
+	// EnableBFD detects failure of the next hops by bfd sessions, failed next hops are bypassed
+	// +optional
+	EnableBFD bool `json:"enableBfd,omitempty"`
+}
+
+type StaticRouteStatus struct {
+	Policy     RoutePolicy `json:"policy,omitempty"`
+	CIDR       string      `json:"cidr"`
+	RouteTable string      `json:"routeTable,omitempty"`
+	NextHopIP  string      `json:"nextHopIP"`
+	// ActiveNextHopIP is the next hops in use, next hops whose bfd sessions are not up are excluded
+	ActiveNextHopIP string `json:"activeNextHopIP"`
 }
 
 type PolicyRouteAction string
@@ -402,6 +623,10 @@ type VpcStatus struct {
 	UdpSessionLoadBalancer string   `json:"udpSessionLoadBalancer"`
 	Subnets                []string `json:"subnets"`
 	VpcPeerings            []string `json:"vpcPeerings"`
+
+	StaticRoutes  []StaticRouteStatus `json:"staticRoutes"`
+	PeeringStatus []VpcPeeringStatus  `json:"peeringStatus"`
+	QuotaUsage    *VpcQuotaUsage      `json:"quotaUsage"`
 }
 
 // Condition describes the state of an object at a certain point.
@@ -562,3 +787,210 @@ type HtbQosList struct {
 
 	Items []HtbQos `json:"items"`
 }
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient:nonNamespaced
+
+type IPPool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   IPPoolSpec   `json:"spec"`
+	Status IPPoolStatus `json:"status,omitempty"`
+}
+
+type IPPoolSpec struct {
+	Subnet string `json:"subnet"`
+	// IPs is a list of addresses in the subnet reserved by this pool,
+	// each item can be a single IP, an IP range like 10.0.0.10..10.0.0.20 or a CIDR
+	IPs        []string              `json:"ips"`
+	Namespaces []string              `json:"namespaces,omitempty"`
+	Selector   *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+type IPPoolStatus struct {
+	V4AvailableIPs float64 `json:"v4AvailableIPs"`
+	V4UsingIPs     float64 `json:"v4UsingIPs"`
+	V6AvailableIPs float64 `json:"v6AvailableIPs"`
+	V6UsingIPs     float64 `json:"v6UsingIPs"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type IPPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []IPPool `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient:nonNamespaced
+
+type IPBlock struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   IPBlockSpec   `json:"spec"`
+	Status IPBlockStatus `json:"status,omitempty"`
+}
+
+type IPBlockSpec struct {
+	Subnet   string `json:"subnet"`
+	NodeName string `json:"nodeName"`
+	// IPs is a list of addresses in the subnet leased to the node,
+	// each item can be a single IP or an IP range like 10.0.0.10..10.0.0.20
+	IPs []string `json:"ips"`
+}
+
+type IPBlockStatus struct {
+	V4AvailableIPs float64 `json:"v4AvailableIPs"`
+	V4UsingIPs     float64 `json:"v4UsingIPs"`
+	V6AvailableIPs float64 `json:"v6AvailableIPs"`
+	V6UsingIPs     float64 `json:"v6UsingIPs"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type IPBlockList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []IPBlock `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient:nonNamespaced
+// +resourceName=mirror-sessions
+
+type MirrorSession struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MirrorSessionSpec   `json:"spec"`
+	Status MirrorSessionStatus `json:"status,omitempty"`
+}
+
+type MirrorSessionSpec struct {
+	// Pods in the subnets, in the namespaces or matching the selector are mirrored
+	Subnets     []string              `json:"subnets,omitempty"`
+	Namespaces  []string              `json:"namespaces,omitempty"`
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+	// Direction is relative to the pod, ingress mirrors packets received by the pod
+	// and egress mirrors packets sent by the pod, defaults to both
+	Direction string `json:"direction,omitempty"`
+	// Snaplen truncates mirrored packets to the length if it is not zero
+	Snaplen     int               `json:"snaplen,omitempty"`
+	Destination MirrorDestination `json:"destination"`
+}
+
+type MirrorDestination struct {
+	Type     string `json:"type"`
+	RemoteIP string `json:"remoteIP"`
+	// Key is the gre key or the erspan session id
+	Key           int `json:"key,omitempty"`
+	ERSPANVersion int `json:"erspanVersion,omitempty"`
+}
+
+type MirrorSessionStatus struct {
+	// Nodes is keyed by node name and every kube-ovn-cni only updates its own node
+	Nodes map[string]MirrorSessionNodeStatus `json:"nodes,omitempty"`
+}
+
+type MirrorSessionNodeStatus struct {
+	Ready          bool        `json:"ready"`
+	Ports          int         `json:"ports"`
+	Message        string      `json:"message"`
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type MirrorSessionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []MirrorSession `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient:nonNamespaced
+// +resourceName=subnet-templates
+
+type SubnetTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SubnetTemplateSpec   `json:"spec"`
+	Status SubnetTemplateStatus `json:"status,omitempty"`
+}
+
+type SubnetTemplateSpec struct {
+	// Supernet is carved into blocks of PrefixLength, one for each namespace matching NamespaceSelector
+	Supernet          string                `json:"supernet"`
+	PrefixLength      int                   `json:"prefixLength"`
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// Template is the spec of the carved subnets, cidrBlock, gateway, excludeIps and namespaces are ignored
+	Template SubnetSpec `json:"template,omitempty"`
+}
+
+type SubnetTemplateStatus struct {
+	Allocations []SubnetTemplateAllocation `json:"allocations"`
+}
+
+type SubnetTemplateAllocation struct {
+	Namespace string `json:"namespace"`
+	Subnet    string `json:"subnet"`
+	CIDRBlock string `json:"cidrBlock"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type SubnetTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []SubnetTemplate `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient:nonNamespaced
+// +resourceName=dns-records
+
+type DNSRecord struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DNSRecordSpec   `json:"spec"`
+	Status DNSRecordStatus `json:"status,omitempty"`
+}
+
+type DNSRecordSpec struct {
+	// Vpc is the vpc whose subnets resolve the records, the default vpc is used if it is empty
+	Vpc      string `json:"vpc,omitempty"`
+	Hostname string `json:"hostname"`
+	// IPs are the addresses of the A and AAAA records of the hostname
+	IPs []string `json:"ips"`
+	// EnablePTR adds PTR records which resolve the ips to the hostname
+	// +optional
+	EnablePTR bool `json:"enablePtr,omitempty"`
+}
+
+type DNSRecordStatus struct {
+	// LogicalSwitches is the logical switches of the vpc which the records are set to
+	LogicalSwitches []string `json:"logicalSwitches"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type DNSRecordList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []DNSRecord `json:"items"`
+}
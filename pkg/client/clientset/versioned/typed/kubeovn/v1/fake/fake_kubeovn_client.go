@@ -28,6 +28,10 @@ type FakeKubeovnV1 struct {
 	*testing.Fake
 }
 
+func (c *FakeKubeovnV1) DNSRecords() v1.DNSRecordInterface {
+	return &FakeDNSRecords{c}
+}
+
 func (c *FakeKubeovnV1) HtbQoses() v1.HtbQosInterface {
 	return &FakeHtbQoses{c}
 }
@@ -36,6 +40,18 @@ func (c *FakeKubeovnV1) IPs() v1.IPInterface {
 	return &FakeIPs{c}
 }
 
+func (c *FakeKubeovnV1) IPBlocks() v1.IPBlockInterface {
+	return &FakeIPBlocks{c}
+}
+
+func (c *FakeKubeovnV1) IPPools() v1.IPPoolInterface {
+	return &FakeIPPools{c}
+}
+
+func (c *FakeKubeovnV1) MirrorSessions() v1.MirrorSessionInterface {
+	return &FakeMirrorSessions{c}
+}
+
 func (c *FakeKubeovnV1) ProviderNetworks() v1.ProviderNetworkInterface {
 	return &FakeProviderNetworks{c}
 }
@@ -48,6 +64,10 @@ func (c *FakeKubeovnV1) Subnets() v1.SubnetInterface {
 	return &FakeSubnets{c}
 }
 
+func (c *FakeKubeovnV1) SubnetTemplates() v1.SubnetTemplateInterface {
+	return &FakeSubnetTemplates{c}
+}
+
 func (c *FakeKubeovnV1) Vlans() v1.VlanInterface {
 	return &FakeVlans{c}
 }
@@ -18,16 +18,26 @@ limitations under the License.
 
 package v1
 
+type DNSRecordExpansion interface{}
+
 type HtbQosExpansion interface{}
 
 type IPExpansion interface{}
 
+type IPBlockExpansion interface{}
+
+type IPPoolExpansion interface{}
+
+type MirrorSessionExpansion interface{}
+
 type ProviderNetworkExpansion interface{}
 
 type SecurityGroupExpansion interface{}
 
 type SubnetExpansion interface{}
 
+type SubnetTemplateExpansion interface{}
+
 type VlanExpansion interface{}
 
 type VpcExpansion interface{}
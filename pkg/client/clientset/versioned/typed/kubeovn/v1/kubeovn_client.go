@@ -28,11 +28,16 @@ import (
 
 type KubeovnV1Interface interface {
 	RESTClient() rest.Interface
+	DNSRecordsGetter
 	HtbQosesGetter
 	IPsGetter
+	IPBlocksGetter
+	IPPoolsGetter
+	MirrorSessionsGetter
 	ProviderNetworksGetter
 	SecurityGroupsGetter
 	SubnetsGetter
+	SubnetTemplatesGetter
 	VlansGetter
 	VpcsGetter
 	VpcNatGatewaysGetter
@@ -43,6 +48,10 @@ type KubeovnV1Client struct {
 	restClient rest.Interface
 }
 
+func (c *KubeovnV1Client) DNSRecords() DNSRecordInterface {
+	return newDNSRecords(c)
+}
+
 func (c *KubeovnV1Client) HtbQoses() HtbQosInterface {
 	return newHtbQoses(c)
 }
@@ -51,6 +60,18 @@ func (c *KubeovnV1Client) IPs() IPInterface {
 	return newIPs(c)
 }
 
+func (c *KubeovnV1Client) IPBlocks() IPBlockInterface {
+	return newIPBlocks(c)
+}
+
+func (c *KubeovnV1Client) IPPools() IPPoolInterface {
+	return newIPPools(c)
+}
+
+func (c *KubeovnV1Client) MirrorSessions() MirrorSessionInterface {
+	return newMirrorSessions(c)
+}
+
 func (c *KubeovnV1Client) ProviderNetworks() ProviderNetworkInterface {
 	return newProviderNetworks(c)
 }
@@ -63,6 +84,10 @@ func (c *KubeovnV1Client) Subnets() SubnetInterface {
 	return newSubnets(c)
 }
 
+func (c *KubeovnV1Client) SubnetTemplates() SubnetTemplateInterface {
+	return newSubnetTemplates(c)
+}
+
 func (c *KubeovnV1Client) Vlans() VlanInterface {
 	return newVlans(c)
 }
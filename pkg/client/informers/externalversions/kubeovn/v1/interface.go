@@ -24,16 +24,26 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// DNSRecords returns a DNSRecordInformer.
+	DNSRecords() DNSRecordInformer
 	// HtbQoses returns a HtbQosInformer.
 	HtbQoses() HtbQosInformer
 	// IPs returns a IPInformer.
 	IPs() IPInformer
+	// IPBlocks returns a IPBlockInformer.
+	IPBlocks() IPBlockInformer
+	// IPPools returns a IPPoolInformer.
+	IPPools() IPPoolInformer
+	// MirrorSessions returns a MirrorSessionInformer.
+	MirrorSessions() MirrorSessionInformer
 	// ProviderNetworks returns a ProviderNetworkInformer.
 	ProviderNetworks() ProviderNetworkInformer
 	// SecurityGroups returns a SecurityGroupInformer.
 	SecurityGroups() SecurityGroupInformer
 	// Subnets returns a SubnetInformer.
 	Subnets() SubnetInformer
+	// SubnetTemplates returns a SubnetTemplateInformer.
+	SubnetTemplates() SubnetTemplateInformer
 	// Vlans returns a VlanInformer.
 	Vlans() VlanInformer
 	// Vpcs returns a VpcInformer.
@@ -53,6 +63,11 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// DNSRecords returns a DNSRecordInformer.
+func (v *version) DNSRecords() DNSRecordInformer {
+	return &dNSRecordInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // HtbQoses returns a HtbQosInformer.
 func (v *version) HtbQoses() HtbQosInformer {
 	return &htbQosInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
@@ -63,6 +78,21 @@ func (v *version) IPs() IPInformer {
 	return &iPInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
 
+// IPBlocks returns a IPBlockInformer.
+func (v *version) IPBlocks() IPBlockInformer {
+	return &iPBlockInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// IPPools returns a IPPoolInformer.
+func (v *version) IPPools() IPPoolInformer {
+	return &iPPoolInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// MirrorSessions returns a MirrorSessionInformer.
+func (v *version) MirrorSessions() MirrorSessionInformer {
+	return &mirrorSessionInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // ProviderNetworks returns a ProviderNetworkInformer.
 func (v *version) ProviderNetworks() ProviderNetworkInformer {
 	return &providerNetworkInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
@@ -78,6 +108,11 @@ func (v *version) Subnets() SubnetInformer {
 	return &subnetInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
 
+// SubnetTemplates returns a SubnetTemplateInformer.
+func (v *version) SubnetTemplates() SubnetTemplateInformer {
+	return &subnetTemplateInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // Vlans returns a VlanInformer.
 func (v *version) Vlans() VlanInformer {
 	return &vlanInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
@@ -18,6 +18,10 @@ limitations under the License.
 
 package v1
 
+// DNSRecordListerExpansion allows custom methods to be added to
+// DNSRecordLister.
+type DNSRecordListerExpansion interface{}
+
 // HtbQosListerExpansion allows custom methods to be added to
 // HtbQosLister.
 type HtbQosListerExpansion interface{}
@@ -26,6 +30,18 @@ type HtbQosListerExpansion interface{}
 // IPLister.
 type IPListerExpansion interface{}
 
+// IPBlockListerExpansion allows custom methods to be added to
+// IPBlockLister.
+type IPBlockListerExpansion interface{}
+
+// IPPoolListerExpansion allows custom methods to be added to
+// IPPoolLister.
+type IPPoolListerExpansion interface{}
+
+// MirrorSessionListerExpansion allows custom methods to be added to
+// MirrorSessionLister.
+type MirrorSessionListerExpansion interface{}
+
 // ProviderNetworkListerExpansion allows custom methods to be added to
 // ProviderNetworkLister.
 type ProviderNetworkListerExpansion interface{}
@@ -38,6 +54,10 @@ type SecurityGroupListerExpansion interface{}
 // SubnetLister.
 type SubnetListerExpansion interface{}
 
+// SubnetTemplateListerExpansion allows custom methods to be added to
+// SubnetTemplateLister.
+type SubnetTemplateListerExpansion interface{}
+
 // VlanListerExpansion allows custom methods to be added to
 // VlanLister.
 type VlanListerExpansion interface{}
@@ -70,6 +70,20 @@ type Configuration struct {
 	EnableNP          bool
 	EnableExternalVpc bool
 	EnableMcast       bool
+
+	EnableGatewayBFD     bool
+	GatewayBFDInterval   int
+	GatewayBFDDetectMult int
+
+	IPAMCheckpointInterval time.Duration
+	IPAMCheckpointMaxAge   time.Duration
+
+	IPAMAuditInterval time.Duration
+	IPAMAuditRepair   bool
+
+	IPAMAPIPort     int
+	IPAMAPICertFile string
+	IPAMAPIKeyFile  string
 }
 
 // ParseFlags parses cmd args then init kubeclient and conf
@@ -113,6 +127,20 @@ func ParseFlags() (*Configuration, error) {
 		argEnableNP             = pflag.Bool("enable-np", true, "Enable network policy support")
 		argEnableExternalVpc    = pflag.Bool("enable-external-vpc", true, "Enable external vpc support")
 		argEnableMcast          = pflag.Bool("enable-multicast", false, "Enable multicast support")
+
+		argEnableGatewayBFD     = pflag.Bool("enable-gateway-bfd", false, "Detect failure of centralized gateway nodes by bfd sessions instead of ping")
+		argGatewayBFDInterval   = pflag.Int("gateway-bfd-interval", 100, "The interval in milliseconds of bfd packets sent to centralized gateway nodes and next hops of vpc static routes")
+		argGatewayBFDDetectMult = pflag.Int("gateway-bfd-detect-mult", 3, "The number of lost bfd packets before a centralized gateway node or a next hop of vpc static routes is considered down")
+
+		argIPAMCheckpointInterval = pflag.Duration("ipam-checkpoint-interval", 0, "The interval to save IPAM state into a configmap which is used to speed up startup, 0 to disable")
+		argIPAMCheckpointMaxAge   = pflag.Duration("ipam-checkpoint-max-age", time.Hour, "IPAM is rebuilt from scratch if the checkpoint is older than this")
+
+		argIPAMAuditInterval = pflag.Duration("ipam-audit-interval", 10*time.Minute, "The interval to cross-check IPAM, IP CRs and OVN logical switch ports, 0 to disable")
+		argIPAMAuditRepair   = pflag.Bool("ipam-audit-repair", false, "Repair safe discrepancies found by IPAM audit, e.g. IP CRs without pod and logical switch ports without IP CR")
+
+		argIPAMAPIPort     = pflag.Int("ipam-api-port", 0, "The port to serve the IPAM query API, 0 to disable")
+		argIPAMAPICertFile = pflag.String("ipam-api-tls-cert-file", "", "The TLS certificate file of the IPAM query API, plain HTTP is served on 127.0.0.1 only if not set")
+		argIPAMAPIKeyFile  = pflag.String("ipam-api-tls-key-file", "", "The TLS private key file of the IPAM query API")
 	)
 
 	klogFlags := flag.NewFlagSet("klog", flag.ExitOnError)
@@ -167,12 +195,26 @@ func ParseFlags() (*Configuration, error) {
 		EnableNP:                      *argEnableNP,
 		EnableExternalVpc:             *argEnableExternalVpc,
 		EnableMcast:                   *argEnableMcast,
+		EnableGatewayBFD:              *argEnableGatewayBFD,
+		GatewayBFDInterval:            *argGatewayBFDInterval,
+		GatewayBFDDetectMult:          *argGatewayBFDDetectMult,
+		IPAMCheckpointInterval:        *argIPAMCheckpointInterval,
+		IPAMCheckpointMaxAge:          *argIPAMCheckpointMaxAge,
+		IPAMAuditInterval:             *argIPAMAuditInterval,
+		IPAMAuditRepair:               *argIPAMAuditRepair,
+		IPAMAPIPort:                   *argIPAMAPIPort,
+		IPAMAPICertFile:               *argIPAMAPICertFile,
+		IPAMAPIKeyFile:                *argIPAMAPIKeyFile,
 	}
 
 	if config.NetworkType == util.NetworkTypeVlan && config.DefaultHostInterface == "" {
 		return nil, fmt.Errorf("no host nic for vlan")
 	}
 
+	if (config.IPAMAPICertFile == "") != (config.IPAMAPIKeyFile == "") {
+		return nil, fmt.Errorf("ipam-api-tls-cert-file and ipam-api-tls-key-file must be set together")
+	}
+
 	if config.DefaultGateway == "" {
 		gw, err := util.GetGwByCidr(config.DefaultCIDR)
 		if err != nil {
@@ -3,6 +3,7 @@ package controller
 import (
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/neverlee/keymutex"
@@ -34,12 +35,14 @@ const controllerAgentName = "kube-ovn-controller"
 
 const (
 	logicalSwitchKey      = "ls"
+	logicalSwitchPortKey  = "lsp"
 	logicalRouterKey      = "lr"
 	portGroupKey          = "pg"
 	networkPolicyKey      = "np"
 	sgKey                 = "sg"
 	associatedSgKeyPrefix = "associated_sg_"
 	sgsKey                = "security_groups"
+	dnsRecordKey          = "dns_record"
 )
 
 // Controller is kube-ovn main controller that watch ns/pod/node/svc/ep and operate ovn
@@ -51,15 +54,27 @@ type Controller struct {
 	ovnLegacyClient *ovs.LegacyClient
 	ovnClient       ovs.OvnClient
 	ipam            *ovnipam.IPAM
-
-	podsLister              v1.PodLister
-	podsSynced              cache.InformerSynced
-	addPodQueue             workqueue.RateLimitingInterface
-	deletePodQueue          workqueue.RateLimitingInterface
-	updatePodQueue          workqueue.RateLimitingInterface
-	updatePodSecurityQueue  workqueue.RateLimitingInterface
-	updatePodIPAddressQueue workqueue.RateLimitingInterface
-	podKeyMutex             *keymutex.KeyMutex
+	// set to 1 after ipam is initialized, accessed atomically
+	ipamInitialized int32
+	// serializes namespace ip quota checks with address allocation
+	ipQuotaMutex sync.Mutex
+	// blocks of subnet templates are carved one at a time
+	subnetTemplateMutex sync.Mutex
+	// subnets carved just now which may not be seen by the lister yet, guarded by subnetTemplateMutex
+	carvedSubnets map[string]*kubeovnv1.Subnet
+	// discrepancies found by the last ipam audit, a discrepancy is repaired
+	// only if it is found by two consecutive audits
+	lastAuditDiscrepancies map[string]bool
+
+	podsLister               v1.PodLister
+	podsSynced               cache.InformerSynced
+	addPodQueue              workqueue.RateLimitingInterface
+	deletePodQueue           workqueue.RateLimitingInterface
+	updatePodQueue           workqueue.RateLimitingInterface
+	updatePodSecurityQueue   workqueue.RateLimitingInterface
+	updatePodIPAddressQueue  workqueue.RateLimitingInterface
+	upgradePodDualStackQueue workqueue.RateLimitingInterface
+	podKeyMutex              *keymutex.KeyMutex
 
 	vpcsLister           kubeovnlister.VpcLister
 	vpcSynced            cache.InformerSynced
@@ -87,9 +102,33 @@ type Controller struct {
 	deleteRouteQueue        workqueue.RateLimitingInterface
 	updateSubnetStatusQueue workqueue.RateLimitingInterface
 	syncVirtualPortsQueue   workqueue.RateLimitingInterface
-
-	ipsLister kubeovnlister.IPLister
-	ipSynced  cache.InformerSynced
+	migrateSubnetQueue      workqueue.RateLimitingInterface
+
+	ipsLister                kubeovnlister.IPLister
+	ipSynced                 cache.InformerSynced
+	updateIPDHCPOptionsQueue workqueue.RateLimitingInterface
+
+	ipPoolsLister           kubeovnlister.IPPoolLister
+	ipPoolSynced            cache.InformerSynced
+	addOrUpdateIPPoolQueue  workqueue.RateLimitingInterface
+	delIPPoolQueue          workqueue.RateLimitingInterface
+	updateIPPoolStatusQueue workqueue.RateLimitingInterface
+
+	ipBlocksLister           kubeovnlister.IPBlockLister
+	ipBlockSynced            cache.InformerSynced
+	addOrUpdateIPBlockQueue  workqueue.RateLimitingInterface
+	delIPBlockQueue          workqueue.RateLimitingInterface
+	updateIPBlockStatusQueue workqueue.RateLimitingInterface
+	leaseIPBlockQueue        workqueue.RateLimitingInterface
+
+	subnetTemplatesLister           kubeovnlister.SubnetTemplateLister
+	subnetTemplateSynced            cache.InformerSynced
+	updateSubnetTemplateStatusQueue workqueue.RateLimitingInterface
+
+	dnsRecordsLister          kubeovnlister.DNSRecordLister
+	dnsRecordSynced           cache.InformerSynced
+	addOrUpdateDNSRecordQueue workqueue.RateLimitingInterface
+	delDNSRecordQueue         workqueue.RateLimitingInterface
 
 	vlansLister kubeovnlister.VlanLister
 	vlanSynced  cache.InformerSynced
@@ -101,9 +140,10 @@ type Controller struct {
 	delVlanQueue    workqueue.RateLimitingInterface
 	updateVlanQueue workqueue.RateLimitingInterface
 
-	namespacesLister  v1.NamespaceLister
-	namespacesSynced  cache.InformerSynced
-	addNamespaceQueue workqueue.RateLimitingInterface
+	namespacesLister     v1.NamespaceLister
+	namespacesSynced     cache.InformerSynced
+	addNamespaceQueue    workqueue.RateLimitingInterface
+	deleteNamespaceQueue workqueue.RateLimitingInterface
 
 	nodesLister     v1.NodeLister
 	nodesSynced     cache.InformerSynced
@@ -168,6 +208,10 @@ func NewController(config *Configuration) *Controller {
 	vpcNatGatewayInformer := kubeovnInformerFactory.Kubeovn().V1().VpcNatGateways()
 	subnetInformer := kubeovnInformerFactory.Kubeovn().V1().Subnets()
 	ipInformer := kubeovnInformerFactory.Kubeovn().V1().IPs()
+	ipPoolInformer := kubeovnInformerFactory.Kubeovn().V1().IPPools()
+	ipBlockInformer := kubeovnInformerFactory.Kubeovn().V1().IPBlocks()
+	subnetTemplateInformer := kubeovnInformerFactory.Kubeovn().V1().SubnetTemplates()
+	dnsRecordInformer := kubeovnInformerFactory.Kubeovn().V1().DNSRecords()
 	vlanInformer := kubeovnInformerFactory.Kubeovn().V1().Vlans()
 	providerNetworkInformer := kubeovnInformerFactory.Kubeovn().V1().ProviderNetworks()
 	sgInformer := kubeovnInformerFactory.Kubeovn().V1().SecurityGroups()
@@ -211,9 +255,33 @@ func NewController(config *Configuration) *Controller {
 		deleteRouteQueue:        workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "DeleteRoute"),
 		updateSubnetStatusQueue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "UpdateSubnetStatus"),
 		syncVirtualPortsQueue:   workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "SyncVirtualPort"),
-
-		ipsLister: ipInformer.Lister(),
-		ipSynced:  ipInformer.Informer().HasSynced,
+		migrateSubnetQueue:      workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "MigrateSubnet"),
+
+		ipsLister:                ipInformer.Lister(),
+		ipSynced:                 ipInformer.Informer().HasSynced,
+		updateIPDHCPOptionsQueue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "UpdateIPDHCPOptions"),
+
+		ipPoolsLister:           ipPoolInformer.Lister(),
+		ipPoolSynced:            ipPoolInformer.Informer().HasSynced,
+		addOrUpdateIPPoolQueue:  workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "AddOrUpdateIPPool"),
+		delIPPoolQueue:          workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "DeleteIPPool"),
+		updateIPPoolStatusQueue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "UpdateIPPoolStatus"),
+
+		ipBlocksLister:           ipBlockInformer.Lister(),
+		ipBlockSynced:            ipBlockInformer.Informer().HasSynced,
+		addOrUpdateIPBlockQueue:  workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "AddOrUpdateIPBlock"),
+		delIPBlockQueue:          workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "DeleteIPBlock"),
+		updateIPBlockStatusQueue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "UpdateIPBlockStatus"),
+		leaseIPBlockQueue:        workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "LeaseIPBlock"),
+
+		subnetTemplatesLister:           subnetTemplateInformer.Lister(),
+		subnetTemplateSynced:            subnetTemplateInformer.Informer().HasSynced,
+		updateSubnetTemplateStatusQueue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "UpdateSubnetTemplateStatus"),
+
+		dnsRecordsLister:          dnsRecordInformer.Lister(),
+		dnsRecordSynced:           dnsRecordInformer.Informer().HasSynced,
+		addOrUpdateDNSRecordQueue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "AddOrUpdateDNSRecord"),
+		delDNSRecordQueue:         workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "DeleteDNSRecord"),
 
 		vlansLister:     vlanInformer.Lister(),
 		vlanSynced:      vlanInformer.Informer().HasSynced,
@@ -224,18 +292,20 @@ func NewController(config *Configuration) *Controller {
 		providerNetworksLister: providerNetworkInformer.Lister(),
 		providerNetworkSynced:  providerNetworkInformer.Informer().HasSynced,
 
-		podsLister:              podInformer.Lister(),
-		podsSynced:              podInformer.Informer().HasSynced,
-		addPodQueue:             workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "AddPod"),
-		deletePodQueue:          workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "DeletePod"),
-		updatePodQueue:          workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "UpdatePod"),
-		updatePodSecurityQueue:  workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "UpdatePodSecurity"),
-		updatePodIPAddressQueue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "UpdatePodIPAddress"),
-		podKeyMutex:             keymutex.New(97),
-
-		namespacesLister:  namespaceInformer.Lister(),
-		namespacesSynced:  namespaceInformer.Informer().HasSynced,
-		addNamespaceQueue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "AddNamespace"),
+		podsLister:               podInformer.Lister(),
+		podsSynced:               podInformer.Informer().HasSynced,
+		addPodQueue:              workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "AddPod"),
+		deletePodQueue:           workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "DeletePod"),
+		updatePodQueue:           workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "UpdatePod"),
+		updatePodSecurityQueue:   workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "UpdatePodSecurity"),
+		updatePodIPAddressQueue:  workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "UpdatePodIPAddress"),
+		upgradePodDualStackQueue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "UpgradePodDualStack"),
+		podKeyMutex:              keymutex.New(97),
+
+		namespacesLister:     namespaceInformer.Lister(),
+		namespacesSynced:     namespaceInformer.Informer().HasSynced,
+		addNamespaceQueue:    workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "AddNamespace"),
+		deleteNamespaceQueue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "DeleteNamespace"),
 
 		nodesLister:     nodeInformer.Lister(),
 		nodesSynced:     nodeInformer.Informer().HasSynced,
@@ -322,6 +392,29 @@ func NewController(config *Configuration) *Controller {
 		DeleteFunc: controller.enqueueAddOrDelIP,
 	})
 
+	ipPoolInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    controller.enqueueAddIPPool,
+		UpdateFunc: controller.enqueueUpdateIPPool,
+		DeleteFunc: controller.enqueueDeleteIPPool,
+	})
+
+	ipBlockInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    controller.enqueueAddIPBlock,
+		UpdateFunc: controller.enqueueUpdateIPBlock,
+		DeleteFunc: controller.enqueueDeleteIPBlock,
+	})
+
+	subnetTemplateInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    controller.enqueueAddSubnetTemplate,
+		UpdateFunc: controller.enqueueUpdateSubnetTemplate,
+	})
+
+	dnsRecordInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    controller.enqueueAddDNSRecord,
+		UpdateFunc: controller.enqueueUpdateDNSRecord,
+		DeleteFunc: controller.enqueueDeleteDNSRecord,
+	})
+
 	vlanInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc:    controller.enqueueAddVlan,
 		DeleteFunc: controller.enqueueDelVlan,
@@ -361,6 +454,10 @@ func (c *Controller) Run(config *Configuration, stopCh <-chan struct{}) {
 	defer c.shutdown()
 	klog.Info("Starting OVN controller")
 
+	if c.config.IPAMAPIPort > 0 {
+		go c.runIPAMServer()
+	}
+
 	// wait for becoming a leader
 	c.leaderElection()
 
@@ -376,7 +473,7 @@ func (c *Controller) Run(config *Configuration, stopCh <-chan struct{}) {
 	klog.Info("Waiting for informer caches to sync")
 	cacheSyncs := []cache.InformerSynced{
 		c.vpcNatGatewaySynced, c.vpcSynced, c.subnetSynced, c.ipSynced,
-		c.vlanSynced, c.podsSynced, c.namespacesSynced, c.nodesSynced,
+		c.ipPoolSynced, c.ipBlockSynced, c.subnetTemplateSynced, c.dnsRecordSynced, c.vlanSynced, c.podsSynced, c.namespacesSynced, c.nodesSynced,
 		c.serviceSynced, c.endpointsSynced, c.configMapsSynced,
 	}
 	if c.config.EnableNP {
@@ -401,6 +498,7 @@ func (c *Controller) Run(config *Configuration, stopCh <-chan struct{}) {
 	if err := c.InitIPAM(); err != nil {
 		klog.Fatalf("failed to init ipam: %v", err)
 	}
+	atomic.StoreInt32(&c.ipamInitialized, 1)
 
 	if err := c.initNodeChassis(); err != nil {
 		klog.Errorf("failed to init node chassis: %v", err)
@@ -446,14 +544,33 @@ func (c *Controller) shutdown() {
 	c.deletePodQueue.ShutDown()
 	c.updatePodQueue.ShutDown()
 	c.updatePodSecurityQueue.ShutDown()
+	c.upgradePodDualStackQueue.ShutDown()
 
 	c.addNamespaceQueue.ShutDown()
+	c.deleteNamespaceQueue.ShutDown()
 
 	c.addOrUpdateSubnetQueue.ShutDown()
 	c.deleteSubnetQueue.ShutDown()
 	c.deleteRouteQueue.ShutDown()
 	c.updateSubnetStatusQueue.ShutDown()
 	c.syncVirtualPortsQueue.ShutDown()
+	c.migrateSubnetQueue.ShutDown()
+
+	c.updateIPDHCPOptionsQueue.ShutDown()
+
+	c.addOrUpdateIPPoolQueue.ShutDown()
+	c.delIPPoolQueue.ShutDown()
+	c.updateIPPoolStatusQueue.ShutDown()
+
+	c.addOrUpdateIPBlockQueue.ShutDown()
+	c.delIPBlockQueue.ShutDown()
+	c.updateIPBlockStatusQueue.ShutDown()
+	c.leaseIPBlockQueue.ShutDown()
+
+	c.updateSubnetTemplateStatusQueue.ShutDown()
+
+	c.addOrUpdateDNSRecordQueue.ShutDown()
+	c.delDNSRecordQueue.ShutDown()
 
 	c.addNodeQueue.ShutDown()
 	c.updateNodeQueue.ShutDown()
@@ -507,6 +624,7 @@ func (c *Controller) startWorkers(stopCh <-chan struct{}) {
 	go wait.Until(c.runAddSubnetWorker, time.Second, stopCh)
 	go wait.Until(c.runAddVlanWorker, time.Second, stopCh)
 	go wait.Until(c.runAddNamespaceWorker, time.Second, stopCh)
+	go wait.Until(c.runDeleteNamespaceWorker, time.Second, stopCh)
 	for {
 		klog.Infof("wait for %s and %s ready", c.config.DefaultLogicalSwitch, c.config.NodeSwitch)
 		time.Sleep(3 * time.Second)
@@ -564,11 +682,29 @@ func (c *Controller) startWorkers(stopCh <-chan struct{}) {
 		go wait.Until(c.runUpdatePodWorker, time.Second, stopCh)
 		go wait.Until(c.runUpdatePodSecurityWorker, time.Second, stopCh)
 		go wait.Until(c.runUpdatePodIPAddressWorker, time.Second, stopCh)
+		go wait.Until(c.runUpgradePodDualStackWorker, time.Second, stopCh)
 
 		go wait.Until(c.runDeleteSubnetWorker, time.Second, stopCh)
 		go wait.Until(c.runDeleteRouteWorker, time.Second, stopCh)
 		go wait.Until(c.runUpdateSubnetStatusWorker, time.Second, stopCh)
 		go wait.Until(c.runSyncVirtualPortsWorker, time.Second, stopCh)
+		go wait.Until(c.runMigrateSubnetWorker, time.Second, stopCh)
+
+		go wait.Until(c.runUpdateIPDHCPOptionsWorker, time.Second, stopCh)
+
+		go wait.Until(c.runAddOrUpdateIPPoolWorker, time.Second, stopCh)
+		go wait.Until(c.runDelIPPoolWorker, time.Second, stopCh)
+		go wait.Until(c.runUpdateIPPoolStatusWorker, time.Second, stopCh)
+
+		go wait.Until(c.runAddOrUpdateIPBlockWorker, time.Second, stopCh)
+		go wait.Until(c.runDelIPBlockWorker, time.Second, stopCh)
+		go wait.Until(c.runUpdateIPBlockStatusWorker, time.Second, stopCh)
+		go wait.Until(c.runLeaseIPBlockWorker, time.Second, stopCh)
+
+		go wait.Until(c.runUpdateSubnetTemplateStatusWorker, time.Second, stopCh)
+
+		go wait.Until(c.runAddOrUpdateDNSRecordWorker, time.Second, stopCh)
+		go wait.Until(c.runDelDNSRecordWorker, time.Second, stopCh)
 
 		if c.config.EnableLb {
 			go wait.Until(c.runUpdateServiceWorker, time.Second, stopCh)
@@ -615,7 +751,20 @@ func (c *Controller) startWorkers(stopCh <-chan struct{}) {
 	}
 
 	go wait.Until(c.resyncSubnetMetrics, 30*time.Second, stopCh)
-	go wait.Until(c.CheckGatewayReady, 5*time.Second, stopCh)
+	if c.config.IPAMCheckpointInterval > 0 {
+		go wait.Until(c.saveIPAMCheckpoint, c.config.IPAMCheckpointInterval, stopCh)
+	}
+	if c.config.IPAMAuditInterval > 0 {
+		go wait.Until(c.auditIPAM, c.config.IPAMAuditInterval, stopCh)
+	}
+	if c.config.EnableGatewayBFD {
+		go wait.Until(c.CheckGatewayBFD, time.Second, stopCh)
+	} else {
+		go wait.Until(c.CheckGatewayReady, 5*time.Second, stopCh)
+	}
+	go wait.Until(c.CheckVpcStaticRouteBFD, 5*time.Second, stopCh)
+	go wait.Until(c.CheckVpcPeerings, 5*time.Second, stopCh)
+	go wait.Until(c.CheckVpcQuotaUsage, 10*time.Second, stopCh)
 
 	if c.config.EnableNP {
 		go wait.Until(c.CheckNodePortGroup, 10*time.Second, stopCh)
@@ -0,0 +1,233 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	listerv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	kubeovnfake "github.com/kubeovn/kube-ovn/pkg/client/clientset/versioned/fake"
+	kubeovnlister "github.com/kubeovn/kube-ovn/pkg/client/listers/kubeovn/v1"
+	ovnipam "github.com/kubeovn/kube-ovn/pkg/ipam"
+	"github.com/kubeovn/kube-ovn/pkg/ovs"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+)
+
+// fakeOvnClient keeps logical switch ports, bfd sessions and peer router ports in memory,
+// calling methods which are not faked panics
+type fakeOvnClient struct {
+	ovs.OvnClient
+	lsps      map[string]ovnnb.LogicalSwitchPort
+	bfd       map[string]ovnnb.BFD
+	peerPorts []string
+}
+
+func newFakeOvnClient() *fakeOvnClient {
+	return &fakeOvnClient{
+		lsps: map[string]ovnnb.LogicalSwitchPort{},
+		bfd:  map[string]ovnnb.BFD{},
+	}
+}
+
+func (c *fakeOvnClient) ListLogicalSwitch(needVendorFilter bool, filter func(ls *ovnnb.LogicalSwitch) bool) ([]ovnnb.LogicalSwitch, error) {
+	return nil, nil
+}
+
+func (c *fakeOvnClient) ListNormalLogicalSwitchPorts(needVendorFilter bool, externalIDs map[string]string) ([]ovnnb.LogicalSwitchPort, error) {
+	lsps := make([]ovnnb.LogicalSwitchPort, 0, len(c.lsps))
+	for _, lsp := range c.lsps {
+		lsps = append(lsps, lsp)
+	}
+	return lsps, nil
+}
+
+func (c *fakeOvnClient) ListLogicalSwitchPortsWithLegacyExternalIDs() ([]ovnnb.LogicalSwitchPort, error) {
+	return nil, nil
+}
+
+func (c *fakeOvnClient) GetLogicalSwitchPort(lspName string, ignoreNotFound bool) (*ovnnb.LogicalSwitchPort, error) {
+	if lsp, ok := c.lsps[lspName]; ok {
+		return &lsp, nil
+	}
+	return nil, nil
+}
+
+func (c *fakeOvnClient) SetLogicalSwitchPortExternalIds(lspName string, externalIds map[string]string) error {
+	return nil
+}
+
+func (c *fakeOvnClient) DeleteLogicalSwitchPort(lspName string) error {
+	delete(c.lsps, lspName)
+	return nil
+}
+
+func (c *fakeOvnClient) GetLogicalRouterPort(lrpName string, ignoreNotFound bool) (*ovnnb.LogicalRouterPort, error) {
+	return nil, nil
+}
+
+func (c *fakeOvnClient) CreatePeerRouterPort(localRouter, remoteRouter, localRouterPortIP string) error {
+	c.peerPorts = append(c.peerPorts, fmt.Sprintf("%s-%s", localRouter, remoteRouter))
+	return nil
+}
+
+func (c *fakeOvnClient) CreateBFD(lrpName, dstIP string, minRx, minTx, detectMult int, externalIDs map[string]string) (*ovnnb.BFD, error) {
+	key := fmt.Sprintf("%s/%s", lrpName, dstIP)
+	bfd := ovnnb.BFD{UUID: key, LogicalPort: lrpName, DstIP: dstIP, ExternalIDs: externalIDs}
+	c.bfd[key] = bfd
+	return &bfd, nil
+}
+
+func (c *fakeOvnClient) ListBFDByExternalIDs(externalIDs map[string]string) ([]ovnnb.BFD, error) {
+	var bfdList []ovnnb.BFD
+	for _, bfd := range c.bfd {
+		if bfd.ExternalIDs[logicalRouterKey] == externalIDs[logicalRouterKey] {
+			bfdList = append(bfdList, bfd)
+		}
+	}
+	return bfdList, nil
+}
+
+func (c *fakeOvnClient) DeleteBFD(lrpName, dstIP string) error {
+	delete(c.bfd, fmt.Sprintf("%s/%s", lrpName, dstIP))
+	return nil
+}
+
+func (c *fakeOvnClient) SetLogicalRouterStaticRouteBFD(lrName, routeTable, policy, ipPrefix, nexthop string, bfdUUID *string) error {
+	return nil
+}
+
+// testController is a controller whose listers are backed by indexers of the test objects,
+// the objects are also served by the fake clientsets
+type testController struct {
+	*Controller
+	kubeClient    *k8sfake.Clientset
+	kubeOvnClient *kubeovnfake.Clientset
+	ovnClient     *fakeOvnClient
+	recorder      *record.FakeRecorder
+	indexers      map[string]cache.Indexer
+
+	objects []runtime.Object
+}
+
+type testControllerOption func(*testController)
+
+// withObjects adds the objects to the listers and fake clientsets
+func withObjects(objects ...runtime.Object) testControllerOption {
+	return func(test *testController) {
+		test.objects = append(test.objects, objects...)
+	}
+}
+
+// withKubeClient replaces the fake kube clientset in the configuration,
+// e.g. by a clientset of a fake apiserver for requests the fake clientset does not support
+func withKubeClient(kubeClient kubernetes.Interface) testControllerOption {
+	return func(test *testController) {
+		test.config.KubeClient = kubeClient
+	}
+}
+
+// withConfig updates the configuration of the controller
+func withConfig(update func(config *Configuration)) testControllerOption {
+	return func(test *testController) {
+		update(test.config)
+	}
+}
+
+func newTestController(t *testing.T, opts ...testControllerOption) *testController {
+	test := &testController{
+		ovnClient: newFakeOvnClient(),
+		recorder:  record.NewFakeRecorder(100),
+		indexers:  map[string]cache.Indexer{},
+	}
+	for _, kind := range []string{"Subnet", "IP", "IPPool", "IPBlock", "SubnetTemplate", "Vpc", "VpcNatGateway", "Pod", "Node", "Namespace", "ConfigMap"} {
+		test.indexers[kind] = cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	}
+	test.Controller = &Controller{
+		config:                &Configuration{},
+		recorder:              test.recorder,
+		ipam:                  ovnipam.NewIPAM(),
+		ovnClient:             test.ovnClient,
+		ovnLegacyClient:       &ovs.LegacyClient{},
+		subnetsLister:         kubeovnlister.NewSubnetLister(test.indexers["Subnet"]),
+		ipsLister:             kubeovnlister.NewIPLister(test.indexers["IP"]),
+		ipPoolsLister:         kubeovnlister.NewIPPoolLister(test.indexers["IPPool"]),
+		ipBlocksLister:        kubeovnlister.NewIPBlockLister(test.indexers["IPBlock"]),
+		subnetTemplatesLister: kubeovnlister.NewSubnetTemplateLister(test.indexers["SubnetTemplate"]),
+		vpcsLister:            kubeovnlister.NewVpcLister(test.indexers["Vpc"]),
+		vpcNatGatewayLister:   kubeovnlister.NewVpcNatGatewayLister(test.indexers["VpcNatGateway"]),
+		podsLister:            listerv1.NewPodLister(test.indexers["Pod"]),
+		nodesLister:           listerv1.NewNodeLister(test.indexers["Node"]),
+		namespacesLister:      listerv1.NewNamespaceLister(test.indexers["Namespace"]),
+		configMapsLister:      listerv1.NewConfigMapLister(test.indexers["ConfigMap"]),
+	}
+	for _, opt := range opts {
+		opt(test)
+	}
+
+	var kubeObjects, kubeOvnObjects []runtime.Object
+	for _, obj := range test.objects {
+		test.addObject(t, obj)
+		switch obj.(type) {
+		case *corev1.Pod, *corev1.Node, *corev1.Namespace, *corev1.ConfigMap:
+			kubeObjects = append(kubeObjects, obj)
+		default:
+			kubeOvnObjects = append(kubeOvnObjects, obj)
+		}
+	}
+	test.kubeClient = k8sfake.NewSimpleClientset(kubeObjects...)
+	test.kubeOvnClient = kubeovnfake.NewSimpleClientset(kubeOvnObjects...)
+	if test.config.KubeClient == nil {
+		test.config.KubeClient = test.kubeClient
+	}
+	test.config.KubeOvnClient = test.kubeOvnClient
+	return test
+}
+
+// indexer returns the indexer of the lister for the object
+func (test *testController) indexer(t *testing.T, obj runtime.Object) cache.Indexer {
+	var kind string
+	switch obj.(type) {
+	case *kubeovnv1.Subnet:
+		kind = "Subnet"
+	case *kubeovnv1.IP:
+		kind = "IP"
+	case *kubeovnv1.IPPool:
+		kind = "IPPool"
+	case *kubeovnv1.IPBlock:
+		kind = "IPBlock"
+	case *kubeovnv1.SubnetTemplate:
+		kind = "SubnetTemplate"
+	case *kubeovnv1.Vpc:
+		kind = "Vpc"
+	case *kubeovnv1.VpcNatGateway:
+		kind = "VpcNatGateway"
+	case *corev1.Pod:
+		kind = "Pod"
+	case *corev1.Node:
+		kind = "Node"
+	case *corev1.Namespace:
+		kind = "Namespace"
+	case *corev1.ConfigMap:
+		kind = "ConfigMap"
+	}
+	require.NotEmpty(t, kind, "no lister for %T", obj)
+	return test.indexers[kind]
+}
+
+// addObject adds the object to its lister
+func (test *testController) addObject(t *testing.T, obj runtime.Object) {
+	require.NoError(t, test.indexer(t, obj).Add(obj))
+}
+
+// deleteObject deletes the object from its lister
+func (test *testController) deleteObject(t *testing.T, obj runtime.Object) {
+	require.NoError(t, test.indexer(t, obj).Delete(obj))
+}
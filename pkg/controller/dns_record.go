@@ -0,0 +1,258 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func (c *Controller) enqueueAddDNSRecord(obj interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	var key string
+	var err error
+	if key, err = cache.MetaNamespaceKeyFunc(obj); err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	klog.V(3).Infof("enqueue add dns record %s", key)
+	c.addOrUpdateDNSRecordQueue.Add(key)
+}
+
+func (c *Controller) enqueueUpdateDNSRecord(old, new interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	oldRecord := old.(*kubeovnv1.DNSRecord)
+	newRecord := new.(*kubeovnv1.DNSRecord)
+	if reflect.DeepEqual(oldRecord.Spec, newRecord.Spec) {
+		return
+	}
+
+	var key string
+	var err error
+	if key, err = cache.MetaNamespaceKeyFunc(new); err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	klog.V(3).Infof("enqueue update dns record %s", key)
+	c.addOrUpdateDNSRecordQueue.Add(key)
+}
+
+func (c *Controller) enqueueDeleteDNSRecord(obj interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	var key string
+	var err error
+	if key, err = cache.DeletionHandlingMetaNamespaceKeyFunc(obj); err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	klog.V(3).Infof("enqueue delete dns record %s", key)
+	c.delDNSRecordQueue.Add(key)
+}
+
+// enqueueVpcDNSRecords enqueue dns records of the vpc to update logical switches they are set to
+func (c *Controller) enqueueVpcDNSRecords(vpc string) {
+	records, err := c.dnsRecordsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list dns records, %v", err)
+		return
+	}
+	for _, record := range records {
+		if dnsRecordVpc(record) == vpc {
+			c.addOrUpdateDNSRecordQueue.Add(record.Name)
+		}
+	}
+}
+
+func (c *Controller) runAddOrUpdateDNSRecordWorker() {
+	for c.processNextAddOrUpdateDNSRecordWorkItem() {
+	}
+}
+
+func (c *Controller) runDelDNSRecordWorker() {
+	for c.processNextDeleteDNSRecordWorkItem() {
+	}
+}
+
+func (c *Controller) processNextAddOrUpdateDNSRecordWorkItem() bool {
+	obj, shutdown := c.addOrUpdateDNSRecordQueue.Get()
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.addOrUpdateDNSRecordQueue.Done(obj)
+		var key string
+		var ok bool
+		if key, ok = obj.(string); !ok {
+			c.addOrUpdateDNSRecordQueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+			return nil
+		}
+		if err := c.handleAddOrUpdateDNSRecord(key); err != nil {
+			c.addOrUpdateDNSRecordQueue.AddRateLimited(key)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
+		}
+		c.addOrUpdateDNSRecordQueue.Forget(obj)
+		return nil
+	}(obj)
+
+	if err != nil {
+		utilruntime.HandleError(err)
+		return true
+	}
+	return true
+}
+
+func (c *Controller) processNextDeleteDNSRecordWorkItem() bool {
+	obj, shutdown := c.delDNSRecordQueue.Get()
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.delDNSRecordQueue.Done(obj)
+		var key string
+		var ok bool
+		if key, ok = obj.(string); !ok {
+			c.delDNSRecordQueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+			return nil
+		}
+		if err := c.handleDeleteDNSRecord(key); err != nil {
+			c.delDNSRecordQueue.AddRateLimited(key)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
+		}
+		c.delDNSRecordQueue.Forget(obj)
+		return nil
+	}(obj)
+
+	if err != nil {
+		utilruntime.HandleError(err)
+		return true
+	}
+	return true
+}
+
+func dnsRecordVpc(record *kubeovnv1.DNSRecord) string {
+	if record.Spec.Vpc == "" {
+		return util.DefaultVpc
+	}
+	return record.Spec.Vpc
+}
+
+// dnsRecords generate records of the ovn dns for the dns record, the hostname resolves to all ips
+// and the ips resolve to the hostname if ptr is enabled
+func dnsRecords(record *kubeovnv1.DNSRecord) (map[string]string, error) {
+	hostname := strings.ToLower(strings.TrimSuffix(record.Spec.Hostname, "."))
+	records := map[string]string{hostname: strings.Join(record.Spec.IPs, " ")}
+	if record.Spec.EnablePTR {
+		for _, ip := range record.Spec.IPs {
+			name, err := util.ReverseDNSName(ip)
+			if err != nil {
+				return nil, err
+			}
+			records[name] = hostname
+		}
+	}
+	return records, nil
+}
+
+func (c *Controller) handleAddOrUpdateDNSRecord(key string) error {
+	record, err := c.dnsRecordsLister.Get(key)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if err = util.ValidateDNSRecord(record); err != nil {
+		klog.Errorf("invalid dns record %s, %v", key, err)
+		c.recorder.Eventf(record, v1.EventTypeWarning, "ValidateDNSRecordFailed", err.Error())
+		return nil
+	}
+	records, err := dnsRecords(record)
+	if err != nil {
+		return err
+	}
+
+	if _, err = c.ovnClient.CreateDNS(key, records); err != nil {
+		klog.Errorf("failed to create dns of dns record %s, %v", key, err)
+		c.recorder.Eventf(record, v1.EventTypeWarning, "CreateDNSFailed", err.Error())
+		return err
+	}
+
+	// records are set to all logical switches of the vpc, which answer dns queries of the ports
+	vpc := dnsRecordVpc(record)
+	subnets, err := c.subnetsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list subnets, %v", err)
+		return err
+	}
+	lsNames := make([]string, 0, len(subnets))
+	for _, subnet := range subnets {
+		if subnet.DeletionTimestamp != nil || subnet.Spec.Vpc != vpc {
+			continue
+		}
+		exist, err := c.ovnClient.LogicalSwitchExists(subnet.Name)
+		if err != nil {
+			klog.Errorf("failed to check logical switch %s exist, %v", subnet.Name, err)
+			return err
+		}
+		if exist {
+			lsNames = append(lsNames, subnet.Name)
+		}
+	}
+	sort.Strings(lsNames)
+
+	if err = c.ovnClient.SetDNSLogicalSwitches(key, lsNames...); err != nil {
+		klog.Errorf("failed to set dns of dns record %s to logical switches, %v", key, err)
+		c.recorder.Eventf(record, v1.EventTypeWarning, "SetDNSFailed", err.Error())
+		return err
+	}
+
+	if len(lsNames) == len(record.Status.LogicalSwitches) && (len(lsNames) == 0 || reflect.DeepEqual(lsNames, record.Status.LogicalSwitches)) {
+		return nil
+	}
+	status := kubeovnv1.DNSRecordStatus{LogicalSwitches: lsNames}
+	bytes, err := status.Bytes()
+	if err != nil {
+		return err
+	}
+	if _, err = c.config.KubeOvnClient.KubeovnV1().DNSRecords().Patch(context.Background(), key, types.MergePatchType, bytes, metav1.PatchOptions{}, "status"); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		klog.Errorf("failed to patch status of dns record %s, %v", key, err)
+		return err
+	}
+	return nil
+}
+
+func (c *Controller) handleDeleteDNSRecord(key string) error {
+	klog.Infof("delete dns of dns record %s", key)
+	if err := c.ovnClient.DeleteDNS(key); err != nil {
+		klog.Errorf("failed to delete dns of dns record %s, %v", key, err)
+		return err
+	}
+	return nil
+}
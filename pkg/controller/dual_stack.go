@@ -0,0 +1,176 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ovs"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+// enqueueDualStackPods enqueue pods with nics in the dual stack subnet which only have an address of one family,
+// which are created before the subnet is upgraded to dual stack
+func (c *Controller) enqueueDualStackPods(subnet *kubeovnv1.Subnet) error {
+	if subnet.Spec.Protocol != kubeovnv1.ProtocolDual {
+		return nil
+	}
+
+	pods, err := c.podsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list pods, %v", err)
+		return err
+	}
+
+	lsSuffix := strings.TrimPrefix(util.LogicalSwitchAnnotationTemplate, "%s")
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil || pod.Spec.HostNetwork {
+			continue
+		}
+		for k, v := range pod.Annotations {
+			if v != subnet.Name || !strings.HasSuffix(k, lsSuffix) {
+				continue
+			}
+			provider := strings.TrimSuffix(k, lsSuffix)
+			ipStr := pod.Annotations[fmt.Sprintf(util.IpAddressAnnotationTemplate, provider)]
+			if ipStr != "" && util.CheckProtocol(ipStr) != kubeovnv1.ProtocolDual {
+				key := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
+				klog.V(3).Infof("enqueue upgrade pod %s to dual stack", key)
+				c.upgradePodDualStackQueue.Add(key)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Controller) runUpgradePodDualStackWorker() {
+	for c.processNextUpgradePodDualStackWorkItem() {
+	}
+}
+
+func (c *Controller) processNextUpgradePodDualStackWorkItem() bool {
+	obj, shutdown := c.upgradePodDualStackQueue.Get()
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.upgradePodDualStackQueue.Done(obj)
+		var key string
+		var ok bool
+		if key, ok = obj.(string); !ok {
+			c.upgradePodDualStackQueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+			return nil
+		}
+		if err := c.handleUpgradePodDualStack(key); err != nil {
+			c.upgradePodDualStackQueue.AddRateLimited(key)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
+		}
+		c.upgradePodDualStackQueue.Forget(obj)
+		return nil
+	}(obj)
+
+	if err != nil {
+		utilruntime.HandleError(err)
+		return true
+	}
+	return true
+}
+
+// handleUpgradePodDualStack allocate an address of the missing family to every nic of the pod in dual stack subnets,
+// the existing address is kept and kube-ovn-cni adds the new address to the running pod
+func (c *Controller) handleUpgradePodDualStack(key string) error {
+	c.podKeyMutex.Lock(key)
+	defer c.podKeyMutex.Unlock(key)
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("invalid resource key: %s", key))
+		return nil
+	}
+	oripod, err := c.podsLister.Pods(namespace).Get(name)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if oripod.DeletionTimestamp != nil {
+		return nil
+	}
+	pod := oripod.DeepCopy()
+
+	podNets, err := c.getPodKubeovnNets(pod)
+	if err != nil {
+		klog.Errorf("failed to get pod nets %v", err)
+		return err
+	}
+
+	var upgraded []string
+	for _, podNet := range podNets {
+		if !isOvnSubnet(podNet.Subnet) || podNet.Subnet.Spec.Protocol != kubeovnv1.ProtocolDual {
+			continue
+		}
+		provider := podNet.ProviderName
+		if pod.Annotations[fmt.Sprintf(util.AllocatedAnnotationTemplate, provider)] != "true" ||
+			pod.Annotations[fmt.Sprintf(util.LogicalSwitchAnnotationTemplate, provider)] != podNet.Subnet.Name {
+			continue
+		}
+		ipStr := pod.Annotations[fmt.Sprintf(util.IpAddressAnnotationTemplate, provider)]
+		if ipStr == "" || util.CheckProtocol(ipStr) == kubeovnv1.ProtocolDual {
+			continue
+		}
+
+		// the address of the nic is reused by ipam and only the missing one is allocated
+		nicName := ovs.PodNameToPortName(pod.Name, pod.Namespace, provider)
+		v4IP, v6IP, _, err := c.ipam.GetRandomAddress(key, nicName, podNet.Subnet.Name, nil)
+		if err != nil {
+			klog.Errorf("failed to allocate dual stack address for pod %s nic %s, %v", key, nicName, err)
+			c.recorder.Eventf(pod, v1.EventTypeWarning, "AcquireAddressFailed", err.Error())
+			return err
+		}
+		if ipStr != v4IP && ipStr != v6IP {
+			return fmt.Errorf("address %s of pod %s nic %s is not kept in ipam, got %s,%s", ipStr, key, nicName, v4IP, v6IP)
+		}
+
+		ipStr = fmt.Sprintf("%s,%s", v4IP, v6IP)
+		klog.Infof("upgrade pod %s nic %s to dual stack with address %s", key, nicName, ipStr)
+		mac := pod.Annotations[fmt.Sprintf(util.MacAddressAnnotationTemplate, provider)]
+		if err = c.setPodNicAddress(pod, podNet, nicName, mac, ipStr, v4IP, v6IP); err != nil {
+			klog.Errorf("failed to set address of pod %s nic %s, %v", key, nicName, err)
+			return err
+		}
+
+		cidr, gw := subnetCIDRAndGatewayByIP(podNet.Subnet, ipStr)
+		pod.Annotations[fmt.Sprintf(util.IpAddressAnnotationTemplate, provider)] = ipStr
+		pod.Annotations[fmt.Sprintf(util.CidrAnnotationTemplate, provider)] = cidr
+		pod.Annotations[fmt.Sprintf(util.GatewayAnnotationTemplate, provider)] = gw
+		upgraded = append(upgraded, ipStr)
+	}
+	if len(upgraded) == 0 {
+		return nil
+	}
+
+	if _, err = c.config.KubeClient.CoreV1().Pods(namespace).Patch(context.Background(), name, types.JSONPatchType, generatePatchPayload(pod.Annotations, "replace"), metav1.PatchOptions{}, ""); err != nil {
+		if k8serrors.IsNotFound(err) {
+			// the pod is deleted after its addresses are allocated
+			c.deletePodQueue.AddRateLimited(key)
+			return nil
+		}
+		klog.Errorf("patch pod %s/%s failed: %v", name, namespace, err)
+		return err
+	}
+	c.recorder.Eventf(pod, v1.EventTypeNormal, "DualStackAddressAllocated", fmt.Sprintf("nic addresses are upgraded to %s", strings.Join(upgraded, " ")))
+	return nil
+}
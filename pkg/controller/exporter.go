@@ -8,6 +8,7 @@ import (
 	"k8s.io/klog/v2"
 
 	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/util"
 )
 
 var registerMetricsOnce sync.Once
@@ -33,6 +34,7 @@ func (c *Controller) exportSubnetMetrics() bool {
 	for _, subnet := range subnets {
 		c.exportSubnetAvailableIPsGauge(subnet)
 		c.exportSubnetUsedIPsGauge(subnet)
+		c.exportSubnetProtocolIPsGauges(subnet)
 	}
 
 	return true
@@ -59,3 +61,32 @@ func (c *Controller) exportSubnetUsedIPsGauge(subnet *kubeovnv1.Subnet) {
 	}
 	metricSubnetUsedIPs.WithLabelValues(subnet.Name, subnet.Spec.Protocol, subnet.Spec.CIDRBlock).Set(usingIPs)
 }
+
+// exportSubnetProtocolIPsGauges exports address counts of each protocol in the subnet
+func (c *Controller) exportSubnetProtocolIPsGauges(subnet *kubeovnv1.Subnet) {
+	if subnet.Spec.CIDRBlock == "" || subnet.Spec.CIDRBlock == util.CIDRNone {
+		return
+	}
+
+	cidrBlocks := subnetCIDRBlocks(subnet)
+	v4ExcludeIps, v6ExcludeIps := util.SplitIpsByProtocol(subnet.Spec.ExcludeIps)
+	protocols := []struct {
+		name             string
+		excludeIps       []string
+		using, available float64
+	}{
+		{kubeovnv1.ProtocolIPv4, v4ExcludeIps, subnet.Status.V4UsingIPs, subnet.Status.V4AvailableIPs},
+		{kubeovnv1.ProtocolIPv6, v6ExcludeIps, subnet.Status.V6UsingIPs, subnet.Status.V6AvailableIPs},
+	}
+	for _, p := range protocols {
+		total := cidrBlocksAddressCount(cidrBlocks, p.name)
+		if total == 0 {
+			continue
+		}
+		metricSubnetTotalIPs.WithLabelValues(subnet.Name, p.name).Set(total)
+		metricSubnetReservedIPs.WithLabelValues(subnet.Name, p.name).Set(util.CountIpNums(util.ExpandExcludeIPs(p.excludeIps, cidrBlocks)))
+		metricSubnetProtocolAvailableIPs.WithLabelValues(subnet.Name, p.name).Set(p.available)
+		metricSubnetProtocolUsedIPs.WithLabelValues(subnet.Name, p.name).Set(p.using)
+		metricSubnetUtilization.WithLabelValues(subnet.Name, p.name).Set(addressUtilization(p.using, p.available))
+	}
+}
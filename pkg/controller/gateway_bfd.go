@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func (c *Controller) CheckGatewayBFD() {
+	if err := c.checkGatewayBFD(); err != nil {
+		klog.Errorf("failed to check gateway bfd %v", err)
+	}
+}
+
+// checkGatewayBFD reconcile ecmp routes and bfd sessions of centralized subnets and update activateGateway
+// with the gateway nodes whose bfd sessions are up, nexthops whose bfd sessions are down are bypassed by ovn itself
+func (c *Controller) checkGatewayBFD() error {
+	klog.V(3).Infoln("start to check gateway bfd status")
+	subnetList, err := c.subnetsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list subnets %v", err)
+		return err
+	}
+
+	lrpName := c.gatewayBFDLogicalRouterPort()
+	bfdStatus, err := c.ovnLegacyClient.GetBFDStatus(lrpName)
+	if err != nil {
+		klog.Errorf("failed to get bfd status of %s, %v", lrpName, err)
+		return err
+	}
+
+	inUse := make(map[string]bool)
+	var failed bool
+	for _, subnet := range subnetList {
+		if !isCentralizedSubnet(subnet) {
+			continue
+		}
+
+		gwIPs, err := c.reconcileGatewayBFD(subnet, bfdStatus)
+		if err != nil {
+			klog.Errorf("failed to reconcile gateway bfd of subnet %s, %v", subnet.Name, err)
+			failed = true
+			continue
+		}
+		for _, ip := range gwIPs {
+			inUse[ip] = true
+		}
+	}
+	// sessions in use by the failed subnets are unknown
+	if failed {
+		return nil
+	}
+
+	bfdList, err := c.ovnClient.ListBFD(lrpName, "")
+	if err != nil {
+		klog.Errorf("failed to list bfd of %s, %v", lrpName, err)
+		return err
+	}
+	for _, bfd := range bfdList {
+		if bfd.ExternalIDs["vendor"] != util.CniTypeName || inUse[bfd.DstIP] {
+			continue
+		}
+		klog.Infof("delete bfd session to %s which is not a gateway any more", bfd.DstIP)
+		if err = c.ovnClient.DeleteBFD(lrpName, bfd.DstIP); err != nil {
+			klog.Errorf("failed to delete bfd session to %s, %v", bfd.DstIP, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileGatewayBFD add ecmp routes with bfd sessions to all gateway nodes of the centralized subnet,
+// activateGateway of the subnet is updated if bfdStatus is not nil, ips of the gateway nodes are returned
+func (c *Controller) reconcileGatewayBFD(subnet *kubeovnv1.Subnet, bfdStatus map[string]string) ([]string, error) {
+	nodes, err := c.nodesLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list nodes, %v", err)
+		return nil, err
+	}
+
+	var gwIPs, activeGateways []string
+	for _, node := range nodes {
+		if !util.GatewayContains(subnet.Spec.GatewayNode, node.Name) {
+			continue
+		}
+		nodeIPs := strings.TrimSpace(node.Annotations[util.IpAddressAnnotation])
+		if nodeIPs == "" {
+			klog.Errorf("gateway node %v has no ip annotation", node.Name)
+			continue
+		}
+
+		active := true
+		for _, ip := range strings.Split(nodeIPs, ",") {
+			gwIPs = append(gwIPs, ip)
+			if bfdStatus[ip] != ovnnb.BFDStatusUp {
+				active = false
+			}
+		}
+		if active {
+			activeGateways = append(activeGateways, node.Name)
+		}
+	}
+
+	lrpName := c.gatewayBFDLogicalRouterPort()
+	policy := ovnnb.LogicalRouterStaticRoutePolicySrcIP
+	for _, cidr := range strings.Split(subnetCIDRBlocks(subnet), ",") {
+		var nextHops []string
+		for _, ip := range gwIPs {
+			if util.CheckProtocol(ip) == util.CheckProtocol(cidr) {
+				nextHops = append(nextHops, ip)
+			}
+		}
+		if len(nextHops) == 0 {
+			klog.Warningf("no gateway node of subnet %s has address for cidr %s", subnet.Name, cidr)
+			continue
+		}
+
+		if err = c.ovnClient.AddLogicalRouterStaticRoute(c.config.ClusterRouter, util.MainRouteTable, policy, cidr, nextHops...); err != nil {
+			klog.Errorf("failed to add ecmp static route for cidr %s of subnet %s: %v", cidr, subnet.Name, err)
+			return nil, err
+		}
+		for _, nextHop := range nextHops {
+			bfd, err := c.ovnClient.CreateBFD(lrpName, nextHop, c.config.GatewayBFDInterval, c.config.GatewayBFDInterval, c.config.GatewayBFDDetectMult, nil)
+			if err != nil {
+				klog.Errorf("failed to create bfd session to %s: %v", nextHop, err)
+				return nil, err
+			}
+			if err = c.ovnClient.SetLogicalRouterStaticRouteBFD(c.config.ClusterRouter, util.MainRouteTable, policy, cidr, nextHop, &bfd.UUID); err != nil {
+				klog.Errorf("failed to set bfd session of static route %s via %s: %v", cidr, nextHop, err)
+				return nil, err
+			}
+		}
+	}
+
+	if bfdStatus == nil {
+		return gwIPs, nil
+	}
+
+	activateGateway := strings.Join(activeGateways, ",")
+	if subnet.Status.ActivateGateway == activateGateway {
+		return gwIPs, nil
+	}
+	klog.Infof("active gateways of subnet %s change from %q to %q", subnet.Name, subnet.Status.ActivateGateway, activateGateway)
+	subnet = subnet.DeepCopy()
+	subnet.Status.ActivateGateway = activateGateway
+	if activateGateway == "" {
+		c.recorder.Eventf(subnet, v1.EventTypeWarning, "NoActiveGateway", fmt.Sprintf("bfd sessions to all gateway nodes %s are down", subnet.Spec.GatewayNode))
+	}
+	bytes, err := subnet.Status.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	if _, err = c.config.KubeOvnClient.KubeovnV1().Subnets().Patch(context.Background(), subnet.Name, types.MergePatchType, bytes, metav1.PatchOptions{}, "status"); err != nil {
+		klog.Errorf("failed to patch activateGateway of subnet %s, %v", subnet.Name, err)
+		return nil, err
+	}
+	return gwIPs, nil
+}
+
+// gatewayBFDLogicalRouterPort return the port of the cluster router which gateway nodes are reached through
+func (c *Controller) gatewayBFDLogicalRouterPort() string {
+	return fmt.Sprintf("%s-%s", c.config.ClusterRouter, c.config.NodeSwitch)
+}
+
+func isCentralizedSubnet(subnet *kubeovnv1.Subnet) bool {
+	return (subnet.Spec.Vlan == "" || subnet.Spec.LogicalGateway) &&
+		subnet.Spec.GatewayNode != "" &&
+		subnet.Spec.GatewayType == kubeovnv1.GWCentralizedType
+}
@@ -134,7 +134,7 @@ func (c *Controller) gcLogicalSwitch() error {
 	klog.Infof("ls in ovn %v", lss)
 	klog.Infof("subnet in kubernetes %v", subnetNames)
 	for _, ls := range lss {
-		if ls.Name == util.InterconnectionSwitch || ls.Name == util.ExternalGatewaySwitch {
+		if ls.Name == util.InterconnectionSwitch || ls.Name == util.ExternalGatewaySwitch || strings.HasPrefix(ls.Name, util.VpcPeeringSwitchPrefix) {
 			continue
 		}
 		if !util.IsStringIn(ls.Name, subnetNames) {
@@ -157,6 +157,13 @@ func (c *Controller) gcLogicalSwitch() error {
 		ls := strings.TrimSuffix(item.ExternalIDs["ls"], util.DHCPLsNonRouterSuffix)
 		if !util.IsStringIn(ls, subnetNames) {
 			uuidToDeleteList = append(uuidToDeleteList, item.UUID)
+			continue
+		}
+		// dhcp options dedicated to ports are deleted with the ip
+		if lsp := item.ExternalIDs[logicalSwitchPortKey]; lsp != "" {
+			if _, err := c.ipsLister.Get(lsp); k8serrors.IsNotFound(err) {
+				uuidToDeleteList = append(uuidToDeleteList, item.UUID)
+			}
 		}
 	}
 	klog.Infof("gc dhcp options %v", uuidToDeleteList)
@@ -233,6 +240,21 @@ func (c *Controller) gcNode() error {
 			}
 		}
 	}
+
+	blocks, err := c.ipBlocksLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list ip block, %v", err)
+		return err
+	}
+	for _, block := range blocks {
+		if !util.IsStringIn(block.Spec.NodeName, nodeNames) {
+			klog.Infof("gc ip block %s of node %s", block.Name, block.Spec.NodeName)
+			if err = c.config.KubeOvnClient.KubeovnV1().IPBlocks().Delete(context.Background(), block.Name, metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+				klog.Errorf("failed to gc ip block %s, %v", block.Name, err)
+				return err
+			}
+		}
+	}
 	return nil
 }
 
@@ -567,7 +589,7 @@ func (c *Controller) gcStaticRoute() error {
 		if route.Policy == ovs.PolicyDstIP || route.Policy == "" {
 			if !c.ipam.ContainAddress(route.NextHop) {
 				klog.Infof("gc static route %s %s %s", route.Policy, route.CIDR, route.NextHop)
-				if err := c.ovnClient.DeleteLogicalRouterStaticRoute(c.config.ClusterRouter, &route.Policy, route.CIDR, route.NextHop); err != nil {
+				if err := c.ovnClient.DeleteLogicalRouterStaticRoute(c.config.ClusterRouter, &route.RouteTable, &route.Policy, route.CIDR, route.NextHop); err != nil {
 					klog.Errorf("failed to delete stale nexthop route %s, %v", route.NextHop, err)
 				}
 			}
@@ -577,7 +599,7 @@ func (c *Controller) gcStaticRoute() error {
 			}
 			if !c.ipam.ContainAddress(route.CIDR) {
 				klog.Infof("gc static route %s %s %s", route.Policy, route.CIDR, route.NextHop)
-				if err := c.ovnClient.DeleteLogicalRouterStaticRoute(c.config.ClusterRouter, &route.Policy, route.CIDR, route.NextHop); err != nil {
+				if err := c.ovnClient.DeleteLogicalRouterStaticRoute(c.config.ClusterRouter, &route.RouteTable, &route.Policy, route.CIDR, route.NextHop); err != nil {
 					klog.Errorf("failed to delete stale route %s, %v", route.NextHop, err)
 				}
 			}
@@ -664,6 +686,28 @@ func (c *Controller) gcDNS() error {
 			}
 		}
 	}
+	// dns of dns records are destroyed if the dns records do not exist
+	recordDnsList, err := c.ovnClient.ListDNS(map[string]string{dnsRecordKey: ""})
+	if err != nil {
+		klog.Errorf("failed to list dns of dns records %v", err)
+		return err
+	}
+	for _, dns := range recordDnsList {
+		name := dns.ExternalIDs[dnsRecordKey]
+		if _, err = c.dnsRecordsLister.Get(name); err == nil {
+			continue
+		} else if !k8serrors.IsNotFound(err) {
+			klog.Errorf("failed to get dns record %s, %v", name, err)
+			return err
+		}
+		klog.Infof("gc dns of dns record %s", name)
+		if err = c.ovnClient.DeleteDNS(name); err != nil {
+			klog.Errorf("failed to delete dns of dns record %s, %v", name, err)
+			return err
+		}
+	}
+
+	// dns of dns records are not listed
 	dnsList, err := c.ovnLegacyClient.ListDns()
 	if err != nil {
 		klog.Errorf("failed to list dns %v", err)
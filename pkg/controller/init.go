@@ -15,6 +15,7 @@ import (
 	"k8s.io/klog/v2"
 
 	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	ovnipam "github.com/kubeovn/kube-ovn/pkg/ipam"
 	"github.com/kubeovn/kube-ovn/pkg/ovs"
 	"github.com/kubeovn/kube-ovn/pkg/util"
 )
@@ -182,21 +183,85 @@ func (c *Controller) initClusterRouter() error {
 
 func (c *Controller) InitIPAM() error {
 	start := time.Now()
+	if c.config.IPAMCheckpointInterval > 0 {
+		if cp := c.loadIPAMCheckpoint(); cp != nil {
+			stale, err := c.initIPAM(cp)
+			if err != nil {
+				return err
+			}
+			if !stale {
+				klog.Infof("take %.2f seconds to initialize IPAM from checkpoint", time.Since(start).Seconds())
+				return nil
+			}
+			klog.Warning("ipam checkpoint is stale, fall back to full rebuild")
+			c.ipam = ovnipam.NewIPAM()
+		}
+	}
+
+	if _, err := c.initIPAM(nil); err != nil {
+		return err
+	}
+	klog.Infof("take %.2f seconds to initialize IPAM", time.Since(start).Seconds())
+	return nil
+}
+
+// initIPAM rebuilds IPAM from subnets, pods, IP CRs and nodes. If a checkpoint is given,
+// subnets matching the checkpoint are restored first and only pods, IP CRs and nodes changed
+// since the checkpoint are replayed, addresses of objects no longer present are released,
+// true is returned if the checkpoint conflicts with the current resources.
+func (c *Controller) initIPAM(cp *ovnipam.Checkpoint) (bool, error) {
 	subnets, err := c.subnetsLister.List(labels.Everything())
 	if err != nil {
 		klog.Errorf("failed to list subnet: %v", err)
-		return err
+		return false, err
 	}
 	for _, subnet := range subnets {
-		if err := c.ipam.AddOrUpdateSubnet(subnet.Name, subnet.Spec.CIDRBlock, subnet.Spec.ExcludeIps); err != nil {
+		if err := c.ipam.AddOrUpdateSubnet(subnet.Name, subnetCIDRBlocks(subnet), subnetExcludeIps(subnet)); err != nil {
 			klog.Errorf("failed to init subnet %s: %v", subnet.Name, err)
+			continue
+		}
+		if err := c.ipam.SetReleasePolicy(subnet.Name, subnetReleasePolicy(subnet)); err != nil {
+			klog.Errorf("failed to set release policy of subnet %s: %v", subnet.Name, err)
+		}
+		if err := c.ipam.SetAllocationStrategy(subnet.Name, subnet.Spec.AllocationStrategy); err != nil {
+			klog.Errorf("failed to set allocation strategy of subnet %s: %v", subnet.Name, err)
+		}
+		if err := c.ipam.SetMacPolicy(subnet.Name, subnet.Spec.MacPrefix, subnet.Spec.MacPolicy); err != nil {
+			klog.Errorf("failed to set mac policy of subnet %s: %v", subnet.Name, err)
+		}
+	}
+
+	pools, err := c.ipPoolsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list ip pool: %v", err)
+		return false, err
+	}
+	for _, pool := range pools {
+		if err := c.ipam.AddOrUpdateIPPool(pool.Spec.Subnet, pool.Name, pool.Spec.IPs); err != nil {
+			klog.Errorf("failed to init ip pool %s: %v", pool.Name, err)
+		}
+	}
+
+	blocks, err := c.ipBlocksLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list ip block: %v", err)
+		return false, err
+	}
+	for _, block := range blocks {
+		if err := c.ipam.AddOrUpdateNodeBlock(block.Spec.Subnet, block.Name, block.Spec.NodeName, block.Spec.IPs); err != nil {
+			klog.Errorf("failed to init ip block %s: %v", block.Name, err)
 		}
 	}
 
+	var tracker *checkpointTracker
+	if cp != nil {
+		tracker = newCheckpointTracker(c.ipam, c.ipam.RestoreCheckpoint(cp), cp.Objects)
+	}
+
 	lsList, err := c.ovnClient.ListLogicalSwitch(false, nil)
 	if err != nil {
 		klog.Errorf("failed to list LS: %v", err)
-		return err
+		return false, err
 	}
 	lsPortsMap := make(map[string]*strset.Set, len(lsList))
 	for _, ls := range lsList {
@@ -206,7 +271,7 @@ func (c *Controller) InitIPAM() error {
 	lspList, err := c.ovnClient.ListLogicalSwitchPortsWithLegacyExternalIDs()
 	if err != nil {
 		klog.Errorf("failed to list LSP: %v", err)
-		return err
+		return false, err
 	}
 	lspWithoutVendor := strset.NewWithSize(len(lspList))
 	lspWithoutLS := make(map[string]string, len(lspList))
@@ -222,7 +287,7 @@ func (c *Controller) InitIPAM() error {
 	pods, err := c.podsLister.List(labels.Everything())
 	if err != nil {
 		klog.Errorf("failed to list pods: %v", err)
-		return err
+		return false, err
 	}
 	for _, pod := range pods {
 		if isPodAlive(pod) && pod.Annotations[util.AllocatedAnnotation] == "true" {
@@ -230,15 +295,21 @@ func (c *Controller) InitIPAM() error {
 			if err != nil {
 				klog.Errorf("failed to get pod kubeovn nets %s.%s address %s: %v", pod.Name, pod.Namespace, pod.Annotations[util.IpAddressAnnotation], err)
 			}
+			unchanged := tracker.unchanged("pod", pod)
 			for _, podNet := range podNets {
-				_, _, _, err := c.ipam.GetStaticAddress(
-					fmt.Sprintf("%s/%s", pod.Namespace, pod.Name),
-					ovs.PodNameToPortName(pod.Name, pod.Namespace, podNet.ProviderName),
-					pod.Annotations[fmt.Sprintf(util.IpAddressAnnotationTemplate, podNet.ProviderName)],
-					pod.Annotations[fmt.Sprintf(util.MacAddressAnnotationTemplate, podNet.ProviderName)],
-					pod.Annotations[fmt.Sprintf(util.LogicalSwitchAnnotationTemplate, podNet.ProviderName)], false)
-				if err != nil {
-					klog.Errorf("failed to init pod %s.%s address %s: %v", pod.Name, pod.Namespace, pod.Annotations[util.IpAddressAnnotation], err)
+				subnet := pod.Annotations[fmt.Sprintf(util.LogicalSwitchAnnotationTemplate, podNet.ProviderName)]
+				nic := ovs.PodNameToPortName(pod.Name, pod.Namespace, podNet.ProviderName)
+				if !unchanged || !tracker.keep(subnet, nic) {
+					tracker.observe(subnet, nic, pod.Annotations[fmt.Sprintf(util.IpAddressAnnotationTemplate, podNet.ProviderName)])
+					_, _, _, err := c.ipam.GetStaticAddressForOwner(
+						fmt.Sprintf("%s/%s", pod.Namespace, pod.Name),
+						nic,
+						pod.Annotations[fmt.Sprintf(util.IpAddressAnnotationTemplate, podNet.ProviderName)],
+						pod.Annotations[fmt.Sprintf(util.MacAddressAnnotationTemplate, podNet.ProviderName)],
+						subnet, getPodOwner(pod), false)
+					if err != nil {
+						klog.Errorf("failed to init pod %s.%s address %s: %v", pod.Name, pod.Namespace, pod.Annotations[util.IpAddressAnnotation], err)
+					}
 				}
 				if podNet.ProviderName == util.OvnProvider || strings.HasSuffix(podNet.ProviderName, util.OvnProvider) {
 					portName := ovs.PodNameToPortName(pod.Name, pod.Namespace, podNet.ProviderName)
@@ -268,7 +339,7 @@ func (c *Controller) InitIPAM() error {
 	ips, err := c.ipsLister.List(labels.Everything())
 	if err != nil {
 		klog.Errorf("failed to list IPs: %v", err)
-		return err
+		return false, err
 	}
 	for _, ip := range ips {
 		var ipamKey string
@@ -284,17 +355,27 @@ func (c *Controller) InitIPAM() error {
 				continue
 			}
 			ipamKey = fmt.Sprintf("%s/%s", ip.Spec.Namespace, ip.Spec.PodName)
+		} else if name := ip.Labels[util.IPReservationLabel]; name != "" {
+			ipamKey = ipamReservationKey(name)
 		} else {
 			ipamKey = fmt.Sprintf("node-%s", ip.Spec.PodName)
 		}
-		if _, _, _, err = c.ipam.GetStaticAddress(ipamKey, ip.Name, ip.Spec.IPAddress, ip.Spec.MacAddress, ip.Spec.Subnet, false); err != nil {
-			klog.Errorf("failed to init IPAM from IP CR %s: %v", ip.Name, err)
+		unchanged := tracker.unchanged("ip", ip)
+		if !unchanged || !tracker.keep(ip.Spec.Subnet, ip.Name) {
+			tracker.observe(ip.Spec.Subnet, ip.Name, ip.Spec.IPAddress)
+			if _, _, _, err = c.ipam.GetStaticAddress(ipamKey, ip.Name, ip.Spec.IPAddress, ip.Spec.MacAddress, ip.Spec.Subnet, false); err != nil {
+				klog.Errorf("failed to init IPAM from IP CR %s: %v", ip.Name, err)
+			}
 		}
 		for i := range ip.Spec.AttachSubnets {
 			if i == len(ip.Spec.AttachIPs) || i == len(ip.Spec.AttachMacs) {
 				klog.Errorf("attachment IP/MAC of IP CR %s is invalid", ip.Name)
 				break
 			}
+			if unchanged && tracker.keep(ip.Spec.AttachSubnets[i], ip.Name) {
+				continue
+			}
+			tracker.observe(ip.Spec.AttachSubnets[i], ip.Name, ip.Spec.AttachIPs[i])
 			if _, _, _, err = c.ipam.GetStaticAddress(ipamKey, ip.Name, ip.Spec.AttachIPs[i], ip.Spec.AttachMacs[i], ip.Spec.AttachSubnets[i], false); err != nil {
 				klog.Errorf("failed to init IPAM from IP CR %s: %v", ip.Name, err)
 			}
@@ -304,19 +385,22 @@ func (c *Controller) InitIPAM() error {
 	nodes, err := c.nodesLister.List(labels.Everything())
 	if err != nil {
 		klog.Errorf("failed to list nodes: %v", err)
-		return err
+		return false, err
 	}
 	for _, node := range nodes {
 		if node.Annotations[util.AllocatedAnnotation] == "true" {
 			portName := fmt.Sprintf("node-%s", node.Name)
-			v4IP, v6IP, _, err := c.ipam.GetStaticAddress(portName, portName, node.Annotations[util.IpAddressAnnotation],
-				node.Annotations[util.MacAddressAnnotation],
-				node.Annotations[util.LogicalSwitchAnnotation], true)
-			if err != nil {
-				klog.Errorf("failed to init node %s.%s address %s: %v", node.Name, node.Namespace, node.Annotations[util.IpAddressAnnotation], err)
-			}
-			if v4IP != "" && v6IP != "" {
-				node.Annotations[util.IpAddressAnnotation] = util.GetStringIP(v4IP, v6IP)
+			if !tracker.unchanged("node", node) || !tracker.keep(node.Annotations[util.LogicalSwitchAnnotation], portName) {
+				tracker.observe(node.Annotations[util.LogicalSwitchAnnotation], portName, node.Annotations[util.IpAddressAnnotation])
+				v4IP, v6IP, _, err := c.ipam.GetStaticAddress(portName, portName, node.Annotations[util.IpAddressAnnotation],
+					node.Annotations[util.MacAddressAnnotation],
+					node.Annotations[util.LogicalSwitchAnnotation], true)
+				if err != nil {
+					klog.Errorf("failed to init node %s.%s address %s: %v", node.Name, node.Namespace, node.Annotations[util.IpAddressAnnotation], err)
+				}
+				if v4IP != "" && v6IP != "" {
+					node.Annotations[util.IpAddressAnnotation] = util.GetStringIP(v4IP, v6IP)
+				}
 			}
 
 			externalIDs := make(map[string]string, 2)
@@ -337,8 +421,12 @@ func (c *Controller) InitIPAM() error {
 			}
 		}
 	}
-	klog.Infof("take %.2f seconds to initialize IPAM", time.Since(start).Seconds())
-	return nil
+
+	if tracker.stale() {
+		return true, nil
+	}
+	tracker.releaseStaleAddresses()
+	return false, nil
 }
 
 func (c *Controller) initDefaultProviderNetwork() error {
@@ -486,7 +574,7 @@ func (c *Controller) initSyncCrdVlans() error {
 }
 
 func (c *Controller) migrateNodeRoute(af int, node, ip, nexthop string, cidrs []string) error {
-	if err := c.ovnClient.DeleteLogicalRouterStaticRoute(c.config.ClusterRouter, nil, ip, ""); err != nil {
+	if err := c.ovnClient.DeleteLogicalRouterStaticRoute(c.config.ClusterRouter, nil, nil, ip, ""); err != nil {
 		klog.Errorf("failed to delete obsolete static route for node %s: %v", node, err)
 		return err
 	}
@@ -1,9 +1,17 @@
 package controller
 
 import (
+	"fmt"
+	"reflect"
+
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/klog/v2"
 
 	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/util"
 )
 
 func (c *Controller) enqueueAddOrDelIP(obj interface{}) {
@@ -17,16 +25,120 @@ func (c *Controller) enqueueAddOrDelIP(obj interface{}) {
 		klog.V(3).Infof("enqueue update status subnet %s", as)
 		c.updateSubnetStatusQueue.Add(as)
 	}
+	if hasDHCPOptionsOverride(ipObj) {
+		klog.V(3).Infof("enqueue update dhcp options of ip %s", ipObj.Name)
+		c.updateIPDHCPOptionsQueue.Add(ipObj.Name)
+	}
 }
 
 func (c *Controller) enqueueUpdateIP(old, new interface{}) {
 	if !c.isLeader() {
 		return
 	}
+	oldIP := old.(*kubeovnv1.IP)
 	ipObj := new.(*kubeovnv1.IP)
 	klog.V(3).Infof("enqueue update status subnet %s", ipObj.Spec.Subnet)
 	for _, as := range ipObj.Spec.AttachSubnets {
 		klog.V(3).Infof("enqueue update status subnet %s", as)
 		c.updateSubnetStatusQueue.Add(as)
 	}
+	if !reflect.DeepEqual(oldIP.Spec.DHCPv4Options, ipObj.Spec.DHCPv4Options) ||
+		!reflect.DeepEqual(oldIP.Spec.DHCPv6Options, ipObj.Spec.DHCPv6Options) {
+		klog.V(3).Infof("enqueue update dhcp options of ip %s", ipObj.Name)
+		c.updateIPDHCPOptionsQueue.Add(ipObj.Name)
+	}
+}
+
+// enqueueSubnetIPDHCPOptions enqueue ips of the subnet which override dhcp options of the subnet
+func (c *Controller) enqueueSubnetIPDHCPOptions(subnet string) error {
+	ips, err := c.ipsLister.List(labels.SelectorFromSet(labels.Set{util.SubnetNameLabel: subnet}))
+	if err != nil {
+		klog.Errorf("failed to list ips of subnet %s, %v", subnet, err)
+		return err
+	}
+	for _, ip := range ips {
+		if hasDHCPOptionsOverride(ip) {
+			c.updateIPDHCPOptionsQueue.Add(ip.Name)
+		}
+	}
+	return nil
+}
+
+func (c *Controller) runUpdateIPDHCPOptionsWorker() {
+	for c.processNextUpdateIPDHCPOptionsWorkItem() {
+	}
+}
+
+func (c *Controller) processNextUpdateIPDHCPOptionsWorkItem() bool {
+	obj, shutdown := c.updateIPDHCPOptionsQueue.Get()
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.updateIPDHCPOptionsQueue.Done(obj)
+		var key string
+		var ok bool
+		if key, ok = obj.(string); !ok {
+			c.updateIPDHCPOptionsQueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+			return nil
+		}
+		if err := c.handleUpdateIPDHCPOptions(key); err != nil {
+			c.updateIPDHCPOptionsQueue.AddRateLimited(key)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
+		}
+		c.updateIPDHCPOptionsQueue.Forget(obj)
+		return nil
+	}(obj)
+
+	if err != nil {
+		utilruntime.HandleError(err)
+		return true
+	}
+	return true
+}
+
+// handleUpdateIPDHCPOptions sync dhcp options of the logical switch port with the overrides of the ip,
+// the ip and the logical switch port share the same name
+func (c *Controller) handleUpdateIPDHCPOptions(key string) error {
+	ip, err := c.ipsLister.Get(key)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return c.ovnClient.DeleteLogicalSwitchPortDHCPOptions(key)
+		}
+		return err
+	}
+
+	if err = util.ValidateDHCPOptions(kubeovnv1.ProtocolIPv4, ip.Spec.DHCPv4Options); err != nil {
+		klog.Errorf("invalid dhcp options of ip %s, %v", key, err)
+		c.recorder.Eventf(ip, v1.EventTypeWarning, "ValidateDHCPOptionsFailed", err.Error())
+		return nil
+	}
+	if err = util.ValidateDHCPOptions(kubeovnv1.ProtocolIPv6, ip.Spec.DHCPv6Options); err != nil {
+		klog.Errorf("invalid dhcp options of ip %s, %v", key, err)
+		c.recorder.Eventf(ip, v1.EventTypeWarning, "ValidateDHCPOptionsFailed", err.Error())
+		return nil
+	}
+
+	// the port is not created yet, dhcp options are synced after it is created
+	exists, err := c.ovnClient.LogicalSwitchPortExists(key)
+	if err != nil {
+		klog.Errorf("failed to check logical switch port %s, %v", key, err)
+		return err
+	}
+	if !exists {
+		return nil
+	}
+
+	klog.Infof("update dhcp options of logical switch port %s", key)
+	if err = c.ovnClient.UpdateLogicalSwitchPortDHCPOptions(key, ip.Spec.DHCPv4Options, ip.Spec.DHCPv6Options); err != nil {
+		klog.Errorf("failed to update dhcp options of logical switch port %s, %v", key, err)
+		return err
+	}
+	return nil
+}
+
+func hasDHCPOptionsOverride(ip *kubeovnv1.IP) bool {
+	return len(ip.Spec.DHCPv4Options) != 0 || len(ip.Spec.DHCPv6Options) != 0
 }
@@ -0,0 +1,438 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ipam"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func (c *Controller) enqueueAddIPBlock(obj interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	var key string
+	var err error
+	if key, err = cache.MetaNamespaceKeyFunc(obj); err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	klog.V(3).Infof("enqueue add ip block %s", key)
+	c.addOrUpdateIPBlockQueue.Add(key)
+}
+
+func (c *Controller) enqueueUpdateIPBlock(old, new interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	oldBlock := old.(*kubeovnv1.IPBlock)
+	newBlock := new.(*kubeovnv1.IPBlock)
+	if reflect.DeepEqual(oldBlock.Spec, newBlock.Spec) {
+		return
+	}
+
+	var key string
+	var err error
+	if key, err = cache.MetaNamespaceKeyFunc(new); err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	klog.V(3).Infof("enqueue update ip block %s", key)
+	c.addOrUpdateIPBlockQueue.Add(key)
+}
+
+func (c *Controller) enqueueDeleteIPBlock(obj interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	var key string
+	var err error
+	if key, err = cache.DeletionHandlingMetaNamespaceKeyFunc(obj); err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	klog.V(3).Infof("enqueue delete ip block %s", key)
+	c.delIPBlockQueue.Add(key)
+}
+
+// enqueueLeaseIPBlock checks whether a new block should be leased to the node
+func (c *Controller) enqueueLeaseIPBlock(subnet, node string) {
+	c.leaseIPBlockQueue.Add(fmt.Sprintf("%s/%s", subnet, node))
+}
+
+func (c *Controller) runAddOrUpdateIPBlockWorker() {
+	for c.processNextAddOrUpdateIPBlockWorkItem() {
+	}
+}
+
+func (c *Controller) runDelIPBlockWorker() {
+	for c.processNextDeleteIPBlockWorkItem() {
+	}
+}
+
+func (c *Controller) runUpdateIPBlockStatusWorker() {
+	for c.processNextUpdateIPBlockStatusWorkItem() {
+	}
+}
+
+func (c *Controller) runLeaseIPBlockWorker() {
+	for c.processNextLeaseIPBlockWorkItem() {
+	}
+}
+
+func (c *Controller) processNextAddOrUpdateIPBlockWorkItem() bool {
+	obj, shutdown := c.addOrUpdateIPBlockQueue.Get()
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.addOrUpdateIPBlockQueue.Done(obj)
+		var key string
+		var ok bool
+		if key, ok = obj.(string); !ok {
+			c.addOrUpdateIPBlockQueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+			return nil
+		}
+		if err := c.handleAddOrUpdateIPBlock(key); err != nil {
+			c.addOrUpdateIPBlockQueue.AddRateLimited(key)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
+		}
+		c.addOrUpdateIPBlockQueue.Forget(obj)
+		return nil
+	}(obj)
+
+	if err != nil {
+		utilruntime.HandleError(err)
+		return true
+	}
+	return true
+}
+
+func (c *Controller) processNextDeleteIPBlockWorkItem() bool {
+	obj, shutdown := c.delIPBlockQueue.Get()
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.delIPBlockQueue.Done(obj)
+		var key string
+		var ok bool
+		if key, ok = obj.(string); !ok {
+			c.delIPBlockQueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+			return nil
+		}
+		if err := c.handleDeleteIPBlock(key); err != nil {
+			c.delIPBlockQueue.AddRateLimited(key)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
+		}
+		c.delIPBlockQueue.Forget(obj)
+		return nil
+	}(obj)
+
+	if err != nil {
+		utilruntime.HandleError(err)
+		return true
+	}
+	return true
+}
+
+func (c *Controller) processNextUpdateIPBlockStatusWorkItem() bool {
+	obj, shutdown := c.updateIPBlockStatusQueue.Get()
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.updateIPBlockStatusQueue.Done(obj)
+		var key string
+		var ok bool
+		if key, ok = obj.(string); !ok {
+			c.updateIPBlockStatusQueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+			return nil
+		}
+		if err := c.handleUpdateIPBlockStatus(key); err != nil {
+			c.updateIPBlockStatusQueue.AddRateLimited(key)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
+		}
+		c.updateIPBlockStatusQueue.Forget(obj)
+		return nil
+	}(obj)
+
+	if err != nil {
+		utilruntime.HandleError(err)
+		return true
+	}
+	return true
+}
+
+func (c *Controller) processNextLeaseIPBlockWorkItem() bool {
+	obj, shutdown := c.leaseIPBlockQueue.Get()
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.leaseIPBlockQueue.Done(obj)
+		var key string
+		var ok bool
+		if key, ok = obj.(string); !ok {
+			c.leaseIPBlockQueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+			return nil
+		}
+		if err := c.handleLeaseIPBlock(key); err != nil {
+			c.leaseIPBlockQueue.AddRateLimited(key)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
+		}
+		c.leaseIPBlockQueue.Forget(obj)
+		return nil
+	}(obj)
+
+	if err != nil {
+		utilruntime.HandleError(err)
+		return true
+	}
+	return true
+}
+
+func (c *Controller) handleAddOrUpdateIPBlock(key string) error {
+	cachedBlock, err := c.ipBlocksLister.Get(key)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	block := cachedBlock.DeepCopy()
+	klog.Infof("handle add or update ip block %s", block.Name)
+
+	if err = c.ipam.AddOrUpdateNodeBlock(block.Spec.Subnet, block.Name, block.Spec.NodeName, block.Spec.IPs); err != nil {
+		klog.Errorf("failed to add ip block %s to subnet %s: %v", block.Name, block.Spec.Subnet, err)
+		c.recorder.Eventf(block, v1.EventTypeWarning, "AddIPBlockFailed", err.Error())
+		return err
+	}
+
+	c.updateIPBlockStatusQueue.Add(block.Name)
+	return nil
+}
+
+func (c *Controller) handleDeleteIPBlock(key string) error {
+	klog.Infof("handle delete ip block %s", key)
+	c.ipam.RemoveNodeBlock(key)
+	return nil
+}
+
+func (c *Controller) handleUpdateIPBlockStatus(key string) error {
+	cachedBlock, err := c.ipBlocksLister.Get(key)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	block := cachedBlock.DeepCopy()
+	if returned, err := c.returnIdleIPBlock(block); err != nil || returned {
+		return err
+	}
+
+	v4Available, v4Using, v6Available, v6Using, err := c.ipam.NodeBlockStatistics(block.Spec.Subnet, block.Name)
+	if err != nil {
+		klog.Errorf("failed to get statistics of ip block %s: %v", block.Name, err)
+		return err
+	}
+	status := kubeovnv1.IPBlockStatus{
+		V4AvailableIPs: v4Available,
+		V4UsingIPs:     v4Using,
+		V6AvailableIPs: v6Available,
+		V6UsingIPs:     v6Using,
+	}
+	if reflect.DeepEqual(block.Status, status) {
+		return nil
+	}
+
+	block.Status = status
+	bytes, err := block.Status.Bytes()
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	if _, err = c.config.KubeOvnClient.KubeovnV1().IPBlocks().Patch(context.Background(), block.Name, types.MergePatchType, bytes, metav1.PatchOptions{}, "status"); err != nil {
+		klog.Errorf("failed to patch status of ip block %s: %v", block.Name, err)
+		return err
+	}
+	return nil
+}
+
+// handleLeaseIPBlock leases a new block to the node if less than half
+// of the block size is available in blocks of the node
+func (c *Controller) handleLeaseIPBlock(key string) error {
+	subnetName, node, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("invalid resource key: %s", key))
+		return nil
+	}
+	subnet, err := c.subnetsLister.Get(subnetName)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	size := subnet.Spec.NodeBlockSize
+	if size <= 0 {
+		return nil
+	}
+	if _, err = c.nodesLister.Get(node); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if available := c.ipam.NodeAvailableIPs(subnetName, node); available*2 >= float64(size) {
+		return nil
+	}
+
+	var name string
+	var ips []string
+	for i := 0; ; i++ {
+		name = fmt.Sprintf("%s-%s-%d", subnetName, node, i)
+		if _, err = c.ipBlocksLister.Get(name); err == nil {
+			continue
+		}
+		if ips, err = c.ipam.LeaseNodeBlock(subnetName, name, node, size); err != ipam.ErrConflict {
+			break
+		}
+	}
+	if err != nil {
+		klog.Warningf("failed to lease ip block of subnet %s to node %s: %v", subnetName, node, err)
+		return nil
+	}
+
+	block := &kubeovnv1.IPBlock{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{util.SubnetNameLabel: subnetName},
+		},
+		Spec: kubeovnv1.IPBlockSpec{
+			Subnet:   subnetName,
+			NodeName: node,
+			IPs:      ips,
+		},
+	}
+	if _, err = c.config.KubeOvnClient.KubeovnV1().IPBlocks().Create(context.Background(), block, metav1.CreateOptions{}); err != nil {
+		klog.Errorf("failed to create ip block %s: %v", name, err)
+		c.ipam.RemoveNodeBlock(name)
+		return err
+	}
+	return nil
+}
+
+// returnIdleIPBlock deletes the block if no address of it is in use and other blocks of the node
+// have at least half of the block size available, so blocks leased for a burst of pods are returned
+func (c *Controller) returnIdleIPBlock(block *kubeovnv1.IPBlock) (bool, error) {
+	subnet, err := c.subnetsLister.Get(block.Spec.Subnet)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	size := subnet.Spec.NodeBlockSize
+	if size <= 0 || !c.ipam.NodeBlockIdle(subnet.Name, block.Name, float64(size)/2) {
+		return false, nil
+	}
+
+	klog.Infof("return ip block %s of node %s which has no address in use", block.Name, block.Spec.NodeName)
+	if err = c.config.KubeOvnClient.KubeovnV1().IPBlocks().Delete(context.Background(), block.Name, metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+		klog.Errorf("failed to delete ip block %s: %v", block.Name, err)
+		return false, err
+	}
+	return true, nil
+}
+
+// syncSubnetIPBlocks leases blocks to all nodes in advance,
+// blocks of the subnet are deleted if per-node blocks are disabled
+func (c *Controller) syncSubnetIPBlocks(subnet *kubeovnv1.Subnet) error {
+	if subnet.Spec.NodeBlockSize <= 0 {
+		return c.deleteIPBlocks(subnet.Name, "")
+	}
+
+	nodes, err := c.nodesLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list nodes: %v", err)
+		return err
+	}
+	for _, node := range nodes {
+		c.enqueueLeaseIPBlock(subnet.Name, node.Name)
+	}
+	return nil
+}
+
+// deleteIPBlocks deletes ip blocks of the subnet and the node, empty subnet or node matches all
+func (c *Controller) deleteIPBlocks(subnet, node string) error {
+	blocks, err := c.ipBlocksLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list ip blocks: %v", err)
+		return err
+	}
+	for _, block := range blocks {
+		if (subnet != "" && block.Spec.Subnet != subnet) || (node != "" && block.Spec.NodeName != node) {
+			continue
+		}
+		klog.Infof("delete ip block %s of node %s", block.Name, block.Spec.NodeName)
+		if err = c.config.KubeOvnClient.KubeovnV1().IPBlocks().Delete(context.Background(), block.Name, metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+			klog.Errorf("failed to delete ip block %s: %v", block.Name, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// enqueueSubnetIPBlocks updates status of all ip blocks in the subnet
+func (c *Controller) enqueueSubnetIPBlocks(subnet string) {
+	blocks, err := c.ipBlocksLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list ip blocks: %v", err)
+		return
+	}
+	for _, block := range blocks {
+		if block.Spec.Subnet == subnet {
+			c.updateIPBlockStatusQueue.Add(block.Name)
+		}
+	}
+}
+
+// useNodeBlock returns true if the address of the pod is allocated from ip blocks of its node,
+// a new block is leased to the node in advance if its blocks are running out
+func (c *Controller) useNodeBlock(pod *v1.Pod, subnet *kubeovnv1.Subnet) bool {
+	if subnet.Spec.NodeBlockSize <= 0 || pod.Spec.NodeName == "" {
+		return false
+	}
+	c.enqueueLeaseIPBlock(subnet.Name, pod.Spec.NodeName)
+	return true
+}
+
+// waitForNodeBlock returns true if allocating the address of the pod should wait until the pod is bound,
+// since addresses are allocated from ip blocks of the node the pod is scheduled to
+func waitForNodeBlock(pod *v1.Pod, podNet *kubeovnNet) bool {
+	return podNet.Subnet.Spec.NodeBlockSize > 0 && pod.Spec.NodeName == "" &&
+		pod.Annotations[fmt.Sprintf(util.IpAddressAnnotationTemplate, podNet.ProviderName)] == "" &&
+		pod.Annotations[fmt.Sprintf(util.IpPoolAnnotationTemplate, podNet.ProviderName)] == ""
+}
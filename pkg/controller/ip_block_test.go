@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+)
+
+func TestReturnIdleIPBlock(t *testing.T) {
+	subnet := &kubeovnv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: "net1"},
+		Spec:       kubeovnv1.SubnetSpec{CIDRBlock: "10.16.0.0/24", Protocol: kubeovnv1.ProtocolIPv4, NodeBlockSize: 4},
+	}
+	c := newTestController(t, withObjects(subnet))
+	require.NoError(t, c.ipam.AddOrUpdateSubnet("net1", "10.16.0.0/24", nil))
+	for _, name := range []string{"net1-node1-0", "net1-node1-1"} {
+		ips, err := c.ipam.LeaseNodeBlock("net1", name, "node1", 4)
+		require.NoError(t, err)
+		block := &kubeovnv1.IPBlock{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       kubeovnv1.IPBlockSpec{Subnet: "net1", NodeName: "node1", IPs: ips},
+		}
+		c.addObject(t, block)
+		_, err = c.kubeOvnClient.KubeovnV1().IPBlocks().Create(context.Background(), block, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+	blockExists := func(name string) bool {
+		_, err := c.kubeOvnClient.KubeovnV1().IPBlocks().Get(context.Background(), name, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			return false
+		}
+		require.NoError(t, err)
+		return true
+	}
+
+	ip, _, _, err := c.ipam.GetRandomAddressFromNodeBlocks("default/p1", "p1.default", "net1", "node1", nil)
+	require.NoError(t, err)
+	require.Equal(t, "10.16.0.1", ip)
+
+	// the block with an address in use is kept
+	require.NoError(t, c.handleUpdateIPBlockStatus("net1-node1-0"))
+	require.True(t, blockExists("net1-node1-0"))
+
+	// the idle block is returned since the other block has enough addresses available
+	require.NoError(t, c.handleUpdateIPBlockStatus("net1-node1-1"))
+	require.False(t, blockExists("net1-node1-1"))
+	require.NoError(t, c.handleDeleteIPBlock("net1-node1-1"))
+	ip, _, _, err = c.ipam.GetRandomAddress("default/p2", "p2.default", "net1", nil)
+	require.NoError(t, err)
+	require.Equal(t, "10.16.0.5", ip)
+
+	// the last block of the node is kept even if it becomes idle
+	c.ipam.ReleaseAddressByPod("default/p1")
+	require.NoError(t, c.handleUpdateIPBlockStatus("net1-node1-0"))
+	require.True(t, blockExists("net1-node1-0"))
+}
@@ -0,0 +1,308 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+// types of discrepancies found by IPAM audit
+const (
+	auditIPWithoutPod    = "ip_without_pod"
+	auditLSPWithoutIP    = "lsp_without_ip"
+	auditAddressMismatch = "address_mismatch"
+	auditIPNotInIPAM     = "ip_not_in_ipam"
+	auditIPConflict      = "ip_conflict"
+)
+
+type ipamAudit struct {
+	found  map[string]bool
+	counts map[ipamAuditKey]int
+}
+
+type ipamAuditKey struct {
+	subnet string
+	kind   string
+}
+
+// auditIPAM cross-checks addresses in IPAM, IP CRs and logical switch ports
+func (c *Controller) auditIPAM() {
+	if !c.isLeader() {
+		return
+	}
+	c.runIPAMAudit()
+}
+
+func (c *Controller) runIPAMAudit() {
+	klog.Infof("start to audit ipam")
+	ips, err := c.ipsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list ip, %v", err)
+		return
+	}
+	lsps, err := c.ovnClient.ListNormalLogicalSwitchPorts(true, nil)
+	if err != nil {
+		klog.Errorf("failed to list logical switch port, %v", err)
+		return
+	}
+
+	ipMap := make(map[string]*kubeovnv1.IP, len(ips))
+	for _, ip := range ips {
+		ipMap[ip.Name] = ip
+	}
+	lspMap := make(map[string]*ovnnb.LogicalSwitchPort, len(lsps))
+	for i := range lsps {
+		lspMap[lsps[i].Name] = &lsps[i]
+	}
+
+	audit := &ipamAudit{found: map[string]bool{}, counts: map[ipamAuditKey]int{}}
+	for _, ip := range ips {
+		c.auditIP(audit, ip, lspMap[ip.Name])
+	}
+	for _, lsp := range lspMap {
+		c.auditLogicalSwitchPort(audit, lsp, ipMap[lsp.Name])
+	}
+
+	metricIPAMAuditDiscrepancies.Reset()
+	for key, count := range audit.counts {
+		metricIPAMAuditDiscrepancies.WithLabelValues(key.subnet, key.kind).Set(float64(count))
+	}
+	c.lastAuditDiscrepancies = audit.found
+	klog.Infof("finish ipam audit, %d discrepancies found", len(audit.found))
+}
+
+func (c *Controller) auditIP(audit *ipamAudit, ip *kubeovnv1.IP, lsp *ovnnb.LogicalSwitchPort) {
+	if ip.Spec.Namespace == "" {
+		// addresses of nodes and vips
+		return
+	}
+
+	key := fmt.Sprintf("%s/%s", ip.Spec.Namespace, ip.Spec.PodName)
+	exist, err := c.auditPodExists(ip.Spec.Namespace, ip.Spec.PodName)
+	if err != nil {
+		klog.Errorf("failed to get pod %s, %v", key, err)
+		return
+	}
+	if !exist {
+		keep, err := c.auditKeepIP(ip)
+		if err != nil {
+			klog.Errorf("failed to check owner of ip %s, %v", ip.Name, err)
+			return
+		}
+		if keep {
+			klog.V(3).Infof("ip %s is kept for the owner of pod %s", ip.Name, key)
+			return
+		}
+
+		msg := fmt.Sprintf("ip %s is not used by any pod", ip.Name)
+		if c.reportDiscrepancy(audit, ip, ip.Spec.Subnet, auditIPWithoutPod, ip.Name, msg) {
+			if err = c.config.KubeOvnClient.KubeovnV1().IPs().Delete(context.Background(), ip.Name, metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+				klog.Errorf("failed to delete ip %s, %v", ip.Name, err)
+				return
+			}
+			c.ipam.ReleaseAddressByPod(key)
+			c.repairedDiscrepancy(ip.Spec.Subnet, auditIPWithoutPod, msg)
+		}
+		return
+	}
+
+	owners := []string{key}
+	if lsp != nil && lsp.ExternalIDs["pod"] != "" {
+		owners = append(owners, lsp.ExternalIDs["pod"])
+	}
+	var missing bool
+	for _, addr := range strings.Split(ip.Spec.IPAddress, ",") {
+		if addr == "" {
+			continue
+		}
+		pods := c.ipam.GetPodByIP(addr, ip.Spec.Subnet)
+		if len(pods) == 0 {
+			missing = true
+			continue
+		}
+		if !ownedByAny(pods, owners) {
+			c.reportDiscrepancy(audit, ip, ip.Spec.Subnet, auditIPConflict, ip.Spec.Subnet+"/"+addr,
+				fmt.Sprintf("address %s of ip %s is allocated to %s in ipam", addr, ip.Name, strings.Join(pods, ",")))
+		}
+	}
+	if !missing {
+		return
+	}
+
+	msg := fmt.Sprintf("address %s of ip %s is not allocated in ipam", ip.Spec.IPAddress, ip.Name)
+	if c.reportDiscrepancy(audit, ip, ip.Spec.Subnet, auditIPNotInIPAM, ip.Name, msg) {
+		if _, _, _, err = c.ipam.GetStaticAddress(key, ip.Name, ip.Spec.IPAddress, ip.Spec.MacAddress, ip.Spec.Subnet, true); err != nil {
+			klog.Errorf("failed to allocate address %s of ip %s in ipam, %v", ip.Spec.IPAddress, ip.Name, err)
+			return
+		}
+		c.repairedDiscrepancy(ip.Spec.Subnet, auditIPNotInIPAM, msg)
+	}
+}
+
+func (c *Controller) auditLogicalSwitchPort(audit *ipamAudit, lsp *ovnnb.LogicalSwitchPort, ip *kubeovnv1.IP) {
+	key := lsp.ExternalIDs["pod"]
+	if key == "" {
+		return
+	}
+	subnetName := lsp.ExternalIDs[logicalSwitchKey]
+	addresses := logicalSwitchPortIPs(lsp)
+
+	if ip == nil {
+		namespace, name, err := cache.SplitMetaNamespaceKey(key)
+		if err != nil {
+			klog.Errorf("invalid pod key %s of logical switch port %s", key, lsp.Name)
+			return
+		}
+		exist, err := c.auditPodExists(namespace, name)
+		if err != nil {
+			klog.Errorf("failed to get pod %s, %v", key, err)
+			return
+		}
+
+		var subnet runtime.Object
+		if s, err := c.subnetsLister.Get(subnetName); err == nil {
+			subnet = s
+		}
+		msg := fmt.Sprintf("logical switch port %s of pod %s has no ip", lsp.Name, key)
+		// the ip of a running pod is created by cni, only report it
+		if c.reportDiscrepancy(audit, subnet, subnetName, auditLSPWithoutIP, lsp.Name, msg) && !exist {
+			if err = c.ovnClient.DeleteLogicalSwitchPort(lsp.Name); err != nil {
+				klog.Errorf("failed to delete lsp %s, %v", lsp.Name, err)
+				return
+			}
+			c.ipam.ReleaseAddressByPod(key)
+			c.repairedDiscrepancy(subnetName, auditLSPWithoutIP, msg)
+		}
+		return
+	}
+
+	if len(addresses) != 0 && !sameAddresses(addresses, strings.Split(ip.Spec.IPAddress, ",")) {
+		c.reportDiscrepancy(audit, ip, subnetName, auditAddressMismatch, lsp.Name,
+			fmt.Sprintf("addresses %s of logical switch port %s do not match ip %s", strings.Join(addresses, ","), lsp.Name, ip.Spec.IPAddress))
+	}
+	for _, addr := range addresses {
+		pods := c.ipam.GetPodByIP(addr, subnetName)
+		if len(pods) != 0 && !ownedByAny(pods, []string{key, fmt.Sprintf("%s/%s", ip.Spec.Namespace, ip.Spec.PodName)}) {
+			c.reportDiscrepancy(audit, ip, subnetName, auditIPConflict, subnetName+"/"+addr,
+				fmt.Sprintf("address %s of logical switch port %s is allocated to %s in ipam", addr, lsp.Name, strings.Join(pods, ",")))
+		}
+	}
+}
+
+// reportDiscrepancy records the discrepancy, it returns true if the discrepancy
+// should be repaired, which means it has been found by the last audit as well
+func (c *Controller) reportDiscrepancy(audit *ipamAudit, obj runtime.Object, subnet, kind, name, msg string) bool {
+	id := kind + "/" + name
+	if audit.found[id] {
+		return false
+	}
+	audit.found[id] = true
+	audit.counts[ipamAuditKey{subnet: subnet, kind: kind}]++
+
+	klog.Warningf("ipam audit: %s", msg)
+	if obj != nil {
+		c.recorder.Event(obj, v1.EventTypeWarning, "IPAMDiscrepancy", msg)
+	}
+	return c.config.IPAMAuditRepair && c.lastAuditDiscrepancies[id]
+}
+
+func (c *Controller) repairedDiscrepancy(subnet, kind, msg string) {
+	klog.Infof("ipam audit: repaired %s", msg)
+	metricIPAMAuditRepairs.WithLabelValues(subnet, kind).Inc()
+}
+
+// auditPodExists checks whether the pod exists, the name may be the name of a kubevirt vm
+func (c *Controller) auditPodExists(namespace, name string) (bool, error) {
+	_, err := c.podsLister.Pods(namespace).Get(name)
+	if err == nil {
+		return true, nil
+	}
+	if !k8serrors.IsNotFound(err) {
+		return false, err
+	}
+	pods, err := c.podsLister.Pods(namespace).List(labels.Set{util.KubeVirtVmLabel: name}.AsSelector())
+	if err != nil {
+		return false, err
+	}
+	return len(pods) != 0, nil
+}
+
+// auditKeepIP checks whether the ip of an absent pod is kept for its owner, which may be
+// a statefulset recreating the pod or a stopped kubevirt vm
+func (c *Controller) auditKeepIP(ip *kubeovnv1.IP) (bool, error) {
+	if i := strings.LastIndex(ip.Spec.PodName, "-"); i > 0 {
+		if _, err := strconv.Atoi(ip.Spec.PodName[i+1:]); err == nil {
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: ip.Spec.PodName, Namespace: ip.Spec.Namespace}}
+			if !isStatefulSetPodToDel(c.config.KubeClient, pod, ip.Spec.PodName[:i]) {
+				return true, nil
+			}
+		}
+	}
+	return c.kubevirtVMExists(ip.Spec.Namespace, ip.Spec.PodName)
+}
+
+// kubevirtVMExists checks whether the kubevirt vm exists, false is returned if kubevirt is not installed
+func (c *Controller) kubevirtVMExists(namespace, name string) (bool, error) {
+	err := c.config.KubeClient.Discovery().RESTClient().Get().
+		AbsPath("/apis/kubevirt.io/v1/namespaces", namespace, "virtualmachines", name).
+		Do(context.Background()).
+		Error()
+	if err == nil {
+		return true, nil
+	}
+	if k8serrors.IsNotFound(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+// logicalSwitchPortIPs returns ip addresses in the addresses column of the lsp
+func logicalSwitchPortIPs(lsp *ovnnb.LogicalSwitchPort) []string {
+	var ips []string
+	for _, addr := range lsp.Addresses {
+		// the first field is the mac, "unknown", "router" and "dynamic" have no ip
+		fields := strings.Fields(addr)
+		if len(fields) > 1 {
+			ips = append(ips, fields[1:]...)
+		}
+	}
+	return ips
+}
+
+func sameAddresses(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	x, y := append([]string{}, a...), append([]string{}, b...)
+	sort.Strings(x)
+	sort.Strings(y)
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func ownedByAny(pods, owners []string) bool {
+	for _, owner := range owners {
+		if util.ContainsString(pods, owner) {
+			return true
+		}
+	}
+	return false
+}
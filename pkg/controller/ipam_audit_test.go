@@ -0,0 +1,192 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+)
+
+type ipamAuditTest struct {
+	*testController
+	// objects served by the fake apiserver keyed by path, they are read by the audit directly
+	apiObjects map[string]interface{}
+}
+
+func newIPAMAuditTest(t *testing.T, repair bool, pods []*corev1.Pod, ips []*kubeovnv1.IP, lsps ...ovnnb.LogicalSwitchPort) *ipamAuditTest {
+	objects := []runtime.Object{&kubeovnv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: "net1"},
+		Spec:       kubeovnv1.SubnetSpec{CIDRBlock: "10.16.0.0/24", Protocol: kubeovnv1.ProtocolIPv4},
+	}}
+	for _, pod := range pods {
+		objects = append(objects, pod)
+	}
+	for _, ip := range ips {
+		objects = append(objects, ip)
+	}
+
+	test := &ipamAuditTest{apiObjects: map[string]interface{}{}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		obj, ok := test.apiObjects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			obj = &metav1.Status{Status: metav1.StatusFailure, Reason: metav1.StatusReasonNotFound, Code: http.StatusNotFound}
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(obj))
+	}))
+	t.Cleanup(server.Close)
+	kubeClient, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	require.NoError(t, err)
+
+	test.testController = newTestController(t, withObjects(objects...), withKubeClient(kubeClient), withConfig(func(config *Configuration) {
+		config.IPAMAuditRepair = repair
+	}))
+	for _, lsp := range lsps {
+		test.ovnClient.lsps[lsp.Name] = lsp
+	}
+	require.NoError(t, test.ipam.AddOrUpdateSubnet("net1", "10.16.0.0/24", nil))
+	return test
+}
+
+// deletedIPs returns names of the IP CRs deleted by the audit
+func (test *ipamAuditTest) deletedIPs() []string {
+	var names []string
+	for _, action := range test.kubeOvnClient.Actions() {
+		if action.GetVerb() == "delete" && action.GetResource().Resource == "ips" {
+			names = append(names, action.(k8stesting.DeleteAction).GetName())
+		}
+	}
+	return names
+}
+
+func newIPAMAuditTestPod(name string) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+}
+
+func newIPAMAuditTestIP(name, address string) *kubeovnv1.IP {
+	return &kubeovnv1.IP{
+		ObjectMeta: metav1.ObjectMeta{Name: name + ".default"},
+		Spec:       kubeovnv1.IPSpec{PodName: name, Namespace: "default", Subnet: "net1", IPAddress: address},
+	}
+}
+
+func newIPAMAuditTestLSP(name, address string) ovnnb.LogicalSwitchPort {
+	return ovnnb.LogicalSwitchPort{
+		Name:        name + ".default",
+		Addresses:   []string{"00:00:00:00:00:01 " + address},
+		ExternalIDs: map[string]string{"pod": "default/" + name, logicalSwitchKey: "net1"},
+	}
+}
+
+func TestIPAMAudit(t *testing.T) {
+	t.Run("repair ip without pod found by two audits", func(t *testing.T) {
+		test := newIPAMAuditTest(t, true, nil, []*kubeovnv1.IP{newIPAMAuditTestIP("p1", "10.16.0.10")})
+		_, _, _, err := test.ipam.GetStaticAddress("default/p1", "p1.default", "10.16.0.10", "", "net1", true)
+		require.NoError(t, err)
+
+		test.runIPAMAudit()
+		require.Empty(t, test.deletedIPs())
+		require.Equal(t, []string{"default/p1"}, test.ipam.GetPodByIP("10.16.0.10", "net1"))
+
+		test.runIPAMAudit()
+		require.Equal(t, []string{"p1.default"}, test.deletedIPs())
+		require.Empty(t, test.ipam.GetPodByIP("10.16.0.10", "net1"))
+	})
+
+	t.Run("report only if repair is disabled", func(t *testing.T) {
+		test := newIPAMAuditTest(t, false, nil, []*kubeovnv1.IP{newIPAMAuditTestIP("p1", "10.16.0.10")})
+		test.runIPAMAudit()
+		test.runIPAMAudit()
+		require.Empty(t, test.deletedIPs())
+		require.Len(t, test.recorder.Events, 2)
+	})
+
+	t.Run("skip discrepancy not found by the last audit", func(t *testing.T) {
+		test := newIPAMAuditTest(t, true, nil, []*kubeovnv1.IP{newIPAMAuditTestIP("p1", "10.16.0.10")})
+		test.runIPAMAudit()
+
+		pod := newIPAMAuditTestPod("p1")
+		test.addObject(t, pod)
+		test.runIPAMAudit()
+		require.NotContains(t, test.lastAuditDiscrepancies, auditIPWithoutPod+"/p1.default")
+
+		test.deleteObject(t, pod)
+		test.runIPAMAudit()
+		require.Empty(t, test.deletedIPs())
+	})
+
+	t.Run("keep ip of statefulset pod", func(t *testing.T) {
+		test := newIPAMAuditTest(t, true, nil, []*kubeovnv1.IP{newIPAMAuditTestIP("web-0", "10.16.0.10"), newIPAMAuditTestIP("web-1", "10.16.0.11")})
+		replicas := int32(1)
+		test.apiObjects["/apis/apps/v1/namespaces/default/statefulsets/web"] = &appsv1.StatefulSet{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "StatefulSet"},
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+			Status:     appsv1.StatefulSetStatus{Replicas: 1},
+		}
+		test.runIPAMAudit()
+		test.runIPAMAudit()
+		// the ip of the pod out of the replicas is repaired
+		require.Equal(t, []string{"web-1.default"}, test.deletedIPs())
+	})
+
+	t.Run("keep ip of stopped vm", func(t *testing.T) {
+		test := newIPAMAuditTest(t, true, nil, []*kubeovnv1.IP{newIPAMAuditTestIP("vm1", "10.16.0.10")})
+		test.apiObjects["/apis/kubevirt.io/v1/namespaces/default/virtualmachines/vm1"] = map[string]interface{}{
+			"apiVersion": "kubevirt.io/v1",
+			"kind":       "VirtualMachine",
+			"metadata":   map[string]interface{}{"name": "vm1", "namespace": "default"},
+		}
+		test.runIPAMAudit()
+		test.runIPAMAudit()
+		require.Empty(t, test.deletedIPs())
+		require.Empty(t, test.lastAuditDiscrepancies)
+	})
+
+	t.Run("allocate ip missing in ipam", func(t *testing.T) {
+		test := newIPAMAuditTest(t, true, []*corev1.Pod{newIPAMAuditTestPod("p1")}, []*kubeovnv1.IP{newIPAMAuditTestIP("p1", "10.16.0.10")})
+		test.runIPAMAudit()
+		require.Empty(t, test.ipam.GetPodByIP("10.16.0.10", "net1"))
+
+		test.runIPAMAudit()
+		require.Equal(t, []string{"default/p1"}, test.ipam.GetPodByIP("10.16.0.10", "net1"))
+	})
+
+	t.Run("delete lsp without ip of deleted pod", func(t *testing.T) {
+		test := newIPAMAuditTest(t, true, []*corev1.Pod{newIPAMAuditTestPod("p2")}, nil,
+			newIPAMAuditTestLSP("p1", "10.16.0.10"), newIPAMAuditTestLSP("p2", "10.16.0.11"))
+		test.runIPAMAudit()
+		require.Len(t, test.ovnClient.lsps, 2)
+
+		// the lsp of the running pod is only reported
+		test.runIPAMAudit()
+		require.Len(t, test.ovnClient.lsps, 1)
+		require.Contains(t, test.ovnClient.lsps, "p2.default")
+	})
+
+	t.Run("report conflicts", func(t *testing.T) {
+		test := newIPAMAuditTest(t, true, []*corev1.Pod{newIPAMAuditTestPod("p1"), newIPAMAuditTestPod("p2")},
+			[]*kubeovnv1.IP{newIPAMAuditTestIP("p1", "10.16.0.10")}, newIPAMAuditTestLSP("p1", "10.16.0.10"))
+		_, _, _, err := test.ipam.GetStaticAddress("default/p2", "p2.default", "10.16.0.10", "", "net1", true)
+		require.NoError(t, err)
+
+		test.runIPAMAudit()
+		test.runIPAMAudit()
+		require.Equal(t, map[string]bool{auditIPConflict + "/net1/10.16.0.10": true}, test.lastAuditDiscrepancies)
+		require.Equal(t, []string{"default/p2"}, test.ipam.GetPodByIP("10.16.0.10", "net1"))
+		require.Empty(t, test.deletedIPs())
+	})
+}
@@ -0,0 +1,212 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	ovnipam "github.com/kubeovn/kube-ovn/pkg/ipam"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+const (
+	ipamCheckpointKey = "checkpoint"
+	// leave some room for the other fields of the configmap
+	maxIPAMCheckpointSize = 1000 * 1024
+)
+
+// checkpointTracker records nics observed while initializing IPAM from a checkpoint
+type checkpointTracker struct {
+	ipam     *ovnipam.IPAM
+	nics     map[string]map[string]bool
+	objects  map[string]string
+	conflict bool
+}
+
+func newCheckpointTracker(ipam *ovnipam.IPAM, restoredSubnets []string, objects map[string]string) *checkpointTracker {
+	t := &checkpointTracker{ipam: ipam, nics: make(map[string]map[string]bool, len(restoredSubnets)), objects: objects}
+	for _, subnet := range restoredSubnets {
+		t.nics[subnet] = map[string]bool{}
+	}
+	return t
+}
+
+// checkpointObjectKey returns the key of the pod, IP CR or node in the checkpoint
+func checkpointObjectKey(kind string, obj metav1.Object) string {
+	if obj.GetNamespace() != "" {
+		return fmt.Sprintf("%s/%s/%s", kind, obj.GetNamespace(), obj.GetName())
+	}
+	return fmt.Sprintf("%s/%s", kind, obj.GetName())
+}
+
+func checkpointObjectVersion(obj metav1.Object) string {
+	return fmt.Sprintf("%s/%s", obj.GetUID(), obj.GetResourceVersion())
+}
+
+// unchanged returns true if the object is recorded in the checkpoint with the same uid and resource version
+func (t *checkpointTracker) unchanged(kind string, obj metav1.Object) bool {
+	if t == nil {
+		return false
+	}
+	version, ok := t.objects[checkpointObjectKey(kind, obj)]
+	return ok && version == checkpointObjectVersion(obj)
+}
+
+// keep marks the nic of an unchanged object as in use, false is returned if the subnet
+// is not restored from the checkpoint and the address of the nic must be replayed
+func (t *checkpointTracker) keep(subnet, nic string) bool {
+	if t == nil {
+		return false
+	}
+	nics, ok := t.nics[subnet]
+	if !ok {
+		return false
+	}
+	nics[nic] = true
+	return true
+}
+
+// observe must be called before the address of nic is allocated in IPAM
+func (t *checkpointTracker) observe(subnet, nic, ip string) {
+	if t == nil {
+		return
+	}
+	nics, ok := t.nics[subnet]
+	if !ok {
+		return
+	}
+	nics[nic] = true
+	if t.ipam.NicAddressConflict(subnet, nic, ip) {
+		klog.Warningf("address %s of nic %s conflicts with checkpoint of subnet %s", ip, nic, subnet)
+		t.conflict = true
+	}
+}
+
+func (t *checkpointTracker) stale() bool {
+	return t != nil && t.conflict
+}
+
+// releaseStaleAddresses releases addresses in the checkpoint which are not used by any resource
+func (t *checkpointTracker) releaseStaleAddresses() {
+	if t == nil {
+		return
+	}
+	for subnet, nics := range t.nics {
+		if count := t.ipam.ReleaseNicsExcept(subnet, nics); count != 0 {
+			klog.Infof("released %d stale addresses of subnet %s in checkpoint", count, subnet)
+		}
+	}
+}
+
+func (c *Controller) loadIPAMCheckpoint() *ovnipam.Checkpoint {
+	cm, err := c.configMapsLister.ConfigMaps(c.config.PodNamespace).Get(util.IPAMCheckpointConfig)
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			klog.Errorf("failed to get ipam checkpoint: %v", err)
+		}
+		return nil
+	}
+	data := cm.BinaryData[ipamCheckpointKey]
+	if len(data) == 0 {
+		return nil
+	}
+
+	cp, err := ovnipam.UnmarshalCheckpoint(data)
+	if err != nil {
+		klog.Errorf("failed to decode ipam checkpoint: %v", err)
+		return nil
+	}
+	if age := time.Since(cp.Time); age > c.config.IPAMCheckpointMaxAge {
+		klog.Infof("ipam checkpoint was taken %s ago, ignore it", age.Round(time.Second))
+		return nil
+	}
+	return cp
+}
+
+// ipamCheckpointObjects returns the versions of the pods, IP CRs and nodes with allocated addresses,
+// which must be listed before the snapshot of IPAM since addresses are allocated before the objects are updated
+func (c *Controller) ipamCheckpointObjects() (map[string]string, error) {
+	pods, err := c.podsLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	ips, err := c.ipsLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := c.nodesLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make(map[string]string, len(pods)+len(ips)+len(nodes))
+	for _, pod := range pods {
+		if isPodAlive(pod) && pod.Annotations[util.AllocatedAnnotation] == "true" {
+			objects[checkpointObjectKey("pod", pod)] = checkpointObjectVersion(pod)
+		}
+	}
+	for _, ip := range ips {
+		objects[checkpointObjectKey("ip", ip)] = checkpointObjectVersion(ip)
+	}
+	for _, node := range nodes {
+		if node.Annotations[util.AllocatedAnnotation] == "true" {
+			objects[checkpointObjectKey("node", node)] = checkpointObjectVersion(node)
+		}
+	}
+	return objects, nil
+}
+
+func (c *Controller) saveIPAMCheckpoint() {
+	if !c.isLeader() {
+		return
+	}
+
+	objects, err := c.ipamCheckpointObjects()
+	if err != nil {
+		klog.Errorf("failed to list objects for ipam checkpoint: %v", err)
+		return
+	}
+	cp := c.ipam.Checkpoint()
+	cp.Objects = objects
+	data, err := cp.Marshal()
+	if err != nil {
+		klog.Errorf("failed to encode ipam checkpoint: %v", err)
+		return
+	}
+	if len(data) > maxIPAMCheckpointSize {
+		klog.Errorf("size of ipam checkpoint %d exceeds the limit %d, skip it", len(data), maxIPAMCheckpointSize)
+		return
+	}
+
+	cmClient := c.config.KubeClient.CoreV1().ConfigMaps(c.config.PodNamespace)
+	cm, err := c.configMapsLister.ConfigMaps(c.config.PodNamespace).Get(util.IPAMCheckpointConfig)
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			klog.Errorf("failed to get ipam checkpoint: %v", err)
+			return
+		}
+		cm = &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      util.IPAMCheckpointConfig,
+				Namespace: c.config.PodNamespace,
+			},
+			BinaryData: map[string][]byte{ipamCheckpointKey: data},
+		}
+		if _, err = cmClient.Create(context.Background(), cm, metav1.CreateOptions{}); err != nil {
+			klog.Errorf("failed to create ipam checkpoint: %v", err)
+		}
+		return
+	}
+
+	cm = cm.DeepCopy()
+	cm.BinaryData = map[string][]byte{ipamCheckpointKey: data}
+	if _, err = cmClient.Update(context.Background(), cm, metav1.UpdateOptions{}); err != nil {
+		klog.Errorf("failed to update ipam checkpoint: %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	ovnipam "github.com/kubeovn/kube-ovn/pkg/ipam"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func newInitIPAMTestController(t *testing.T, subnet *kubeovnv1.Subnet, pods ...*corev1.Pod) *testController {
+	objects := []runtime.Object{subnet}
+	for _, pod := range pods {
+		objects = append(objects, pod)
+	}
+	return newTestController(t, withObjects(objects...))
+}
+
+func newInitIPAMTestPod(name, resourceVersion, ip string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "default",
+			UID:             types.UID("uid-" + name),
+			ResourceVersion: resourceVersion,
+			Annotations: map[string]string{
+				util.AllocatedAnnotation:     "true",
+				util.LogicalSwitchAnnotation: "net1",
+				util.IpAddressAnnotation:     ip,
+			},
+		},
+	}
+}
+
+func podAddresses(c *testController, name string) []string {
+	var ips []string
+	for _, address := range c.ipam.GetPodAddress("default/" + name) {
+		ips = append(ips, address.Ip)
+	}
+	return ips
+}
+
+func TestInitIPAMFromCheckpoint(t *testing.T) {
+	subnet := &kubeovnv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: "net1"},
+		Spec: kubeovnv1.SubnetSpec{
+			CIDRBlock:  "10.16.0.0/24",
+			Gateway:    "10.16.0.1",
+			ExcludeIps: []string{"10.16.0.1"},
+			Protocol:   kubeovnv1.ProtocolIPv4,
+		},
+	}
+	c := newInitIPAMTestController(t, subnet,
+		newInitIPAMTestPod("p1", "1", "10.16.0.10"),
+		newInitIPAMTestPod("p2", "1", "10.16.0.11"),
+	)
+	stale, err := c.initIPAM(nil)
+	require.NoError(t, err)
+	require.False(t, stale)
+	objects, err := c.ipamCheckpointObjects()
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"pod/default/p1": "uid-p1/1", "pod/default/p2": "uid-p2/1"}, objects)
+	cp := c.ipam.Checkpoint()
+	cp.Objects = objects
+
+	t.Run("replay changed objects only", func(t *testing.T) {
+		// the annotation of an unchanged pod is not replayed, so the address in the checkpoint is kept
+		unchanged := newInitIPAMTestPod("p1", "1", "10.16.0.20")
+		c := newInitIPAMTestController(t, subnet, unchanged, newInitIPAMTestPod("p3", "1", "10.16.0.12"))
+		stale, err := c.initIPAM(cp)
+		require.NoError(t, err)
+		require.False(t, stale)
+		require.Equal(t, []string{"10.16.0.10"}, podAddresses(c, "p1"))
+		require.Equal(t, []string{"10.16.0.12"}, podAddresses(c, "p3"))
+		// the address of the pod no longer present is released
+		require.Empty(t, podAddresses(c, "p2"))
+	})
+
+	t.Run("recreated pod", func(t *testing.T) {
+		recreated := newInitIPAMTestPod("p1", "1", "10.16.0.10")
+		recreated.UID = "uid-p1-new"
+		c := newInitIPAMTestController(t, subnet, recreated)
+		stale, err := c.initIPAM(cp)
+		require.NoError(t, err)
+		require.False(t, stale)
+		require.Equal(t, []string{"10.16.0.10"}, podAddresses(c, "p1"))
+		require.Empty(t, podAddresses(c, "p2"))
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		c := newInitIPAMTestController(t, subnet, newInitIPAMTestPod("p1", "2", "10.16.0.20"))
+		stale, err := c.initIPAM(cp)
+		require.NoError(t, err)
+		require.True(t, stale)
+	})
+}
+
+func TestInitIPAMPodOwner(t *testing.T) {
+	subnet := &kubeovnv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: "net1"},
+		Spec: kubeovnv1.SubnetSpec{
+			CIDRBlock:  "10.16.0.0/24",
+			Gateway:    "10.16.0.1",
+			ExcludeIps: []string{"10.16.0.1"},
+			Protocol:   kubeovnv1.ProtocolIPv4,
+		},
+	}
+	isController := true
+	pod := newInitIPAMTestPod("p1", "1", "10.16.0.10")
+	pod.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "sts", Controller: &isController}}
+
+	// the owner is recorded when the address is restored without a checkpoint,
+	// so the address is held for the owner after the pod is deleted
+	c := newInitIPAMTestController(t, subnet, pod)
+	_, err := c.initIPAM(nil)
+	require.NoError(t, err)
+	require.NoError(t, c.ipam.SetReleasePolicy("net1", ovnipam.ReleasePolicy{ReuseTTL: time.Hour}))
+	c.ipam.ReleaseAddressByPod("default/p1")
+
+	ip, _, _, err := c.ipam.GetRandomAddressForOwner("default/p2", "p2.default", "net1", "", "default/StatefulSet/other", nil)
+	require.NoError(t, err)
+	require.NotEqual(t, "10.16.0.10", ip)
+	ip, _, _, err = c.ipam.GetRandomAddressForOwner("default/p1", "p1.default", "net1", "", getPodOwner(pod), nil)
+	require.NoError(t, err)
+	require.Equal(t, "10.16.0.10", ip)
+}
@@ -0,0 +1,388 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/emicklei/go-restful/v3"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ipam"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+// IPAMReservation is an address reserved by the IPAM API, it is recorded by an IP CR
+type IPAMReservation struct {
+	Name       string `json:"name"`
+	Subnet     string `json:"subnet,omitempty"`
+	IPAddress  string `json:"ipAddress,omitempty"`
+	MacAddress string `json:"macAddress,omitempty"`
+}
+
+// IPAMAddressOwner is the owner of an address in a subnet
+type IPAMAddressOwner struct {
+	Subnet    string   `json:"subnet"`
+	IPAddress string   `json:"ipAddress"`
+	Owners    []string `json:"owners"`
+}
+
+// IPAMPodAddress is an address allocated to a pod
+type IPAMPodAddress struct {
+	Subnet     string `json:"subnet"`
+	IPAddress  string `json:"ipAddress"`
+	MacAddress string `json:"macAddress"`
+}
+
+type ipamAPIError struct {
+	Err string `json:"error"`
+}
+
+// ipamReservationKey returns the pod name used in IPAM for the reservation
+func ipamReservationKey(name string) string {
+	return "reservation/" + name
+}
+
+// ipamReservationIPName returns the name of the IP CR which records the reservation
+func ipamReservationIPName(name string) string {
+	return "reservation." + name
+}
+
+// runIPAMServer serves the IPAM API, requests are authenticated by TokenReview
+// and authorized against the ipam subresource of subnets by SubjectAccessReview.
+// Bearer tokens must not be sent in plain text over the network, so the API is
+// only served on the loopback address if no TLS certificate is configured
+func (c *Controller) runIPAMServer() {
+	server := &http.Server{
+		Addr:    ipamServerAddr(c.config),
+		Handler: c.createIPAMHandler(),
+	}
+	klog.Infof("start ipam api server on %s", server.Addr)
+
+	var err error
+	if c.config.IPAMAPICertFile != "" {
+		err = server.ListenAndServeTLS(c.config.IPAMAPICertFile, c.config.IPAMAPIKeyFile)
+	} else {
+		klog.Warningf("no tls certificate is configured for the ipam api, serve plain http on the loopback address only")
+		err = server.ListenAndServe()
+	}
+	klog.Fatalf("ipam api server exited: %v", err)
+}
+
+func ipamServerAddr(config *Configuration) string {
+	if config.IPAMAPICertFile == "" {
+		return fmt.Sprintf("127.0.0.1:%d", config.IPAMAPIPort)
+	}
+	return fmt.Sprintf("0.0.0.0:%d", config.IPAMAPIPort)
+}
+
+func (c *Controller) createIPAMHandler() http.Handler {
+	wsContainer := restful.NewContainer()
+	wsContainer.EnableContentEncoding(true)
+
+	ws := new(restful.WebService)
+	ws.Path("/api/v1/ipam").
+		Consumes(restful.MIME_JSON).
+		Produces(restful.MIME_JSON)
+	wsContainer.Add(ws)
+
+	ws.Route(
+		ws.GET("/subnets/{subnet}").
+			Filter(c.ipamAuthFilter("get")).
+			To(c.handleGetSubnetAddresses))
+	ws.Route(
+		ws.GET("/addresses/{ip}").
+			Filter(c.ipamAuthFilter("get")).
+			To(c.handleLookupAddress))
+	ws.Route(
+		ws.GET("/pods/{namespace}/{pod}").
+			Filter(c.ipamAuthFilter("get")).
+			To(c.handleGetPodAddresses))
+	ws.Route(
+		ws.GET("/subnets/{subnet}/reservations").
+			Filter(c.ipamAuthFilter("get")).
+			To(c.handleListReservations))
+	ws.Route(
+		ws.POST("/subnets/{subnet}/reservations").
+			Filter(c.ipamAuthFilter("update")).
+			Filter(c.ipamLeaderFilter).
+			To(c.handleReserveAddress).
+			Reads(IPAMReservation{}))
+	ws.Route(
+		ws.DELETE("/subnets/{subnet}/reservations/{name}").
+			Filter(c.ipamAuthFilter("update")).
+			Filter(c.ipamLeaderFilter).
+			To(c.handleReleaseReservation))
+
+	ws.Filter(c.ipamReadyFilter)
+
+	return wsContainer
+}
+
+func writeIPAMError(resp *restful.Response, status int, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	if status >= http.StatusInternalServerError {
+		klog.Error(msg)
+	}
+	if err := resp.WriteHeaderAndEntity(status, ipamAPIError{Err: msg}); err != nil {
+		klog.Errorf("failed to write response, %v", err)
+	}
+}
+
+func writeIPAMResponse(resp *restful.Response, status int, entity interface{}) {
+	if err := resp.WriteHeaderAndEntity(status, entity); err != nil {
+		klog.Errorf("failed to write response, %v", err)
+	}
+}
+
+// ipamReadyFilter rejects requests before IPAM is initialized, which happens only after becoming the leader
+func (c *Controller) ipamReadyFilter(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+	if atomic.LoadInt32(&c.ipamInitialized) == 0 {
+		writeIPAMError(resp, http.StatusServiceUnavailable, "ipam is not initialized, the controller may not be the leader")
+		return
+	}
+	chain.ProcessFilter(req, resp)
+}
+
+// ipamLeaderFilter rejects writes if the controller is not the leader
+func (c *Controller) ipamLeaderFilter(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+	if !c.isLeader() {
+		writeIPAMError(resp, http.StatusServiceUnavailable, "the controller is not the leader")
+		return
+	}
+	chain.ProcessFilter(req, resp)
+}
+
+// ipamAuthFilter checks whether the bearer token is allowed to perform verb on the ipam subresource of the subnet,
+// requests not bound to a subnet are checked against all subnets
+func (c *Controller) ipamAuthFilter(verb string) restful.FilterFunction {
+	return func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+		authorization := req.HeaderParameter("Authorization")
+		token := strings.TrimPrefix(authorization, "Bearer ")
+		if token == "" || token == authorization {
+			writeIPAMError(resp, http.StatusUnauthorized, "bearer token is required")
+			return
+		}
+
+		tr, err := c.config.KubeClient.AuthenticationV1().TokenReviews().Create(context.Background(), &authenticationv1.TokenReview{
+			Spec: authenticationv1.TokenReviewSpec{Token: token},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			writeIPAMError(resp, http.StatusInternalServerError, "failed to review token, %v", err)
+			return
+		}
+		if !tr.Status.Authenticated {
+			writeIPAMError(resp, http.StatusUnauthorized, "invalid token")
+			return
+		}
+
+		subnet := req.PathParameter("subnet")
+		if subnet == "" {
+			subnet = req.QueryParameter("subnet")
+		}
+		user := tr.Status.User
+		extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+		for k, v := range user.Extra {
+			extra[k] = authorizationv1.ExtraValue(v)
+		}
+		sar, err := c.config.KubeClient.AuthorizationV1().SubjectAccessReviews().Create(context.Background(), &authorizationv1.SubjectAccessReview{
+			Spec: authorizationv1.SubjectAccessReviewSpec{
+				User:   user.Username,
+				Groups: user.Groups,
+				UID:    user.UID,
+				Extra:  extra,
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Verb:        verb,
+					Group:       kubeovnv1.SchemeGroupVersion.Group,
+					Resource:    "subnets",
+					Subresource: "ipam",
+					Name:        subnet,
+				},
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			writeIPAMError(resp, http.StatusInternalServerError, "failed to review access, %v", err)
+			return
+		}
+		if !sar.Status.Allowed {
+			writeIPAMError(resp, http.StatusForbidden, "user %s is not allowed to %s ipam of subnet %q", user.Username, verb, subnet)
+			return
+		}
+		chain.ProcessFilter(req, resp)
+	}
+}
+
+func (c *Controller) handleGetSubnetAddresses(req *restful.Request, resp *restful.Response) {
+	subnet := req.PathParameter("subnet")
+	addresses, err := c.ipam.GetSubnetAddresses(subnet)
+	if err != nil {
+		writeIPAMError(resp, http.StatusNotFound, "subnet %s not found", subnet)
+		return
+	}
+	writeIPAMResponse(resp, http.StatusOK, addresses)
+}
+
+func (c *Controller) handleLookupAddress(req *restful.Request, resp *restful.Response) {
+	ip := req.PathParameter("ip")
+	if net.ParseIP(ip) == nil {
+		writeIPAMError(resp, http.StatusBadRequest, "%s is not a valid ip address", ip)
+		return
+	}
+
+	var subnets []string
+	if subnet := req.QueryParameter("subnet"); subnet != "" {
+		subnets = append(subnets, subnet)
+	} else {
+		list, err := c.subnetsLister.List(labels.Everything())
+		if err != nil {
+			writeIPAMError(resp, http.StatusInternalServerError, "failed to list subnets, %v", err)
+			return
+		}
+		for _, subnet := range list {
+			subnets = append(subnets, subnet.Name)
+		}
+	}
+
+	owners := []IPAMAddressOwner{}
+	for _, subnet := range subnets {
+		if pods := c.ipam.GetPodByIP(ip, subnet); len(pods) != 0 {
+			owners = append(owners, IPAMAddressOwner{Subnet: subnet, IPAddress: ip, Owners: pods})
+		}
+	}
+	if len(owners) == 0 {
+		writeIPAMError(resp, http.StatusNotFound, "address %s is not allocated", ip)
+		return
+	}
+	writeIPAMResponse(resp, http.StatusOK, owners)
+}
+
+func (c *Controller) handleGetPodAddresses(req *restful.Request, resp *restful.Response) {
+	key := fmt.Sprintf("%s/%s", req.PathParameter("namespace"), req.PathParameter("pod"))
+	addresses := []IPAMPodAddress{}
+	for _, addr := range c.ipam.GetPodAddress(key) {
+		addresses = append(addresses, IPAMPodAddress{Subnet: addr.Subnet.Name, IPAddress: addr.Ip, MacAddress: addr.Mac})
+	}
+	if len(addresses) == 0 {
+		writeIPAMError(resp, http.StatusNotFound, "no address is allocated to pod %s", key)
+		return
+	}
+	writeIPAMResponse(resp, http.StatusOK, addresses)
+}
+
+func (c *Controller) handleListReservations(req *restful.Request, resp *restful.Response) {
+	subnet := req.PathParameter("subnet")
+	ips, err := c.ipsLister.List(labels.SelectorFromSet(labels.Set{util.SubnetNameLabel: subnet}))
+	if err != nil {
+		writeIPAMError(resp, http.StatusInternalServerError, "failed to list ips, %v", err)
+		return
+	}
+
+	reservations := []IPAMReservation{}
+	for _, ip := range ips {
+		if name := ip.Labels[util.IPReservationLabel]; name != "" {
+			reservations = append(reservations, IPAMReservation{Name: name, Subnet: ip.Spec.Subnet, IPAddress: ip.Spec.IPAddress, MacAddress: ip.Spec.MacAddress})
+		}
+	}
+	writeIPAMResponse(resp, http.StatusOK, reservations)
+}
+
+func (c *Controller) handleReserveAddress(req *restful.Request, resp *restful.Response) {
+	subnetName := req.PathParameter("subnet")
+	var reservation IPAMReservation
+	if err := req.ReadEntity(&reservation); err != nil {
+		writeIPAMError(resp, http.StatusBadRequest, "failed to parse request body, %v", err)
+		return
+	}
+	if errs := validation.IsDNS1123Label(reservation.Name); len(errs) != 0 {
+		writeIPAMError(resp, http.StatusBadRequest, "invalid reservation name %q: %s", reservation.Name, strings.Join(errs, ", "))
+		return
+	}
+	if _, err := c.subnetsLister.Get(subnetName); err != nil {
+		writeIPAMError(resp, http.StatusNotFound, "subnet %s not found", subnetName)
+		return
+	}
+	ipName := ipamReservationIPName(reservation.Name)
+	if _, err := c.ipsLister.Get(ipName); err == nil {
+		writeIPAMError(resp, http.StatusConflict, "reservation %s already exists", reservation.Name)
+		return
+	}
+
+	key := ipamReservationKey(reservation.Name)
+	var v4IP, v6IP, mac string
+	var err error
+	if reservation.IPAddress != "" {
+		v4IP, v6IP, mac, err = c.ipam.GetStaticAddress(key, ipName, reservation.IPAddress, reservation.MacAddress, subnetName, true)
+	} else {
+		v4IP, v6IP, mac, err = c.ipam.GetRandomAddress(key, ipName, subnetName, nil)
+	}
+	if err != nil {
+		c.ipam.ReleaseAddressByPod(key)
+		switch err {
+		case ipam.ErrConflict, ipam.ErrNoAvailable:
+			writeIPAMError(resp, http.StatusConflict, "failed to reserve address in subnet %s, %v", subnetName, err)
+		default:
+			writeIPAMError(resp, http.StatusBadRequest, "failed to reserve address in subnet %s, %v", subnetName, err)
+		}
+		return
+	}
+
+	ipStr := util.GetStringIP(v4IP, v6IP)
+	ip := &kubeovnv1.IP{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: ipName,
+			Labels: map[string]string{
+				util.SubnetNameLabel:    subnetName,
+				subnetName:              "",
+				util.IPReservationLabel: reservation.Name,
+			},
+		},
+		Spec: kubeovnv1.IPSpec{
+			PodName:       reservation.Name,
+			Subnet:        subnetName,
+			IPAddress:     ipStr,
+			V4IPAddress:   v4IP,
+			V6IPAddress:   v6IP,
+			MacAddress:    mac,
+			AttachIPs:     []string{},
+			AttachMacs:    []string{},
+			AttachSubnets: []string{},
+		},
+	}
+	if _, err = c.config.KubeOvnClient.KubeovnV1().IPs().Create(context.Background(), ip, metav1.CreateOptions{}); err != nil {
+		c.ipam.ReleaseAddressByPod(key)
+		writeIPAMError(resp, http.StatusInternalServerError, "failed to create ip %s, %v", ipName, err)
+		return
+	}
+
+	klog.Infof("reserve address %s in subnet %s for %s", ipStr, subnetName, reservation.Name)
+	writeIPAMResponse(resp, http.StatusCreated, IPAMReservation{Name: reservation.Name, Subnet: subnetName, IPAddress: ipStr, MacAddress: mac})
+}
+
+func (c *Controller) handleReleaseReservation(req *restful.Request, resp *restful.Response) {
+	subnetName, name := req.PathParameter("subnet"), req.PathParameter("name")
+	ipName := ipamReservationIPName(name)
+	ip, err := c.ipsLister.Get(ipName)
+	if err != nil || ip.Labels[util.IPReservationLabel] != name || ip.Spec.Subnet != subnetName {
+		writeIPAMError(resp, http.StatusNotFound, "reservation %s not found in subnet %s", name, subnetName)
+		return
+	}
+
+	if err = c.config.KubeOvnClient.KubeovnV1().IPs().Delete(context.Background(), ipName, metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+		writeIPAMError(resp, http.StatusInternalServerError, "failed to delete ip %s, %v", ipName, err)
+		return
+	}
+	c.ipam.ReleaseAddressByPod(ipamReservationKey(name))
+
+	klog.Infof("release address %s in subnet %s reserved by %s", ip.Spec.IPAddress, subnetName, name)
+	resp.WriteHeader(http.StatusNoContent)
+}
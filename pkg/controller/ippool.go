@@ -0,0 +1,298 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func (c *Controller) enqueueAddIPPool(obj interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	var key string
+	var err error
+	if key, err = cache.MetaNamespaceKeyFunc(obj); err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	klog.V(3).Infof("enqueue add ip pool %s", key)
+	c.addOrUpdateIPPoolQueue.Add(key)
+}
+
+func (c *Controller) enqueueUpdateIPPool(old, new interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	oldPool := old.(*kubeovnv1.IPPool)
+	newPool := new.(*kubeovnv1.IPPool)
+	if reflect.DeepEqual(oldPool.Spec, newPool.Spec) {
+		return
+	}
+
+	var key string
+	var err error
+	if key, err = cache.MetaNamespaceKeyFunc(new); err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	klog.V(3).Infof("enqueue update ip pool %s", key)
+	c.addOrUpdateIPPoolQueue.Add(key)
+}
+
+func (c *Controller) enqueueDeleteIPPool(obj interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	var key string
+	var err error
+	if key, err = cache.DeletionHandlingMetaNamespaceKeyFunc(obj); err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	klog.V(3).Infof("enqueue delete ip pool %s", key)
+	c.delIPPoolQueue.Add(key)
+}
+
+func (c *Controller) runAddOrUpdateIPPoolWorker() {
+	for c.processNextAddOrUpdateIPPoolWorkItem() {
+	}
+}
+
+func (c *Controller) runDelIPPoolWorker() {
+	for c.processNextDeleteIPPoolWorkItem() {
+	}
+}
+
+func (c *Controller) runUpdateIPPoolStatusWorker() {
+	for c.processNextUpdateIPPoolStatusWorkItem() {
+	}
+}
+
+func (c *Controller) processNextAddOrUpdateIPPoolWorkItem() bool {
+	obj, shutdown := c.addOrUpdateIPPoolQueue.Get()
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.addOrUpdateIPPoolQueue.Done(obj)
+		var key string
+		var ok bool
+		if key, ok = obj.(string); !ok {
+			c.addOrUpdateIPPoolQueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+			return nil
+		}
+		if err := c.handleAddOrUpdateIPPool(key); err != nil {
+			c.addOrUpdateIPPoolQueue.AddRateLimited(key)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
+		}
+		c.addOrUpdateIPPoolQueue.Forget(obj)
+		return nil
+	}(obj)
+
+	if err != nil {
+		utilruntime.HandleError(err)
+		return true
+	}
+	return true
+}
+
+func (c *Controller) processNextDeleteIPPoolWorkItem() bool {
+	obj, shutdown := c.delIPPoolQueue.Get()
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.delIPPoolQueue.Done(obj)
+		var key string
+		var ok bool
+		if key, ok = obj.(string); !ok {
+			c.delIPPoolQueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+			return nil
+		}
+		if err := c.handleDeleteIPPool(key); err != nil {
+			c.delIPPoolQueue.AddRateLimited(key)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
+		}
+		c.delIPPoolQueue.Forget(obj)
+		return nil
+	}(obj)
+
+	if err != nil {
+		utilruntime.HandleError(err)
+		return true
+	}
+	return true
+}
+
+func (c *Controller) processNextUpdateIPPoolStatusWorkItem() bool {
+	obj, shutdown := c.updateIPPoolStatusQueue.Get()
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.updateIPPoolStatusQueue.Done(obj)
+		var key string
+		var ok bool
+		if key, ok = obj.(string); !ok {
+			c.updateIPPoolStatusQueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+			return nil
+		}
+		if err := c.handleUpdateIPPoolStatus(key); err != nil {
+			c.updateIPPoolStatusQueue.AddRateLimited(key)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
+		}
+		c.updateIPPoolStatusQueue.Forget(obj)
+		return nil
+	}(obj)
+
+	if err != nil {
+		utilruntime.HandleError(err)
+		return true
+	}
+	return true
+}
+
+func (c *Controller) handleAddOrUpdateIPPool(key string) error {
+	cachedPool, err := c.ipPoolsLister.Get(key)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	pool := cachedPool.DeepCopy()
+	klog.Infof("handle add or update ip pool %s", pool.Name)
+
+	if err = c.ipam.AddOrUpdateIPPool(pool.Spec.Subnet, pool.Name, pool.Spec.IPs); err != nil {
+		klog.Errorf("failed to add ip pool %s to subnet %s: %v", pool.Name, pool.Spec.Subnet, err)
+		c.recorder.Eventf(pool, v1.EventTypeWarning, "AddIPPoolFailed", err.Error())
+		return err
+	}
+
+	c.updateIPPoolStatusQueue.Add(pool.Name)
+	return nil
+}
+
+func (c *Controller) handleDeleteIPPool(key string) error {
+	klog.Infof("handle delete ip pool %s", key)
+	c.ipam.RemoveIPPool(key)
+	return nil
+}
+
+func (c *Controller) handleUpdateIPPoolStatus(key string) error {
+	cachedPool, err := c.ipPoolsLister.Get(key)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	pool := cachedPool.DeepCopy()
+
+	v4Available, v4Using, v6Available, v6Using, err := c.ipam.IPPoolStatistics(pool.Spec.Subnet, pool.Name)
+	if err != nil {
+		klog.Errorf("failed to get statistics of ip pool %s: %v", pool.Name, err)
+		return err
+	}
+	status := kubeovnv1.IPPoolStatus{
+		V4AvailableIPs: v4Available,
+		V4UsingIPs:     v4Using,
+		V6AvailableIPs: v6Available,
+		V6UsingIPs:     v6Using,
+	}
+	if reflect.DeepEqual(pool.Status, status) {
+		return nil
+	}
+
+	pool.Status = status
+	bytes, err := pool.Status.Bytes()
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	if _, err = c.config.KubeOvnClient.KubeovnV1().IPPools().Patch(context.Background(), pool.Name, types.MergePatchType, bytes, metav1.PatchOptions{}, "status"); err != nil {
+		klog.Errorf("failed to patch status of ip pool %s: %v", pool.Name, err)
+		return err
+	}
+	return nil
+}
+
+// enqueueSubnetIPPools updates status of all ip pools in the subnet
+func (c *Controller) enqueueSubnetIPPools(subnet string) {
+	pools, err := c.ipPoolsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list ip pools: %v", err)
+		return
+	}
+	for _, pool := range pools {
+		if pool.Spec.Subnet == subnet {
+			c.updateIPPoolStatusQueue.Add(pool.Name)
+		}
+	}
+}
+
+// getPodIPPool returns the ip pool the pod should allocate address from, the pool
+// can be specified by annotation or matched by namespace and label selector
+func (c *Controller) getPodIPPool(pod *v1.Pod, subnet, poolName string) (string, error) {
+	if poolName != "" {
+		pool, err := c.ipPoolsLister.Get(poolName)
+		if err != nil {
+			klog.Errorf("failed to get ip pool %s: %v", poolName, err)
+			return "", err
+		}
+		if pool.Spec.Subnet != subnet {
+			return "", fmt.Errorf("ip pool %s does not belong to subnet %s", poolName, subnet)
+		}
+		if len(pool.Spec.Namespaces) != 0 && !util.ContainsString(pool.Spec.Namespaces, pod.Namespace) {
+			return "", fmt.Errorf("ip pool %s is not available for namespace %s", poolName, pod.Namespace)
+		}
+		return poolName, nil
+	}
+
+	pools, err := c.ipPoolsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list ip pools: %v", err)
+		return "", err
+	}
+	sort.Slice(pools, func(i, j int) bool { return pools[i].Name < pools[j].Name })
+	for _, pool := range pools {
+		if pool.Spec.Subnet != subnet || (len(pool.Spec.Namespaces) == 0 && pool.Spec.Selector == nil) {
+			continue
+		}
+		if len(pool.Spec.Namespaces) != 0 && !util.ContainsString(pool.Spec.Namespaces, pod.Namespace) {
+			continue
+		}
+		if pool.Spec.Selector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(pool.Spec.Selector)
+			if err != nil {
+				klog.Errorf("invalid selector of ip pool %s: %v", pool.Name, err)
+				continue
+			}
+			if !selector.Matches(labels.Set(pod.Labels)) {
+				continue
+			}
+		}
+		return pool.Name, nil
+	}
+	return "", nil
+}
@@ -46,6 +46,13 @@ func (c *Controller) enqueueDeleteNamespace(obj interface{}) {
 			c.updateNpQueue.Add(np)
 		}
 	}
+
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.deleteNamespaceQueue.Add(key)
 }
 
 func (c *Controller) enqueueUpdateNamespace(old, new interface{}) {
@@ -70,6 +77,12 @@ func (c *Controller) enqueueUpdateNamespace(old, new interface{}) {
 	if newNs.Annotations == nil || newNs.Annotations[util.LogicalSwitchAnnotation] == "" {
 		klog.Warningf("no logical switch annotation for ns %s", newNs.Name)
 		c.addNamespaceQueue.Add(newNs.Name)
+		return
+	}
+
+	// the namespace may match a subnet template now
+	if !reflect.DeepEqual(oldNs.Labels, newNs.Labels) {
+		c.addNamespaceQueue.Add(newNs.Name)
 	}
 }
 
@@ -110,6 +123,43 @@ func (c *Controller) processNextAddNamespaceWorkItem() bool {
 	return true
 }
 
+func (c *Controller) runDeleteNamespaceWorker() {
+	for c.processNextDeleteNamespaceWorkItem() {
+	}
+}
+
+func (c *Controller) processNextDeleteNamespaceWorkItem() bool {
+	obj, shutdown := c.deleteNamespaceQueue.Get()
+
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.deleteNamespaceQueue.Done(obj)
+		var key string
+		var ok bool
+		if key, ok = obj.(string); !ok {
+			c.deleteNamespaceQueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+			return nil
+		}
+		if err := c.handleDeleteNamespace(key); err != nil {
+			c.deleteNamespaceQueue.AddRateLimited(key)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
+		}
+		c.deleteNamespaceQueue.Forget(obj)
+		return nil
+	}(obj)
+
+	if err != nil {
+		utilruntime.HandleError(err)
+		return true
+	}
+
+	return true
+}
+
 func (c *Controller) handleAddNamespace(key string) error {
 	orinamespace, err := c.namespacesLister.Get(key)
 	if err != nil {
@@ -127,8 +177,20 @@ func (c *Controller) handleAddNamespace(key string) error {
 		klog.Errorf("failed to list subnets %v", err)
 		return err
 	}
+	carved, err := c.carveNamespaceSubnet(namespace)
+	if err != nil {
+		return err
+	}
+	if carved != nil {
+		ls = carved.Name
+		cidr = carved.Spec.CIDRBlock
+		excludeIps = carved.Spec.ExcludeIps
+	}
 	// check if subnet bind ns
 	for _, s := range subnets {
+		if ls != "" {
+			break
+		}
 		for _, ns := range s.Spec.Namespaces {
 			if ns == key {
 				ls = s.Name
@@ -137,9 +199,6 @@ func (c *Controller) handleAddNamespace(key string) error {
 				break
 			}
 		}
-		if ls != "" {
-			break
-		}
 	}
 
 	if ls == "" {
@@ -198,3 +257,14 @@ func (c *Controller) handleAddNamespace(key string) error {
 	}
 	return err
 }
+
+// handleDeleteNamespace release the blocks carved from subnet templates for the namespace
+func (c *Controller) handleDeleteNamespace(key string) error {
+	// the namespace may be created again
+	if _, err := c.namespacesLister.Get(key); err == nil {
+		return nil
+	} else if !errors.IsNotFound(err) {
+		return err
+	}
+	return c.releaseNamespaceSubnets(key)
+}
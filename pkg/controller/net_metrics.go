@@ -24,9 +24,86 @@ var (
 			"protocol",
 			"subnet_cidr",
 		})
+
+	metricSubnetTotalIPs = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "subnet_total_ip_count",
+			Help: "The total num of ip address of each protocol in subnet.",
+		},
+		[]string{
+			"subnet_name",
+			"protocol",
+		})
+
+	metricSubnetReservedIPs = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "subnet_reserved_ip_count",
+			Help: "The num of excluded ip address of each protocol in subnet.",
+		},
+		[]string{
+			"subnet_name",
+			"protocol",
+		})
+
+	metricSubnetProtocolAvailableIPs = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "subnet_protocol_available_ip_count",
+			Help: "The available num of ip address of each protocol in subnet.",
+		},
+		[]string{
+			"subnet_name",
+			"protocol",
+		})
+
+	metricSubnetProtocolUsedIPs = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "subnet_protocol_used_ip_count",
+			Help: "The used num of ip address of each protocol in subnet.",
+		},
+		[]string{
+			"subnet_name",
+			"protocol",
+		})
+
+	metricSubnetUtilization = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "subnet_ip_utilization_percent",
+			Help: "The percentage of used ip address of each protocol in subnet.",
+		},
+		[]string{
+			"subnet_name",
+			"protocol",
+		})
+
+	metricIPAMAuditDiscrepancies = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ipam_audit_discrepancies",
+			Help: "The num of discrepancies between IPAM, IP CRs and logical switch ports found by the last audit.",
+		},
+		[]string{
+			"subnet_name",
+			"type",
+		})
+
+	metricIPAMAuditRepairs = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ipam_audit_repairs_total",
+			Help: "The num of discrepancies repaired by IPAM audit.",
+		},
+		[]string{
+			"subnet_name",
+			"type",
+		})
 )
 
 func registerMetrics() {
 	prometheus.MustRegister(metricSubnetAvailableIPs)
 	prometheus.MustRegister(metricSubnetUsedIPs)
+	prometheus.MustRegister(metricSubnetTotalIPs)
+	prometheus.MustRegister(metricSubnetReservedIPs)
+	prometheus.MustRegister(metricSubnetProtocolAvailableIPs)
+	prometheus.MustRegister(metricSubnetProtocolUsedIPs)
+	prometheus.MustRegister(metricSubnetUtilization)
+	prometheus.MustRegister(metricIPAMAuditDiscrepancies)
+	prometheus.MustRegister(metricIPAMAuditRepairs)
 }
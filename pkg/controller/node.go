@@ -367,6 +367,17 @@ func (c *Controller) handleAddNode(key string) error {
 		return err
 	}
 
+	subnets, err = c.subnetsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list subnets: %v", err)
+		return err
+	}
+	for _, subnet := range subnets {
+		if subnet.Spec.NodeBlockSize > 0 {
+			c.enqueueLeaseIPBlock(subnet.Name, key)
+		}
+	}
+
 	return nil
 }
 
@@ -413,6 +424,11 @@ func (c *Controller) handleDeleteNode(key string) error {
 
 	c.ipam.ReleaseAddressByPod(portName)
 
+	if err := c.deleteIPBlocks("", key); err != nil {
+		klog.Errorf("failed to delete ip blocks of node %s: %v", key, err)
+		return err
+	}
+
 	providerNetworks, err := c.providerNetworksLister.List(labels.Everything())
 	if err != nil && !k8serrors.IsNotFound(err) {
 		klog.Errorf("failed to list provider networks: %v", err)
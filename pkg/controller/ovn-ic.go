@@ -68,7 +68,7 @@ func (c *Controller) resyncInterConnection() {
 		}
 		for _, subnet := range subnets {
 			if subnet.Spec.DisableInterConnection || subnet.Name == c.config.NodeSwitch {
-				blackList = append(blackList, subnet.Spec.CIDRBlock)
+				blackList = append(blackList, strings.Split(subnetCIDRBlocks(subnet), ",")...)
 			}
 		}
 		nodes, err := c.nodesLister.List(labels.Everything())
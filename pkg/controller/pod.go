@@ -13,6 +13,7 @@ import (
 
 	"gopkg.in/k8snetworkplumbingwg/multus-cni.v3/pkg/logging"
 	multustypes "gopkg.in/k8snetworkplumbingwg/multus-cni.v3/pkg/types"
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -230,6 +231,12 @@ func (c *Controller) enqueueUpdatePod(oldObj, newObj interface{}) {
 		return
 	}
 
+	// addresses in ip blocks of nodes are allocated once the pod is bound
+	if oldPod.Spec.NodeName == "" && newPod.Spec.NodeName != "" && len(needAllocateSubnets(newPod, podNets)) != 0 {
+		klog.V(3).Infof("enqueue add pod %s bound to node %s", key, newPod.Spec.NodeName)
+		c.addPodQueue.Add(key)
+	}
+
 	// pod assigned an ip
 	if newPod.Annotations[util.AllocatedAnnotation] == "true" &&
 		newPod.Spec.NodeName != "" {
@@ -499,6 +506,56 @@ func (c *Controller) getPodKubeovnNets(pod *v1.Pod) ([]*kubeovnNet, error) {
 	return podNets, nil
 }
 
+// subnetCIDRAndGatewayByIP returns cidr blocks and gateways of the subnet which contain the ips
+func subnetCIDRAndGatewayByIP(subnet *kubeovnv1.Subnet, ipStr string) (string, string) {
+	cidr, gw := checkoutCidrByIP(ipStr, subnet.Spec.CIDRBlock), checkoutCidrByIP(ipStr, subnet.Spec.Gateway)
+	if len(subnet.Spec.ExtraCIDRBlocks) == 0 {
+		return cidr, gw
+	}
+	gateways, err := subnetGateways(subnet)
+	if err != nil {
+		klog.Errorf("failed to get gateways of subnet %s, %v", subnet.Name, err)
+		return cidr, gw
+	}
+
+	cidrBlocks, gwList := strings.Split(subnetCIDRBlocks(subnet), ","), strings.Split(gateways, ",")
+	if len(cidrBlocks) != len(gwList) {
+		return cidr, gw
+	}
+	var cidrs, gws []string
+	for _, ip := range strings.Split(ipStr, ",") {
+		for i, cidrBlock := range cidrBlocks {
+			if util.CIDRContainIP(cidrBlock, ip) {
+				cidrs = append(cidrs, cidrBlock)
+				gws = append(gws, gwList[i])
+				break
+			}
+		}
+	}
+	if len(cidrs) == 0 {
+		return cidr, gw
+	}
+	return strings.Join(cidrs, ","), strings.Join(gws, ",")
+}
+
+// subnetContainIP returns true if all the ips are in cidr blocks of the subnet
+func subnetContainIP(subnet *kubeovnv1.Subnet, ipStr string) bool {
+	cidrBlocks := strings.Split(subnetCIDRBlocks(subnet), ",")
+	for _, ip := range strings.Split(ipStr, ",") {
+		var found bool
+		for _, cidrBlock := range cidrBlocks {
+			if util.CIDRContainIP(cidrBlock, ip) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
 func checkoutCidrByIP(ipStr, cidr string) string {
 	cidrs := strings.Split(cidr, ",")
 	if len(cidr) == 1 {
@@ -586,8 +643,22 @@ func (c *Controller) handleAddPod(key string) error {
 		pod.Annotations = map[string]string{}
 	}
 
-	// Avoid create lsp for already running pod in ovn-nb when controller restart
+	var deferred bool
+	allocateNets := make([]*kubeovnNet, 0, len(podNets))
 	for _, podNet := range needAllocateSubnets(pod, podNets) {
+		if waitForNodeBlock(pod, podNet) {
+			klog.Infof("allocate address of pod %s in subnet %s after it is bound to a node", key, podNet.Subnet.Name)
+			deferred = true
+			continue
+		}
+		allocateNets = append(allocateNets, podNet)
+	}
+	if deferred && len(allocateNets) == 0 {
+		return nil
+	}
+
+	// Avoid create lsp for already running pod in ovn-nb when controller restart
+	for _, podNet := range allocateNets {
 		subnet := podNet.Subnet
 		var mac, ipStr string
 		var cidrAnnotationValue, gwAnnotationValue string
@@ -599,13 +670,16 @@ func (c *Controller) handleAddPod(key string) error {
 				return err
 			}
 			ipStr = util.GetStringIP(v4IP, v6IP)
-			cidrAnnotationValue = checkoutCidrByIP(ipStr, subnet.Spec.CIDRBlock)
-			gwAnnotationValue = checkoutCidrByIP(ipStr, subnet.Spec.Gateway)
+			cidrAnnotationValue, gwAnnotationValue = subnetCIDRAndGatewayByIP(subnet, ipStr)
 		} else {
 			ipStr = util.CIDRNone
 			mac = pod.Annotations[fmt.Sprintf(util.MacAddressAnnotationTemplate, podNet.ProviderName)]
 			if mac == "" {
-				mac = util.GenerateMac()
+				if subnet.Spec.MacPrefix != "" {
+					mac = util.GenerateMacWithPrefix(strings.ToUpper(subnet.Spec.MacPrefix))
+				} else {
+					mac = util.GenerateMac()
+				}
 			}
 			klog.Infof("allocate mac %s for %s", mac, pod.Name)
 			cidrAnnotationValue = subnet.Spec.CIDRBlock
@@ -628,7 +702,7 @@ func (c *Controller) handleAddPod(key string) error {
 
 		isDefaultRoute := pod.Annotations[fmt.Sprintf(util.DefaultRouteAnnotationTemplate, podNet.ProviderName)] == "true"
 
-		if err := util.ValidatePodCidr(podNet.Subnet.Spec.CIDRBlock, ipStr); err != nil {
+		if err := util.ValidatePodCidr(subnetCIDRBlocks(podNet.Subnet), ipStr); err != nil {
 			klog.Errorf("validate pod %s/%s failed: %v", namespace, name, err)
 			c.recorder.Eventf(pod, v1.EventTypeWarning, "ValidatePodNetworkFailed", err.Error())
 			return err
@@ -688,6 +762,9 @@ func (c *Controller) handleAddPod(key string) error {
 				c.recorder.Eventf(pod, v1.EventTypeWarning, "CreateOVNPortFailed", err.Error())
 				return err
 			}
+			if ipCr, err := c.ipsLister.Get(portName); err == nil && hasDHCPOptionsOverride(ipCr) {
+				c.updateIPDHCPOptionsQueue.Add(portName)
+			}
 
 			if pod.Annotations[fmt.Sprintf(util.Layer2ForwardAnnotationTemplate, podNet.ProviderName)] == "true" {
 				if err := c.ovnLegacyClient.EnablePortLayer2forward(subnet.Name, portName); err != nil {
@@ -768,7 +845,7 @@ func (c *Controller) handleDeletePod(pod *v1.Pod) error {
 				return err
 			}
 			if subnet.Spec.Vpc == util.DefaultVpc {
-				if err := c.ovnClient.DeleteLogicalRouterStaticRoute(vpc.Status.Router, &policySrcIP, address.Ip, ""); err != nil {
+				if err := c.ovnClient.DeleteLogicalRouterStaticRoute(vpc.Status.Router, nil, &policySrcIP, address.Ip, ""); err != nil {
 					return err
 				}
 				//if err := c.ovnLegacyClient.DeleteNatRule(address.Ip, vpc.Status.Router); err != nil {
@@ -942,41 +1019,13 @@ func (c *Controller) handleUpdatePodIPAddress(key string) error {
 			}
 		}
 
-		isDefaultRoute := pod.Annotations[fmt.Sprintf(util.DefaultRouteAnnotationTemplate, podNet.ProviderName)] == "true"
-
-		// validate dhcp option
-		var dhcpOptions *ovs.DHCPOptionsUUIDs
-		if podNet.Subnet.Spec.EnableDHCP {
-			dhcpV4Options := strings.Split(podNet.Subnet.Status.DHCPv4OptionsUUID, ",")
-			dhcpV6Options := strings.Split(podNet.Subnet.Status.DHCPv6OptionsUUID, ",")
-			dhcpOptions = &ovs.DHCPOptionsUUIDs{
-				DHCPv4OptionsUUID: dhcpV4Options[0],
-				DHCPv6OptionsUUID: dhcpV6Options[0],
-			}
-			if !isDefaultRoute {
-				if len(dhcpV4Options) > 1 {
-					dhcpOptions.DHCPv4OptionsUUID = dhcpV4Options[1]
-				}
-
-				if len(dhcpV6Options) > 1 {
-					dhcpOptions.DHCPv6OptionsUUID = dhcpV6Options[1]
-				}
-			}
-
-			if (v6 != nil && dhcpOptions.DHCPv6OptionsUUID == "") ||
-				(v4 != nil && dhcpOptions.DHCPv4OptionsUUID == "") {
-				return fmt.Errorf("failed to get DHCPv6OptionsUUID from subnet %s, please check", podNet.Subnet.Name)
-			}
-		} else {
-			dhcpOptions = &ovs.DHCPOptionsUUIDs{}
-		}
-
 		nicName := ovs.PodNameToPortName(pod.Name, pod.Namespace, podNet.ProviderName)
 		klog.Infof("migrate pod nic ip addr from %s to %s", nowIPAddress, migrateIPAddress)
 		// change pod annotation
 		pod.Annotations[fmt.Sprintf(util.IpAddressAnnotationTemplate, podNet.ProviderName)] = migrateIPAddress
-		pod.Annotations[fmt.Sprintf(util.CidrAnnotationTemplate, podNet.ProviderName)] = checkoutCidrByIP(migrateIPAddress, podNet.Subnet.Spec.CIDRBlock)
-		pod.Annotations[fmt.Sprintf(util.GatewayAnnotationTemplate, podNet.ProviderName)] = checkoutCidrByIP(migrateIPAddress, podNet.Subnet.Spec.Gateway)
+		cidr, gw := subnetCIDRAndGatewayByIP(podNet.Subnet, migrateIPAddress)
+		pod.Annotations[fmt.Sprintf(util.CidrAnnotationTemplate, podNet.ProviderName)] = cidr
+		pod.Annotations[fmt.Sprintf(util.GatewayAnnotationTemplate, podNet.ProviderName)] = gw
 		annotationChange = true
 
 		// ipam release old ip
@@ -988,39 +1037,7 @@ func (c *Controller) handleUpdatePodIPAddress(key string) error {
 			return err
 		}
 
-		// sync logical port address
-		if err = c.ovnClient.SetLogicalSwitchPortAddress(nicName, mac, migrateIPAddress); err != nil {
-			klog.Errorf("set port addresses failed, %v", err)
-			return err
-		}
-		// sync external id
-		if err = c.ovnClient.SetLogicalSwitchPortExternalIds(nicName, map[string]string{"ip": strings.ReplaceAll(migrateIPAddress, ",", "/")}); err != nil {
-			klog.Errorf("failed to set port external ids, %v", err)
-			return err
-		}
-
-		// sync port security
-		if pod.Annotations[fmt.Sprintf(util.PortSecurityAnnotationTemplate, podNet.ProviderName)] == "true" {
-			ipStr := migrateIPAddress
-			vips := pod.Annotations[fmt.Sprintf(util.PortVipAnnotationTemplate, podNet.ProviderName)]
-			if err = c.ovnClient.SetLogicalSwitchPortSecurity(true, nicName, mac, ipStr, vips); err != nil {
-				klog.Errorf("setPortSecurity failed. %v", err)
-				return err
-			}
-		}
-
-		// sync dhcp options
-		err = c.ovnClient.SetLogicalSwitchPortDHCPOptions(nicName, dhcpOptions.DHCPv4OptionsUUID, kubeovnv1.ProtocolIPv4)
-		if err != nil {
-			return fmt.Errorf("failed to set port %s dhcpv4 options, %s", nicName, err.Error())
-		}
-		err = c.ovnClient.SetLogicalSwitchPortDHCPOptions(nicName, dhcpOptions.DHCPv6OptionsUUID, kubeovnv1.ProtocolIPv6)
-		if err != nil {
-			return fmt.Errorf("failed to set port %s dhcpv6 options, %s", nicName, err.Error())
-		}
-
-		// update ip crd
-		if err = c.updateIPCRD(context.Background(), nicName, migrateIPAddress, v4Str, v6Str); err != nil {
+		if err = c.setPodNicAddress(pod, podNet, nicName, mac, migrateIPAddress, v4Str, v6Str); err != nil {
 			return err
 		}
 	}
@@ -1041,6 +1058,76 @@ func (c *Controller) handleUpdatePodIPAddress(key string) error {
 	return nil
 }
 
+// setPodNicAddress sync the logical switch port and the ip cr of the pod nic with the address,
+// the address must have been allocated in ipam
+func (c *Controller) setPodNicAddress(pod *v1.Pod, podNet *kubeovnNet, nicName, mac, ipStr, v4IP, v6IP string) error {
+	var err error
+	isDefaultRoute := pod.Annotations[fmt.Sprintf(util.DefaultRouteAnnotationTemplate, podNet.ProviderName)] == "true"
+
+	// validate dhcp option
+	var dhcpOptions *ovs.DHCPOptionsUUIDs
+	if podNet.Subnet.Spec.EnableDHCP {
+		dhcpV4Options := strings.Split(podNet.Subnet.Status.DHCPv4OptionsUUID, ",")
+		dhcpV6Options := strings.Split(podNet.Subnet.Status.DHCPv6OptionsUUID, ",")
+		dhcpOptions = &ovs.DHCPOptionsUUIDs{
+			DHCPv4OptionsUUID: dhcpV4Options[0],
+			DHCPv6OptionsUUID: dhcpV6Options[0],
+		}
+		if !isDefaultRoute {
+			if len(dhcpV4Options) > 1 {
+				dhcpOptions.DHCPv4OptionsUUID = dhcpV4Options[1]
+			}
+
+			if len(dhcpV6Options) > 1 {
+				dhcpOptions.DHCPv6OptionsUUID = dhcpV6Options[1]
+			}
+		}
+
+		if (v6IP != "" && dhcpOptions.DHCPv6OptionsUUID == "") ||
+			(v4IP != "" && dhcpOptions.DHCPv4OptionsUUID == "") {
+			return fmt.Errorf("failed to get DHCPv6OptionsUUID from subnet %s, please check", podNet.Subnet.Name)
+		}
+	} else {
+		dhcpOptions = &ovs.DHCPOptionsUUIDs{}
+	}
+
+	// sync logical port address
+	if err = c.ovnClient.SetLogicalSwitchPortAddress(nicName, mac, ipStr); err != nil {
+		klog.Errorf("set port addresses failed, %v", err)
+		return err
+	}
+	// sync external id
+	if err = c.ovnClient.SetLogicalSwitchPortExternalIds(nicName, map[string]string{"ip": strings.ReplaceAll(ipStr, ",", "/")}); err != nil {
+		klog.Errorf("failed to set port external ids, %v", err)
+		return err
+	}
+
+	// sync port security
+	if pod.Annotations[fmt.Sprintf(util.PortSecurityAnnotationTemplate, podNet.ProviderName)] == "true" {
+		vips := pod.Annotations[fmt.Sprintf(util.PortVipAnnotationTemplate, podNet.ProviderName)]
+		if err = c.ovnClient.SetLogicalSwitchPortSecurity(true, nicName, mac, ipStr, vips); err != nil {
+			klog.Errorf("setPortSecurity failed. %v", err)
+			return err
+		}
+	}
+
+	// sync dhcp options
+	err = c.ovnClient.SetLogicalSwitchPortDHCPOptions(nicName, dhcpOptions.DHCPv4OptionsUUID, kubeovnv1.ProtocolIPv4)
+	if err != nil {
+		return fmt.Errorf("failed to set port %s dhcpv4 options, %s", nicName, err.Error())
+	}
+	err = c.ovnClient.SetLogicalSwitchPortDHCPOptions(nicName, dhcpOptions.DHCPv6OptionsUUID, kubeovnv1.ProtocolIPv6)
+	if err != nil {
+		return fmt.Errorf("failed to set port %s dhcpv6 options, %s", nicName, err.Error())
+	}
+	if ipCr, err := c.ipsLister.Get(nicName); err == nil && hasDHCPOptionsOverride(ipCr) {
+		c.updateIPDHCPOptionsQueue.Add(nicName)
+	}
+
+	// update ip crd
+	return c.updateIPCRD(context.Background(), nicName, ipStr, v4IP, v6IP)
+}
+
 func (c *Controller) updateIPCRD(ctx context.Context, ipCrName string, ipStr, ipv4, ipv6 string) (err error) {
 	ipCr, err := c.config.KubeOvnClient.KubeovnV1().IPs().Get(ctx, ipCrName, metav1.GetOptions{})
 	if err != nil {
@@ -1169,7 +1256,7 @@ func (c *Controller) handleUpdatePod(key string) error {
 					nextHop = addr
 				}
 
-				if err := c.ovnClient.AddLogicalRouterStaticRoute(c.config.ClusterRouter, ovs.PolicySrcIP, podIP, nextHop); err != nil {
+				if err := c.ovnClient.AddLogicalRouterStaticRoute(c.config.ClusterRouter, util.MainRouteTable, ovs.PolicySrcIP, podIP, nextHop); err != nil {
 					klog.Errorf("failed to add static route, %v", err)
 					return err
 				}
@@ -1190,7 +1277,7 @@ func (c *Controller) handleUpdatePod(key string) error {
 							if util.CheckProtocol(nodeAddr.String()) != util.CheckProtocol(podAddr) {
 								continue
 							}
-							if err := c.ovnClient.AddLogicalRouterStaticRoute(c.config.ClusterRouter, ovs.PolicySrcIP, podAddr, nodeAddr.String()); err != nil {
+							if err := c.ovnClient.AddLogicalRouterStaticRoute(c.config.ClusterRouter, util.MainRouteTable, ovs.PolicySrcIP, podAddr, nodeAddr.String()); err != nil {
 								klog.Errorf("failed to add static route, %v", err)
 								return err
 							}
@@ -1199,7 +1286,7 @@ func (c *Controller) handleUpdatePod(key string) error {
 				}
 
 				if pod.Annotations[util.NorthGatewayAnnotation] != "" {
-					if err := c.ovnClient.AddLogicalRouterStaticRoute(c.config.ClusterRouter, ovs.PolicySrcIP, podIP, pod.Annotations[util.NorthGatewayAnnotation]); err != nil {
+					if err := c.ovnClient.AddLogicalRouterStaticRoute(c.config.ClusterRouter, util.MainRouteTable, ovs.PolicySrcIP, podIP, pod.Annotations[util.NorthGatewayAnnotation]); err != nil {
 						klog.Errorf("failed to add static route, %v", err)
 						return err
 					}
@@ -1243,6 +1330,20 @@ func isStatefulSetPod(pod *v1.Pod) (bool, string) {
 	return false, ""
 }
 
+// getPodOwner returns the key of the controller owning the pod, pods of a deployment
+// share the same owner across replica sets. The pod key is returned for bare pods
+func getPodOwner(pod *v1.Pod) string {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
+	}
+	kind, name := owner.Kind, owner.Name
+	if hash := pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey]; kind == "ReplicaSet" && hash != "" && strings.HasSuffix(name, "-"+hash) {
+		kind, name = "Deployment", strings.TrimSuffix(name, "-"+hash)
+	}
+	return fmt.Sprintf("%s/%s/%s", pod.Namespace, kind, name)
+}
+
 func isStatefulSetPodToDel(c kubernetes.Interface, pod *v1.Pod, statefulSetName string) bool {
 	// only delete statefulset pod lsp when statefulset deleted or down scaled
 	ss, err := c.AppsV1().StatefulSets(pod.Namespace).Get(context.Background(), statefulSetName, metav1.GetOptions{})
@@ -1508,17 +1609,125 @@ func (c *Controller) validatePodIP(podName, subnetName, ipv4, ipv6 string) (bool
 	return true, true, nil
 }
 
+// checkNamespaceIPQuota deny allocating a new address in the subnet once addresses of the namespace reach the quota,
+// nics which already have addresses are not counted again
+func (c *Controller) checkNamespaceIPQuota(pod *v1.Pod, podNet *kubeovnNet, quota int) error {
+	nicName := ovs.PodNameToPortName(pod.Name, pod.Namespace, podNet.ProviderName)
+	nics := c.ipam.NamespaceNics(podNet.Subnet.Name, pod.Namespace)
+	if util.ContainsString(nics, nicName) || len(nics) < quota {
+		return nil
+	}
+
+	err := fmt.Errorf("namespace %s has used %d addresses of its quota %d in subnet %s", pod.Namespace, len(nics), quota, podNet.Subnet.Name)
+	klog.Errorf("failed to allocate address for pod %s/%s, %v", pod.Namespace, pod.Name, err)
+	c.recorder.Eventf(pod, v1.EventTypeWarning, "NamespaceIPQuotaExceeded", err.Error())
+	return err
+}
+
+// checkVpcIPQuota deny allocating a new address in the subnet once addresses allocated in the subnets of its vpc
+// reach the quota, nics which already have addresses are not counted again
+func (c *Controller) checkVpcIPQuota(pod *v1.Pod, podNet *kubeovnNet, vpc *kubeovnv1.Vpc) error {
+	nicName := ovs.PodNameToPortName(pod.Name, pod.Namespace, podNet.ProviderName)
+	if util.ContainsString(c.ipam.SubnetNics(podNet.Subnet.Name), nicName) {
+		return nil
+	}
+
+	subnets, err := c.subnetsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list subnets, %v", err)
+		return err
+	}
+	var used int
+	for _, subnet := range subnets {
+		if subnet.Spec.Vpc == vpc.Name || (subnet.Spec.Vpc == "" && vpc.Name == util.DefaultVpc) {
+			used += len(c.ipam.SubnetNics(subnet.Name))
+		}
+	}
+	if used < vpc.Spec.Quota.MaxIPs {
+		return nil
+	}
+
+	err = fmt.Errorf("%d ips reach the quota %d of vpc %s", used, vpc.Spec.Quota.MaxIPs, vpc.Name)
+	klog.Errorf("failed to allocate address for pod %s/%s, %v", pod.Namespace, pod.Name, err)
+	c.recorder.Eventf(pod, v1.EventTypeWarning, "VpcIPQuotaExceeded", err.Error())
+	return err
+}
+
+// getVpcIPQuota get the vpc of the subnet if it has an ip quota, nil is returned otherwise
+func (c *Controller) getVpcIPQuota(subnet *kubeovnv1.Subnet) (*kubeovnv1.Vpc, error) {
+	vpcName := subnet.Spec.Vpc
+	if vpcName == "" {
+		vpcName = util.DefaultVpc
+	}
+	vpc, err := c.vpcsLister.Get(vpcName)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		klog.Errorf("failed to get vpc %s, %v", vpcName, err)
+		return nil, err
+	}
+	if vpc.Spec.Quota == nil || vpc.Spec.Quota.MaxIPs <= 0 {
+		return nil, nil
+	}
+	return vpc, nil
+}
+
 func (c *Controller) acquireAddress(pod *v1.Pod, podNet *kubeovnNet) (string, string, string, error) {
 	key := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
 	macStr := pod.Annotations[fmt.Sprintf(util.MacAddressAnnotationTemplate, podNet.ProviderName)]
 
+	quota, hasNamespaceQuota := podNet.Subnet.Spec.NamespaceIPQuotas[pod.Namespace]
+	vpc, err := c.getVpcIPQuota(podNet.Subnet)
+	if err != nil {
+		return "", "", "", err
+	}
+	if hasNamespaceQuota || vpc != nil {
+		// the quotas are checked and the address is allocated atomically
+		c.ipQuotaMutex.Lock()
+		defer c.ipQuotaMutex.Unlock()
+		if hasNamespaceQuota {
+			if err := c.checkNamespaceIPQuota(pod, podNet, quota); err != nil {
+				return "", "", "", err
+			}
+		}
+		if vpc != nil {
+			if err := c.checkVpcIPQuota(pod, podNet, vpc); err != nil {
+				return "", "", "", err
+			}
+		}
+	}
+
+	// static addresses are checked against the ip pool of the pod as well
+	ipPoolAnnotation := pod.Annotations[fmt.Sprintf(util.IpPoolAnnotationTemplate, podNet.ProviderName)]
+	poolName := ipPoolAnnotation
+	if !util.IsIPPoolName(poolName) {
+		poolName = ""
+	}
+	if poolName, err = c.getPodIPPool(pod, podNet.Subnet.Name, poolName); err != nil {
+		return "", "", "", err
+	}
+
 	// Random allocate
 	if pod.Annotations[fmt.Sprintf(util.IpAddressAnnotationTemplate, podNet.ProviderName)] == "" &&
-		pod.Annotations[fmt.Sprintf(util.IpPoolAnnotationTemplate, podNet.ProviderName)] == "" {
+		(ipPoolAnnotation == "" || util.IsIPPoolName(ipPoolAnnotation)) {
+		nodeBlock := poolName == "" && c.useNodeBlock(pod, podNet.Subnet)
+
 		var skippedAddrs []string
 		for {
+			var ipv4, ipv6, mac string
 			nicName := ovs.PodNameToPortName(pod.Name, pod.Namespace, podNet.ProviderName)
-			ipv4, ipv6, mac, err := c.ipam.GetRandomAddress(key, nicName, podNet.Subnet.Name, skippedAddrs)
+			if nodeBlock {
+				ipv4, ipv6, mac, err = c.ipam.GetRandomAddressFromNodeBlocks(key, nicName, podNet.Subnet.Name, pod.Spec.NodeName, skippedAddrs)
+				if err == ipam.ErrNoAvailable {
+					// blocks of the node are exhausted, fall back to addresses out of all blocks
+					klog.Infof("no available address in ip blocks of node %s for %s, allocate from subnet %s", pod.Spec.NodeName, key, podNet.Subnet.Name)
+					nodeBlock = false
+					continue
+				}
+			} else {
+				ipv4, ipv6, mac, err = c.ipam.GetRandomAddressForOwner(key, nicName, podNet.Subnet.Name, poolName, getPodOwner(pod), skippedAddrs)
+			}
 			if err != nil {
 				return "", "", "", err
 			}
@@ -1544,11 +1753,11 @@ func (c *Controller) acquireAddress(pod *v1.Pod, podNet *kubeovnNet) (string, st
 	// Static allocate
 	if pod.Annotations[fmt.Sprintf(util.IpAddressAnnotationTemplate, podNet.ProviderName)] != "" {
 		ipStr := pod.Annotations[fmt.Sprintf(util.IpAddressAnnotationTemplate, podNet.ProviderName)]
-		return c.acquireStaticAddress(key, nicName, ipStr, macStr, podNet.Subnet.Name, podNet.AllowLiveMigration)
+		return c.acquireStaticAddress(key, nicName, poolName, getPodOwner(pod), ipStr, macStr, podNet.Subnet.Name, podNet.AllowLiveMigration)
 	}
 
 	// IPPool allocate
-	ipPool := strings.Split(pod.Annotations[fmt.Sprintf(util.IpPoolAnnotationTemplate, podNet.ProviderName)], ",")
+	ipPool := strings.Split(ipPoolAnnotation, ",")
 	for i, ip := range ipPool {
 		ipPool[i] = strings.TrimSpace(ip)
 	}
@@ -1559,7 +1768,7 @@ func (c *Controller) acquireAddress(pod *v1.Pod, podNet *kubeovnNet) (string, st
 				klog.Errorf("static address %s for %s has been assigned", staticIP, key)
 				continue
 			}
-			if v4IP, v6IP, mac, err := c.acquireStaticAddress(key, nicName, staticIP, macStr, podNet.Subnet.Name, podNet.AllowLiveMigration); err == nil {
+			if v4IP, v6IP, mac, err := c.acquireStaticAddress(key, nicName, poolName, getPodOwner(pod), staticIP, macStr, podNet.Subnet.Name, podNet.AllowLiveMigration); err == nil {
 				return v4IP, v6IP, mac, nil
 			} else {
 				klog.Errorf("acquire address %s for %s failed, %v", staticIP, key, err)
@@ -1570,7 +1779,7 @@ func (c *Controller) acquireAddress(pod *v1.Pod, podNet *kubeovnNet) (string, st
 		numStr := tempStrs[len(tempStrs)-1]
 		index, _ := strconv.Atoi(numStr)
 		if index < len(ipPool) {
-			return c.acquireStaticAddress(key, nicName, ipPool[index], macStr, podNet.Subnet.Name, podNet.AllowLiveMigration)
+			return c.acquireStaticAddress(key, nicName, poolName, getPodOwner(pod), ipPool[index], macStr, podNet.Subnet.Name, podNet.AllowLiveMigration)
 		}
 	}
 	klog.Errorf("alloc address for %s failed, return NoAvailableAddress", key)
@@ -1637,7 +1846,7 @@ func (c *Controller) recycleAddress(ipStrList []string, subnet string) error {
 	return nil
 }
 
-func (c *Controller) acquireStaticAddress(key, nicName, ip, mac, subnet string, liveMigration bool) (string, string, string, error) {
+func (c *Controller) acquireStaticAddress(key, nicName, poolName, owner, ip, mac, subnet string, liveMigration bool) (string, string, string, error) {
 	var v4IP, v6IP string
 	var err error
 	ipStrList := strings.Split(ip, ",")
@@ -1647,7 +1856,7 @@ func (c *Controller) acquireStaticAddress(key, nicName, ip, mac, subnet string,
 		}
 	}
 
-	if v4IP, v6IP, mac, err = c.ipam.GetStaticAddress(key, nicName, ip, mac, subnet, !liveMigration); err != nil {
+	if v4IP, v6IP, mac, err = c.ipam.GetStaticAddressFromPool(key, nicName, ip, mac, subnet, poolName, owner, !liveMigration); err != nil {
 		klog.Errorf("failed to get static ip %v, mac %v, subnet %v, err %v", ip, mac, subnet, err)
 		if err == ipam.ErrConflict {
 			if errGc := c.recycleAddress(ipStrList, subnet); errGc != nil {
@@ -1707,10 +1916,15 @@ func appendCheckStatefulSetPodToDel(c *Controller, pod *v1.Pod) (bool, error) {
 		klog.Errorf("failed to get subnet %s, %v", pod.Annotations[util.LogicalSwitchAnnotation], err)
 		return false, err
 	}
-	if podSubnet != nil && !util.CIDRContainIP(podSubnet.Spec.CIDRBlock, pod.Annotations[util.IpAddressAnnotation]) {
+	if podSubnet != nil && !subnetContainIP(podSubnet, pod.Annotations[util.IpAddressAnnotation]) {
 		klog.Infof("pod's ip %s is not in the range of subnet %s, delete pod", pod.Annotations[util.IpAddressAnnotation], podSubnet.Name)
 		return true, nil
 	}
+	// subnet is being migrated, and statefulset pod's ip is in the source cidr
+	if source := podSubnet.Annotations[util.SubnetMigrationSourceAnnotation]; source != "" && cidrBlocksContainIP(source, pod.Annotations[util.IpAddressAnnotation]) {
+		klog.Infof("pod's ip %s is in the source cidr %s of migrating subnet %s, delete pod", pod.Annotations[util.IpAddressAnnotation], source, podSubnet.Name)
+		return true, nil
+	}
 
 	return false, nil
 }
@@ -3,9 +3,11 @@ package controller
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"reflect"
+	"sort"
 	"strings"
 	"time"
 
@@ -84,8 +86,14 @@ func (c *Controller) enqueueUpdateSubnet(old, new interface{}) {
 		return
 	}
 
+	if oldSubnet.Spec.UtilizationThreshold != newSubnet.Spec.UtilizationThreshold ||
+		!reflect.DeepEqual(oldSubnet.Spec.NamespaceIPQuotas, newSubnet.Spec.NamespaceIPQuotas) {
+		c.updateSubnetStatusQueue.Add(key)
+	}
+
 	if oldSubnet.Spec.Private != newSubnet.Spec.Private ||
 		oldSubnet.Spec.CIDRBlock != newSubnet.Spec.CIDRBlock ||
+		!reflect.DeepEqual(oldSubnet.Spec.ExtraCIDRBlocks, newSubnet.Spec.ExtraCIDRBlocks) ||
 		!reflect.DeepEqual(oldSubnet.Spec.AllowSubnets, newSubnet.Spec.AllowSubnets) ||
 		!reflect.DeepEqual(oldSubnet.Spec.Namespaces, newSubnet.Spec.Namespaces) ||
 		oldSubnet.Spec.GatewayType != newSubnet.Spec.GatewayType ||
@@ -94,13 +102,25 @@ func (c *Controller) enqueueUpdateSubnet(old, new interface{}) {
 		oldSubnet.Spec.Gateway != newSubnet.Spec.Gateway ||
 		!reflect.DeepEqual(oldSubnet.Spec.ExcludeIps, newSubnet.Spec.ExcludeIps) ||
 		!reflect.DeepEqual(oldSubnet.Spec.Vips, newSubnet.Spec.Vips) ||
+		oldSubnet.Spec.IPReuseTTL != newSubnet.Spec.IPReuseTTL ||
+		oldSubnet.Spec.IPQuarantine != newSubnet.Spec.IPQuarantine ||
+		oldSubnet.Spec.AllocationStrategy != newSubnet.Spec.AllocationStrategy ||
+		oldSubnet.Spec.NodeBlockSize != newSubnet.Spec.NodeBlockSize ||
+		oldSubnet.Spec.MacPrefix != newSubnet.Spec.MacPrefix ||
+		oldSubnet.Spec.MacPolicy != newSubnet.Spec.MacPolicy ||
 		oldSubnet.Spec.Vlan != newSubnet.Spec.Vlan ||
+		oldSubnet.Spec.RouteTable != newSubnet.Spec.RouteTable ||
 		oldSubnet.Spec.EnableDHCP != newSubnet.Spec.EnableDHCP ||
 		oldSubnet.Spec.DHCPv4Options != newSubnet.Spec.DHCPv4Options ||
 		oldSubnet.Spec.DHCPv6Options != newSubnet.Spec.DHCPv6Options ||
+		!reflect.DeepEqual(oldSubnet.Spec.DHCPv4ExtraOptions, newSubnet.Spec.DHCPv4ExtraOptions) ||
+		!reflect.DeepEqual(oldSubnet.Spec.DHCPv6ExtraOptions, newSubnet.Spec.DHCPv6ExtraOptions) ||
 		oldSubnet.Spec.EnableIPv6RA != newSubnet.Spec.EnableIPv6RA ||
 		oldSubnet.Spec.IPv6RAConfigs != newSubnet.Spec.IPv6RAConfigs ||
 		!reflect.DeepEqual(oldSubnet.Spec.Acls, newSubnet.Spec.Acls) ||
+		!reflect.DeepEqual(oldSubnet.Spec.AclRules, newSubnet.Spec.AclRules) ||
+		!reflect.DeepEqual(oldSubnet.Spec.Migration, newSubnet.Spec.Migration) ||
+		!reflect.DeepEqual(oldSubnet.Spec.QoS, newSubnet.Spec.QoS) ||
 		oldSubnet.Annotations[util.IPv6ExtensionVpcPrefixAnnotation] != newSubnet.Annotations[util.IPv6ExtensionVpcPrefixAnnotation] {
 		klog.V(3).Infof("enqueue update subnet %s", key)
 		c.addOrUpdateSubnetQueue.Add(key)
@@ -369,6 +389,17 @@ func checkAndUpdateCIDR(subnet *kubeovnv1.Subnet) (bool, error) {
 		cidrBlocks = append(cidrBlocks, ipNet.String())
 	}
 	subnet.Spec.CIDRBlock = strings.Join(cidrBlocks, ",")
+
+	for i, cidr := range subnet.Spec.ExtraCIDRBlocks {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return false, fmt.Errorf("subnet %s extra cidr %s is not a valid cidrblock", subnet.Name, cidr)
+		}
+		if ipNet.String() != cidr {
+			subnet.Spec.ExtraCIDRBlocks[i] = ipNet.String()
+			changed = true
+		}
+	}
 	return changed, nil
 }
 
@@ -400,6 +431,12 @@ func checkAndUpdateExcludeIps(subnet *kubeovnv1.Subnet) bool {
 	changed := false
 	var excludeIps []string
 	excludeIps = append(excludeIps, strings.Split(subnet.Spec.Gateway, ",")...)
+	if len(subnet.Spec.ExtraCIDRBlocks) != 0 {
+		// gateways of extra cidr blocks are always the first addresses
+		if gws, err := util.GetGwByCidr(strings.Join(subnet.Spec.ExtraCIDRBlocks, ",")); err == nil {
+			excludeIps = append(excludeIps, strings.Split(gws, ",")...)
+		}
+	}
 	if len(subnet.Spec.ExcludeIps) == 0 {
 		subnet.Spec.ExcludeIps = excludeIps
 		changed = true
@@ -473,6 +510,61 @@ func (c *Controller) patchSubnetStatus(subnet *kubeovnv1.Subnet, reason string,
 	}
 }
 
+// patchSubnetCondition set the condition of a reconcile step to false with the error or to true if err is nil,
+// the status is patched only if the condition changes so that the timestamps tell when the step last changed
+func (c *Controller) patchSubnetCondition(subnet *kubeovnv1.Subnet, ctype kubeovnv1.ConditionType, reason string, err error) {
+	oldConditions := subnet.Status.DeepCopy().Conditions
+	if err != nil {
+		subnet.Status.ClearCondition(ctype, reason, err.Error())
+		subnet.Status.SetError(reason, err.Error())
+		subnet.Status.NotReady(reason, err.Error())
+		c.recorder.Eventf(subnet, v1.EventTypeWarning, reason, err.Error())
+	} else {
+		subnet.Status.SetCondition(ctype, reason, "")
+	}
+	if reflect.DeepEqual(oldConditions, subnet.Status.Conditions) {
+		return
+	}
+
+	bytes, err := subnet.Status.Bytes()
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+	if _, err = c.config.KubeOvnClient.KubeovnV1().Subnets().Patch(context.Background(), subnet.Name, types.MergePatchType, bytes, metav1.PatchOptions{}, "status"); err != nil {
+		klog.Errorf("failed to patch condition %s of subnet %s, %v", ctype, subnet.Name, err)
+	}
+}
+
+// compileSubnetAclRules return acls of the subnet together with the ones compiled from aclRules,
+// aclRules are ignored and the reason is recorded in the status if they can not be compiled
+func (c *Controller) compileSubnetAclRules(subnet *kubeovnv1.Subnet) []kubeovnv1.Acl {
+	acls := subnet.Spec.Acls
+	var status *kubeovnv1.SubnetAclRulesStatus
+	if len(subnet.Spec.AclRules) != 0 {
+		compiled, err := util.CompileAclRules(subnetCIDRBlocks(subnet), subnet.Spec.AclRules)
+		if err != nil {
+			klog.Errorf("failed to compile acl rules of subnet %s, %v", subnet.Name, err)
+			c.recorder.Eventf(subnet, v1.EventTypeWarning, "CompileAclRulesFailed", err.Error())
+			status = &kubeovnv1.SubnetAclRulesStatus{Message: err.Error()}
+		} else {
+			acls = append(append([]kubeovnv1.Acl{}, acls...), compiled...)
+			status = &kubeovnv1.SubnetAclRulesStatus{Acls: compiled}
+		}
+	}
+
+	if !reflect.DeepEqual(subnet.Status.AclRules, status) {
+		subnet.Status.AclRules = status
+		bytes, err := subnet.Status.Bytes()
+		if err != nil {
+			klog.Error(err)
+		} else if _, err = c.config.KubeOvnClient.KubeovnV1().Subnets().Patch(context.Background(), subnet.Name, types.MergePatchType, bytes, metav1.PatchOptions{}, "status"); err != nil {
+			klog.Errorf("failed to patch acl rules status of subnet %s, %v", subnet.Name, err)
+		}
+	}
+	return acls
+}
+
 func (c *Controller) handleAddOrUpdateNoneCIDRSubnet(subnet *kubeovnv1.Subnet) error {
 
 	// format
@@ -622,7 +714,22 @@ func (c *Controller) handleAddOrUpdateSubnet(key string) error {
 		return err
 	}
 
-	if err := c.ipam.AddOrUpdateSubnet(subnet.Name, subnet.Spec.CIDRBlock, subnet.Spec.ExcludeIps); err != nil {
+	if err := c.ipam.AddOrUpdateSubnet(subnet.Name, subnetCIDRBlocks(subnet), subnetExcludeIps(subnet)); err != nil {
+		return err
+	}
+	if err := c.ipam.SetReleasePolicy(subnet.Name, subnetReleasePolicy(subnet)); err != nil {
+		return err
+	}
+	if err := c.ipam.SetAllocationStrategy(subnet.Name, subnet.Spec.AllocationStrategy); err != nil {
+		klog.Errorf("failed to set allocation strategy of subnet %s: %v", subnet.Name, err)
+		return err
+	}
+	if err := c.ipam.SetMacPolicy(subnet.Name, subnet.Spec.MacPrefix, subnet.Spec.MacPolicy); err != nil {
+		klog.Errorf("failed to set mac policy of subnet %s: %v", subnet.Name, err)
+		return err
+	}
+	if err := c.syncSubnetIPBlocks(subnet); err != nil {
+		klog.Errorf("failed to sync ip blocks of subnet %s: %v", subnet.Name, err)
 		return err
 	}
 
@@ -649,8 +756,8 @@ func (c *Controller) handleAddOrUpdateSubnet(key string) error {
 			continue
 		}
 
-		if util.CIDRConflict(sub.Spec.CIDRBlock, subnet.Spec.CIDRBlock) {
-			err = fmt.Errorf("subnet %s cidr %s is conflict with subnet %s cidr %s", subnet.Name, subnet.Spec.CIDRBlock, sub.Name, sub.Spec.CIDRBlock)
+		if util.CIDRConflict(subnetCIDRBlocks(sub), subnetCIDRBlocks(subnet)) {
+			err = fmt.Errorf("subnet %s cidr %s is conflict with subnet %s cidr %s", subnet.Name, subnetCIDRBlocks(subnet), sub.Name, subnetCIDRBlocks(sub))
 			klog.Error(err)
 			c.patchSubnetStatus(subnet, "ValidateLogicalSwitchFailed", err.Error())
 			return err
@@ -673,11 +780,16 @@ func (c *Controller) handleAddOrUpdateSubnet(key string) error {
 		}
 		for _, node := range nodes {
 			for _, addr := range node.Status.Addresses {
-				if addr.Type == v1.NodeInternalIP && util.CIDRContainIP(subnet.Spec.CIDRBlock, addr.Address) {
-					err = fmt.Errorf("subnet %s cidr %s conflict with node %s address %s", subnet.Name, subnet.Spec.CIDRBlock, node.Name, addr.Address)
-					klog.Error(err)
-					c.patchSubnetStatus(subnet, "ValidateLogicalSwitchFailed", err.Error())
-					return err
+				if addr.Type != v1.NodeInternalIP {
+					continue
+				}
+				for _, cidr := range strings.Split(subnetCIDRBlocks(subnet), ",") {
+					if util.CIDRContainIP(cidr, addr.Address) {
+						err = fmt.Errorf("subnet %s cidr %s conflict with node %s address %s", subnet.Name, cidr, node.Name, addr.Address)
+						klog.Error(err)
+						c.patchSubnetStatus(subnet, "ValidateLogicalSwitchFailed", err.Error())
+						return err
+					}
 				}
 			}
 		}
@@ -686,40 +798,62 @@ func (c *Controller) handleAddOrUpdateSubnet(key string) error {
 	exist, err := c.ovnLegacyClient.LogicalSwitchExists(subnet.Name, c.config.EnableExternalVpc)
 	if err != nil {
 		klog.Errorf("failed to list logical switch, %v", err)
-		c.patchSubnetStatus(subnet, "ListLogicalSwitchFailed", err.Error())
+		c.patchSubnetCondition(subnet, kubeovnv1.LogicalSwitchReady, "ListLogicalSwitchFailed", err)
 		return err
 	}
 
+	gateways, err := subnetGateways(subnet)
+	if err != nil {
+		klog.Errorf("failed to get gateways of subnet %s, %v", subnet.Name, err)
+		return err
+	}
 	needRouter := (subnet.Spec.Vlan == "" || subnet.Spec.LogicalGateway) || subnet.Spec.Vpc != util.DefaultVpc
 	if !exist {
 		subnet.Status.EnsureStandardConditions()
 		// If multiple namespace use same ls name, only first one will success
-		if err := c.ovnLegacyClient.CreateLogicalSwitch(subnet.Name, vpc.Status.Router, subnet.Spec.CIDRBlock, subnet.Spec.Gateway, needRouter); err != nil {
-			c.patchSubnetStatus(subnet, "CreateLogicalSwitchFailed", err.Error())
+		if err := c.ovnLegacyClient.CreateLogicalSwitch(subnet.Name, vpc.Status.Router, subnetCIDRBlocks(subnet), gateways, needRouter); err != nil {
+			c.patchSubnetCondition(subnet, kubeovnv1.LogicalSwitchReady, "CreateLogicalSwitchFailed", err)
 			return err
 		}
 	} else {
 		// logical switch exists, only update other_config
-		if err := c.ovnLegacyClient.SetLogicalSwitchConfig(subnet.Name, vpc.Status.Router, subnet.Spec.Protocol, subnet.Spec.CIDRBlock, subnet.Spec.Gateway, subnet.Spec.ExcludeIps, needRouter); err != nil {
-			c.patchSubnetStatus(subnet, "SetLogicalSwitchConfigFailed", err.Error())
+		if err := c.ovnLegacyClient.SetLogicalSwitchConfig(subnet.Name, vpc.Status.Router, subnet.Spec.Protocol, subnetCIDRBlocks(subnet), gateways, subnet.Spec.ExcludeIps, needRouter); err != nil {
+			c.patchSubnetCondition(subnet, kubeovnv1.LogicalSwitchReady, "SetLogicalSwitchConfigFailed", err)
 			return err
 		}
 		if !needRouter {
 			if err := c.ovnLegacyClient.RemoveRouterPort(subnet.Name, vpc.Status.Router); err != nil {
 				klog.Errorf("failed to remove router port from %s, %v", subnet.Name, err)
+				c.patchSubnetCondition(subnet, kubeovnv1.LogicalSwitchReady, "RemoveRouterPortFailed", err)
 				return err
 			}
 		}
 	}
 
+	if needRouter {
+		// routes in the route table of the subnet apply to traffic entering the vpc router from the subnet
+		if subnet.Spec.RouteTable != util.MainRouteTable && !c.ovnClient.RouteTableSupported() {
+			klog.Warningf("route table %s of subnet %s does not take effect, route tables require OVN 21.12 or later", subnet.Spec.RouteTable, subnet.Name)
+			c.recorder.Eventf(subnet, v1.EventTypeWarning, "RouteTableNotSupported", "route table %s does not take effect, route tables require OVN 21.12 or later", subnet.Spec.RouteTable)
+		}
+		lrpName := fmt.Sprintf("%s-%s", vpc.Status.Router, subnet.Name)
+		if err := c.ovnClient.UpdateLogicalRouterPortOptions(lrpName, map[string]string{"route_table": subnet.Spec.RouteTable}); err != nil {
+			klog.Errorf("failed to set route table of router port %s, %v", lrpName, err)
+			c.patchSubnetCondition(subnet, kubeovnv1.LogicalSwitchReady, "SetRouterPortRouteTableFailed", err)
+			return err
+		}
+	}
+
 	if c.config.EnableMcast {
 		if err = c.ovnLegacyClient.SetLogicalSwitchMulticast(subnet.Name, vpc.Name, subnet.Spec.Gateway); err != nil {
 			klog.Errorf("failed to set ls '%s' multicast mode, %v", subnet.Name, err)
+			c.patchSubnetCondition(subnet, kubeovnv1.LogicalSwitchReady, "SetLogicalSwitchMulticastFailed", err)
 			return err
 		}
 	} else {
 		if err = c.ovnLegacyClient.UnsetLogicalSwitchMulticast(subnet.Name); err != nil {
 			klog.Errorf("failed to unset ls '%s' multicast mode, %v", subnet.Name, err)
+			c.patchSubnetCondition(subnet, kubeovnv1.LogicalSwitchReady, "UnsetLogicalSwitchMulticastFailed", err)
 			return err
 		}
 	}
@@ -728,6 +862,7 @@ func (c *Controller) handleAddOrUpdateSubnet(key string) error {
 	dhcpOptionsUUIDs, err = c.ovnClient.UpdateDHCPOptions(subnet, false)
 	if err != nil {
 		klog.Errorf("failed to update dhcp options for switch %s, %v", subnet.Name, err)
+		c.patchSubnetCondition(subnet, kubeovnv1.DHCPReady, "UpdateDHCPOptionsFailed", err)
 		return err
 	}
 
@@ -737,12 +872,14 @@ func (c *Controller) handleAddOrUpdateSubnet(key string) error {
 	dhcpOptionsUUIDs2, err = c.ovnClient.UpdateDHCPOptions(subnetNonRouter, true)
 	if err != nil {
 		klog.Errorf("failed to update dhcp options for switch %s, %v", subnet.Name, err)
+		c.patchSubnetCondition(subnet, kubeovnv1.DHCPReady, "UpdateDHCPOptionsFailed", err)
 		return err
 	}
 
 	if needRouter {
 		if err := c.ovnLegacyClient.UpdateRouterPortIPv6RA(subnet.Name, vpc.Status.Router, subnet.Spec.CIDRBlock, subnet.Spec.Gateway, subnet.Spec.IPv6RAConfigs, subnet.Spec.EnableIPv6RA); err != nil {
 			klog.Errorf("failed to update ipv6 ra configs for router port %s-%s, %v", vpc.Status.Router, subnet.Name, err)
+			c.patchSubnetCondition(subnet, kubeovnv1.LogicalSwitchReady, "UpdateIPv6RAFailed", err)
 			return err
 		}
 	}
@@ -753,10 +890,12 @@ func (c *Controller) handleAddOrUpdateSubnet(key string) error {
 		subnet.Spec.Vpc != util.DefaultVpc ||
 		subnet.Spec.LogicalGateway {
 		if err = c.detachExtensionIPv6RA(subnet); err != nil {
+			c.patchSubnetCondition(subnet, kubeovnv1.LogicalSwitchReady, "DetachExtensionIPv6RAFailed", err)
 			return err
 		}
 	} else {
 		if err = c.attachExtensionIPv6RA(subnet); err != nil {
+			c.patchSubnetCondition(subnet, kubeovnv1.LogicalSwitchReady, "AttachExtensionIPv6RAFailed", err)
 			return err
 		}
 	}
@@ -773,7 +912,8 @@ func (c *Controller) handleAddOrUpdateSubnet(key string) error {
 
 	if subnet.Status.DHCPv4OptionsUUID != dhcpV4Options || subnet.Status.DHCPv6OptionsUUID != dhcpV6Options {
 		enableOp := false
-		if subnet.Status.DHCPv4OptionsUUID == "" {
+		// the dhcpv6 options are created when an ipv4 subnet is upgraded to dual stack
+		if subnet.Status.DHCPv4OptionsUUID == "" || (subnet.Status.DHCPv6OptionsUUID == "" && dhcpV6Options != "") {
 			enableOp = true
 		}
 		subnet.Status.DHCPv4OptionsUUID = dhcpV4Options
@@ -792,22 +932,31 @@ func (c *Controller) handleAddOrUpdateSubnet(key string) error {
 		if enableOp {
 			// update all dhcp options
 			if err := c.enableDHCP(subnet); err != nil {
+				c.patchSubnetCondition(subnet, kubeovnv1.DHCPReady, "EnableDHCPFailed", err)
 				return err
 			}
 		}
 	}
 
+	// dhcp options dedicated to ports are derived from the ones of the subnet,
+	// they are synced after the ports are attached to the dhcp options of the subnet
+	if err = c.enqueueSubnetIPDHCPOptions(subnet.Name); err != nil {
+		return err
+	}
+
 	if err = c.updateNodeAddressSetsForSubnet(subnet, false); err != nil {
 		klog.Errorf("failed to update node address sets for addition of subnet %s: %v", subnet.Name, err)
+		c.patchSubnetCondition(subnet, kubeovnv1.ACLReady, "UpdateNodeAddressSetsFailed", err)
 		return err
 	}
 
 	if vpc.Annotations[util.VpcEnableOvnLbAnnotation] == "true" && c.config.EnableLb && subnet.Name != c.config.NodeSwitch {
 		if err := c.ovnLegacyClient.AddLbToLogicalSwitch(vpc.Status.TcpLoadBalancer, vpc.Status.TcpSessionLoadBalancer, vpc.Status.UdpLoadBalancer, vpc.Status.UdpSessionLoadBalancer, subnet.Name); err != nil {
-			c.patchSubnetStatus(subnet, "AddLbToLogicalSwitchFailed", err.Error())
+			c.patchSubnetCondition(subnet, kubeovnv1.LogicalSwitchReady, "AddLbToLogicalSwitchFailed", err)
 			return err
 		}
 	}
+	c.patchSubnetCondition(subnet, kubeovnv1.LogicalSwitchReady, "LogicalSwitchReconciled", nil)
 
 	if err := c.reconcileSubnet(subnet); err != nil {
 		klog.Errorf("reconcile subnet for %s failed, %v", subnet.Name, err)
@@ -815,27 +964,36 @@ func (c *Controller) handleAddOrUpdateSubnet(key string) error {
 	}
 
 	if subnet.Spec.Private {
-		for _, cidrBlock := range strings.Split(subnet.Spec.CIDRBlock, ",") {
-			protocol := util.CheckProtocol(cidrBlock)
-			if err := c.ovnLegacyClient.SetPrivateLogicalSwitch(subnet.Name, protocol, cidrBlock, subnet.Spec.AllowSubnets); err != nil {
-				c.patchSubnetStatus(subnet, "SetPrivateLogicalSwitchFailed", err.Error())
-				return err
-			}
-			c.patchSubnetStatus(subnet, "SetPrivateLogicalSwitchSuccess", "")
+		if err := c.ovnLegacyClient.SetPrivateLogicalSwitch(subnet.Name, subnetCIDRBlocks(subnet), subnet.Spec.AllowSubnets); err != nil {
+			c.patchSubnetCondition(subnet, kubeovnv1.ACLReady, "SetPrivateLogicalSwitchFailed", err)
+			return err
 		}
+		c.patchSubnetStatus(subnet, "SetPrivateLogicalSwitchSuccess", "")
 	} else {
 		if err := c.ovnLegacyClient.ResetLogicalSwitchAcl(subnet.Name); err != nil {
-			c.patchSubnetStatus(subnet, "ResetLogicalSwitchAclFailed", err.Error())
+			c.patchSubnetCondition(subnet, kubeovnv1.ACLReady, "ResetLogicalSwitchAclFailed", err)
 			return err
 		}
 		c.patchSubnetStatus(subnet, "ResetLogicalSwitchAclSuccess", "")
 	}
 
 	// subnet acl改为ovsdb client接口
-	if err := c.ovnClient.UpdateLogicalSwitchAcl(subnet.Name, subnet.Spec.Acls); err != nil {
-		c.patchSubnetStatus(subnet, "SetLogicalSwitchAclsFailed", err.Error())
+	acls := c.compileSubnetAclRules(subnet)
+	if err := c.ovnClient.UpdateLogicalSwitchAcl(subnet.Name, acls); err != nil {
+		c.patchSubnetCondition(subnet, kubeovnv1.ACLReady, "SetLogicalSwitchAclsFailed", err)
 		return err
 	}
+	if subnet.Status.AclRules != nil && subnet.Status.AclRules.Message != "" {
+		c.patchSubnetCondition(subnet, kubeovnv1.ACLReady, "CompileAclRulesFailed", errors.New(subnet.Status.AclRules.Message))
+	} else {
+		c.patchSubnetCondition(subnet, kubeovnv1.ACLReady, "AclsReconciled", nil)
+	}
+
+	if err := c.ovnClient.UpdateLogicalSwitchQoS(subnet.Name, subnetCIDRBlocks(subnet), subnet.Spec.QoS); err != nil {
+		c.patchSubnetCondition(subnet, kubeovnv1.QoSReady, "SetLogicalSwitchQoSFailed", err)
+		return err
+	}
+	c.patchSubnetCondition(subnet, kubeovnv1.QoSReady, "QoSReconciled", nil)
 
 	// vpc dns
 	if vpc.Annotations[util.DnsEnableAnnotation] == "true" {
@@ -847,6 +1005,14 @@ func (c *Controller) handleAddOrUpdateSubnet(key string) error {
 		}
 	}
 
+	if subnet.Spec.Migration != nil || subnet.Annotations[util.SubnetMigrationSourceAnnotation] != "" {
+		c.migrateSubnetQueue.Add(subnet.Name)
+	}
+
+	if err := c.enqueueDualStackPods(subnet); err != nil {
+		return err
+	}
+
 	c.updateVpcStatusQueue.Add(subnet.Spec.Vpc)
 	return nil
 }
@@ -867,6 +1033,10 @@ func (c *Controller) enableDHCP(subnet *kubeovnv1.Subnet) error {
 	}
 
 	for _, lsp := range lsps {
+		// ports with dedicated dhcp options are handled by handleUpdateIPDHCPOptions
+		if ip, err := c.ipsLister.Get(lsp.Name); err == nil && hasDHCPOptionsOverride(ip) {
+			continue
+		}
 		if key := lsp.ExternalIDs["pod"]; key != "" {
 			podName := strings.Split(key, "/")
 			if len(podName) > 1 {
@@ -1066,12 +1236,14 @@ func (c *Controller) updateNodeAddressSetsForSubnet(subnet *kubeovnv1.Subnet, de
 
 	for _, node := range nodes {
 		nodeIPv4, nodeIPv6 := util.GetNodeInternalIP(*node)
-		v4CIDR, v6CIDR := util.SplitStringIP(subnet.Spec.CIDRBlock)
-		if err := c.handleNodeAddressSetForSubnet(v4CIDR, node.Name, nodeIPv4, 4, delete); err != nil {
-			return err
-		}
-		if err := c.handleNodeAddressSetForSubnet(v6CIDR, node.Name, nodeIPv6, 6, delete); err != nil {
-			return err
+		for _, cidr := range strings.Split(subnetCIDRBlocks(subnet), ",") {
+			nodeIP, af := nodeIPv4, 4
+			if util.CheckProtocol(cidr) == kubeovnv1.ProtocolIPv6 {
+				nodeIP, af = nodeIPv6, 6
+			}
+			if err := c.handleNodeAddressSetForSubnet(cidr, node.Name, nodeIP, af, delete); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -1087,6 +1259,8 @@ func (c *Controller) handleUpdateSubnetStatus(key string) error {
 		}
 		return err
 	}
+	c.enqueueSubnetIPPools(subnet.Name)
+	c.enqueueSubnetIPBlocks(subnet.Name)
 	if util.CheckProtocol(subnet.Spec.CIDRBlock) == kubeovnv1.ProtocolDual {
 		return calcDualSubnetStatusIP(subnet, c)
 	} else {
@@ -1102,11 +1276,14 @@ func (c *Controller) handleDeleteRoute(subnet *kubeovnv1.Subnet) error {
 		}
 		return err
 	}
-	return c.deleteStaticRoute(subnet.Spec.CIDRBlock, vpc.Status.Router, subnet)
+	return c.deleteStaticRoute(subnetCIDRBlocks(subnet), vpc.Status.Router, subnet)
 }
 
 func (c *Controller) handleDeleteLogicalSwitch(key string) (err error) {
 	c.ipam.DeleteSubnet(key)
+	if err = c.deleteIPBlocks(key, ""); err != nil {
+		return err
+	}
 
 	exist, err := c.ovnLegacyClient.LogicalSwitchExists(key, c.config.EnableExternalVpc)
 	if err != nil {
@@ -1239,30 +1416,40 @@ func (c *Controller) updateVlanStatusForSubnetDeletion(vlan *kubeovnv1.Vlan, sub
 func (c *Controller) reconcileSubnet(subnet *kubeovnv1.Subnet) error {
 	if err := c.reconcileNamespaces(subnet); err != nil {
 		klog.Errorf("reconcile namespaces for subnet %s failed, %v", subnet.Name, err)
+		c.patchSubnetCondition(subnet, kubeovnv1.NamespaceReady, "ReconcileNamespacesFailed", err)
 		return err
 	}
+	c.patchSubnetCondition(subnet, kubeovnv1.NamespaceReady, "NamespacesReconciled", nil)
 
 	if subnet.Name != c.config.NodeSwitch {
 		if err := c.reconcileGateway(subnet); err != nil {
 			klog.Errorf("reconcile centralized gateway for subnet %s failed, %v", subnet.Name, err)
+			c.patchSubnetCondition(subnet, kubeovnv1.GatewayReady, "ReconcileGatewayFailed", err)
 			return err
 		}
+		c.patchSubnetCondition(subnet, kubeovnv1.GatewayReady, "GatewayReconciled", nil)
 	}
 
 	if err := c.reconcileVlan(subnet); err != nil {
 		klog.Errorf("reconcile vlan for subnet %s failed, %v", subnet.Name, err)
+		c.patchSubnetCondition(subnet, kubeovnv1.VlanReady, "ReconcileVlanFailed", err)
 		return err
 	}
+	c.patchSubnetCondition(subnet, kubeovnv1.VlanReady, "VlanReconciled", nil)
 
 	if err := c.reconcileVips(subnet); err != nil {
 		klog.Errorf("reconcile vips for subnet %s failed, %v", subnet.Name, err)
+		c.patchSubnetCondition(subnet, kubeovnv1.VIPReady, "ReconcileVipsFailed", err)
 		return err
 	}
+	c.patchSubnetCondition(subnet, kubeovnv1.VIPReady, "VipsReconciled", nil)
 
 	if err := c.reconcileDHCP(subnet); err != nil {
 		klog.Errorf("reconcile dhcp for subnet %s failed, %v", subnet.Name, err)
+		c.patchSubnetCondition(subnet, kubeovnv1.DHCPReady, "ReconcileDHCPFailed", err)
 		return err
 	}
+	c.patchSubnetCondition(subnet, kubeovnv1.DHCPReady, "DHCPReconciled", nil)
 	return nil
 }
 
@@ -1311,7 +1498,8 @@ func (c *Controller) cleanupRedundantDHCPOption(lsName string, optionsUUID strin
 	// if dhcp options more than one
 	if len(dhcpOpts) > 1 {
 		for _, dhcpOpt := range dhcpOpts {
-			if dhcpOpt.UUID == optionsUUID {
+			// dhcp options dedicated to logical switch ports are managed by ip
+			if dhcpOpt.UUID == optionsUUID || dhcpOpt.ExternalIDs[logicalSwitchPortKey] != "" {
 				continue
 			}
 
@@ -1442,7 +1630,7 @@ func (c *Controller) syncVirtualPort(key string) error {
 	}
 
 	for _, vip := range subnet.Spec.Vips {
-		if !util.CIDRContainIP(subnet.Spec.CIDRBlock, vip) {
+		if !subnetContainIP(subnet, vip) {
 			klog.Errorf("vip %s is out of range to subnet %s", vip, subnet.Name)
 			continue
 		}
@@ -1533,7 +1721,7 @@ func (c *Controller) reconcileGateway(subnet *kubeovnv1.Subnet) error {
 			}
 		}
 
-		if err := c.deleteStaticRoute(subnet.Spec.CIDRBlock, c.config.ClusterRouter, subnet); err != nil {
+		if err := c.deleteStaticRoute(subnetCIDRBlocks(subnet), c.config.ClusterRouter, subnet); err != nil {
 			return err
 		}
 
@@ -1588,12 +1776,12 @@ func (c *Controller) reconcileGateway(subnet *kubeovnv1.Subnet) error {
 					nextHop = pod.Annotations[util.NorthGatewayAnnotation]
 				}
 
-				if err := c.ovnClient.AddLogicalRouterStaticRoute(c.config.ClusterRouter, ovs.PolicySrcIP, pod.Annotations[util.IpAddressAnnotation], nextHop); err != nil {
+				if err := c.ovnClient.AddLogicalRouterStaticRoute(c.config.ClusterRouter, util.MainRouteTable, ovs.PolicySrcIP, pod.Annotations[util.IpAddressAnnotation], nextHop); err != nil {
 					klog.Errorf("add static route failed, %v", err)
 					return err
 				}
 			}
-			return c.deleteStaticRoute(subnet.Spec.CIDRBlock, c.config.ClusterRouter, subnet)
+			return c.deleteStaticRoute(subnetCIDRBlocks(subnet), c.config.ClusterRouter, subnet)
 		} else {
 			if subnet.Spec.GatewayNode == "" {
 				klog.Errorf("subnet %s Spec.GatewayNode field must be specified for centralized gateway type", subnet.Name)
@@ -1612,39 +1800,14 @@ func (c *Controller) reconcileGateway(subnet *kubeovnv1.Subnet) error {
 				return fmt.Errorf("failed to add ecmp static route, no gateway node exists")
 			}
 
-			nodeIPs := make([]string, 0, len(strings.Split(subnet.Spec.GatewayNode, ",")))
-			for _, gw := range strings.Split(subnet.Spec.GatewayNode, ",") {
-				// the format of gatewayNodeStr can be like 'kube-ovn-worker:172.18.0.2, kube-ovn-control-plane:172.18.0.3', which consists of node name and designative egress ip
-				if strings.Contains(gw, ":") {
-					gw = strings.TrimSpace(strings.Split(gw, ":")[0])
-				} else {
-					gw = strings.TrimSpace(gw)
-				}
-
-				node, err := c.nodesLister.Get(gw)
-				if err == nil && nodeReady(node) {
-					nodeTunlIP := strings.TrimSpace(node.Annotations[util.IpAddressAnnotation])
-					if nodeTunlIP == "" {
-						klog.Errorf("gateway node %v has no ip annotation", node.Name)
-						continue
-					}
-					nodeIPs = append(nodeIPs, strings.Split(nodeTunlIP, ",")...)
-				}
-			}
-
-			for _, cidr := range strings.Split(subnet.Spec.CIDRBlock, ",") {
-				nextHops, err := c.filterRepeatEcmpRoutes(nodeIPs, cidr)
-				if err != nil {
-					klog.Errorf("failed to filter ecmp static route for CIDR %s of subnet %s: %v", cidr, subnet.Name, err)
-					continue
+			if c.config.EnableGatewayBFD {
+				// routes to all gateway nodes are kept, ovn bypasses the ones whose bfd sessions are down
+				if _, err = c.reconcileGatewayBFD(subnet, nil); err != nil {
+					return err
 				}
-				klog.Infof("subnet %s adds centralized gw %v", subnet.Name, nextHops)
-
-				for _, nextHop := range nextHops {
-					if err = c.ovnClient.AddLogicalRouterStaticRoute(c.config.ClusterRouter, ovs.PolicySrcIP, cidr, nextHop); err != nil {
-						klog.Errorf("failed to add static route: %v", err)
-						return err
-					}
+			} else {
+				if err = c.addCentralizedGatewayRoutes(subnet); err != nil {
+					return err
 				}
 			}
 
@@ -1668,6 +1831,46 @@ func (c *Controller) reconcileGateway(subnet *kubeovnv1.Subnet) error {
 	return nil
 }
 
+// addCentralizedGatewayRoutes add ecmp routes to the ready gateway nodes of the centralized subnet
+func (c *Controller) addCentralizedGatewayRoutes(subnet *kubeovnv1.Subnet) error {
+	nodeIPs := make([]string, 0, len(strings.Split(subnet.Spec.GatewayNode, ",")))
+	for _, gw := range strings.Split(subnet.Spec.GatewayNode, ",") {
+		// the format of gatewayNodeStr can be like 'kube-ovn-worker:172.18.0.2, kube-ovn-control-plane:172.18.0.3', which consists of node name and designative egress ip
+		if strings.Contains(gw, ":") {
+			gw = strings.TrimSpace(strings.Split(gw, ":")[0])
+		} else {
+			gw = strings.TrimSpace(gw)
+		}
+
+		node, err := c.nodesLister.Get(gw)
+		if err == nil && nodeReady(node) {
+			nodeTunlIP := strings.TrimSpace(node.Annotations[util.IpAddressAnnotation])
+			if nodeTunlIP == "" {
+				klog.Errorf("gateway node %v has no ip annotation", node.Name)
+				continue
+			}
+			nodeIPs = append(nodeIPs, strings.Split(nodeTunlIP, ",")...)
+		}
+	}
+
+	for _, cidr := range strings.Split(subnetCIDRBlocks(subnet), ",") {
+		nextHops, err := c.filterRepeatEcmpRoutes(nodeIPs, cidr)
+		if err != nil {
+			klog.Errorf("failed to filter ecmp static route for CIDR %s of subnet %s: %v", cidr, subnet.Name, err)
+			continue
+		}
+		klog.Infof("subnet %s adds centralized gw %v", subnet.Name, nextHops)
+
+		for _, nextHop := range nextHops {
+			if err = c.ovnClient.AddLogicalRouterStaticRoute(c.config.ClusterRouter, util.MainRouteTable, ovs.PolicySrcIP, cidr, nextHop); err != nil {
+				klog.Errorf("failed to add static route: %v", err)
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (c *Controller) deleteStaticRoute(ip, router string, subnet *kubeovnv1.Subnet) error {
 	for _, ipStr := range strings.Split(ip, ",") {
 		if err := c.ovnLegacyClient.DeleteStaticRoute(ipStr, router); err != nil {
@@ -1717,7 +1920,7 @@ func (c *Controller) reconcileVlan(subnet *kubeovnv1.Subnet) error {
 }
 
 func calcDualSubnetStatusIP(subnet *kubeovnv1.Subnet, c *Controller) error {
-	if err := util.CheckCidrs(subnet.Spec.CIDRBlock); err != nil {
+	if err := util.CheckCidrs(subnetCIDRBlocks(subnet)); err != nil {
 		return err
 	}
 	// Get the number of pods, not ips. For one pod with two ip(v4 & v6) in dualstack, num of Items is 1
@@ -1731,11 +1934,9 @@ func calcDualSubnetStatusIP(subnet *kubeovnv1.Subnet, c *Controller) error {
 	// subnet.Spec.ExcludeIps contains both v4 and v6 addresses
 	v4ExcludeIps, v6ExcludeIps := util.SplitIpsByProtocol(subnet.Spec.ExcludeIps)
 	// gateway always in excludeIPs
-	cidrBlocks := strings.Split(subnet.Spec.CIDRBlock, ",")
-	v4toSubIPs := util.ExpandExcludeIPs(v4ExcludeIps, cidrBlocks[0])
-	v6toSubIPs := util.ExpandExcludeIPs(v6ExcludeIps, cidrBlocks[1])
-	_, v4CIDR, _ := net.ParseCIDR(cidrBlocks[0])
-	_, v6CIDR, _ := net.ParseCIDR(cidrBlocks[1])
+	cidrBlocks := subnetCIDRBlocks(subnet)
+	v4toSubIPs := util.ExpandExcludeIPs(v4ExcludeIps, cidrBlocks)
+	v6toSubIPs := util.ExpandExcludeIPs(v6ExcludeIps, cidrBlocks)
 	v4UsingIPs := make([]string, 0, len(podUsedIPs.Items))
 	v6UsingIPs := make([]string, 0, len(podUsedIPs.Items))
 	for _, podUsedIP := range podUsedIPs.Items {
@@ -1748,11 +1949,11 @@ func calcDualSubnetStatusIP(subnet *kubeovnv1.Subnet, c *Controller) error {
 			v6UsingIPs = append(v6UsingIPs, splitIPs[1])
 		}
 	}
-	v4availableIPs := util.AddressCount(v4CIDR) - util.CountIpNums(v4toSubIPs)
+	v4availableIPs := cidrBlocksAddressCount(cidrBlocks, kubeovnv1.ProtocolIPv4) - util.CountIpNums(v4toSubIPs)
 	if v4availableIPs < 0 {
 		v4availableIPs = 0
 	}
-	v6availableIPs := util.AddressCount(v6CIDR) - util.CountIpNums(v6toSubIPs)
+	v6availableIPs := cidrBlocksAddressCount(cidrBlocks, kubeovnv1.ProtocolIPv6) - util.CountIpNums(v6toSubIPs)
 	if v6availableIPs < 0 {
 		v6availableIPs = 0
 	}
@@ -1761,6 +1962,8 @@ func calcDualSubnetStatusIP(subnet *kubeovnv1.Subnet, c *Controller) error {
 	subnet.Status.V6AvailableIPs = v6availableIPs
 	subnet.Status.V4UsingIPs = float64(len(v4UsingIPs))
 	subnet.Status.V6UsingIPs = float64(len(v6UsingIPs))
+	c.checkSubnetUtilization(subnet)
+	subnet.Status.NamespaceIPQuotas = namespaceIPQuotaStatus(subnet, podUsedIPs.Items)
 
 	bytes, err := subnet.Status.Bytes()
 	if err != nil {
@@ -1771,8 +1974,8 @@ func calcDualSubnetStatusIP(subnet *kubeovnv1.Subnet, c *Controller) error {
 }
 
 func calcSubnetStatusIP(subnet *kubeovnv1.Subnet, c *Controller) error {
-	_, cidr, err := net.ParseCIDR(subnet.Spec.CIDRBlock)
-	if err != nil {
+	cidrBlocks := subnetCIDRBlocks(subnet)
+	if err := util.CheckCidrs(cidrBlocks); err != nil {
 		return err
 	}
 	podUsedIPs, err := c.config.KubeOvnClient.KubeovnV1().IPs().List(context.Background(), metav1.ListOptions{
@@ -1782,11 +1985,11 @@ func calcSubnetStatusIP(subnet *kubeovnv1.Subnet, c *Controller) error {
 		return err
 	}
 	// gateway always in excludeIPs
-	toSubIPs := util.ExpandExcludeIPs(subnet.Spec.ExcludeIps, subnet.Spec.CIDRBlock)
+	toSubIPs := util.ExpandExcludeIPs(subnet.Spec.ExcludeIps, cidrBlocks)
 	for _, podUsedIP := range podUsedIPs.Items {
 		toSubIPs = append(toSubIPs, podUsedIP.Spec.IPAddress)
 	}
-	availableIPs := util.AddressCount(cidr) - util.CountIpNums(toSubIPs)
+	availableIPs := cidrBlocksAddressCount(cidrBlocks, util.CheckProtocol(subnet.Spec.CIDRBlock)) - util.CountIpNums(toSubIPs)
 	if availableIPs < 0 {
 		availableIPs = 0
 	}
@@ -1798,6 +2001,8 @@ func calcSubnetStatusIP(subnet *kubeovnv1.Subnet, c *Controller) error {
 		subnet.Status.V6AvailableIPs = availableIPs
 		subnet.Status.V6UsingIPs = usingIPs
 	}
+	c.checkSubnetUtilization(subnet)
+	subnet.Status.NamespaceIPQuotas = namespaceIPQuotaStatus(subnet, podUsedIPs.Items)
 
 	bytes, err := subnet.Status.Bytes()
 	if err != nil {
@@ -1807,6 +2012,124 @@ func calcSubnetStatusIP(subnet *kubeovnv1.Subnet, c *Controller) error {
 	return err
 }
 
+// namespaceIPQuotaStatus returns usage of the namespaces with ip quotas in the subnet,
+// each ip cr is an address of a pod nic no matter how many protocols the subnet has
+func namespaceIPQuotaStatus(subnet *kubeovnv1.Subnet, ips []kubeovnv1.IP) []kubeovnv1.NamespaceIPQuotaStatus {
+	if len(subnet.Spec.NamespaceIPQuotas) == 0 {
+		return nil
+	}
+
+	used := make(map[string]int)
+	for _, ip := range ips {
+		used[ip.Spec.Namespace]++
+	}
+
+	namespaces := make([]string, 0, len(subnet.Spec.NamespaceIPQuotas))
+	for ns := range subnet.Spec.NamespaceIPQuotas {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+
+	status := make([]kubeovnv1.NamespaceIPQuotaStatus, 0, len(namespaces))
+	for _, ns := range namespaces {
+		status = append(status, kubeovnv1.NamespaceIPQuotaStatus{
+			Namespace: ns,
+			Quota:     subnet.Spec.NamespaceIPQuotas[ns],
+			Used:      used[ns],
+		})
+	}
+	return status
+}
+
+// subnetUtilization returns the highest percentage of used addresses among protocols of the subnet
+func subnetUtilization(subnet *kubeovnv1.Subnet) (float64, string) {
+	var utilization float64
+	var protocol string
+	protocols := []struct {
+		name             string
+		using, available float64
+	}{
+		{kubeovnv1.ProtocolIPv4, subnet.Status.V4UsingIPs, subnet.Status.V4AvailableIPs},
+		{kubeovnv1.ProtocolIPv6, subnet.Status.V6UsingIPs, subnet.Status.V6AvailableIPs},
+	}
+	for _, p := range protocols {
+		if p.using+p.available == 0 {
+			continue
+		}
+		if u := addressUtilization(p.using, p.available); protocol == "" || u > utilization {
+			utilization, protocol = u, p.name
+		}
+	}
+	return utilization, protocol
+}
+
+func addressUtilization(using, available float64) float64 {
+	if using+available == 0 {
+		return 0
+	}
+	return using * 100 / (using + available)
+}
+
+// checkSubnetUtilization updates the NearlyExhausted condition of the subnet,
+// an event is recorded when the utilization crosses the threshold
+func (c *Controller) checkSubnetUtilization(subnet *kubeovnv1.Subnet) {
+	threshold := subnet.Spec.UtilizationThreshold
+	if threshold <= 0 {
+		subnet.Status.RemoveCondition(kubeovnv1.NearlyExhausted)
+		return
+	}
+
+	exhausted := subnet.Status.IsConditionTrue(kubeovnv1.NearlyExhausted)
+	utilization, protocol := subnetUtilization(subnet)
+	if utilization >= float64(threshold) {
+		msg := fmt.Sprintf("%s address utilization %.1f%% reaches the threshold %d%%", protocol, utilization, threshold)
+		subnet.Status.SetCondition(kubeovnv1.NearlyExhausted, "UtilizationAboveThreshold", msg)
+		if !exhausted {
+			klog.Warningf("subnet %s is nearly exhausted, %s", subnet.Name, msg)
+			c.recorder.Eventf(subnet, v1.EventTypeWarning, "SubnetNearlyExhausted", msg)
+		}
+		return
+	}
+
+	msg := fmt.Sprintf("address utilization %.1f%% is below the threshold %d%%", utilization, threshold)
+	subnet.Status.ClearCondition(kubeovnv1.NearlyExhausted, "UtilizationBelowThreshold", msg)
+	if exhausted {
+		klog.Infof("subnet %s is no longer nearly exhausted, %s", subnet.Name, msg)
+		c.recorder.Eventf(subnet, v1.EventTypeNormal, "SubnetUtilizationNormal", msg)
+	}
+}
+
+// subnetCIDRBlocks returns all cidr blocks of the subnet, including the extra ones
+func subnetCIDRBlocks(subnet *kubeovnv1.Subnet) string {
+	return util.JoinCIDRBlocks(subnet.Spec.CIDRBlock, subnet.Spec.ExtraCIDRBlocks)
+}
+
+// subnetGateways returns gateways of the subnet in the same order as subnetCIDRBlocks
+func subnetGateways(subnet *kubeovnv1.Subnet) (string, error) {
+	if len(subnet.Spec.ExtraCIDRBlocks) == 0 {
+		return subnet.Spec.Gateway, nil
+	}
+	gws, err := util.GetGwByCidr(strings.Join(subnet.Spec.ExtraCIDRBlocks, ","))
+	if err != nil {
+		return "", err
+	}
+	return subnet.Spec.Gateway + "," + gws, nil
+}
+
+// cidrBlocksAddressCount returns the number of addresses of the protocol in the cidr blocks
+func cidrBlocksAddressCount(cidrBlocks, protocol string) float64 {
+	var count float64
+	for _, cidrBlock := range strings.Split(cidrBlocks, ",") {
+		if util.CheckProtocol(cidrBlock) != protocol {
+			continue
+		}
+		if _, cidr, err := net.ParseCIDR(cidrBlock); err == nil {
+			count += util.AddressCount(cidr)
+		}
+	}
+	return count
+}
+
 func isOvnSubnet(subnet *kubeovnv1.Subnet) bool {
 	if subnet.Spec.Provider == util.OvnProvider || subnet.Spec.Provider == "" || strings.HasSuffix(subnet.Spec.Provider, "ovn") {
 		return true
@@ -1951,3 +2274,10 @@ func (c *Controller) checkGwNodeExists(gatewayNode string) bool {
 	}
 	return found
 }
+
+func subnetReleasePolicy(subnet *kubeovnv1.Subnet) ipam.ReleasePolicy {
+	return ipam.ReleasePolicy{
+		ReuseTTL:   time.Duration(subnet.Spec.IPReuseTTL) * time.Second,
+		Quarantine: time.Duration(subnet.Spec.IPQuarantine) * time.Second,
+	}
+}
@@ -0,0 +1,493 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+const (
+	defaultMigrationBatchSize     = 10
+	defaultMigrationBatchInterval = 30
+)
+
+// subnetMigrationPlan is the result of checking what is still using the source cidr blocks
+type subnetMigrationPlan struct {
+	status *kubeovnv1.SubnetMigrationStatus
+	// pods which can be recreated by their owners
+	pods []*v1.Pod
+	// num of pods being deleted
+	terminating int
+	// num of pods already in the target cidr blocks but not ready
+	notReady int
+}
+
+func (p *subnetMigrationPlan) completed() bool {
+	s := p.status
+	return len(s.Pods)+len(s.ManualPods)+len(s.IPs)+len(s.Vips)+len(s.StaticRoutes)+len(s.Nats) == 0
+}
+
+func (c *Controller) runMigrateSubnetWorker() {
+	for c.processNextMigrateSubnetWorkItem() {
+	}
+}
+
+func (c *Controller) processNextMigrateSubnetWorkItem() bool {
+	obj, shutdown := c.migrateSubnetQueue.Get()
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.migrateSubnetQueue.Done(obj)
+		var key string
+		var ok bool
+		if key, ok = obj.(string); !ok {
+			c.migrateSubnetQueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+			return nil
+		}
+		if err := c.handleMigrateSubnet(key); err != nil {
+			c.migrateSubnetQueue.AddRateLimited(key)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
+		}
+		c.migrateSubnetQueue.Forget(obj)
+		return nil
+	}(obj)
+
+	if err != nil {
+		utilruntime.HandleError(err)
+		return true
+	}
+	return true
+}
+
+// handleMigrateSubnet plans the migration of a subnet to the cidr blocks in spec.migration,
+// and recreates pods in batches once the migration is started.
+// The source cidr blocks of a started migration are kept in an annotation of the subnet,
+// so the migration goes on even if the status is lost.
+func (c *Controller) handleMigrateSubnet(key string) error {
+	cachedSubnet, err := c.subnetsLister.Get(key)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !cachedSubnet.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	subnet := cachedSubnet.DeepCopy()
+
+	if source := subnet.Annotations[util.SubnetMigrationSourceAnnotation]; source != "" {
+		return c.migrateSubnet(subnet, source)
+	}
+
+	migration := subnet.Spec.Migration
+	if migration == nil || migration.CIDRBlock == subnet.Spec.CIDRBlock {
+		return nil
+	}
+
+	source, target := subnet.Spec.CIDRBlock, migration.CIDRBlock
+	plan, err := c.planSubnetMigration(subnet, source, target)
+	if err != nil {
+		klog.Errorf("failed to plan migration of subnet %s: %v", subnet.Name, err)
+		return err
+	}
+	if err = c.checkSubnetMigration(subnet, target); err != nil {
+		klog.Errorf("failed to migrate subnet %s to %s: %v", subnet.Name, target, err)
+		plan.status.Phase = kubeovnv1.MigrationFailed
+		plan.status.Message = err.Error()
+		_, err = c.patchSubnetMigrationStatus(subnet, plan.status)
+		return err
+	}
+	if migration.DryRun {
+		plan.status.Phase = kubeovnv1.MigrationPlanned
+		_, err = c.patchSubnetMigrationStatus(subnet, plan.status)
+		return err
+	}
+	return c.startSubnetMigration(subnet, plan.status)
+}
+
+// checkSubnetMigration checks whether the subnet can be migrated to the target cidr blocks
+func (c *Controller) checkSubnetMigration(subnet *kubeovnv1.Subnet, target string) error {
+	if subnet.Name == c.config.NodeSwitch {
+		return fmt.Errorf("migration of the join subnet is not supported")
+	}
+	if util.CheckProtocol(target) != util.CheckProtocol(subnet.Spec.CIDRBlock) {
+		return fmt.Errorf("target cidr %s does not match protocol of cidr %s", target, subnet.Spec.CIDRBlock)
+	}
+	if util.CIDRConflict(target, subnetCIDRBlocks(subnet)) {
+		return fmt.Errorf("target cidr %s overlaps with cidr %s", target, subnetCIDRBlocks(subnet))
+	}
+	// the source cidr blocks are kept as extra cidr blocks whose gateways are the first addresses
+	gw, err := util.GetGwByCidr(subnet.Spec.CIDRBlock)
+	if err != nil {
+		return err
+	}
+	if gw != subnet.Spec.Gateway {
+		return fmt.Errorf("gateway %s is not the first address of cidr %s", subnet.Spec.Gateway, subnet.Spec.CIDRBlock)
+	}
+
+	subnets, err := c.subnetsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list subnets: %v", err)
+		return err
+	}
+	for _, sub := range subnets {
+		if sub.Name == subnet.Name || sub.Spec.Vpc != subnet.Spec.Vpc || sub.Spec.Vlan != subnet.Spec.Vlan {
+			continue
+		}
+		if util.CIDRConflict(target, subnetCIDRBlocks(sub)) {
+			return fmt.Errorf("target cidr %s conflicts with subnet %s cidr %s", target, sub.Name, subnetCIDRBlocks(sub))
+		}
+	}
+	return nil
+}
+
+// planSubnetMigration finds pods, ip crs, vips, static routes and nat rules using the source cidr blocks
+func (c *Controller) planSubnetMigration(subnet *kubeovnv1.Subnet, source, target string) (*subnetMigrationPlan, error) {
+	plan := &subnetMigrationPlan{
+		status: &kubeovnv1.SubnetMigrationStatus{SourceCIDR: source, TargetCIDR: target},
+	}
+	status := plan.status
+
+	ips, err := c.ipsLister.List(labels.SelectorFromSet(labels.Set{util.SubnetNameLabel: subnet.Name}))
+	if err != nil {
+		klog.Errorf("failed to list ips of subnet %s: %v", subnet.Name, err)
+		return nil, err
+	}
+	for _, ip := range ips {
+		if !cidrBlocksContainIP(source, ip.Spec.V4IPAddress) && !cidrBlocksContainIP(source, ip.Spec.V6IPAddress) {
+			if ip.Spec.Namespace == "" || (!cidrBlocksContainIP(target, ip.Spec.V4IPAddress) && !cidrBlocksContainIP(target, ip.Spec.V6IPAddress)) {
+				continue
+			}
+			// pods recreated by previous batches
+			pod, err := c.podsLister.Pods(ip.Spec.Namespace).Get(ip.Spec.PodName)
+			if err != nil {
+				if k8serrors.IsNotFound(err) {
+					continue
+				}
+				klog.Errorf("failed to get pod %s/%s: %v", ip.Spec.Namespace, ip.Spec.PodName, err)
+				return nil, err
+			}
+			if pod.DeletionTimestamp.IsZero() && !isPodReady(pod) {
+				plan.notReady++
+			}
+			continue
+		}
+		if ip.Spec.Namespace == "" {
+			status.IPs = append(status.IPs, ip.Name)
+			continue
+		}
+
+		pod, err := c.podsLister.Pods(ip.Spec.Namespace).Get(ip.Spec.PodName)
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				// left for gc
+				continue
+			}
+			klog.Errorf("failed to get pod %s/%s: %v", ip.Spec.Namespace, ip.Spec.PodName, err)
+			return nil, err
+		}
+		key := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
+		if !isPodMigratable(pod, subnet.Spec.Provider) {
+			status.ManualPods = append(status.ManualPods, key)
+			continue
+		}
+		status.Pods = append(status.Pods, key)
+		if !pod.DeletionTimestamp.IsZero() {
+			plan.terminating++
+			continue
+		}
+		plan.pods = append(plan.pods, pod)
+	}
+
+	for _, vip := range subnet.Spec.Vips {
+		if cidrBlocksContainIP(source, vip) {
+			status.Vips = append(status.Vips, vip)
+		}
+	}
+
+	vpc, err := c.vpcsLister.Get(subnet.Spec.Vpc)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		klog.Errorf("failed to get vpc %s: %v", subnet.Spec.Vpc, err)
+		return nil, err
+	}
+	if vpc != nil {
+		for _, route := range vpc.Spec.StaticRoutes {
+			for _, nextHop := range splitNextHops(route.NextHopIP) {
+				if cidrBlocksContainIP(source, nextHop) {
+					status.StaticRoutes = append(status.StaticRoutes, fmt.Sprintf("%s via %s", route.CIDR, nextHop))
+				}
+			}
+		}
+	}
+
+	gws, err := c.vpcNatGatewayLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list vpc nat gateways: %v", err)
+		return nil, err
+	}
+	for _, gw := range gws {
+		if gw.Spec.Vpc != subnet.Spec.Vpc {
+			continue
+		}
+		if gw.Spec.Subnet == subnet.Name && cidrBlocksContainIP(source, gw.Spec.LanIp) {
+			status.Nats = append(status.Nats, fmt.Sprintf("%s lanIp %s", gw.Name, gw.Spec.LanIp))
+		}
+		for _, rule := range gw.Spec.FloatingIpRules {
+			if cidrBlocksContainIP(source, rule.InternalIp) {
+				status.Nats = append(status.Nats, fmt.Sprintf("%s fip %s to %s", gw.Name, rule.Eip, rule.InternalIp))
+			}
+		}
+		for _, rule := range gw.Spec.DnatRules {
+			if cidrBlocksContainIP(source, rule.InternalIp) {
+				status.Nats = append(status.Nats, fmt.Sprintf("%s dnat %s:%s to %s:%s", gw.Name, rule.Eip, rule.ExternalPort, rule.InternalIp, rule.InternalPort))
+			}
+		}
+		for _, rule := range gw.Spec.SnatRules {
+			if util.CIDRConflict(source, rule.InternalCIDR) {
+				status.Nats = append(status.Nats, fmt.Sprintf("%s snat %s to %s", gw.Name, rule.InternalCIDR, rule.Eip))
+			}
+		}
+	}
+
+	sort.Strings(status.Pods)
+	sort.Strings(status.ManualPods)
+	sort.Strings(status.IPs)
+	sort.Slice(plan.pods, func(i, j int) bool {
+		if plan.pods[i].Namespace != plan.pods[j].Namespace {
+			return plan.pods[i].Namespace < plan.pods[j].Namespace
+		}
+		return plan.pods[i].Name < plan.pods[j].Name
+	})
+	return plan, nil
+}
+
+// startSubnetMigration switches the subnet to the target cidr blocks and keeps the source
+// cidr blocks as extra cidr blocks, so existing pods stay routable during the migration
+func (c *Controller) startSubnetMigration(subnet *kubeovnv1.Subnet, status *kubeovnv1.SubnetMigrationStatus) error {
+	gw, err := util.GetGwByCidr(status.TargetCIDR)
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+
+	if subnet.Annotations == nil {
+		subnet.Annotations = map[string]string{}
+	}
+	subnet.Annotations[util.SubnetMigrationSourceAnnotation] = status.SourceCIDR
+	for _, cidr := range strings.Split(status.SourceCIDR, ",") {
+		if !util.ContainsString(subnet.Spec.ExtraCIDRBlocks, cidr) {
+			subnet.Spec.ExtraCIDRBlocks = append(subnet.Spec.ExtraCIDRBlocks, cidr)
+		}
+	}
+	subnet.Spec.CIDRBlock = status.TargetCIDR
+	subnet.Spec.Gateway = gw
+	newSubnet, err := c.config.KubeOvnClient.KubeovnV1().Subnets().Update(context.Background(), subnet, metav1.UpdateOptions{})
+	if err != nil {
+		klog.Errorf("failed to switch subnet %s to cidr %s: %v", subnet.Name, status.TargetCIDR, err)
+		return err
+	}
+	subnet = newSubnet
+
+	klog.Infof("start to migrate subnet %s from %s to %s", subnet.Name, status.SourceCIDR, status.TargetCIDR)
+	c.recorder.Eventf(subnet, v1.EventTypeNormal, "MigrationStarted", "start to migrate from %s to %s", status.SourceCIDR, status.TargetCIDR)
+	status.Phase = kubeovnv1.MigrationInProgress
+	_, err = c.patchSubnetMigrationStatus(subnet, status)
+	return err
+}
+
+// migrateSubnet recreates a batch of pods still using the source cidr blocks,
+// and completes the migration when nothing uses them anymore
+func (c *Controller) migrateSubnet(subnet *kubeovnv1.Subnet, source string) error {
+	target := subnet.Spec.CIDRBlock
+	if migration := subnet.Spec.Migration; migration != nil && migration.CIDRBlock != target {
+		c.recorder.Eventf(subnet, v1.EventTypeWarning, "MigrationInProgress", "migration from %s to %s is in progress, target cidr %s is ignored", source, target, migration.CIDRBlock)
+	}
+
+	plan, err := c.planSubnetMigration(subnet, source, target)
+	if err != nil {
+		klog.Errorf("failed to plan migration of subnet %s: %v", subnet.Name, err)
+		return err
+	}
+	status := plan.status
+	status.Phase = kubeovnv1.MigrationInProgress
+	if last := subnet.Status.Migration; last != nil && last.SourceCIDR == source && last.TargetCIDR == target {
+		status.MigratedPods = last.MigratedPods
+		status.LastBatchTime = last.LastBatchTime
+	}
+	if plan.completed() {
+		return c.completeSubnetMigration(subnet, source, status)
+	}
+
+	batchSize, batchInterval := defaultMigrationBatchSize, defaultMigrationBatchInterval
+	if migration := subnet.Spec.Migration; migration != nil {
+		if migration.BatchSize > 0 {
+			batchSize = migration.BatchSize
+		}
+		if migration.BatchInterval > 0 {
+			batchInterval = migration.BatchInterval
+		}
+	}
+	interval := time.Duration(batchInterval) * time.Second
+	next := interval
+	if wait := interval - time.Since(status.LastBatchTime.Time); wait > 0 {
+		next = wait
+	} else if plan.terminating == 0 && plan.notReady == 0 && len(plan.pods) != 0 {
+		// wait for pods of the last batch to be deleted and recreated pods to be ready before starting a new batch
+		if len(plan.pods) > batchSize {
+			plan.pods = plan.pods[:batchSize]
+		}
+		var evicted int
+		for _, pod := range plan.pods {
+			klog.Infof("evict pod %s/%s to migrate it to cidr %s", pod.Namespace, pod.Name, target)
+			eviction := &policyv1.Eviction{ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace}}
+			if err = c.config.KubeClient.PolicyV1().Evictions(pod.Namespace).Evict(context.Background(), eviction); err != nil {
+				if k8serrors.IsNotFound(err) {
+					continue
+				}
+				if k8serrors.IsTooManyRequests(err) {
+					// disallowed by the pod disruption budget, retried in the next batch
+					klog.Warningf("failed to evict pod %s/%s: %v", pod.Namespace, pod.Name, err)
+					continue
+				}
+				klog.Errorf("failed to evict pod %s/%s: %v", pod.Namespace, pod.Name, err)
+				return err
+			}
+			evicted++
+		}
+		status.MigratedPods += evicted
+		status.LastBatchTime = metav1.Now()
+		c.recorder.Eventf(subnet, v1.EventTypeNormal, "MigrationBatch", "evict %d pods to migrate to %s", evicted, target)
+	}
+
+	status.Message = fmt.Sprintf("%d pods to be recreated, %d recreated pods not ready, %d pods, %d ips, %d vips, %d static routes and %d nat rules to be migrated manually",
+		len(status.Pods), plan.notReady, len(status.ManualPods), len(status.IPs), len(status.Vips), len(status.StaticRoutes), len(status.Nats))
+	if _, err = c.patchSubnetMigrationStatus(subnet, status); err != nil {
+		return err
+	}
+	c.migrateSubnetQueue.AddAfter(subnet.Name, next)
+	return nil
+}
+
+// completeSubnetMigration removes the source cidr blocks from the subnet
+func (c *Controller) completeSubnetMigration(subnet *kubeovnv1.Subnet, source string, status *kubeovnv1.SubnetMigrationStatus) error {
+	sourceBlocks := strings.Split(source, ",")
+	var extraCIDRBlocks []string
+	for _, cidr := range subnet.Spec.ExtraCIDRBlocks {
+		if !util.ContainsString(sourceBlocks, cidr) {
+			extraCIDRBlocks = append(extraCIDRBlocks, cidr)
+		}
+	}
+	var excludeIps []string
+	for _, excludeIP := range subnet.Spec.ExcludeIps {
+		if !cidrBlocksContainIP(source, strings.Split(excludeIP, "..")[0]) {
+			excludeIps = append(excludeIps, excludeIP)
+		}
+	}
+	delete(subnet.Annotations, util.SubnetMigrationSourceAnnotation)
+	subnet.Spec.ExtraCIDRBlocks = extraCIDRBlocks
+	subnet.Spec.ExcludeIps = excludeIps
+
+	newSubnet, err := c.config.KubeOvnClient.KubeovnV1().Subnets().Update(context.Background(), subnet, metav1.UpdateOptions{})
+	if err != nil {
+		klog.Errorf("failed to remove cidr %s from subnet %s: %v", source, subnet.Name, err)
+		return err
+	}
+	subnet = newSubnet
+
+	klog.Infof("subnet %s has been migrated from %s to %s", subnet.Name, source, subnet.Spec.CIDRBlock)
+	c.recorder.Eventf(subnet, v1.EventTypeNormal, "MigrationCompleted", "migrated from %s to %s", source, subnet.Spec.CIDRBlock)
+	status.Phase = kubeovnv1.MigrationCompleted
+	status.Message = ""
+	_, err = c.patchSubnetMigrationStatus(subnet, status)
+	return err
+}
+
+func (c *Controller) patchSubnetMigrationStatus(subnet *kubeovnv1.Subnet, status *kubeovnv1.SubnetMigrationStatus) (*kubeovnv1.Subnet, error) {
+	subnet.Status.Migration = status
+	bytes, err := subnet.Status.Bytes()
+	if err != nil {
+		klog.Error(err)
+		return nil, err
+	}
+	newSubnet, err := c.config.KubeOvnClient.KubeovnV1().Subnets().Patch(context.Background(), subnet.Name, types.MergePatchType, bytes, metav1.PatchOptions{}, "status")
+	if err != nil {
+		klog.Errorf("failed to patch migration status of subnet %s: %v", subnet.Name, err)
+		return nil, err
+	}
+	return newSubnet, nil
+}
+
+// isPodMigratable returns whether the pod gets a new address after being recreated by its owner
+func isPodMigratable(pod *v1.Pod, provider string) bool {
+	if pod.Annotations[fmt.Sprintf(util.IpAddressAnnotationTemplate, provider)] != "" ||
+		pod.Annotations[fmt.Sprintf(util.IpPoolAnnotationTemplate, provider)] != "" {
+		return false
+	}
+	// addresses of vm pods are kept across restarts
+	if pod.Labels[util.KubeVirtVmLabel] != "" {
+		return false
+	}
+	return metav1.GetControllerOf(pod) != nil
+}
+
+func isPodReady(pod *v1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// subnetExcludeIps returns addresses not to be allocated, including the source cidr blocks of an ongoing migration
+func subnetExcludeIps(subnet *kubeovnv1.Subnet) []string {
+	source := subnet.Annotations[util.SubnetMigrationSourceAnnotation]
+	if source == "" {
+		return subnet.Spec.ExcludeIps
+	}
+
+	excludeIps := append([]string{}, subnet.Spec.ExcludeIps...)
+	for _, cidr := range strings.Split(source, ",") {
+		firstIP, err := util.FirstIP(cidr)
+		if err != nil {
+			klog.Error(err)
+			continue
+		}
+		lastIP, _ := util.LastIP(cidr)
+		excludeIps = append(excludeIps, firstIP+".."+lastIP)
+	}
+	return excludeIps
+}
+
+// cidrBlocksContainIP returns whether any of the addresses is in the cidr blocks
+func cidrBlocksContainIP(cidrBlocks, ipStr string) bool {
+	if ipStr == "" {
+		return false
+	}
+	for _, cidrBlock := range strings.Split(cidrBlocks, ",") {
+		for _, ip := range strings.Split(ipStr, ",") {
+			if util.CIDRContainIP(cidrBlock, ip) {
+				return true
+			}
+		}
+	}
+	return false
+}
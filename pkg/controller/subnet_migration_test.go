@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/util/workqueue"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+type subnetMigrationTest struct {
+	*testController
+	// pods whose eviction is disallowed by pod disruption budgets
+	blocked map[string]bool
+}
+
+func newSubnetMigrationTest(t *testing.T, pods []*corev1.Pod, ips []*kubeovnv1.IP) *subnetMigrationTest {
+	objects := []runtime.Object{&kubeovnv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "net1",
+			Annotations: map[string]string{util.SubnetMigrationSourceAnnotation: "10.16.0.0/24"},
+		},
+		Spec: kubeovnv1.SubnetSpec{
+			CIDRBlock:       "10.17.0.0/24",
+			ExtraCIDRBlocks: []string{"10.16.0.0/24"},
+			Gateway:         "10.17.0.1",
+			Protocol:        kubeovnv1.ProtocolIPv4,
+			Provider:        util.OvnProvider,
+			Vpc:             util.DefaultVpc,
+			Migration:       &kubeovnv1.SubnetMigration{CIDRBlock: "10.17.0.0/24", BatchSize: 2},
+		},
+	}}
+	for _, pod := range pods {
+		objects = append(objects, pod)
+	}
+	for _, ip := range ips {
+		objects = append(objects, ip)
+	}
+
+	test := &subnetMigrationTest{testController: newTestController(t, withObjects(objects...)), blocked: map[string]bool{}}
+	test.kubeClient.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := action.(k8stesting.CreateAction).GetObject().(*policyv1.Eviction)
+		if test.blocked[eviction.Name] {
+			return true, nil, k8serrors.NewTooManyRequests("cannot evict pod as it would violate the pod's disruption budget", 10)
+		}
+		return true, nil, nil
+	})
+
+	test.migrateSubnetQueue = workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "MigrateSubnet")
+	t.Cleanup(test.migrateSubnetQueue.ShutDown)
+	return test
+}
+
+// evicted returns names of the pods evicted successfully
+func (test *subnetMigrationTest) evicted() []string {
+	var names []string
+	for _, action := range test.kubeClient.Actions() {
+		if action.GetVerb() != "create" || action.GetSubresource() != "eviction" {
+			continue
+		}
+		if name := action.(k8stesting.CreateAction).GetObject().(*policyv1.Eviction).Name; !test.blocked[name] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (test *subnetMigrationTest) subnet(t *testing.T) *kubeovnv1.Subnet {
+	subnet, err := test.kubeOvnClient.KubeovnV1().Subnets().Get(context.Background(), "net1", metav1.GetOptions{})
+	require.NoError(t, err)
+	return subnet
+}
+
+func newMigrationTestPod(name, ip string, ready bool) (*corev1.Pod, *kubeovnv1.IP) {
+	isController := true
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "default",
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "rs", Controller: &isController}},
+			Annotations:     map[string]string{util.LogicalSwitchAnnotation: "net1"},
+		},
+		Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}}},
+	}
+	return pod, &kubeovnv1.IP{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name + ".default",
+			Labels: map[string]string{util.SubnetNameLabel: "net1"},
+		},
+		Spec: kubeovnv1.IPSpec{PodName: name, Namespace: "default", Subnet: "net1", V4IPAddress: ip},
+	}
+}
+
+func TestMigrateSubnet(t *testing.T) {
+	sourcePods := func(names ...string) ([]*corev1.Pod, []*kubeovnv1.IP) {
+		var pods []*corev1.Pod
+		var ips []*kubeovnv1.IP
+		for i, name := range names {
+			pod, ip := newMigrationTestPod(name, fmt.Sprintf("10.16.0.%d", i+2), true)
+			pods, ips = append(pods, pod), append(ips, ip)
+		}
+		return pods, ips
+	}
+
+	t.Run("evict a batch", func(t *testing.T) {
+		pods, ips := sourcePods("p1", "p2", "p3")
+		test := newSubnetMigrationTest(t, pods, ips)
+		require.NoError(t, test.handleMigrateSubnet("net1"))
+		require.Equal(t, []string{"p1", "p2"}, test.evicted())
+		for _, action := range test.kubeClient.Actions() {
+			require.NotEqual(t, "delete", action.GetVerb())
+		}
+
+		status := test.subnet(t).Status.Migration
+		require.NotNil(t, status)
+		require.Equal(t, kubeovnv1.MigrationInProgress, status.Phase)
+		require.Equal(t, []string{"default/p1", "default/p2", "default/p3"}, status.Pods)
+		require.Equal(t, 2, status.MigratedPods)
+		require.False(t, status.LastBatchTime.IsZero())
+	})
+
+	t.Run("wait for terminating pods", func(t *testing.T) {
+		pods, ips := sourcePods("p1", "p2", "p3")
+		now := metav1.Now()
+		pods[0].DeletionTimestamp = &now
+		test := newSubnetMigrationTest(t, pods, ips)
+		require.NoError(t, test.handleMigrateSubnet("net1"))
+		require.Empty(t, test.evicted())
+		require.Equal(t, 0, test.subnet(t).Status.Migration.MigratedPods)
+	})
+
+	t.Run("wait for recreated pods to be ready", func(t *testing.T) {
+		pods, ips := sourcePods("p1", "p2")
+		recreated, recreatedIP := newMigrationTestPod("p3", "10.17.0.2", false)
+		test := newSubnetMigrationTest(t, append(pods, recreated), append(ips, recreatedIP))
+		require.NoError(t, test.handleMigrateSubnet("net1"))
+		require.Empty(t, test.evicted())
+		require.Contains(t, test.subnet(t).Status.Migration.Message, "1 recreated pods not ready")
+
+		recreated, recreatedIP = newMigrationTestPod("p3", "10.17.0.2", true)
+		test = newSubnetMigrationTest(t, append(pods, recreated), append(ips, recreatedIP))
+		require.NoError(t, test.handleMigrateSubnet("net1"))
+		require.Equal(t, []string{"p1", "p2"}, test.evicted())
+	})
+
+	t.Run("pod disruption budget", func(t *testing.T) {
+		pods, ips := sourcePods("p1", "p2", "p3")
+		test := newSubnetMigrationTest(t, pods, ips)
+		test.blocked["p1"] = true
+		require.NoError(t, test.handleMigrateSubnet("net1"))
+		require.Equal(t, []string{"p2"}, test.evicted())
+		require.Equal(t, 1, test.subnet(t).Status.Migration.MigratedPods)
+	})
+
+	t.Run("complete", func(t *testing.T) {
+		recreated, recreatedIP := newMigrationTestPod("p1", "10.17.0.2", true)
+		test := newSubnetMigrationTest(t, []*corev1.Pod{recreated}, []*kubeovnv1.IP{recreatedIP})
+		require.NoError(t, test.handleMigrateSubnet("net1"))
+		require.Empty(t, test.evicted())
+
+		subnet := test.subnet(t)
+		require.Empty(t, subnet.Spec.ExtraCIDRBlocks)
+		require.NotContains(t, subnet.Annotations, util.SubnetMigrationSourceAnnotation)
+		require.Equal(t, kubeovnv1.MigrationCompleted, subnet.Status.Migration.Phase)
+	})
+}
@@ -0,0 +1,275 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func (c *Controller) enqueueAddSubnetTemplate(obj interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	var key string
+	var err error
+	if key, err = cache.MetaNamespaceKeyFunc(obj); err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	klog.V(3).Infof("enqueue add subnet template %s", key)
+	c.updateSubnetTemplateStatusQueue.Add(key)
+	c.enqueueAllNamespaces()
+}
+
+func (c *Controller) enqueueUpdateSubnetTemplate(old, new interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	oldTemplate := old.(*kubeovnv1.SubnetTemplate)
+	newTemplate := new.(*kubeovnv1.SubnetTemplate)
+	if reflect.DeepEqual(oldTemplate.Spec, newTemplate.Spec) {
+		return
+	}
+
+	// subnets carved already are kept, the new spec only applies to namespaces without a carved subnet
+	klog.V(3).Infof("enqueue update subnet template %s", newTemplate.Name)
+	c.updateSubnetTemplateStatusQueue.Add(newTemplate.Name)
+	c.enqueueAllNamespaces()
+}
+
+func (c *Controller) enqueueAllNamespaces() {
+	namespaces, err := c.namespacesLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list namespaces, %v", err)
+		return
+	}
+	for _, ns := range namespaces {
+		c.addNamespaceQueue.Add(ns.Name)
+	}
+}
+
+func (c *Controller) runUpdateSubnetTemplateStatusWorker() {
+	for c.processNextUpdateSubnetTemplateStatusWorkItem() {
+	}
+}
+
+func (c *Controller) processNextUpdateSubnetTemplateStatusWorkItem() bool {
+	obj, shutdown := c.updateSubnetTemplateStatusQueue.Get()
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.updateSubnetTemplateStatusQueue.Done(obj)
+		var key string
+		var ok bool
+		if key, ok = obj.(string); !ok {
+			c.updateSubnetTemplateStatusQueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+			return nil
+		}
+		if err := c.handleUpdateSubnetTemplateStatus(key); err != nil {
+			c.updateSubnetTemplateStatusQueue.AddRateLimited(key)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
+		}
+		c.updateSubnetTemplateStatusQueue.Forget(obj)
+		return nil
+	}(obj)
+
+	if err != nil {
+		utilruntime.HandleError(err)
+		return true
+	}
+	return true
+}
+
+// handleUpdateSubnetTemplateStatus update allocations of the template with the subnets carved from it
+func (c *Controller) handleUpdateSubnetTemplateStatus(key string) error {
+	template, err := c.subnetTemplatesLister.Get(key)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if err = util.ValidateSubnetTemplate(template); err != nil {
+		klog.Errorf("invalid subnet template %s, %v", key, err)
+		c.recorder.Eventf(template, v1.EventTypeWarning, "ValidateSubnetTemplateFailed", err.Error())
+	}
+
+	// the lister may not see the subnets carved or released just now
+	subnetList, err := c.config.KubeOvnClient.KubeovnV1().Subnets().List(context.Background(), metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{util.SubnetTemplateLabel: key}).String(),
+	})
+	if err != nil {
+		klog.Errorf("failed to list subnets of subnet template %s, %v", key, err)
+		return err
+	}
+
+	allocations := make([]kubeovnv1.SubnetTemplateAllocation, 0, len(subnetList.Items))
+	for _, subnet := range subnetList.Items {
+		if subnet.DeletionTimestamp != nil {
+			continue
+		}
+		allocations = append(allocations, kubeovnv1.SubnetTemplateAllocation{
+			Namespace: subnet.Labels[util.SubnetTemplateNamespaceLabel],
+			Subnet:    subnet.Name,
+			CIDRBlock: subnet.Spec.CIDRBlock,
+		})
+	}
+	sort.Slice(allocations, func(i, j int) bool { return allocations[i].Namespace < allocations[j].Namespace })
+	if len(allocations) == len(template.Status.Allocations) &&
+		(len(allocations) == 0 || reflect.DeepEqual(allocations, template.Status.Allocations)) {
+		return nil
+	}
+
+	status := kubeovnv1.SubnetTemplateStatus{Allocations: allocations}
+	bytes, err := status.Bytes()
+	if err != nil {
+		return err
+	}
+	if _, err = c.config.KubeOvnClient.KubeovnV1().SubnetTemplates().Patch(context.Background(), key, types.MergePatchType, bytes, metav1.PatchOptions{}, "status"); err != nil {
+		klog.Errorf("failed to patch status of subnet template %s, %v", key, err)
+		return err
+	}
+	return nil
+}
+
+// carveNamespaceSubnet create a subnet bound to the namespace with the next free block in the supernet of
+// the first subnet template matching the namespace, nil is returned if no template applies to the namespace
+func (c *Controller) carveNamespaceSubnet(namespace *v1.Namespace) (*kubeovnv1.Subnet, error) {
+	templates, err := c.subnetTemplatesLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list subnet templates, %v", err)
+		return nil, err
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+
+	var template *kubeovnv1.SubnetTemplate
+	for _, t := range templates {
+		// invalid templates are reported by handleUpdateSubnetTemplateStatus
+		if util.ValidateSubnetTemplate(t) != nil {
+			continue
+		}
+		selector, _ := metav1.LabelSelectorAsSelector(t.Spec.NamespaceSelector)
+		if selector.Matches(labels.Set(namespace.Labels)) {
+			template = t
+			break
+		}
+	}
+	if template == nil {
+		return nil, nil
+	}
+
+	c.subnetTemplateMutex.Lock()
+	defer c.subnetTemplateMutex.Unlock()
+
+	subnets, err := c.subnetsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list subnets, %v", err)
+		return nil, err
+	}
+	// the lister may not see the subnets carved just now
+	for _, subnet := range subnets {
+		delete(c.carvedSubnets, subnet.Name)
+	}
+	for _, subnet := range c.carvedSubnets {
+		subnets = append(subnets, subnet)
+	}
+
+	used := []string{c.config.ServiceClusterIPRange}
+	for _, subnet := range subnets {
+		if subnet.Labels[util.SubnetTemplateLabel] != "" && subnet.Labels[util.SubnetTemplateNamespaceLabel] == namespace.Name {
+			if subnet.DeletionTimestamp != nil {
+				return nil, fmt.Errorf("subnet %s carved for namespace %s is being deleted", subnet.Name, namespace.Name)
+			}
+			return subnet, nil
+		}
+		// the namespace is bound to a subnet by hand
+		if subnet.Labels[util.SubnetTemplateLabel] == "" && util.ContainsString(subnet.Spec.Namespaces, namespace.Name) {
+			return nil, nil
+		}
+		used = append(used, subnetCIDRBlocks(subnet))
+	}
+
+	block, err := util.CarveCIDRBlock(template.Spec.Supernet, template.Spec.PrefixLength, used)
+	if err != nil {
+		klog.Errorf("failed to carve subnet for namespace %s from subnet template %s, %v", namespace.Name, template.Name, err)
+		c.recorder.Eventf(template, v1.EventTypeWarning, "CarveSubnetFailed", fmt.Sprintf("namespace %s: %v", namespace.Name, err))
+		return nil, err
+	}
+
+	subnet := &kubeovnv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("%s-%s", template.Name, namespace.Name),
+			Labels: map[string]string{
+				util.SubnetTemplateLabel:          template.Name,
+				util.SubnetTemplateNamespaceLabel: namespace.Name,
+			},
+		},
+		Spec: *template.Spec.Template.DeepCopy(),
+	}
+	subnet.Spec.CIDRBlock = block
+	subnet.Spec.Protocol = util.CheckProtocol(block)
+	subnet.Spec.Gateway = ""
+	subnet.Spec.ExcludeIps = nil
+	subnet.Spec.ExtraCIDRBlocks = nil
+	subnet.Spec.Namespaces = []string{namespace.Name}
+
+	klog.Infof("carve subnet %s with cidr %s for namespace %s from subnet template %s", subnet.Name, block, namespace.Name, template.Name)
+	if subnet, err = c.config.KubeOvnClient.KubeovnV1().Subnets().Create(context.Background(), subnet, metav1.CreateOptions{}); err != nil {
+		klog.Errorf("failed to create subnet for namespace %s, %v", namespace.Name, err)
+		c.recorder.Eventf(template, v1.EventTypeWarning, "CarveSubnetFailed", fmt.Sprintf("namespace %s: %v", namespace.Name, err))
+		return nil, err
+	}
+	if c.carvedSubnets == nil {
+		c.carvedSubnets = map[string]*kubeovnv1.Subnet{}
+	}
+	c.carvedSubnets[subnet.Name] = subnet
+	c.updateSubnetTemplateStatusQueue.Add(template.Name)
+	return subnet, nil
+}
+
+// releaseNamespaceSubnets delete the subnets carved from subnet templates for the namespace
+func (c *Controller) releaseNamespaceSubnets(namespace string) error {
+	c.subnetTemplateMutex.Lock()
+	for name, subnet := range c.carvedSubnets {
+		if subnet.Labels[util.SubnetTemplateNamespaceLabel] == namespace {
+			delete(c.carvedSubnets, name)
+		}
+	}
+	c.subnetTemplateMutex.Unlock()
+
+	selector := fmt.Sprintf("%s,%s=%s", util.SubnetTemplateLabel, util.SubnetTemplateNamespaceLabel, namespace)
+	subnetList, err := c.config.KubeOvnClient.KubeovnV1().Subnets().List(context.Background(), metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		klog.Errorf("failed to list subnets carved for namespace %s, %v", namespace, err)
+		return err
+	}
+
+	for _, subnet := range subnetList.Items {
+		if subnet.DeletionTimestamp == nil {
+			klog.Infof("release subnet %s carved for namespace %s", subnet.Name, namespace)
+			if err = c.config.KubeOvnClient.KubeovnV1().Subnets().Delete(context.Background(), subnet.Name, metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+				klog.Errorf("failed to delete subnet %s, %v", subnet.Name, err)
+				return err
+			}
+		}
+		c.updateSubnetTemplateStatusQueue.Add(subnet.Labels[util.SubnetTemplateLabel])
+	}
+	return nil
+}
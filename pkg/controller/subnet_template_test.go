@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func TestCarveNamespaceSubnet(t *testing.T) {
+	template := &kubeovnv1.SubnetTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "tpl"},
+		Spec: kubeovnv1.SubnetTemplateSpec{
+			Supernet:          "10.100.0.0/16",
+			PrefixLength:      24,
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "true"}},
+		},
+	}
+	namespace := func(name string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"tenant": "true"}}}
+	}
+	manual := &kubeovnv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: "manual"},
+		Spec:       kubeovnv1.SubnetSpec{CIDRBlock: "10.100.0.0/24", Namespaces: []string{"ns3"}},
+	}
+	c := newTestController(t, withObjects(template, manual))
+	c.updateSubnetTemplateStatusQueue = workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "UpdateSubnetTemplateStatus")
+	t.Cleanup(c.updateSubnetTemplateStatusQueue.ShutDown)
+
+	// blocks carved just now are not reused although the lister does not see the subnets yet
+	subnet1, err := c.carveNamespaceSubnet(namespace("ns1"))
+	require.NoError(t, err)
+	require.Equal(t, "10.100.1.0/24", subnet1.Spec.CIDRBlock)
+	require.Equal(t, "ns1", subnet1.Labels[util.SubnetTemplateNamespaceLabel])
+	subnet2, err := c.carveNamespaceSubnet(namespace("ns2"))
+	require.NoError(t, err)
+	require.Equal(t, "10.100.2.0/24", subnet2.Spec.CIDRBlock)
+	subnet, err := c.carveNamespaceSubnet(namespace("ns1"))
+	require.NoError(t, err)
+	require.Equal(t, subnet1.Name, subnet.Name)
+
+	// carved subnets seen by the lister are not tracked any more
+	c.addObject(t, subnet1)
+	_, err = c.carveNamespaceSubnet(namespace("ns2"))
+	require.NoError(t, err)
+	require.NotContains(t, c.carvedSubnets, subnet1.Name)
+	require.Contains(t, c.carvedSubnets, subnet2.Name)
+
+	// the namespace bound to a subnet by hand is skipped
+	subnet, err = c.carveNamespaceSubnet(namespace("ns3"))
+	require.NoError(t, err)
+	require.Nil(t, subnet)
+}
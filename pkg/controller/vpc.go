@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	v1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -63,6 +64,7 @@ func (c *Controller) enqueueUpdateVpc(old, new interface{}) {
 		!reflect.DeepEqual(oldVpc.Spec.StaticRoutes, newVpc.Spec.StaticRoutes) ||
 		!reflect.DeepEqual(oldVpc.Spec.PolicyRoutes, newVpc.Spec.PolicyRoutes) ||
 		!reflect.DeepEqual(oldVpc.Spec.VpcPeerings, newVpc.Spec.VpcPeerings) ||
+		!reflect.DeepEqual(oldVpc.Spec.Quota, newVpc.Spec.Quota) ||
 		!reflect.DeepEqual(oldVpc.Annotations, newVpc.Annotations) {
 		klog.V(3).Infof("enqueue update vpc %s", key)
 		c.addOrUpdateVpcQueue.Add(key)
@@ -115,6 +117,43 @@ func (c *Controller) handleDelVpc(vpc *kubeovnv1.Vpc) error {
 		return err
 	}
 
+	// bfd sessions are not deleted with the router ports
+	for _, subnet := range vpc.Status.Subnets {
+		if err = c.ovnClient.DeleteBFD(fmt.Sprintf("%s-%s", vpc.Status.Router, subnet), ""); err != nil {
+			klog.Errorf("failed to delete bfd sessions of vpc %s, %v", vpc.Name, err)
+			return err
+		}
+	}
+	bfdList, err := c.ovnClient.ListBFDByExternalIDs(map[string]string{logicalRouterKey: vpc.Name})
+	if err != nil {
+		klog.Errorf("failed to list bfd sessions of vpc %s, %v", vpc.Name, err)
+		return err
+	}
+	for _, bfd := range bfdList {
+		if err = c.ovnClient.DeleteBFD(bfd.LogicalPort, bfd.DstIP); err != nil {
+			klog.Errorf("failed to delete bfd sessions of vpc %s, %v", vpc.Name, err)
+			return err
+		}
+	}
+
+	// ports on transit switches of vpc peerings across azs are not deleted with the router
+	if len(vpc.Status.PeeringStatus) != 0 {
+		_, icConfig, err := c.getICAZName()
+		if err != nil {
+			klog.Errorf("failed to get ovn-ic config, %v", err)
+			return err
+		}
+		for _, status := range vpc.Status.PeeringStatus {
+			if status.TransitSwitch == "" {
+				continue
+			}
+			if err = c.disconnectICVpcPeering(icConfig["az-name"], status); err != nil {
+				klog.Errorf("failed to disconnect vpc %s from vpc %s in az %s, %v", vpc.Name, status.RemoteVpc, status.RemoteAZ, err)
+				return err
+			}
+		}
+	}
+
 	if vpc.Annotations[util.DnsEnableAnnotation] == "true" {
 		// delete dns and clear dns_records from logical_switch
 		if err := c.destroyVpcDns(vpc); err != nil {
@@ -150,6 +189,20 @@ func (c *Controller) handleUpdateVpcStatus(key string) error {
 
 	vpc.Status.DefaultLogicalSwitch = defaultSubnet
 	vpc.Status.Subnets = subnets
+	if vpc.Status.StaticRoutes, err = c.getVpcStaticRouteStatus(vpc); err != nil {
+		klog.Errorf("failed to get static route status of vpc %s, %v", vpc.Name, err)
+		return err
+	}
+	if vpc.Status.QuotaUsage, err = c.getVpcQuotaUsage(vpc); err != nil {
+		klog.Errorf("failed to get quota usage of vpc %s, %v", vpc.Name, err)
+		return err
+	}
+	// the quota is not enforced if the webhook is not deployed or the quota is lowered
+	if vpc.Status.QuotaUsage != nil && !reflect.DeepEqual(vpc.Status.QuotaUsage, orivpc.Status.QuotaUsage) {
+		if err = util.ValidateVpcQuota(vpc.Name, vpc.Spec.Quota, *vpc.Status.QuotaUsage, kubeovnv1.VpcQuotaUsage{}); err != nil {
+			c.recorder.Eventf(vpc, v1.EventTypeWarning, "VpcQuotaExceeded", err.Error())
+		}
+	}
 	bytes, err := vpc.Status.Bytes()
 	if err != nil {
 		return err
@@ -164,6 +217,7 @@ func (c *Controller) handleUpdateVpcStatus(key string) error {
 			c.addNamespaceQueue.Add(ns)
 		}
 	}
+	c.enqueueVpcDNSRecords(vpc.Name)
 
 	natGws, err := c.vpcNatGatewayLister.List(labels.Everything())
 	if err != nil {
@@ -289,25 +343,10 @@ func (c *Controller) handleAddOrUpdateVpc(key string) error {
 		return err
 	}
 
-	var newPeers []string
-	for _, peering := range vpc.Spec.VpcPeerings {
-		if err = util.CheckCidrs(peering.LocalConnectIP); err != nil {
-			klog.Errorf("invalid cidr %s", peering.LocalConnectIP)
-			return err
-		}
-		newPeers = append(newPeers, peering.RemoteVpc)
-		if err := c.ovnClient.CreatePeerRouterPort(vpc.Name, peering.RemoteVpc, peering.LocalConnectIP); err != nil {
-			klog.Errorf("failed to create peer router port for vpc %s, %v", vpc.Name, err)
-			return err
-		}
-	}
-	for _, oldPeer := range vpc.Status.VpcPeerings {
-		if !util.ContainsString(newPeers, oldPeer) {
-			if err = c.ovnLegacyClient.DeleteLogicalRouterPort(fmt.Sprintf("%s-%s", vpc.Name, oldPeer)); err != nil {
-				klog.Errorf("failed to delete peer router port for vpc %s, %v", vpc.Name, err)
-				return err
-			}
-		}
+	newPeers, peeringStatus, err := c.reconcileVpcPeerings(vpc)
+	if err != nil {
+		klog.Errorf("failed to reconcile peerings of vpc %s, %v", vpc.Name, err)
+		return err
 	}
 
 	if vpc.Name != util.DefaultVpc {
@@ -318,24 +357,51 @@ func (c *Controller) handleAddOrUpdateVpc(key string) error {
 			return err
 		}
 
-		routeNeedDel, routeNeedAdd, err := diffStaticRoute(existRoute, vpc.Spec.StaticRoutes)
+		// routes to the cidrs of vpcs peered across azs are managed along with the static routes in spec
+		targetRoutes := make([]*kubeovnv1.StaticRoute, 0, len(vpc.Spec.StaticRoutes))
+		targetRoutes = append(targetRoutes, vpc.Spec.StaticRoutes...)
+		targetRoutes = append(targetRoutes, vpcPeeringStaticRoutes(peeringStatus)...)
+		routeNeedDel, err := diffStaticRoute(existRoute, targetRoutes)
 		if err != nil {
 			klog.Errorf("failed to diff vpc %s static route, %v", vpc.Name, err)
 			return err
 		}
 		for _, item := range routeNeedDel {
-			if err = c.ovnLegacyClient.DeleteStaticRoute(item.CIDR, vpc.Name); err != nil {
+			policy := convertPolicy(item.Policy)
+			if err = c.ovnClient.DeleteLogicalRouterStaticRoute(vpc.Name, &item.RouteTable, &policy, item.CIDR, item.NextHopIP); err != nil {
 				klog.Errorf("del vpc %s static route failed, %v", vpc.Name, err)
 				return err
 			}
 		}
 
-		for _, item := range routeNeedAdd {
-			if err = c.ovnClient.AddLogicalRouterStaticRoute(vpc.Name, convertPolicy(item.Policy), item.CIDR, item.NextHopIP); err != nil {
+		// routes with the same prefix are added at once as ecmp routes
+		staticRoutes := targetRoutes
+		if !c.ovnClient.RouteTableSupported() {
+			staticRoutes = make([]*kubeovnv1.StaticRoute, 0, len(targetRoutes))
+			for _, item := range targetRoutes {
+				if item.RouteTable != util.MainRouteTable {
+					klog.Warningf("skip static route %s of vpc %s in route table %s, route tables require OVN 21.12 or later", item.CIDR, vpc.Name, item.RouteTable)
+					c.recorder.Eventf(vpc, v1.EventTypeWarning, "RouteTableNotSupported", "static route %s in route table %s is skipped, route tables require OVN 21.12 or later", item.CIDR, item.RouteTable)
+					continue
+				}
+				staticRoutes = append(staticRoutes, item)
+			}
+		}
+		routes := mergeVpcStaticRoutes(staticRoutes)
+		for _, item := range routes {
+			if err = c.ovnClient.AddLogicalRouterStaticRoute(vpc.Name, item.RouteTable, convertPolicy(item.Policy), item.CIDR, splitNextHops(item.NextHopIP)...); err != nil {
 				klog.Errorf("add static route to vpc %s failed, %v", vpc.Name, err)
 				return err
 			}
 		}
+		if err = c.reconcileVpcStaticRouteBFD(vpc, routes); err != nil {
+			klog.Errorf("failed to reconcile bfd of vpc %s static routes, %v", vpc.Name, err)
+			return err
+		}
+		if vpc.Status.StaticRoutes, err = c.getVpcStaticRouteStatus(vpc); err != nil {
+			klog.Errorf("failed to get static route status of vpc %s, %v", vpc.Name, err)
+			return err
+		}
 		// handle policy route
 		existPolicyRoute, err := c.ovnLegacyClient.GetPolicyRouteList(vpc.Name)
 		if err != nil {
@@ -365,6 +431,11 @@ func (c *Controller) handleAddOrUpdateVpc(key string) error {
 	vpc.Status.Router = key
 	vpc.Status.Standby = true
 	vpc.Status.VpcPeerings = newPeers
+	vpc.Status.PeeringStatus = peeringStatus
+	if vpc.Status.QuotaUsage, err = c.getVpcQuotaUsage(vpc); err != nil {
+		klog.Errorf("failed to get quota usage of vpc %s, %v", vpc.Name, err)
+		return err
+	}
 	if vpc.Annotations[util.VpcEnableOvnLbAnnotation] == "true" && c.config.EnableLb {
 		vpcLb, err := c.addLoadBalancer(key)
 		if err != nil {
@@ -524,7 +595,8 @@ func getPolicyRouteItemKey(item *kubeovnv1.PolicyRoute) (key string) {
 	return fmt.Sprintf("%d:%s:%s:%s", item.Priority, item.Match, item.Action, item.NextHopIP)
 }
 
-func diffStaticRoute(exist []*ovs.StaticRoute, target []*kubeovnv1.StaticRoute) (routeNeedDel []*kubeovnv1.StaticRoute, routeNeedAdd []*kubeovnv1.StaticRoute, err error) {
+// diffStaticRoute get the existing routes whose next hops are not in the target routes
+func diffStaticRoute(exist []*ovs.StaticRoute, target []*kubeovnv1.StaticRoute) (routeNeedDel []*kubeovnv1.StaticRoute, err error) {
 	existV1 := make([]*kubeovnv1.StaticRoute, 0, len(exist))
 	for _, item := range exist {
 		policy := kubeovnv1.PolicyDst
@@ -532,9 +604,10 @@ func diffStaticRoute(exist []*ovs.StaticRoute, target []*kubeovnv1.StaticRoute)
 			policy = kubeovnv1.PolicySrc
 		}
 		existV1 = append(existV1, &kubeovnv1.StaticRoute{
-			Policy:    policy,
-			CIDR:      item.CIDR,
-			NextHopIP: item.NextHop,
+			Policy:     policy,
+			CIDR:       item.CIDR,
+			NextHopIP:  item.NextHop,
+			RouteTable: item.RouteTable,
 		})
 	}
 
@@ -543,12 +616,11 @@ func diffStaticRoute(exist []*ovs.StaticRoute, target []*kubeovnv1.StaticRoute)
 		existRouteMap[getStaticRouteItemKey(item)] = item
 	}
 
-	for _, item := range target {
-		key := getStaticRouteItemKey(item)
-		if _, ok := existRouteMap[key]; ok {
-			delete(existRouteMap, key)
-		} else {
-			routeNeedAdd = append(routeNeedAdd, item)
+	for _, item := range mergeVpcStaticRoutes(target) {
+		for _, nextHop := range splitNextHops(item.NextHopIP) {
+			route := *item
+			route.NextHopIP = nextHop
+			delete(existRouteMap, getStaticRouteItemKey(&route))
 		}
 	}
 	for _, item := range existRouteMap {
@@ -559,10 +631,15 @@ func diffStaticRoute(exist []*ovs.StaticRoute, target []*kubeovnv1.StaticRoute)
 
 func getStaticRouteItemKey(item *kubeovnv1.StaticRoute) (key string) {
 	if item.Policy == kubeovnv1.PolicyDst {
-		return fmt.Sprintf("dst:%s=>%s", item.CIDR, item.NextHopIP)
+		key = fmt.Sprintf("dst:%s=>%s", item.CIDR, item.NextHopIP)
 	} else {
-		return fmt.Sprintf("src:%s=>%s", item.CIDR, item.NextHopIP)
+		key = fmt.Sprintf("src:%s=>%s", item.CIDR, item.NextHopIP)
 	}
+	// routes in the main route table keep the key without route table
+	if item.RouteTable != util.MainRouteTable {
+		key = fmt.Sprintf("%s@%s", key, item.RouteTable)
+	}
+	return key
 }
 
 func formatVpc(vpc *kubeovnv1.Vpc, c *Controller) error {
@@ -592,9 +669,15 @@ func formatVpc(vpc *kubeovnv1.Vpc, c *Controller) error {
 			} else if ip := net.ParseIP(item.CIDR); ip == nil {
 				return fmt.Errorf("invalid IP %s", item.CIDR)
 			}
-			// check next hop ip
-			if ip := net.ParseIP(item.NextHopIP); ip == nil {
-				return fmt.Errorf("invalid next hop IP %s", item.NextHopIP)
+			// check next hop ips
+			nextHops := splitNextHops(item.NextHopIP)
+			if len(nextHops) == 0 {
+				return fmt.Errorf("no next hop IP for static route %s", item.CIDR)
+			}
+			for _, nextHop := range nextHops {
+				if ip := net.ParseIP(nextHop); ip == nil {
+					return fmt.Errorf("invalid next hop IP %s", nextHop)
+				}
 			}
 		}
 
@@ -0,0 +1,370 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+// CheckVpcPeerings resync vpcs whose vpc peerings across azs change, the peerings become active after ovn-ic
+// syncs the transit switches and the remote azs connect their vpcs to them, and the routes of the peerings
+// change with the cidrs of the vpcs on both sides
+func (c *Controller) CheckVpcPeerings() {
+	vpcs, err := c.vpcsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list vpcs, %v", err)
+		return
+	}
+	az, _, err := c.getICAZName()
+	if err != nil {
+		klog.Errorf("failed to get ovn-ic config, %v", err)
+		return
+	}
+
+	for _, vpc := range vpcs {
+		for _, status := range vpc.Status.PeeringStatus {
+			if status.RemoteAZ == "" {
+				continue
+			}
+			changed, err := c.icVpcPeeringChanged(vpc.Name, az, status)
+			if err != nil {
+				klog.Errorf("failed to check vpc peering of vpc %s to vpc %s in az %s, %v", vpc.Name, status.RemoteVpc, status.RemoteAZ, err)
+				continue
+			}
+			if changed {
+				klog.V(3).Infof("enqueue update vpc %s for change of vpc peering to %s", vpc.Name, status.RemoteVpc)
+				c.addOrUpdateVpcQueue.Add(vpc.Name)
+				break
+			}
+		}
+	}
+}
+
+// icVpcPeeringChanged check whether the state of the vpc peering across azs or the cidrs of the vpcs change
+func (c *Controller) icVpcPeeringChanged(vpc, az string, status kubeovnv1.VpcPeeringStatus) (bool, error) {
+	if status.TransitSwitch == "" || az == "" {
+		return status.TransitSwitch == "" && az != "", nil
+	}
+
+	remotePort := fmt.Sprintf("%s-%s", status.TransitSwitch, status.RemoteAZ)
+	exist, err := c.ovnLegacyClient.LogicalSwitchPortExists(remotePort)
+	if err != nil {
+		klog.Errorf("failed to check logical switch port %s exist, %v", remotePort, err)
+		return false, err
+	}
+	if exist != (status.State == kubeovnv1.VpcPeeringStateActive) {
+		return true, nil
+	}
+	if !exist {
+		return false, nil
+	}
+
+	remoteCIDRs, err := c.ovnLegacyClient.GetTransitSwitchExternalID(status.TransitSwitch, vpcPeeringCIDRsKey(status.RemoteAZ))
+	if err != nil {
+		return false, err
+	}
+	if remoteCIDRs != strings.Join(status.RemoteCIDRs, ",") {
+		return true, nil
+	}
+	localCIDRs, err := c.ovnLegacyClient.GetTransitSwitchExternalID(status.TransitSwitch, vpcPeeringCIDRsKey(az))
+	if err != nil {
+		return false, err
+	}
+	cidrs, err := c.getVpcCIDRs(vpc)
+	if err != nil {
+		return false, err
+	}
+	return localCIDRs != strings.Join(cidrs, ","), nil
+}
+
+// vpcPeeringCIDRsKey return the key in external_ids of the transit switch of a vpc peering across azs, whose value
+// is the cidrs of the vpc in the az, the vpc in the other az routes the cidrs through the transit switch
+func vpcPeeringCIDRsKey(az string) string {
+	return fmt.Sprintf("%s-cidrs", az)
+}
+
+// getVpcCIDRs get the sorted cidrs of all subnets in the vpc
+func (c *Controller) getVpcCIDRs(vpc string) ([]string, error) {
+	subnets, err := c.subnetsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list subnets, %v", err)
+		return nil, err
+	}
+	cidrs := []string{}
+	for _, subnet := range subnets {
+		if subnet.Spec.Vpc != vpc {
+			continue
+		}
+		for _, cidr := range strings.Split(subnetCIDRBlocks(subnet), ",") {
+			if cidr != "" && !util.ContainsString(cidrs, cidr) {
+				cidrs = append(cidrs, cidr)
+			}
+		}
+	}
+	sort.Strings(cidrs)
+	return cidrs, nil
+}
+
+// vpcPeeringStaticRoutes return the routes to the cidrs of the vpcs peered across azs through the transit switches
+func vpcPeeringStaticRoutes(peeringStatus []kubeovnv1.VpcPeeringStatus) []*kubeovnv1.StaticRoute {
+	var routes []*kubeovnv1.StaticRoute
+	for _, status := range peeringStatus {
+		if status.State != kubeovnv1.VpcPeeringStateActive || status.RemoteConnectIP == "" {
+			continue
+		}
+		for _, cidr := range status.RemoteCIDRs {
+			for _, ip := range strings.Split(status.RemoteConnectIP, ",") {
+				if util.CheckProtocol(ip) == util.CheckProtocol(cidr) {
+					routes = append(routes, &kubeovnv1.StaticRoute{Policy: kubeovnv1.PolicyDst, CIDR: cidr, NextHopIP: ip})
+					break
+				}
+			}
+		}
+	}
+	return routes
+}
+
+// getICAZName get the az name of the cluster, an empty name is returned if ovn-ic is not established
+func (c *Controller) getICAZName() (string, map[string]string, error) {
+	cm, err := c.configMapsLister.ConfigMaps(c.config.PodNamespace).Get(util.InterconnectionConfig)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return "", nil, nil
+		}
+		return "", nil, err
+	}
+	if icEnabled != "true" || cm.Data["enable-ic"] != "true" {
+		return "", cm.Data, nil
+	}
+	return cm.Data["az-name"], cm.Data, nil
+}
+
+func (c *Controller) getICGatewayChassises(gwNodes string) ([]string, error) {
+	var chassises []string
+	for _, gw := range strings.Split(gwNodes, ",") {
+		if gw = strings.TrimSpace(gw); gw == "" {
+			continue
+		}
+		chassisID, err := c.ovnLegacyClient.GetChassis(gw)
+		if err != nil {
+			klog.Errorf("failed to get gw %s chassisID, %v", gw, err)
+			return nil, err
+		}
+		if chassisID != "" {
+			chassises = append(chassises, chassisID)
+		}
+	}
+	if len(chassises) == 0 {
+		return nil, fmt.Errorf("no available ic gw")
+	}
+	return chassises, nil
+}
+
+// reconcileVpcPeerings connect the vpc to the peered vpcs and disconnect it from vpcs no longer peered,
+// vpcs in the same az are connected directly and vpcs in different azs are connected by transit switches
+func (c *Controller) reconcileVpcPeerings(vpc *kubeovnv1.Vpc) ([]string, []kubeovnv1.VpcPeeringStatus, error) {
+	az, icConfig, err := c.getICAZName()
+	if err != nil {
+		klog.Errorf("failed to get ovn-ic config, %v", err)
+		return nil, nil, err
+	}
+
+	var newPeers, newTransitSwitches, localCIDRs []string
+	peeringStatus := make([]kubeovnv1.VpcPeeringStatus, 0, len(vpc.Spec.VpcPeerings))
+	for _, peering := range vpc.Spec.VpcPeerings {
+		if err = util.CheckCidrs(peering.LocalConnectIP); err != nil {
+			klog.Errorf("invalid cidr %s", peering.LocalConnectIP)
+			return nil, nil, err
+		}
+		status := kubeovnv1.VpcPeeringStatus{
+			RemoteVpc:      peering.RemoteVpc,
+			RemoteAZ:       peering.RemoteAZ,
+			LocalConnectIP: peering.LocalConnectIP,
+			State:          kubeovnv1.VpcPeeringStateActive,
+		}
+
+		if peering.RemoteAZ == "" {
+			newPeers = append(newPeers, peering.RemoteVpc)
+			if err = c.ovnClient.CreatePeerRouterPort(vpc.Name, peering.RemoteVpc, peering.LocalConnectIP); err != nil {
+				klog.Errorf("failed to create peer router port for vpc %s, %v", vpc.Name, err)
+				return nil, nil, err
+			}
+			peeringStatus = append(peeringStatus, status)
+			continue
+		}
+
+		if az == "" {
+			status.State, status.Reason = kubeovnv1.VpcPeeringStatePending, "ovn-ic is not established"
+			peeringStatus = append(peeringStatus, status)
+			continue
+		}
+		if az == peering.RemoteAZ {
+			status.State, status.Reason = kubeovnv1.VpcPeeringStatePending, fmt.Sprintf("remote az %s is the local az", az)
+			peeringStatus = append(peeringStatus, status)
+			continue
+		}
+
+		if localCIDRs == nil {
+			if localCIDRs, err = c.getVpcCIDRs(vpc.Name); err != nil {
+				return nil, nil, err
+			}
+		}
+		status.TransitSwitch = util.VpcPeeringTransitSwitch(az, vpc.Name, peering.RemoteAZ, peering.RemoteVpc)
+		newTransitSwitches = append(newTransitSwitches, status.TransitSwitch)
+		if err = c.connectICVpcPeering(vpc.Name, az, peering, icConfig["gw-nodes"], strings.Join(localCIDRs, ","), &status); err != nil {
+			klog.Errorf("failed to connect vpc %s to vpc %s in az %s, %v", vpc.Name, peering.RemoteVpc, peering.RemoteAZ, err)
+			return nil, nil, err
+		}
+		peeringStatus = append(peeringStatus, status)
+	}
+
+	for _, oldPeer := range vpc.Status.VpcPeerings {
+		if !util.ContainsString(newPeers, oldPeer) {
+			if err = c.ovnLegacyClient.DeleteLogicalRouterPort(fmt.Sprintf("%s-%s", vpc.Name, oldPeer)); err != nil {
+				klog.Errorf("failed to delete peer router port for vpc %s, %v", vpc.Name, err)
+				return nil, nil, err
+			}
+		}
+	}
+	for _, oldStatus := range vpc.Status.PeeringStatus {
+		if oldStatus.TransitSwitch == "" || util.ContainsString(newTransitSwitches, oldStatus.TransitSwitch) {
+			continue
+		}
+		if err = c.disconnectICVpcPeering(icConfig["az-name"], oldStatus); err != nil {
+			klog.Errorf("failed to disconnect vpc %s from vpc %s in az %s, %v", vpc.Name, oldStatus.RemoteVpc, oldStatus.RemoteAZ, err)
+			return nil, nil, err
+		}
+	}
+
+	return newPeers, peeringStatus, nil
+}
+
+// connectICVpcPeering connect the vpc to the transit switch of the vpc peering across azs, the cidrs of the vpc
+// are published in external_ids of the transit switch for the remote az, and the address and cidrs of the remote
+// vpc are filled in the status to route the remote cidrs through the transit switch by static routes
+func (c *Controller) connectICVpcPeering(vpc, az string, peering *kubeovnv1.VpcPeering, gwNodes, localCIDRs string, status *kubeovnv1.VpcPeeringStatus) error {
+	ts := status.TransitSwitch
+	if err := c.ovnLegacyClient.CreateTransitSwitch(ts); err != nil {
+		klog.Errorf("failed to create transit switch %s, %v", ts, err)
+		return err
+	}
+	exist, err := c.ovnLegacyClient.LogicalSwitchExists(ts, false)
+	if err != nil {
+		klog.Errorf("failed to list logical switch, %v", err)
+		return err
+	}
+	if !exist {
+		// retry later since ovn-ic creates the logical switch of the transit switch asynchronously
+		return fmt.Errorf("transit switch %s is not synced by ovn-ic yet", ts)
+	}
+
+	chassises, err := c.getICGatewayChassises(gwNodes)
+	if err != nil {
+		klog.Errorf("failed to get ic gw chassises, %v", err)
+		return err
+	}
+
+	// keep the mac of the existing port since lrp-add fails if it is different
+	mac := util.GenerateMac()
+	lrpName := fmt.Sprintf("%s-%s", az, ts)
+	lrp, err := c.ovnClient.GetLogicalRouterPort(lrpName, true)
+	if err != nil {
+		klog.Errorf("failed to get logical router port %s, %v", lrpName, err)
+		return err
+	}
+	if lrp != nil {
+		mac = lrp.MAC
+		if networks := strings.Split(peering.LocalConnectIP, ","); !reflect.DeepEqual(lrp.Networks, networks) {
+			lrp.Networks = networks
+			if err = c.ovnClient.UpdateLogicalRouterPort(lrp, &lrp.Networks); err != nil {
+				klog.Errorf("failed to update networks of logical router port %s, %v", lrpName, err)
+				return err
+			}
+		}
+	}
+	if err = c.ovnLegacyClient.CreateICPeerRouterPort(vpc, ts, az, mac, peering.LocalConnectIP, chassises); err != nil {
+		klog.Errorf("failed to create vpc peering port of vpc %s, %v", vpc, err)
+		return err
+	}
+	if err = c.ovnLegacyClient.SetTransitSwitchExternalID(ts, vpcPeeringCIDRsKey(az), localCIDRs); err != nil {
+		klog.Errorf("failed to publish cidrs of vpc %s on transit switch %s, %v", vpc, ts, err)
+		return err
+	}
+
+	// the port of the remote az is synced to the local az by ovn-ic once the remote vpc is connected
+	remotePort := fmt.Sprintf("%s-%s", ts, peering.RemoteAZ)
+	lsp, err := c.ovnClient.GetLogicalSwitchPort(remotePort, true)
+	if err != nil {
+		klog.Errorf("failed to get logical switch port %s, %v", remotePort, err)
+		return err
+	}
+	if lsp == nil {
+		status.State, status.Reason = kubeovnv1.VpcPeeringStatePending, fmt.Sprintf("waiting for vpc %s in az %s to connect", peering.RemoteVpc, peering.RemoteAZ)
+		return nil
+	}
+	// addresses of the remote port are in the form of "mac ip/len ..."
+	var remoteIPs []string
+	for _, address := range lsp.Addresses {
+		fields := strings.Fields(address)
+		for i := 1; i < len(fields); i++ {
+			remoteIPs = append(remoteIPs, strings.Split(fields[i], "/")[0])
+		}
+	}
+	if len(remoteIPs) == 0 {
+		status.State, status.Reason = kubeovnv1.VpcPeeringStatePending, fmt.Sprintf("address of vpc %s in az %s is unknown", peering.RemoteVpc, peering.RemoteAZ)
+		return nil
+	}
+	remoteCIDRs, err := c.ovnLegacyClient.GetTransitSwitchExternalID(ts, vpcPeeringCIDRsKey(peering.RemoteAZ))
+	if err != nil {
+		klog.Errorf("failed to get cidrs of vpc %s in az %s, %v", peering.RemoteVpc, peering.RemoteAZ, err)
+		return err
+	}
+
+	status.State, status.Reason = kubeovnv1.VpcPeeringStateActive, ""
+	status.RemoteConnectIP = strings.Join(remoteIPs, ",")
+	if remoteCIDRs != "" {
+		status.RemoteCIDRs = strings.Split(remoteCIDRs, ",")
+	}
+	return nil
+}
+
+// disconnectICVpcPeering disconnect the vpc from the transit switch of the vpc peering across azs,
+// the transit switch is deleted after the remote vpc is disconnected too
+func (c *Controller) disconnectICVpcPeering(az string, status kubeovnv1.VpcPeeringStatus) error {
+	if az == "" {
+		return nil
+	}
+	klog.Infof("delete vpc peering port on transit switch %s", status.TransitSwitch)
+	if err := c.ovnLegacyClient.DeleteICPeerRouterPort(status.TransitSwitch, az); err != nil {
+		return err
+	}
+	if icEnabled != "true" {
+		return nil
+	}
+	if err := c.ovnLegacyClient.SetTransitSwitchExternalID(status.TransitSwitch, vpcPeeringCIDRsKey(az), ""); err != nil {
+		klog.Errorf("failed to remove cidrs of the vpc from transit switch %s, %v", status.TransitSwitch, err)
+		return err
+	}
+
+	remotePort := fmt.Sprintf("%s-%s", status.TransitSwitch, status.RemoteAZ)
+	exist, err := c.ovnLegacyClient.LogicalSwitchPortExists(remotePort)
+	if err != nil {
+		klog.Errorf("failed to check logical switch port %s exist, %v", remotePort, err)
+		return err
+	}
+	if !exist {
+		if err = c.ovnLegacyClient.DeleteTransitSwitch(status.TransitSwitch); err != nil {
+			klog.Errorf("failed to delete transit switch %s, %v", status.TransitSwitch, err)
+			return err
+		}
+	}
+	return nil
+}
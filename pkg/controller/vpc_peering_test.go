@@ -0,0 +1,224 @@
+package controller
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ovs"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+// fakeOvnCommandScript logs the command to $FAKE_OVN_LOG and prints the output of the first
+// pattern in $FAKE_OVN_RESPONSES matching the command, patterns and outputs are separated by tabs
+const fakeOvnCommandScript = `#!/bin/sh
+cmd="$(basename "$0") $*"
+echo "$cmd" >> "$FAKE_OVN_LOG"
+tab="$(printf '\t')"
+while IFS="$tab" read -r pattern output; do
+	case "$cmd" in
+	$pattern)
+		printf '%s\n' "$output"
+		exit 0
+		;;
+	esac
+done < "$FAKE_OVN_RESPONSES"
+`
+
+type fakeOvnCommands struct {
+	log, responses string
+}
+
+// newFakeOvnCommands put fake ovn-nbctl, ovn-sbctl and ovn-ic-nbctl in PATH
+func newFakeOvnCommands(t *testing.T) *fakeOvnCommands {
+	dir := t.TempDir()
+	for _, name := range []string{ovs.OvnNbCtl, ovs.OvnSbCtl, ovs.OVNIcNbCtl} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(fakeOvnCommandScript), 0o755))
+	}
+	f := &fakeOvnCommands{log: filepath.Join(dir, "log"), responses: filepath.Join(dir, "responses")}
+	require.NoError(t, os.WriteFile(f.responses, nil, 0o644))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("FAKE_OVN_LOG", f.log)
+	t.Setenv("FAKE_OVN_RESPONSES", f.responses)
+	return f
+}
+
+func (f *fakeOvnCommands) respond(t *testing.T, pattern, output string) {
+	file, err := os.OpenFile(f.responses, os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	defer file.Close()
+	_, err = fmt.Fprintf(file, "%s\t%s\n", pattern, output)
+	require.NoError(t, err)
+}
+
+func (f *fakeOvnCommands) commands(t *testing.T) string {
+	data, err := os.ReadFile(f.log)
+	if os.IsNotExist(err) {
+		return ""
+	}
+	require.NoError(t, err)
+	return string(data)
+}
+
+func newVpcPeeringTestController(t *testing.T, icConfig map[string]string, subnets ...*kubeovnv1.Subnet) *testController {
+	enabled := icEnabled
+	t.Cleanup(func() { icEnabled = enabled })
+	icEnabled = "false"
+
+	var objects []runtime.Object
+	if icConfig != nil {
+		icEnabled = "true"
+		objects = append(objects, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: util.InterconnectionConfig, Namespace: "kube-system"},
+			Data:       icConfig,
+		})
+	}
+	for _, subnet := range subnets {
+		objects = append(objects, subnet)
+	}
+	return newTestController(t, withObjects(objects...), withConfig(func(config *Configuration) {
+		config.PodNamespace = "kube-system"
+	}))
+}
+
+func TestReconcileVpcPeerings(t *testing.T) {
+	icConfig := map[string]string{"enable-ic": "true", "az-name": "az1", "gw-nodes": "gw1"}
+	subnets := []*kubeovnv1.Subnet{
+		{ObjectMeta: metav1.ObjectMeta{Name: "net1"}, Spec: kubeovnv1.SubnetSpec{Vpc: "vpc-1", CIDRBlock: "10.0.1.0/24,fd00:1::/64"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "net2"}, Spec: kubeovnv1.SubnetSpec{Vpc: "vpc-1", CIDRBlock: "10.0.0.0/24"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "other"}, Spec: kubeovnv1.SubnetSpec{Vpc: "vpc-3", CIDRBlock: "10.0.3.0/24"}},
+	}
+	ts := util.VpcPeeringTransitSwitch("az1", "vpc-1", "az2", "vpc-2")
+	icVpc := func() *kubeovnv1.Vpc {
+		return &kubeovnv1.Vpc{
+			ObjectMeta: metav1.ObjectMeta{Name: "vpc-1"},
+			Spec: kubeovnv1.VpcSpec{VpcPeerings: []*kubeovnv1.VpcPeering{
+				{RemoteVpc: "vpc-2", RemoteAZ: "az2", LocalConnectIP: "169.254.1.1/24,fd00:169::1/64"},
+			}},
+		}
+	}
+	connected := func(t *testing.T, f *fakeOvnCommands) {
+		f.respond(t, "ovn-nbctl * find logical_switch", ts)
+		f.respond(t, "ovn-sbctl * find chassis hostname=gw1", "chassis-1")
+	}
+
+	t.Run("same az", func(t *testing.T) {
+		newFakeOvnCommands(t)
+		c := newVpcPeeringTestController(t, nil, subnets...)
+		vpc := &kubeovnv1.Vpc{
+			ObjectMeta: metav1.ObjectMeta{Name: "vpc-1"},
+			Spec:       kubeovnv1.VpcSpec{VpcPeerings: []*kubeovnv1.VpcPeering{{RemoteVpc: "vpc-2", LocalConnectIP: "169.254.0.1/30"}}},
+		}
+		peers, status, err := c.reconcileVpcPeerings(vpc)
+		require.NoError(t, err)
+		require.Equal(t, []string{"vpc-2"}, peers)
+		require.Equal(t, []string{"vpc-1-vpc-2"}, c.ovnClient.peerPorts)
+		require.Len(t, status, 1)
+		require.Equal(t, kubeovnv1.VpcPeeringStateActive, status[0].State)
+		require.Empty(t, status[0].TransitSwitch)
+	})
+
+	t.Run("ovn-ic not established", func(t *testing.T) {
+		f := newFakeOvnCommands(t)
+		c := newVpcPeeringTestController(t, nil, subnets...)
+		_, status, err := c.reconcileVpcPeerings(icVpc())
+		require.NoError(t, err)
+		require.Len(t, status, 1)
+		require.Equal(t, kubeovnv1.VpcPeeringStatePending, status[0].State)
+		require.Equal(t, "ovn-ic is not established", status[0].Reason)
+		require.Empty(t, f.commands(t))
+	})
+
+	t.Run("remote az is the local az", func(t *testing.T) {
+		newFakeOvnCommands(t)
+		c := newVpcPeeringTestController(t, map[string]string{"enable-ic": "true", "az-name": "az2"}, subnets...)
+		_, status, err := c.reconcileVpcPeerings(icVpc())
+		require.NoError(t, err)
+		require.Equal(t, kubeovnv1.VpcPeeringStatePending, status[0].State)
+		require.Empty(t, status[0].TransitSwitch)
+	})
+
+	t.Run("transit switch not synced", func(t *testing.T) {
+		newFakeOvnCommands(t)
+		c := newVpcPeeringTestController(t, icConfig, subnets...)
+		_, _, err := c.reconcileVpcPeerings(icVpc())
+		require.Error(t, err)
+	})
+
+	t.Run("remote vpc not connected", func(t *testing.T) {
+		f := newFakeOvnCommands(t)
+		connected(t, f)
+		c := newVpcPeeringTestController(t, icConfig, subnets...)
+		_, status, err := c.reconcileVpcPeerings(icVpc())
+		require.NoError(t, err)
+		require.Len(t, status, 1)
+		require.Equal(t, ts, status[0].TransitSwitch)
+		require.Equal(t, kubeovnv1.VpcPeeringStatePending, status[0].State)
+		require.Empty(t, status[0].RemoteConnectIP)
+		require.Empty(t, vpcPeeringStaticRoutes(status))
+
+		commands := f.commands(t)
+		require.Contains(t, commands, fmt.Sprintf("ts-add %s", ts))
+		require.Contains(t, commands, fmt.Sprintf("lrp-add vpc-1 az1-%s", ts))
+		require.Contains(t, commands, fmt.Sprintf("lrp-set-gateway-chassis az1-%s chassis-1 100", ts))
+		// cidrs of subnets in the vpc are published sorted and deduplicated
+		require.Contains(t, commands, fmt.Sprintf(`set Transit_Switch %s external_ids:"az1-cidrs"="10.0.0.0/24,10.0.1.0/24,fd00:1::/64"`, ts))
+		// route advertisement of ovn-ic is never touched
+		require.NotContains(t, commands, "ic-route")
+	})
+
+	t.Run("active", func(t *testing.T) {
+		f := newFakeOvnCommands(t)
+		connected(t, f)
+		f.respond(t, fmt.Sprintf(`ovn-ic-nbctl * get Transit_Switch %s external_ids:"az2-cidrs"`, ts), `"172.31.0.0/16,fd00:31::/64"`)
+		c := newVpcPeeringTestController(t, icConfig, subnets...)
+		c.ovnClient.lsps[fmt.Sprintf("%s-az2", ts)] = ovnnb.LogicalSwitchPort{
+			Addresses: []string{"00:00:00:a1:b2:c3 169.254.1.2/24 fd00:169::2/64"},
+		}
+
+		_, status, err := c.reconcileVpcPeerings(icVpc())
+		require.NoError(t, err)
+		require.Len(t, status, 1)
+		require.Equal(t, kubeovnv1.VpcPeeringStateActive, status[0].State)
+		require.Equal(t, "169.254.1.2,fd00:169::2", status[0].RemoteConnectIP)
+		require.Equal(t, []string{"172.31.0.0/16", "fd00:31::/64"}, status[0].RemoteCIDRs)
+		require.Equal(t, []*kubeovnv1.StaticRoute{
+			{Policy: kubeovnv1.PolicyDst, CIDR: "172.31.0.0/16", NextHopIP: "169.254.1.2"},
+			{Policy: kubeovnv1.PolicyDst, CIDR: "fd00:31::/64", NextHopIP: "fd00:169::2"},
+		}, vpcPeeringStaticRoutes(status))
+	})
+
+	t.Run("peering removed", func(t *testing.T) {
+		f := newFakeOvnCommands(t)
+		c := newVpcPeeringTestController(t, icConfig, subnets...)
+		vpc := &kubeovnv1.Vpc{
+			ObjectMeta: metav1.ObjectMeta{Name: "vpc-1"},
+			Status: kubeovnv1.VpcStatus{PeeringStatus: []kubeovnv1.VpcPeeringStatus{{
+				RemoteVpc:     "vpc-2",
+				RemoteAZ:      "az2",
+				TransitSwitch: ts,
+				State:         kubeovnv1.VpcPeeringStateActive,
+			}}},
+		}
+		_, status, err := c.reconcileVpcPeerings(vpc)
+		require.NoError(t, err)
+		require.Empty(t, status)
+
+		commands := f.commands(t)
+		require.Contains(t, commands, fmt.Sprintf("lrp-del az1-%s", ts))
+		require.Contains(t, commands, fmt.Sprintf("lsp-del %s-az1", ts))
+		require.Contains(t, commands, fmt.Sprintf(`remove Transit_Switch %s external_ids "az1-cidrs"`, ts))
+		// the transit switch is deleted since the remote vpc is disconnected too
+		require.Contains(t, commands, fmt.Sprintf("ts-del %s", ts))
+		require.Less(t, strings.Index(commands, "remove Transit_Switch"), strings.Index(commands, "ts-del"))
+	})
+}
@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+// CheckVpcQuotaUsage update status of vpcs with quotas whose quota usage changes, addresses allocated
+// in the subnets of the vpcs change without any event of the vpcs
+func (c *Controller) CheckVpcQuotaUsage() {
+	vpcs, err := c.vpcsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list vpcs, %v", err)
+		return
+	}
+
+	for _, vpc := range vpcs {
+		if vpc.Spec.Quota == nil && vpc.Status.QuotaUsage == nil {
+			continue
+		}
+		usage, err := c.getVpcQuotaUsage(vpc)
+		if err != nil {
+			klog.Errorf("failed to get quota usage of vpc %s, %v", vpc.Name, err)
+			continue
+		}
+		if !reflect.DeepEqual(usage, vpc.Status.QuotaUsage) {
+			klog.V(3).Infof("enqueue update status of vpc %s for quota usage changes", vpc.Name)
+			c.updateVpcStatusQueue.Add(vpc.Name)
+		}
+	}
+}
+
+// getVpcQuotaUsage count the resources in the vpc, nil is returned if the vpc has no quota
+func (c *Controller) getVpcQuotaUsage(vpc *kubeovnv1.Vpc) (*kubeovnv1.VpcQuotaUsage, error) {
+	if vpc.Spec.Quota == nil {
+		return nil, nil
+	}
+	usage := &kubeovnv1.VpcQuotaUsage{StaticRoutes: len(vpc.Spec.StaticRoutes)}
+
+	subnets, err := c.subnetsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list subnets, %v", err)
+		return nil, err
+	}
+	vpcSubnets := make(map[string]bool)
+	for _, subnet := range subnets {
+		if subnet.Spec.Vpc == vpc.Name || (subnet.Spec.Vpc == "" && vpc.Name == util.DefaultVpc) {
+			vpcSubnets[subnet.Name] = true
+		}
+	}
+	usage.Subnets = len(vpcSubnets)
+
+	ips, err := c.ipsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list ips, %v", err)
+		return nil, err
+	}
+	for _, ip := range ips {
+		if vpcSubnets[ip.Spec.Subnet] {
+			usage.IPs++
+		}
+	}
+
+	gws, err := c.vpcNatGatewayLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list vpc nat gateways, %v", err)
+		return nil, err
+	}
+	for _, gw := range gws {
+		if gw.Spec.Vpc == vpc.Name {
+			util.AddVpcNatGatewayQuotaUsage(usage, gw)
+		}
+	}
+	return usage, nil
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func TestAcquireAddressVpcIPQuota(t *testing.T) {
+	subnets := []*kubeovnv1.Subnet{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "net1"},
+			Spec:       kubeovnv1.SubnetSpec{CIDRBlock: "10.16.0.0/24", Gateway: "10.16.0.1", Protocol: kubeovnv1.ProtocolIPv4, Vpc: "vpc1"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "net2"},
+			Spec:       kubeovnv1.SubnetSpec{CIDRBlock: "10.17.0.0/24", Gateway: "10.17.0.1", Protocol: kubeovnv1.ProtocolIPv4, Vpc: "vpc1"},
+		},
+	}
+	c := newTestController(t, withObjects(subnets[0], subnets[1], &kubeovnv1.Vpc{
+		ObjectMeta: metav1.ObjectMeta{Name: "vpc1"},
+		Spec:       kubeovnv1.VpcSpec{Quota: &kubeovnv1.VpcQuota{MaxIPs: 2}},
+	}))
+	for _, subnet := range subnets {
+		require.NoError(t, c.ipam.AddOrUpdateSubnet(subnet.Name, subnet.Spec.CIDRBlock, []string{subnet.Spec.Gateway}))
+	}
+
+	acquire := func(name string, subnet *kubeovnv1.Subnet) error {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+		_, _, _, err := c.acquireAddress(pod, &kubeovnNet{ProviderName: util.OvnProvider, Subnet: subnet})
+		return err
+	}
+	require.NoError(t, acquire("p1", subnets[0]))
+	require.NoError(t, acquire("p2", subnets[1]))
+	// addresses in all subnets of the vpc are counted
+	require.Error(t, acquire("p3", subnets[0]))
+	require.Error(t, acquire("p3", subnets[1]))
+	// the nic which already has an address is not counted again
+	require.NoError(t, acquire("p1", subnets[0]))
+
+	c.ipam.ReleaseAddressByPod("default/p2")
+	require.NoError(t, acquire("p3", subnets[0]))
+}
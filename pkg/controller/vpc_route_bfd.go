@@ -0,0 +1,191 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+// CheckVpcStaticRouteBFD update status of custom vpcs whose static routes have next hops become active or inactive
+func (c *Controller) CheckVpcStaticRouteBFD() {
+	vpcs, err := c.vpcsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list vpcs, %v", err)
+		return
+	}
+
+	for _, vpc := range vpcs {
+		if vpc.Status.Default || !vpc.Status.Standby {
+			continue
+		}
+		routes, err := c.getVpcStaticRouteStatus(vpc)
+		if err != nil {
+			klog.Errorf("failed to get static route status of vpc %s, %v", vpc.Name, err)
+			continue
+		}
+		if !reflect.DeepEqual(routes, vpc.Status.StaticRoutes) {
+			klog.V(3).Infof("enqueue update status of vpc %s for static route status changes", vpc.Name)
+			c.updateVpcStatusQueue.Add(vpc.Name)
+		}
+	}
+}
+
+// mergeVpcStaticRoutes merge static routes with the same route table, policy and prefix into one ecmp route,
+// bfd is enabled for the merged route if any of the routes enables it
+func mergeVpcStaticRoutes(routes []*kubeovnv1.StaticRoute) []*kubeovnv1.StaticRoute {
+	var result []*kubeovnv1.StaticRoute
+	nextHops := make(map[*kubeovnv1.StaticRoute][]string, len(routes))
+	index := make(map[string]*kubeovnv1.StaticRoute, len(routes))
+	for _, item := range routes {
+		policy := item.Policy
+		if policy == "" {
+			policy = kubeovnv1.PolicyDst
+		}
+		key := fmt.Sprintf("%s:%s@%s", policy, item.CIDR, item.RouteTable)
+		route := index[key]
+		if route == nil {
+			route = &kubeovnv1.StaticRoute{Policy: policy, CIDR: item.CIDR, RouteTable: item.RouteTable}
+			index[key] = route
+			result = append(result, route)
+		}
+		route.EnableBFD = route.EnableBFD || item.EnableBFD
+		for _, nextHop := range splitNextHops(item.NextHopIP) {
+			if !util.ContainsString(nextHops[route], nextHop) {
+				nextHops[route] = append(nextHops[route], nextHop)
+			}
+		}
+	}
+
+	for _, route := range result {
+		route.NextHopIP = strings.Join(nextHops[route], ",")
+	}
+	return result
+}
+
+func splitNextHops(nextHopIP string) []string {
+	var nextHops []string
+	for _, nextHop := range strings.Split(nextHopIP, ",") {
+		if nextHop = strings.TrimSpace(nextHop); nextHop != "" {
+			nextHops = append(nextHops, nextHop)
+		}
+	}
+	return nextHops
+}
+
+// vpcRouterPortByIP get the logical router port of the vpc connected to the subnet which the ip belongs to
+func vpcRouterPortByIP(vpc *kubeovnv1.Vpc, subnets []*kubeovnv1.Subnet, ip string) string {
+	for _, subnet := range subnets {
+		if subnet.Spec.Vpc == vpc.Name && subnetContainIP(subnet, ip) {
+			return fmt.Sprintf("%s-%s", vpc.Name, subnet.Name)
+		}
+	}
+	return ""
+}
+
+// reconcileVpcStaticRouteBFD create bfd sessions to the next hops of the static routes with bfd enabled and
+// set them to the routes, bfd sessions of the vpc which are not used by any route are deleted
+func (c *Controller) reconcileVpcStaticRouteBFD(vpc *kubeovnv1.Vpc, routes []*kubeovnv1.StaticRoute) error {
+	subnets, err := c.subnetsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list subnets, %v", err)
+		return err
+	}
+
+	inUse := make(map[string]bool)
+	for _, route := range routes {
+		policy := convertPolicy(route.Policy)
+		for _, nextHop := range splitNextHops(route.NextHopIP) {
+			var bfdUUID *string
+			if route.EnableBFD {
+				lrpName := vpcRouterPortByIP(vpc, subnets, nextHop)
+				if lrpName == "" {
+					klog.Warningf("next hop %s of static route %s in vpc %s is not in any subnet of the vpc, skip bfd", nextHop, route.CIDR, vpc.Name)
+				} else {
+					bfd, err := c.ovnClient.CreateBFD(lrpName, nextHop, c.config.GatewayBFDInterval, c.config.GatewayBFDInterval, c.config.GatewayBFDDetectMult, map[string]string{logicalRouterKey: vpc.Name})
+					if err != nil {
+						klog.Errorf("failed to create bfd session to %s: %v", nextHop, err)
+						return err
+					}
+					bfdUUID = &bfd.UUID
+					inUse[fmt.Sprintf("%s/%s", lrpName, nextHop)] = true
+				}
+			}
+			if err = c.ovnClient.SetLogicalRouterStaticRouteBFD(vpc.Name, route.RouteTable, policy, route.CIDR, nextHop, bfdUUID); err != nil {
+				klog.Errorf("failed to set bfd session of static route %s via %s in vpc %s: %v", route.CIDR, nextHop, vpc.Name, err)
+				return err
+			}
+		}
+	}
+
+	// bfd sessions are listed by the vpc rather than by the subnets currently in the vpc,
+	// so that sessions on router ports of subnets removed from the vpc are deleted as well
+	bfdList, err := c.ovnClient.ListBFDByExternalIDs(map[string]string{logicalRouterKey: vpc.Name})
+	if err != nil {
+		klog.Errorf("failed to list bfd of vpc %s, %v", vpc.Name, err)
+		return err
+	}
+	for _, bfd := range bfdList {
+		if inUse[fmt.Sprintf("%s/%s", bfd.LogicalPort, bfd.DstIP)] {
+			continue
+		}
+		klog.Infof("delete bfd session from %s to %s which is not a next hop of static routes any more", bfd.LogicalPort, bfd.DstIP)
+		if err = c.ovnClient.DeleteBFD(bfd.LogicalPort, bfd.DstIP); err != nil {
+			klog.Errorf("failed to delete bfd session to %s, %v", bfd.DstIP, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// getVpcStaticRouteStatus get status of the static routes of the custom vpc, next hops of the routes with bfd enabled
+// are active only when their bfd sessions are up
+func (c *Controller) getVpcStaticRouteStatus(vpc *kubeovnv1.Vpc) ([]kubeovnv1.StaticRouteStatus, error) {
+	if vpc.Status.Default {
+		return nil, nil
+	}
+	routes := mergeVpcStaticRoutes(vpc.Spec.StaticRoutes)
+	if len(routes) == 0 {
+		return nil, nil
+	}
+
+	subnets, err := c.subnetsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list subnets, %v", err)
+		return nil, err
+	}
+
+	result := make([]kubeovnv1.StaticRouteStatus, 0, len(routes))
+	for _, route := range routes {
+		var active []string
+		for _, nextHop := range splitNextHops(route.NextHopIP) {
+			if route.EnableBFD {
+				if lrpName := vpcRouterPortByIP(vpc, subnets, nextHop); lrpName != "" {
+					bfdList, err := c.ovnClient.ListBFD(lrpName, nextHop)
+					if err != nil {
+						klog.Errorf("failed to list bfd of %s, %v", lrpName, err)
+						return nil, err
+					}
+					if len(bfdList) == 0 || bfdList[0].Status == nil || *bfdList[0].Status != ovnnb.BFDStatusUp {
+						continue
+					}
+				}
+			}
+			active = append(active, nextHop)
+		}
+		result = append(result, kubeovnv1.StaticRouteStatus{
+			Policy:          route.Policy,
+			CIDR:            route.CIDR,
+			RouteTable:      route.RouteTable,
+			NextHopIP:       route.NextHopIP,
+			ActiveNextHopIP: strings.Join(active, ","),
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,220 @@
+package controller
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ovs"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func TestSplitNextHops(t *testing.T) {
+	tests := []struct {
+		name      string
+		nextHopIP string
+		expected  []string
+	}{
+		{name: "empty", nextHopIP: "", expected: nil},
+		{name: "single", nextHopIP: "10.0.1.1", expected: []string{"10.0.1.1"}},
+		{name: "multiple", nextHopIP: "10.0.1.1,10.0.1.2", expected: []string{"10.0.1.1", "10.0.1.2"}},
+		{name: "spaces and empty items", nextHopIP: " 10.0.1.1 ,, 10.0.1.2,", expected: []string{"10.0.1.1", "10.0.1.2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, splitNextHops(tt.nextHopIP))
+		})
+	}
+}
+
+func TestMergeVpcStaticRoutes(t *testing.T) {
+	tests := []struct {
+		name     string
+		routes   []*kubeovnv1.StaticRoute
+		expected []*kubeovnv1.StaticRoute
+	}{
+		{
+			name:     "no routes",
+			routes:   nil,
+			expected: nil,
+		},
+		{
+			name: "default policy",
+			routes: []*kubeovnv1.StaticRoute{
+				{CIDR: "0.0.0.0/0", NextHopIP: "10.0.1.1"},
+			},
+			expected: []*kubeovnv1.StaticRoute{
+				{Policy: kubeovnv1.PolicyDst, CIDR: "0.0.0.0/0", NextHopIP: "10.0.1.1"},
+			},
+		},
+		{
+			name: "merge next hops of the same prefix",
+			routes: []*kubeovnv1.StaticRoute{
+				{Policy: kubeovnv1.PolicyDst, CIDR: "0.0.0.0/0", NextHopIP: "10.0.1.1"},
+				{CIDR: "0.0.0.0/0", NextHopIP: "10.0.1.2,10.0.1.3", EnableBFD: true},
+			},
+			expected: []*kubeovnv1.StaticRoute{
+				{Policy: kubeovnv1.PolicyDst, CIDR: "0.0.0.0/0", NextHopIP: "10.0.1.1,10.0.1.2,10.0.1.3", EnableBFD: true},
+			},
+		},
+		{
+			name: "dedup next hops",
+			routes: []*kubeovnv1.StaticRoute{
+				{Policy: kubeovnv1.PolicyDst, CIDR: "0.0.0.0/0", NextHopIP: "10.0.1.1,10.0.1.2"},
+				{Policy: kubeovnv1.PolicyDst, CIDR: "0.0.0.0/0", NextHopIP: "10.0.1.2, 10.0.1.1"},
+			},
+			expected: []*kubeovnv1.StaticRoute{
+				{Policy: kubeovnv1.PolicyDst, CIDR: "0.0.0.0/0", NextHopIP: "10.0.1.1,10.0.1.2"},
+			},
+		},
+		{
+			name: "keep routes of different policies and route tables",
+			routes: []*kubeovnv1.StaticRoute{
+				{Policy: kubeovnv1.PolicyDst, CIDR: "10.0.0.0/24", NextHopIP: "10.0.1.1"},
+				{Policy: kubeovnv1.PolicySrc, CIDR: "10.0.0.0/24", NextHopIP: "10.0.1.2"},
+				{Policy: kubeovnv1.PolicyDst, CIDR: "10.0.0.0/24", NextHopIP: "10.0.1.3", RouteTable: "rtb1"},
+			},
+			expected: []*kubeovnv1.StaticRoute{
+				{Policy: kubeovnv1.PolicyDst, CIDR: "10.0.0.0/24", NextHopIP: "10.0.1.1"},
+				{Policy: kubeovnv1.PolicySrc, CIDR: "10.0.0.0/24", NextHopIP: "10.0.1.2"},
+				{Policy: kubeovnv1.PolicyDst, CIDR: "10.0.0.0/24", NextHopIP: "10.0.1.3", RouteTable: "rtb1"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, mergeVpcStaticRoutes(tt.routes))
+		})
+	}
+}
+
+func TestDiffStaticRoute(t *testing.T) {
+	tests := []struct {
+		name     string
+		exist    []*ovs.StaticRoute
+		target   []*kubeovnv1.StaticRoute
+		expected []string
+	}{
+		{
+			name:   "no existing routes",
+			target: []*kubeovnv1.StaticRoute{{Policy: kubeovnv1.PolicyDst, CIDR: "0.0.0.0/0", NextHopIP: "10.0.1.1"}},
+		},
+		{
+			name: "keep routes merged into ecmp routes",
+			exist: []*ovs.StaticRoute{
+				{Policy: ovs.PolicyDstIP, CIDR: "0.0.0.0/0", NextHop: "10.0.1.1"},
+				{Policy: ovs.PolicyDstIP, CIDR: "0.0.0.0/0", NextHop: "10.0.1.2"},
+			},
+			target: []*kubeovnv1.StaticRoute{
+				{Policy: kubeovnv1.PolicyDst, CIDR: "0.0.0.0/0", NextHopIP: "10.0.1.1"},
+				{CIDR: "0.0.0.0/0", NextHopIP: "10.0.1.2,10.0.1.1"},
+			},
+		},
+		{
+			name: "delete removed next hops",
+			exist: []*ovs.StaticRoute{
+				{Policy: ovs.PolicyDstIP, CIDR: "0.0.0.0/0", NextHop: "10.0.1.1"},
+				{Policy: ovs.PolicyDstIP, CIDR: "0.0.0.0/0", NextHop: "10.0.1.2"},
+			},
+			target: []*kubeovnv1.StaticRoute{
+				{Policy: kubeovnv1.PolicyDst, CIDR: "0.0.0.0/0", NextHopIP: "10.0.1.1"},
+			},
+			expected: []string{"dst:0.0.0.0/0=>10.0.1.2"},
+		},
+		{
+			name: "delete routes of other policies and route tables",
+			exist: []*ovs.StaticRoute{
+				{Policy: ovs.PolicyDstIP, CIDR: "10.0.0.0/24", NextHop: "10.0.1.1"},
+				{Policy: ovs.PolicySrcIP, CIDR: "10.0.0.0/24", NextHop: "10.0.1.1"},
+				{Policy: ovs.PolicyDstIP, CIDR: "10.0.0.0/24", NextHop: "10.0.1.1", RouteTable: "rtb1"},
+			},
+			target: []*kubeovnv1.StaticRoute{
+				{Policy: kubeovnv1.PolicyDst, CIDR: "10.0.0.0/24", NextHopIP: "10.0.1.1", RouteTable: "rtb1"},
+			},
+			expected: []string{"dst:10.0.0.0/24=>10.0.1.1", "src:10.0.0.0/24=>10.0.1.1"},
+		},
+		{
+			name: "delete all routes",
+			exist: []*ovs.StaticRoute{
+				{Policy: ovs.PolicyDstIP, CIDR: "0.0.0.0/0", NextHop: "10.0.1.1"},
+			},
+			expected: []string{"dst:0.0.0.0/0=>10.0.1.1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routeNeedDel, err := diffStaticRoute(tt.exist, tt.target)
+			require.NoError(t, err)
+			var keys []string
+			for _, route := range routeNeedDel {
+				keys = append(keys, getStaticRouteItemKey(route))
+			}
+			sort.Strings(keys)
+			require.Equal(t, tt.expected, keys)
+		})
+	}
+}
+
+func newVpcRouteBFDTestSubnet(name, vpc, cidr string, extraCIDRs ...string) *kubeovnv1.Subnet {
+	return &kubeovnv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: kubeovnv1.SubnetSpec{
+			Vpc:             vpc,
+			CIDRBlock:       cidr,
+			ExtraCIDRBlocks: extraCIDRs,
+			Protocol:        util.CheckProtocol(cidr),
+		},
+	}
+}
+
+func TestVpcRouterPortByIP(t *testing.T) {
+	vpc := &kubeovnv1.Vpc{ObjectMeta: metav1.ObjectMeta{Name: "vpc1"}}
+	subnets := []*kubeovnv1.Subnet{
+		newVpcRouteBFDTestSubnet("net1", "vpc1", "10.0.1.0/24", "10.0.2.0/24"),
+		newVpcRouteBFDTestSubnet("net2", "vpc2", "10.0.3.0/24"),
+	}
+	tests := []struct {
+		name     string
+		ip       string
+		expected string
+	}{
+		{name: "cidr block", ip: "10.0.1.10", expected: "vpc1-net1"},
+		{name: "extra cidr block", ip: "10.0.2.10", expected: "vpc1-net1"},
+		{name: "subnet of another vpc", ip: "10.0.3.10", expected: ""},
+		{name: "not in any subnet", ip: "10.0.4.10", expected: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, vpcRouterPortByIP(vpc, subnets, tt.ip))
+		})
+	}
+}
+
+func TestReconcileVpcStaticRouteBFD(t *testing.T) {
+	c := newTestController(t, withObjects(newVpcRouteBFDTestSubnet("net1", "vpc1", "10.0.1.0/24", "10.0.2.0/24")), withConfig(func(config *Configuration) {
+		config.GatewayBFDInterval, config.GatewayBFDDetectMult = 1000, 3
+	}))
+	vpcTag := map[string]string{logicalRouterKey: "vpc1"}
+	// session on the router port of a subnet removed from the vpc
+	c.ovnClient.bfd["vpc1-net2/10.0.3.1"] = ovnnb.BFD{LogicalPort: "vpc1-net2", DstIP: "10.0.3.1", ExternalIDs: vpcTag}
+	// session of another vpc
+	c.ovnClient.bfd["vpc2-net3/10.0.4.1"] = ovnnb.BFD{LogicalPort: "vpc2-net3", DstIP: "10.0.4.1", ExternalIDs: map[string]string{logicalRouterKey: "vpc2"}}
+
+	vpc := &kubeovnv1.Vpc{ObjectMeta: metav1.ObjectMeta{Name: "vpc1"}}
+	routes := []*kubeovnv1.StaticRoute{
+		{Policy: kubeovnv1.PolicyDst, CIDR: "0.0.0.0/0", NextHopIP: "10.0.1.1,10.0.2.1", EnableBFD: true},
+	}
+	require.NoError(t, c.reconcileVpcStaticRouteBFD(vpc, routes))
+
+	var keys []string
+	for key := range c.ovnClient.bfd {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	require.Equal(t, []string{"vpc1-net1/10.0.1.1", "vpc1-net1/10.0.2.1", "vpc2-net3/10.0.4.1"}, keys)
+	require.Equal(t, vpcTag, c.ovnClient.bfd["vpc1-net1/10.0.2.1"].ExternalIDs)
+}
@@ -0,0 +1,482 @@
+package daemon
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+	"k8s.io/klog/v2"
+)
+
+// bfd control packets of single hop sessions, see RFC 5880 and RFC 5881
+const (
+	bfdControlPort         = 3784
+	bfdSourcePortMin       = 49152
+	bfdSourcePortMax       = 65535
+	bfdVersion             = 1
+	bfdControlPacketLength = 24
+	bfdTTL                 = 255
+
+	// the transmit interval of sessions which are not up
+	bfdSlowInterval = time.Second
+	// sessions which receive nothing for a long time are removed
+	bfdSessionTimeout = time.Minute
+	bfdTickInterval   = 10 * time.Millisecond
+)
+
+type bfdState uint8
+
+const (
+	bfdStateAdminDown bfdState = iota
+	bfdStateDown
+	bfdStateInit
+	bfdStateUp
+)
+
+func (s bfdState) String() string {
+	switch s {
+	case bfdStateAdminDown:
+		return "admin_down"
+	case bfdStateDown:
+		return "down"
+	case bfdStateInit:
+		return "init"
+	case bfdStateUp:
+		return "up"
+	}
+	return fmt.Sprintf("unknown(%d)", uint8(s))
+}
+
+const (
+	bfdDiagNone             uint8 = 0
+	bfdDiagDetectionExpired uint8 = 1
+	bfdDiagNeighborDown     uint8 = 3
+)
+
+type bfdControlPacket struct {
+	Diag       uint8
+	State      bfdState
+	Poll       bool
+	Final      bool
+	DetectMult uint8
+	MyDisc     uint32
+	YourDisc   uint32
+	// intervals in microseconds
+	DesiredMinTx      uint32
+	RequiredMinRx     uint32
+	RequiredMinEchoRx uint32
+}
+
+func (p *bfdControlPacket) marshal() []byte {
+	b := make([]byte, bfdControlPacketLength)
+	b[0] = bfdVersion<<5 | p.Diag&0x1f
+	b[1] = uint8(p.State) << 6
+	if p.Poll {
+		b[1] |= 0x20
+	}
+	if p.Final {
+		b[1] |= 0x10
+	}
+	b[2] = p.DetectMult
+	b[3] = bfdControlPacketLength
+	binary.BigEndian.PutUint32(b[4:], p.MyDisc)
+	binary.BigEndian.PutUint32(b[8:], p.YourDisc)
+	binary.BigEndian.PutUint32(b[12:], p.DesiredMinTx)
+	binary.BigEndian.PutUint32(b[16:], p.RequiredMinRx)
+	binary.BigEndian.PutUint32(b[20:], p.RequiredMinEchoRx)
+	return b
+}
+
+// parseBFDControlPacket parse a bfd control packet and check it as section 6.8.6 of RFC 5880,
+// packets with authentication are not supported
+func parseBFDControlPacket(b []byte) (*bfdControlPacket, error) {
+	if len(b) < bfdControlPacketLength {
+		return nil, fmt.Errorf("packet length %d is too short", len(b))
+	}
+	if version := b[0] >> 5; version != bfdVersion {
+		return nil, fmt.Errorf("unsupported version %d", version)
+	}
+	if length := int(b[3]); length < bfdControlPacketLength || length > len(b) {
+		return nil, fmt.Errorf("invalid length %d", length)
+	}
+	if b[1]&0x04 != 0 {
+		return nil, fmt.Errorf("authentication is not supported")
+	}
+	if b[1]&0x01 != 0 {
+		return nil, fmt.Errorf("multipoint is not supported")
+	}
+
+	p := &bfdControlPacket{
+		Diag:              b[0] & 0x1f,
+		State:             bfdState(b[1] >> 6),
+		Poll:              b[1]&0x20 != 0,
+		Final:             b[1]&0x10 != 0,
+		DetectMult:        b[2],
+		MyDisc:            binary.BigEndian.Uint32(b[4:]),
+		YourDisc:          binary.BigEndian.Uint32(b[8:]),
+		DesiredMinTx:      binary.BigEndian.Uint32(b[12:]),
+		RequiredMinRx:     binary.BigEndian.Uint32(b[16:]),
+		RequiredMinEchoRx: binary.BigEndian.Uint32(b[20:]),
+	}
+	if p.DetectMult == 0 {
+		return nil, fmt.Errorf("detect mult is zero")
+	}
+	if p.MyDisc == 0 {
+		return nil, fmt.Errorf("my discriminator is zero")
+	}
+	if p.YourDisc == 0 && p.State != bfdStateDown && p.State != bfdStateAdminDown {
+		return nil, fmt.Errorf("your discriminator is zero in state %s", p.State)
+	}
+	return p, nil
+}
+
+type bfdSession struct {
+	peer        string
+	localDisc   uint32
+	remoteDisc  uint32
+	state       bfdState
+	remoteState bfdState
+	diag        uint8
+	// intervals of the remote system in microseconds
+	remoteMinTx      uint32
+	remoteMinRx      uint32
+	remoteDetectMult uint8
+	// a poll sequence is sent after the session is up to speed up transmission
+	polling    bool
+	sendFinal  bool
+	lastRx     time.Time
+	nextTx     time.Time
+	detectTime time.Duration
+}
+
+// bfdRxConn receive bfd control packets with the ttl of them
+type bfdRxConn struct {
+	net.PacketConn
+	conn4 *ipv4.PacketConn
+	conn6 *ipv6.PacketConn
+}
+
+func (c *bfdRxConn) readFrom(buf []byte) (n, ttl int, src net.Addr, err error) {
+	if c.conn4 != nil {
+		var cm *ipv4.ControlMessage
+		if n, cm, src, err = c.conn4.ReadFrom(buf); cm != nil {
+			ttl = cm.TTL
+		}
+		return
+	}
+	var cm *ipv6.ControlMessage
+	if n, cm, src, err = c.conn6.ReadFrom(buf); cm != nil {
+		ttl = cm.HopLimit
+	}
+	return
+}
+
+// bfdResponder answer bfd sessions initiated by ovn, it acts as the passive role of section 6.1 of RFC 5880
+// and creates a session for every peer sending bfd control packets to the node
+type bfdResponder struct {
+	interval   time.Duration
+	detectMult uint8
+
+	mutex    sync.Mutex
+	sessions map[string]*bfdSession
+	txConns  map[int]net.PacketConn
+}
+
+func newBFDResponder(interval, detectMult int) (*bfdResponder, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid bfd interval %d", interval)
+	}
+	if detectMult <= 0 || detectMult > 255 {
+		return nil, fmt.Errorf("invalid bfd detect mult %d", detectMult)
+	}
+	return &bfdResponder{
+		interval:   time.Duration(interval) * time.Millisecond,
+		detectMult: uint8(detectMult),
+		sessions:   make(map[string]*bfdSession),
+		txConns:    make(map[int]net.PacketConn),
+	}, nil
+}
+
+// runBFDResponder answer bfd sessions on the node until stopCh is closed, ipv6 is skipped if it is unavailable
+func runBFDResponder(config *Configuration, stopCh <-chan struct{}) error {
+	r, err := newBFDResponder(config.GatewayBFDInterval, config.GatewayBFDDetectMult)
+	if err != nil {
+		return err
+	}
+	defer r.close()
+
+	rxConn4, err := r.listen(4)
+	if err != nil {
+		return fmt.Errorf("failed to listen on udp4 port %d: %v", bfdControlPort, err)
+	}
+	defer rxConn4.Close()
+	go r.serve(rxConn4)
+
+	if rxConn6, err := r.listen(6); err != nil {
+		klog.Warningf("bfd over ipv6 is disabled, failed to listen on udp6 port %d: %v", bfdControlPort, err)
+	} else {
+		defer rxConn6.Close()
+		go r.serve(rxConn6)
+	}
+
+	klog.Infof("bfd responder started with interval %v and detect mult %d", r.interval, r.detectMult)
+	ticker := time.NewTicker(bfdTickInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return nil
+		case now := <-ticker.C:
+			r.tick(now)
+		}
+	}
+}
+
+// listen open the socket receiving bfd control packets and the socket sending them
+func (r *bfdResponder) listen(family int) (*bfdRxConn, error) {
+	network := fmt.Sprintf("udp%d", family)
+	conn, err := net.ListenPacket(network, fmt.Sprintf(":%d", bfdControlPort))
+	if err != nil {
+		return nil, err
+	}
+	rxConn := &bfdRxConn{PacketConn: conn}
+	if family == 4 {
+		rxConn.conn4 = ipv4.NewPacketConn(conn)
+		err = rxConn.conn4.SetControlMessage(ipv4.FlagTTL, true)
+	} else {
+		rxConn.conn6 = ipv6.NewPacketConn(conn)
+		err = rxConn.conn6.SetControlMessage(ipv6.FlagHopLimit, true)
+	}
+	if err != nil {
+		rxConn.Close()
+		return nil, err
+	}
+
+	// the source port must be in the range 49152 through 65535 and be the same for all packets
+	var txConn net.PacketConn
+	for i := 0; i < 100; i++ {
+		port := bfdSourcePortMin + rand.Intn(bfdSourcePortMax-bfdSourcePortMin+1) // #nosec G404
+		if txConn, err = net.ListenPacket(network, fmt.Sprintf(":%d", port)); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		rxConn.Close()
+		return nil, fmt.Errorf("no source port available: %v", err)
+	}
+	if family == 4 {
+		err = ipv4.NewPacketConn(txConn).SetTTL(bfdTTL)
+	} else {
+		err = ipv6.NewPacketConn(txConn).SetHopLimit(bfdTTL)
+	}
+	if err != nil {
+		rxConn.Close()
+		txConn.Close()
+		return nil, err
+	}
+
+	r.mutex.Lock()
+	r.txConns[family] = txConn
+	r.mutex.Unlock()
+	return rxConn, nil
+}
+
+func (r *bfdResponder) close() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, conn := range r.txConns {
+		conn.Close()
+	}
+}
+
+// serve receive bfd control packets until the connection is closed,
+// packets not sent with ttl 255 are dropped since they may come from other hops
+func (r *bfdResponder) serve(conn *bfdRxConn) {
+	buf := make([]byte, 128)
+	for {
+		n, ttl, src, err := conn.readFrom(buf)
+		if err != nil {
+			klog.V(3).Infof("stop receiving bfd packets on %v: %v", conn.LocalAddr(), err)
+			return
+		}
+		if ttl != bfdTTL {
+			klog.V(5).Infof("drop bfd packet from %v with ttl %d", src, ttl)
+			continue
+		}
+		udpAddr, ok := src.(*net.UDPAddr)
+		if !ok {
+			continue
+		}
+		packet, err := parseBFDControlPacket(buf[:n])
+		if err != nil {
+			klog.V(5).Infof("drop invalid bfd packet from %v: %v", src, err)
+			continue
+		}
+		r.receive(udpAddr.IP, packet, time.Now())
+	}
+}
+
+// receive update the session of the peer as section 6.8.6 of RFC 5880
+func (r *bfdResponder) receive(peer net.IP, p *bfdControlPacket, now time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key := peer.String()
+	s := r.sessions[key]
+	if p.YourDisc != 0 && (s == nil || s.localDisc != p.YourDisc) {
+		klog.V(5).Infof("drop bfd packet from %s with unknown discriminator %d", key, p.YourDisc)
+		return
+	}
+	if s == nil {
+		s = &bfdSession{peer: key, localDisc: r.newDiscriminator(), state: bfdStateDown}
+		r.sessions[key] = s
+		klog.Infof("new bfd session from %s", key)
+	}
+
+	s.remoteDisc = p.MyDisc
+	s.remoteState = p.State
+	s.remoteMinTx = p.DesiredMinTx
+	s.remoteMinRx = p.RequiredMinRx
+	s.remoteDetectMult = p.DetectMult
+	s.lastRx = now
+	s.detectTime = time.Duration(p.DetectMult) * maxDuration(r.interval, time.Duration(p.DesiredMinTx)*time.Microsecond)
+	if p.Final {
+		s.polling = false
+	}
+
+	oldState := s.state
+	switch {
+	case p.State == bfdStateAdminDown:
+		if s.state != bfdStateDown {
+			s.state, s.diag = bfdStateDown, bfdDiagNeighborDown
+		}
+	case s.state == bfdStateDown:
+		if p.State == bfdStateDown {
+			s.state = bfdStateInit
+		} else if p.State == bfdStateInit {
+			s.state = bfdStateUp
+		}
+	case s.state == bfdStateInit:
+		if p.State == bfdStateInit || p.State == bfdStateUp {
+			s.state = bfdStateUp
+		}
+	case s.state == bfdStateUp:
+		if p.State == bfdStateDown {
+			s.state, s.diag = bfdStateDown, bfdDiagNeighborDown
+		}
+	}
+	if s.state != oldState {
+		klog.Infof("bfd session from %s changes from %s to %s", key, oldState, s.state)
+		if s.state == bfdStateUp {
+			// the desired transmit interval decreases from the slow interval, which is announced by a poll sequence
+			s.diag, s.polling = bfdDiagNone, true
+		}
+		s.nextTx = now
+	}
+	if p.Poll {
+		// answer the poll sequence of the remote system at once
+		s.sendFinal = true
+		s.nextTx = now
+	}
+}
+
+// tick detect sessions whose peers are down and send periodic control packets
+func (r *bfdResponder) tick(now time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for key, s := range r.sessions {
+		if now.Sub(s.lastRx) > bfdSessionTimeout {
+			klog.Infof("remove bfd session from %s which receives nothing for %v", key, bfdSessionTimeout)
+			delete(r.sessions, key)
+			continue
+		}
+		if s.remoteDisc != 0 && (s.state == bfdStateInit || s.state == bfdStateUp) && now.Sub(s.lastRx) > s.detectTime {
+			klog.Infof("bfd session from %s changes from %s to %s, nothing is received in %v", key, s.state, bfdStateDown, s.detectTime)
+			s.state, s.diag, s.remoteDisc, s.polling = bfdStateDown, bfdDiagDetectionExpired, 0, false
+		}
+		// a passive system stops sending when the remote system requires no packets
+		if s.remoteMinRx == 0 || now.Before(s.nextTx) {
+			continue
+		}
+		r.send(s)
+		s.nextTx = now.Add(r.txInterval(s))
+	}
+}
+
+// txInterval return the interval before the next periodic packet with jitter as section 6.8.7 of RFC 5880
+func (r *bfdResponder) txInterval(s *bfdSession) time.Duration {
+	interval := maxDuration(r.desiredMinTx(s), time.Duration(s.remoteMinRx)*time.Microsecond)
+	jitter := 25
+	if r.detectMult == 1 {
+		jitter = 15
+	}
+	return interval * time.Duration(100-rand.Intn(jitter+1)) / 100 // #nosec G404
+}
+
+func (r *bfdResponder) desiredMinTx(s *bfdSession) time.Duration {
+	if s.state != bfdStateUp {
+		return maxDuration(r.interval, bfdSlowInterval)
+	}
+	return r.interval
+}
+
+func (r *bfdResponder) send(s *bfdSession) {
+	p := &bfdControlPacket{
+		Diag:          s.diag,
+		State:         s.state,
+		Poll:          s.polling && !s.sendFinal,
+		Final:         s.sendFinal,
+		DetectMult:    r.detectMult,
+		MyDisc:        s.localDisc,
+		YourDisc:      s.remoteDisc,
+		DesiredMinTx:  uint32(r.desiredMinTx(s) / time.Microsecond),
+		RequiredMinRx: uint32(r.interval / time.Microsecond),
+	}
+	s.sendFinal = false
+
+	peer := net.ParseIP(s.peer)
+	family := 6
+	if peer.To4() != nil {
+		family = 4
+	}
+	conn := r.txConns[family]
+	if conn == nil {
+		return
+	}
+	if _, err := conn.WriteTo(p.marshal(), &net.UDPAddr{IP: peer, Port: bfdControlPort}); err != nil {
+		klog.Errorf("failed to send bfd packet to %s: %v", s.peer, err)
+	}
+}
+
+// newDiscriminator return a nonzero discriminator unique among the sessions
+func (r *bfdResponder) newDiscriminator() uint32 {
+	for {
+		disc := rand.Uint32() // #nosec G404
+		if disc == 0 {
+			continue
+		}
+		unique := true
+		for _, s := range r.sessions {
+			if s.localDisc == disc {
+				unique = false
+				break
+			}
+		}
+		if unique {
+			return disc
+		}
+	}
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,84 @@
+package daemon
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBFDControlPacket(t *testing.T) {
+	p := &bfdControlPacket{
+		Diag:          bfdDiagNeighborDown,
+		State:         bfdStateUp,
+		Poll:          true,
+		DetectMult:    3,
+		MyDisc:        1,
+		YourDisc:      2,
+		DesiredMinTx:  100000,
+		RequiredMinRx: 200000,
+	}
+	parsed, err := parseBFDControlPacket(p.marshal())
+	require.NoError(t, err)
+	require.Equal(t, p, parsed)
+
+	invalid := map[string]func(b []byte){
+		"version":     func(b []byte) { b[0] = 2<<5 | b[0]&0x1f },
+		"length":      func(b []byte) { b[3] = 30 },
+		"auth":        func(b []byte) { b[1] |= 0x04 },
+		"detect mult": func(b []byte) { b[2] = 0 },
+		"my disc":     func(b []byte) { b[4], b[5], b[6], b[7] = 0, 0, 0, 0 },
+		"your disc":   func(b []byte) { b[8], b[9], b[10], b[11] = 0, 0, 0, 0 },
+	}
+	for name, fn := range invalid {
+		b := p.marshal()
+		fn(b)
+		_, err = parseBFDControlPacket(b)
+		require.Error(t, err, name)
+	}
+	_, err = parseBFDControlPacket(p.marshal()[:20])
+	require.Error(t, err)
+}
+
+func TestBFDResponderSession(t *testing.T) {
+	r, err := newBFDResponder(100, 3)
+	require.NoError(t, err)
+
+	peer := net.ParseIP("100.64.0.1")
+	now := time.Now()
+	remote := &bfdControlPacket{State: bfdStateDown, DetectMult: 3, MyDisc: 7, DesiredMinTx: 100000, RequiredMinRx: 100000}
+
+	r.receive(peer, remote, now)
+	s := r.sessions[peer.String()]
+	require.NotNil(t, s)
+	require.Equal(t, bfdStateInit, s.state)
+	require.Equal(t, uint32(7), s.remoteDisc)
+	require.Equal(t, time.Second, r.desiredMinTx(s))
+
+	// packets with another discriminator are dropped
+	r.receive(peer, &bfdControlPacket{State: bfdStateUp, DetectMult: 3, MyDisc: 7, YourDisc: s.localDisc + 1}, now)
+	require.Equal(t, bfdStateInit, s.state)
+
+	remote.State, remote.YourDisc = bfdStateUp, s.localDisc
+	r.receive(peer, remote, now)
+	require.Equal(t, bfdStateUp, s.state)
+	require.True(t, s.polling)
+	require.Equal(t, 100*time.Millisecond, r.desiredMinTx(s))
+
+	remote.Final = true
+	r.receive(peer, remote, now)
+	require.False(t, s.polling)
+
+	// the session is down after nothing is received in the detection time
+	r.tick(now.Add(200 * time.Millisecond))
+	require.Equal(t, bfdStateUp, s.state)
+	r.tick(now.Add(400 * time.Millisecond))
+	require.Equal(t, bfdStateDown, s.state)
+	require.Equal(t, bfdDiagDetectionExpired, s.diag)
+	require.Zero(t, s.remoteDisc)
+
+	// the session is removed after nothing is received for a long time
+	r.tick(now.Add(bfdSessionTimeout + time.Second))
+	require.Empty(t, r.sessions)
+}
@@ -45,6 +45,9 @@ type Configuration struct {
 	NetworkType           string
 	DefaultProviderName   string
 	DefaultInterfaceName  string
+	EnableGatewayBFD      bool
+	GatewayBFDInterval    int
+	GatewayBFDDetectMult  int
 }
 
 // ParseFlags will parse cmd args then init kubeClient and configuration
@@ -66,6 +69,10 @@ func ParseFlags(nicBridgeMappings map[string]string) (*Configuration, error) {
 		argsNetworkType          = pflag.String("network-type", "geneve", "The ovn network type")
 		argsDefaultProviderName  = pflag.String("default-provider-name", "provider", "The vlan or vxlan type default provider interface name")
 		argsDefaultInterfaceName = pflag.String("default-interface-name", "", "The default host interface name in the vlan/vxlan type")
+
+		argEnableGatewayBFD     = pflag.Bool("enable-gateway-bfd", false, "Answer bfd sessions of centralized gateways from the ovn cluster router, it must be enabled if kube-ovn-controller runs with --enable-gateway-bfd")
+		argGatewayBFDInterval   = pflag.Int("gateway-bfd-interval", 100, "The minimum interval in milliseconds of bfd packets sent and received by the node")
+		argGatewayBFDDetectMult = pflag.Int("gateway-bfd-detect-mult", 3, "The number of lost bfd packets before the bfd session of the node is considered down")
 	)
 
 	// mute info log for ipset lib
@@ -110,6 +117,9 @@ func ParseFlags(nicBridgeMappings map[string]string) (*Configuration, error) {
 		NetworkType:           *argsNetworkType,
 		DefaultProviderName:   *argsDefaultProviderName,
 		DefaultInterfaceName:  *argsDefaultInterfaceName,
+		EnableGatewayBFD:      *argEnableGatewayBFD,
+		GatewayBFDInterval:    *argGatewayBFDInterval,
+		GatewayBFDDetectMult:  *argGatewayBFDDetectMult,
 	}
 
 	if err := config.initKubeClient(); err != nil {
@@ -64,6 +64,10 @@ type Controller struct {
 	htbQosLister kubeovnlister.HtbQosLister
 	htbQosSynced cache.InformerSynced
 
+	mirrorSessionsLister kubeovnlister.MirrorSessionLister
+	mirrorSessionsSynced cache.InformerSynced
+	mirrorSessionQueue   workqueue.RateLimitingInterface
+
 	recorder record.EventRecorder
 
 	iptables  map[string]*iptables.IPTables
@@ -88,6 +92,7 @@ func NewController(config *Configuration, podInformerFactory informers.SharedInf
 	podInformer := podInformerFactory.Core().V1().Pods()
 	nodeInformer := nodeInformerFactory.Core().V1().Nodes()
 	htbQosInformer := kubeovnInformerFactory.Kubeovn().V1().HtbQoses()
+	mirrorSessionInformer := kubeovnInformerFactory.Kubeovn().V1().MirrorSessions()
 
 	controller := &Controller{
 		config: config,
@@ -111,6 +116,10 @@ func NewController(config *Configuration, podInformerFactory informers.SharedInf
 		htbQosLister: htbQosInformer.Lister(),
 		htbQosSynced: htbQosInformer.Informer().HasSynced,
 
+		mirrorSessionsLister: mirrorSessionInformer.Lister(),
+		mirrorSessionsSynced: mirrorSessionInformer.Informer().HasSynced,
+		mirrorSessionQueue:   workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "MirrorSession"),
+
 		recorder: recorder,
 	}
 
@@ -153,6 +162,11 @@ func NewController(config *Configuration, podInformerFactory informers.SharedInf
 	podInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		UpdateFunc: controller.enqueuePod,
 	})
+	mirrorSessionInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    controller.enqueueAddMirrorSession,
+		UpdateFunc: controller.enqueueUpdateMirrorSession,
+		DeleteFunc: controller.enqueueDeleteMirrorSession,
+	})
 
 	return controller, nil
 }
@@ -550,7 +564,7 @@ func (c *Controller) reconcileRouters(event subnetEvent) error {
 			continue
 		}
 
-		for _, cidrBlock := range strings.Split(subnet.Spec.CIDRBlock, ",") {
+		for _, cidrBlock := range strings.Split(util.JoinCIDRBlocks(subnet.Spec.CIDRBlock, subnet.Spec.ExtraCIDRBlocks), ",") {
 			if _, ipNet, err := net.ParseCIDR(cidrBlock); err != nil {
 				klog.Errorf("%s is not a valid cidr block", cidrBlock)
 			} else {
@@ -732,7 +746,7 @@ func (c *Controller) getPolicyRouting(subnet *kubeovnv1.Subnet) ([]netlink.Rule,
 		protocols[0] = protocol
 	}
 
-	cidr := strings.Split(subnet.Spec.CIDRBlock, ",")
+	cidrBlocks := util.JoinCIDRBlocks(subnet.Spec.CIDRBlock, subnet.Spec.ExtraCIDRBlocks)
 	egw := strings.Split(subnet.Spec.ExternalEgressGateway, ",")
 
 	// rules
@@ -779,10 +793,15 @@ func (c *Controller) getPolicyRouting(subnet *kubeovnv1.Subnet) ([]netlink.Rule,
 	} else {
 		for i := range protocols {
 			rule.Family, _ = util.ProtocolToFamily(protocols[i])
-			if len(cidr) == len(protocols) {
-				_, rule.Src, _ = net.ParseCIDR(cidr[i])
+			cidrs := getCidrsByProtocol(cidrBlocks, protocols[i])
+			if len(cidrs) == 0 {
+				rules = append(rules, *rule)
+				continue
+			}
+			for _, cidr := range cidrs {
+				_, rule.Src, _ = net.ParseCIDR(cidr)
+				rules = append(rules, *rule)
 			}
-			rules = append(rules, *rule)
 		}
 	}
 
@@ -810,7 +829,8 @@ func (c *Controller) enqueuePod(old, new interface{}) {
 		oldPod.Annotations[util.NetemQosLatencyAnnotation] != newPod.Annotations[util.NetemQosLatencyAnnotation] ||
 		oldPod.Annotations[util.NetemQosLimitAnnotation] != newPod.Annotations[util.NetemQosLimitAnnotation] ||
 		oldPod.Annotations[util.NetemQosLossAnnotation] != newPod.Annotations[util.NetemQosLossAnnotation] ||
-		oldPod.Annotations[util.MirrorControlAnnotation] != newPod.Annotations[util.MirrorControlAnnotation] {
+		oldPod.Annotations[util.MirrorControlAnnotation] != newPod.Annotations[util.MirrorControlAnnotation] ||
+		oldPod.Annotations[util.IpAddressAnnotation] != newPod.Annotations[util.IpAddressAnnotation] {
 		var key string
 		var err error
 		if key, err = cache.MetaNamespaceKeyFunc(new); err != nil {
@@ -833,7 +853,8 @@ func (c *Controller) enqueuePod(old, new interface{}) {
 				oldPod.Annotations[fmt.Sprintf(util.NetemQosLatencyAnnotationTemplate, provider)] != newPod.Annotations[fmt.Sprintf(util.NetemQosLatencyAnnotationTemplate, provider)] ||
 				oldPod.Annotations[fmt.Sprintf(util.NetemQosLimitAnnotationTemplate, provider)] != newPod.Annotations[fmt.Sprintf(util.NetemQosLimitAnnotationTemplate, provider)] ||
 				oldPod.Annotations[fmt.Sprintf(util.NetemQosLossAnnotationTemplate, provider)] != newPod.Annotations[fmt.Sprintf(util.NetemQosLossAnnotationTemplate, provider)] ||
-				oldPod.Annotations[fmt.Sprintf(util.MirrorControlAnnotationTemplate, provider)] != newPod.Annotations[fmt.Sprintf(util.MirrorControlAnnotationTemplate, provider)] {
+				oldPod.Annotations[fmt.Sprintf(util.MirrorControlAnnotationTemplate, provider)] != newPod.Annotations[fmt.Sprintf(util.MirrorControlAnnotationTemplate, provider)] ||
+				oldPod.Annotations[fmt.Sprintf(util.IpAddressAnnotationTemplate, provider)] != newPod.Annotations[fmt.Sprintf(util.IpAddressAnnotationTemplate, provider)] {
 				var key string
 				var err error
 				if key, err = cache.MetaNamespaceKeyFunc(new); err != nil {
@@ -919,6 +940,9 @@ func (c *Controller) handlePod(key string) error {
 	if err != nil {
 		return err
 	}
+	if err = c.syncPodNicAddress(pod, util.OvnProvider, ifaceID); err != nil {
+		return err
+	}
 
 	// set multus-nic bandwidth
 	attachNets, err := util.ParsePodNetworkAnnotation(pod.Annotations[util.AttachmentNetworkAnnotation], pod.Namespace)
@@ -941,11 +965,49 @@ func (c *Controller) handlePod(key string) error {
 			if err != nil {
 				return err
 			}
+			if err = c.syncPodNicAddress(pod, provider, ifaceID); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
 }
 
+// syncPodNicAddress add the addresses allocated after the nic is created to the running pod,
+// e.g. the ipv6 address allocated when the subnet is upgraded to dual stack
+func (c *Controller) syncPodNicAddress(pod *v1.Pod, provider, ifaceID string) error {
+	ipStr := pod.Annotations[fmt.Sprintf(util.IpAddressAnnotationTemplate, provider)]
+	if ipStr == "" || pod.Annotations[fmt.Sprintf(util.AllocatedAnnotationTemplate, provider)] != "true" {
+		return nil
+	}
+	ifaces := ovs.GetOvsPorts(fmt.Sprintf("external_ids:iface-id=%s", ifaceID))
+	if len(ifaces) == 0 {
+		return nil
+	}
+	iface := ifaces[0]
+	existingIP, netns := iface.ExternalIds["ip"], iface.ExternalIds["pod_netns"]
+	if existingIP == "" || existingIP == ipStr || netns == "" || !util.ContainsString(strings.Split(ipStr, ","), existingIP) {
+		return nil
+	}
+
+	isDefaultRoute := pod.Annotations[fmt.Sprintf(util.DefaultRouteAnnotationTemplate, provider)] == "true" ||
+		(pod.Annotations[fmt.Sprintf(util.DefaultRouteAnnotationTemplate, provider)] == "" && provider == util.OvnProvider)
+	ipAddr := util.GetIpAddrWithMask(ipStr, pod.Annotations[fmt.Sprintf(util.CidrAnnotationTemplate, provider)])
+	if ipAddr == "" {
+		return fmt.Errorf("cidr of address %s of pod %s/%s is invalid", ipStr, pod.Namespace, pod.Name)
+	}
+	klog.Infof("add address %s to nic %s of pod %s/%s", ipStr, iface.Name, pod.Namespace, pod.Name)
+	if err := addContainerNicAddress(netns, existingIP, ipAddr, pod.Annotations[fmt.Sprintf(util.GatewayAnnotationTemplate, provider)], isDefaultRoute); err != nil {
+		klog.Errorf("failed to add address %s to pod %s/%s, %v", ipStr, pod.Namespace, pod.Name, err)
+		c.recorder.Eventf(pod, v1.EventTypeWarning, "AddNicAddressFailed", err.Error())
+		return err
+	}
+	if output, err := ovs.Exec("set", "interface", iface.Name, fmt.Sprintf("external_ids:ip=%s", ipStr)); err != nil {
+		return fmt.Errorf("failed to set ip of interface %s: %v, %q", iface.Name, err, output)
+	}
+	return nil
+}
+
 func (c *Controller) loopEncapIpCheck() {
 	node, err := c.nodesLister.Get(c.config.NodeName)
 	if err != nil {
@@ -1209,11 +1271,12 @@ func (c *Controller) Run(stopCh <-chan struct{}) {
 	defer c.deleteProviderNetworkQueue.ShutDown()
 	defer c.subnetQueue.ShutDown()
 	defer c.podQueue.ShutDown()
+	defer c.mirrorSessionQueue.ShutDown()
 
 	go wait.Until(ovs.CleanLostInterface, time.Minute, stopCh)
 	go wait.Until(recompute, 10*time.Minute, stopCh)
 	go wait.Until(rotateLog, 1*time.Hour, stopCh)
-	if ok := cache.WaitForCacheSync(stopCh, c.providerNetworksSynced, c.subnetsSynced, c.podsSynced, c.nodesSynced, c.htbQosSynced); !ok {
+	if ok := cache.WaitForCacheSync(stopCh, c.providerNetworksSynced, c.subnetsSynced, c.podsSynced, c.nodesSynced, c.htbQosSynced, c.mirrorSessionsSynced); !ok {
 		klog.Fatalf("failed to wait for caches to sync")
 		return
 	}
@@ -1229,6 +1292,8 @@ func (c *Controller) Run(stopCh <-chan struct{}) {
 	go wait.Until(c.runDeleteProviderNetworkWorker, time.Second, stopCh)
 	go wait.Until(c.runSubnetWorker, time.Second, stopCh)
 	go wait.Until(c.runPodWorker, time.Second, stopCh)
+	go wait.Until(c.runMirrorSessionWorker, time.Second, stopCh)
+	go wait.Until(c.resyncMirrorSessions, 10*time.Second, stopCh)
 	go wait.Until(c.runGateway, 3*time.Second, stopCh)
 	go wait.Until(c.loopEncapIpCheck, 3*time.Second, stopCh)
 	go wait.Until(func() {
@@ -1242,6 +1307,13 @@ func (c *Controller) Run(stopCh <-chan struct{}) {
 		}
 	}, 1*time.Minute, stopCh)
 	go wait.Until(c.loopCheckSubnetQosPriority, 5*time.Second, stopCh)
+	if c.config.EnableGatewayBFD {
+		go wait.Until(func() {
+			if err := runBFDResponder(c.config, stopCh); err != nil {
+				klog.Errorf("bfd responder error: %v", err)
+			}
+		}, 10*time.Second, stopCh)
+	}
 	<-stopCh
 	klog.Info("Shutting down workers")
 }
@@ -169,8 +169,8 @@ func (c *Controller) setPolicyRouting() error {
 				return err
 			}
 		}
-		for meta, cidr := range subnetsNeedPR {
-			if err = c.addPolicyRouting(family, meta.gateway, meta.priority, meta.tableID, cidr); err != nil {
+		for meta, cidrs := range subnetsNeedPR {
+			if err = c.addPolicyRouting(family, meta.gateway, meta.priority, meta.tableID, cidrs...); err != nil {
 				klog.Errorf("failed to add policy routing for subnet: %+v", err)
 				return err
 			}
@@ -830,15 +830,15 @@ func (c *Controller) getSubnetsNeedNAT(protocol string) ([]string, error) {
 				continue
 			}
 
-			cidrBlock := getCidrByProtocol(subnet.Spec.CIDRBlock, protocol)
-			subnetsNeedNat = append(subnetsNeedNat, cidrBlock)
+			cidrBlocks := util.JoinCIDRBlocks(subnet.Spec.CIDRBlock, subnet.Spec.ExtraCIDRBlocks)
+			subnetsNeedNat = append(subnetsNeedNat, getCidrsByProtocol(cidrBlocks, protocol)...)
 		}
 	}
 	return subnetsNeedNat, nil
 }
 
-func (c *Controller) getSubnetsNeedPR(protocol string) (map[policyRouteMeta]string, error) {
-	subnetsNeedPR := make(map[policyRouteMeta]string)
+func (c *Controller) getSubnetsNeedPR(protocol string) (map[policyRouteMeta][]string, error) {
+	subnetsNeedPR := make(map[policyRouteMeta][]string)
 	subnets, err := c.subnetsLister.List(labels.Everything())
 	if err != nil {
 		klog.Errorf("failed to list subnets: %v", err)
@@ -868,8 +868,8 @@ func (c *Controller) getSubnetsNeedPR(protocol string) (map[policyRouteMeta]stri
 				meta.gateway = egw[0]
 			}
 			if meta.gateway != "" {
-				cidrBlock := getCidrByProtocol(subnet.Spec.CIDRBlock, protocol)
-				subnetsNeedPR[meta] = cidrBlock
+				cidrBlocks := util.JoinCIDRBlocks(subnet.Spec.CIDRBlock, subnet.Spec.ExtraCIDRBlocks)
+				subnetsNeedPR[meta] = append(subnetsNeedPR[meta], getCidrsByProtocol(cidrBlocks, protocol)...)
 			}
 		}
 	}
@@ -900,8 +900,8 @@ func (c *Controller) getDefaultVpcSubnetsCIDR(protocol string) ([]string, error)
 	}
 	for _, subnet := range subnets {
 		if subnet.Spec.Vpc == util.DefaultVpc && (subnet.Spec.Vlan == "" || subnet.Spec.LogicalGateway) {
-			cidrBlock := getCidrByProtocol(subnet.Spec.CIDRBlock, protocol)
-			ret = append(ret, cidrBlock)
+			cidrBlocks := util.JoinCIDRBlocks(subnet.Spec.CIDRBlock, subnet.Spec.ExtraCIDRBlocks)
+			ret = append(ret, getCidrsByProtocol(cidrBlocks, protocol)...)
 		}
 	}
 	return ret, nil
@@ -991,19 +991,15 @@ func (c *Controller) updateMssRuleByProtocol(protocol string, MssMangleRule util
 	}
 }
 
-func getCidrByProtocol(cidr, protocol string) string {
-	var cidrStr string
-	if util.CheckProtocol(cidr) == kubeovnv1.ProtocolDual {
-		cidrBlocks := strings.Split(cidr, ",")
-		if protocol == kubeovnv1.ProtocolIPv4 {
-			cidrStr = cidrBlocks[0]
-		} else if protocol == kubeovnv1.ProtocolIPv6 {
-			cidrStr = cidrBlocks[1]
+// getCidrsByProtocol returns all cidr blocks of the protocol in a comma separated list
+func getCidrsByProtocol(cidrBlocks, protocol string) []string {
+	var cidrs []string
+	for _, cidr := range strings.Split(cidrBlocks, ",") {
+		if util.CheckProtocol(cidr) == protocol {
+			cidrs = append(cidrs, cidr)
 		}
-	} else {
-		cidrStr = cidr
 	}
-	return cidrStr
+	return cidrs
 }
 
 func (c *Controller) getEgressNatIpByNode(nodeName string) (map[string]string, error) {
@@ -1020,7 +1016,7 @@ func (c *Controller) getEgressNatIpByNode(nodeName string) (map[string]string, e
 		}
 
 		// only check format like 'kube-ovn-worker:172.18.0.2, kube-ovn-control-plane:172.18.0.3'
-		for _, cidr := range strings.Split(subnet.Spec.CIDRBlock, ",") {
+		for _, cidr := range strings.Split(util.JoinCIDRBlocks(subnet.Spec.CIDRBlock, subnet.Spec.ExtraCIDRBlocks), ",") {
 			for _, gw := range strings.Split(subnet.Spec.GatewayNode, ",") {
 				if strings.Contains(gw, ":") && util.GatewayContains(gw, nodeName) && util.CheckProtocol(cidr) == util.CheckProtocol(strings.Split(gw, ":")[1]) {
 					subnetsNatIp[cidr] = strings.TrimSpace(strings.Split(gw, ":")[1])
@@ -0,0 +1,247 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ovs"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func (c *Controller) enqueueAddMirrorSession(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+
+	klog.V(3).Infof("enqueue add mirror session %s", key)
+	c.mirrorSessionQueue.Add(key)
+}
+
+func (c *Controller) enqueueUpdateMirrorSession(old, new interface{}) {
+	oldSession := old.(*kubeovnv1.MirrorSession)
+	newSession := new.(*kubeovnv1.MirrorSession)
+	// status of the session is updated by every node
+	if reflect.DeepEqual(oldSession.Spec, newSession.Spec) {
+		return
+	}
+
+	klog.V(3).Infof("enqueue update mirror session %s", newSession.Name)
+	c.mirrorSessionQueue.Add(newSession.Name)
+}
+
+func (c *Controller) enqueueDeleteMirrorSession(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+
+	klog.V(3).Infof("enqueue delete mirror session %s", key)
+	c.mirrorSessionQueue.Add(key)
+}
+
+// resyncMirrorSessions enqueue all mirror sessions to pick up pods created or deleted on the node,
+// sessions left in ovs without the custom resource are enqueued to be deleted
+func (c *Controller) resyncMirrorSessions() {
+	sessions, err := c.mirrorSessionsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list mirror sessions, %v", err)
+		return
+	}
+	for _, session := range sessions {
+		c.mirrorSessionQueue.Add(session.Name)
+	}
+
+	names, err := ovs.ListMirrorSessions()
+	if err != nil {
+		klog.Errorf("failed to list mirror sessions in ovs, %v", err)
+		return
+	}
+	for _, name := range names {
+		c.mirrorSessionQueue.Add(name)
+	}
+}
+
+func (c *Controller) runMirrorSessionWorker() {
+	for c.processNextMirrorSessionWorkItem() {
+	}
+}
+
+func (c *Controller) processNextMirrorSessionWorkItem() bool {
+	obj, shutdown := c.mirrorSessionQueue.Get()
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.mirrorSessionQueue.Done(obj)
+		var key string
+		var ok bool
+		if key, ok = obj.(string); !ok {
+			c.mirrorSessionQueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+			return nil
+		}
+		if err := c.handleMirrorSession(key); err != nil {
+			c.mirrorSessionQueue.AddRateLimited(key)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
+		}
+		c.mirrorSessionQueue.Forget(obj)
+		return nil
+	}(obj)
+
+	if err != nil {
+		utilruntime.HandleError(err)
+		return true
+	}
+	return true
+}
+
+// handleMirrorSession sync the ovs mirror of the session with the pods selected on this node
+// and report the result in the status of the node
+func (c *Controller) handleMirrorSession(key string) error {
+	session, err := c.mirrorSessionsLister.Get(key)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			klog.Infof("delete mirror session %s", key)
+			return ovs.DeleteMirrorSession(key)
+		}
+		return err
+	}
+
+	if err = util.ValidateMirrorSession(session); err != nil {
+		klog.Errorf("invalid mirror session %s, %v", key, err)
+		// remove the mirror configured by the former spec
+		if err := ovs.DeleteMirrorSession(key); err != nil {
+			klog.Errorf("failed to delete mirror session %s, %v", key, err)
+			return err
+		}
+		return c.patchMirrorSessionNodeStatus(session, kubeovnv1.MirrorSessionNodeStatus{Message: err.Error()})
+	}
+
+	ports, err := c.mirrorSessionPorts(session)
+	if err != nil {
+		klog.Errorf("failed to get ports of mirror session %s, %v", key, err)
+		return err
+	}
+
+	var srcPorts, dstPorts []string
+	direction := session.Spec.Direction
+	// packets sent by the pod are received by its ovs port and vice versa
+	if direction != kubeovnv1.MirrorDirectionIngress {
+		srcPorts = ports
+	}
+	if direction != kubeovnv1.MirrorDirectionEgress {
+		dstPorts = ports
+	}
+
+	if err = ovs.SetMirrorSession(key, session.Spec.Destination, session.Spec.Snaplen, srcPorts, dstPorts); err != nil {
+		klog.Error(err)
+		if patchErr := c.patchMirrorSessionNodeStatus(session, kubeovnv1.MirrorSessionNodeStatus{Message: err.Error()}); patchErr != nil {
+			return patchErr
+		}
+		return err
+	}
+
+	return c.patchMirrorSessionNodeStatus(session, kubeovnv1.MirrorSessionNodeStatus{Ready: true, Ports: len(ports)})
+}
+
+// mirrorSessionPorts returns uuids of ovs ports of the pods on this node selected by the session
+func (c *Controller) mirrorSessionPorts(session *kubeovnv1.MirrorSession) ([]string, error) {
+	var selector labels.Selector
+	if session.Spec.PodSelector != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(session.Spec.PodSelector); err != nil {
+			return nil, err
+		}
+	}
+
+	pods, err := c.podsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list pods, %v", err)
+		return nil, err
+	}
+
+	var ports []string
+	for _, pod := range pods {
+		if pod.Spec.HostNetwork ||
+			pod.DeletionTimestamp != nil ||
+			pod.Annotations[util.AllocatedAnnotation] != "true" ||
+			!mirrorSessionSelectsPod(session, selector, pod) {
+			continue
+		}
+
+		ifaceID := ovs.PodNameToPortName(pod.Name, pod.Namespace, util.OvnProvider)
+		port, err := ovs.GetPortUUIDByIfaceID(ifaceID)
+		if err != nil {
+			klog.Errorf("failed to get ovs port of %s, %v", ifaceID, err)
+			return nil, err
+		}
+		// the port of the pod is not created yet
+		if port != "" {
+			ports = append(ports, port)
+		}
+	}
+	return ports, nil
+}
+
+func mirrorSessionSelectsPod(session *kubeovnv1.MirrorSession, selector labels.Selector, pod *v1.Pod) bool {
+	if util.ContainsString(session.Spec.Subnets, pod.Annotations[util.LogicalSwitchAnnotation]) ||
+		util.ContainsString(session.Spec.Namespaces, pod.Namespace) {
+		return true
+	}
+	return selector != nil && selector.Matches(labels.Set(pod.Labels))
+}
+
+// patchMirrorSessionNodeStatus update the status of this node only, statuses of other nodes are kept by merge patch,
+// statuses of nodes removed from the cluster are pruned since nobody else updates them
+func (c *Controller) patchMirrorSessionNodeStatus(session *kubeovnv1.MirrorSession, status kubeovnv1.MirrorSessionNodeStatus) error {
+	var staleNodes []string
+	for node := range session.Status.Nodes {
+		if node == c.config.NodeName {
+			continue
+		}
+		if _, err := c.nodesLister.Get(node); err != nil {
+			if !k8serrors.IsNotFound(err) {
+				klog.Errorf("failed to get node %s, %v", node, err)
+				return err
+			}
+			staleNodes = append(staleNodes, node)
+		}
+	}
+
+	if current, ok := session.Status.Nodes[c.config.NodeName]; ok && len(staleNodes) == 0 &&
+		current.Ready == status.Ready && current.Ports == status.Ports && current.Message == status.Message {
+		return nil
+	}
+
+	status.LastUpdateTime = metav1.Now()
+	// null values delete the statuses of the stale nodes in the merge patch
+	nodes := map[string]interface{}{c.config.NodeName: status}
+	for _, node := range staleNodes {
+		nodes[node] = nil
+	}
+	bytes, err := json.Marshal(map[string]interface{}{"status": map[string]interface{}{"nodes": nodes}})
+	if err != nil {
+		return err
+	}
+	if _, err = c.config.KubeOvnClient.KubeovnV1().MirrorSessions().Patch(context.Background(), session.Name, types.MergePatchType, bytes, metav1.PatchOptions{}, "status"); err != nil {
+		klog.Errorf("failed to patch status of mirror session %s, %v", session.Name, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package daemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	listerv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	kubeovnfake "github.com/kubeovn/kube-ovn/pkg/client/clientset/versioned/fake"
+)
+
+func TestPatchMirrorSessionNodeStatus(t *testing.T) {
+	session := &kubeovnv1.MirrorSession{
+		ObjectMeta: metav1.ObjectMeta{Name: "session1"},
+		Status: kubeovnv1.MirrorSessionStatus{Nodes: map[string]kubeovnv1.MirrorSessionNodeStatus{
+			"node1": {Ready: true, Ports: 1},
+			"node2": {Ready: true, Ports: 2},
+			"node3": {Ready: true, Ports: 3},
+		}},
+	}
+	nodeIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, name := range []string{"node1", "node2"} {
+		require.NoError(t, nodeIndexer.Add(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}))
+	}
+	kubeOvnClient := kubeovnfake.NewSimpleClientset()
+	session, err := kubeOvnClient.KubeovnV1().MirrorSessions().Create(context.Background(), session, metav1.CreateOptions{})
+	require.NoError(t, err)
+	c := &Controller{
+		config:      &Configuration{NodeName: "node1", KubeOvnClient: kubeOvnClient},
+		nodesLister: listerv1.NewNodeLister(nodeIndexer),
+	}
+
+	// the unchanged status is patched to prune the status of the deleted node
+	require.NoError(t, c.patchMirrorSessionNodeStatus(session, kubeovnv1.MirrorSessionNodeStatus{Ready: true, Ports: 1}))
+	session, err = kubeOvnClient.KubeovnV1().MirrorSessions().Get(context.Background(), "session1", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, session.Status.Nodes, 2)
+	require.Equal(t, 1, session.Status.Nodes["node1"].Ports)
+	require.Equal(t, 2, session.Status.Nodes["node2"].Ports)
+
+	// nothing is patched if the status is unchanged and no node is deleted
+	actions := len(kubeOvnClient.Actions())
+	require.NoError(t, c.patchMirrorSessionNodeStatus(session, kubeovnv1.MirrorSessionNodeStatus{Ready: true, Ports: 1}))
+	require.Len(t, kubeOvnClient.Actions(), actions)
+}
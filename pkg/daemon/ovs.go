@@ -352,6 +352,81 @@ func configureContainerNic(podName, nicName, ifName string, ipAddr, gateway stri
 	})
 }
 
+// addContainerNicAddress add the addresses missing on the container nic holding existingIP,
+// which happens when the subnet of a running pod is upgraded to dual stack
+func addContainerNicAddress(netnsPath, existingIP, ipAddr, gateway string, isDefaultRoute bool) error {
+	return ns.WithNetNSPath(netnsPath, func(_ ns.NetNS) error {
+		addrs, err := netlink.AddrList(nil, netlink.FAMILY_ALL)
+		if err != nil {
+			return fmt.Errorf("failed to list addresses: %v", err)
+		}
+		var link netlink.Link
+		existing := make(map[string]bool)
+		for _, addr := range addrs {
+			if addr.IP.String() == existingIP {
+				if link, err = netlink.LinkByIndex(addr.LinkIndex); err != nil {
+					return fmt.Errorf("failed to get link of address %s: %v", existingIP, err)
+				}
+			}
+			existing[addr.IPNet.String()] = true
+		}
+		if link == nil {
+			return fmt.Errorf("no nic holds address %s", existingIP)
+		}
+
+		if util.CheckProtocol(ipAddr) == kubeovnv1.ProtocolDual {
+			value, err := sysctl.Sysctl("net.ipv6.conf.all.disable_ipv6")
+			if err != nil {
+				return fmt.Errorf("failed to get sysctl net.ipv6.conf.all.disable_ipv6: %v", err)
+			}
+			if value != "0" {
+				if _, err = sysctl.Sysctl("net.ipv6.conf.all.disable_ipv6", "0"); err != nil {
+					return fmt.Errorf("failed to enable ipv6 on all nic: %v", err)
+				}
+			}
+		}
+
+		gws := strings.Split(gateway, ",")
+		for _, ipStr := range strings.Split(ipAddr, ",") {
+			addr, err := netlink.ParseAddr(ipStr)
+			if err != nil {
+				return fmt.Errorf("can not parse address %s: %v", ipStr, err)
+			}
+			if existing[addr.IPNet.String()] || addr.IP.String() == existingIP {
+				continue
+			}
+			if err = netlink.AddrAdd(link, addr); err != nil {
+				return fmt.Errorf("can not add address %s to nic %s: %v", ipStr, link.Attrs().Name, err)
+			}
+			klog.Infof("add address %s to nic %s", ipStr, link.Attrs().Name)
+
+			if !isDefaultRoute {
+				continue
+			}
+			protocol := util.CheckProtocol(ipStr)
+			for _, gw := range gws {
+				if util.CheckProtocol(gw) != protocol {
+					continue
+				}
+				defaultNet := "0.0.0.0/0"
+				if protocol == kubeovnv1.ProtocolIPv6 {
+					defaultNet = "::/0"
+				}
+				_, dst, _ := net.ParseCIDR(defaultNet)
+				if err = netlink.RouteReplace(&netlink.Route{
+					LinkIndex: link.Attrs().Index,
+					Scope:     netlink.SCOPE_UNIVERSE,
+					Dst:       dst,
+					Gw:        net.ParseIP(gw),
+				}); err != nil {
+					return fmt.Errorf("failed to configure gateway %s: %v", gw, err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
 func waitNetworkReady(nic, ipAddr, gateway string, underlayGateway, verbose bool) error {
 	ips := strings.Split(ipAddr, ",")
 	for i, gw := range strings.Split(gateway, ",") {
@@ -0,0 +1,146 @@
+package ipam
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"time"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+// Allocator chooses the address to allocate from the free ranges of a subnet,
+// it is always called with the subnet mutex held
+type Allocator interface {
+	// Allocate returns an address in iprl which is not skipped, or an empty IP if there is none
+	Allocate(iprl IPRangeList, skip func(IP) bool) IP
+	// Release is called after ip is returned to the subnet
+	Release(ip IP)
+}
+
+// NewAllocator returns the allocator of the strategy, an empty strategy means sequential
+func NewAllocator(strategy string) (Allocator, error) {
+	switch strategy {
+	case "", kubeovnv1.AllocationStrategySequential:
+		return &sequentialAllocator{}, nil
+	case kubeovnv1.AllocationStrategyRandom:
+		return &randomAllocator{rand: rand.New(rand.NewSource(time.Now().UnixNano()))}, nil
+	case kubeovnv1.AllocationStrategyLeastRecentlyReleased:
+		return &lrrAllocator{released: map[IP]uint64{}}, nil
+	}
+	return nil, fmt.Errorf("unsupported allocation strategy %q", strategy)
+}
+
+// sequentialAllocator returns the first available address of the ranges
+type sequentialAllocator struct{}
+
+func (a *sequentialAllocator) Allocate(iprl IPRangeList, skip func(IP) bool) IP {
+	for _, ipr := range iprl {
+		for next := ipr.Start; !next.GreaterThan(ipr.End); next = next.Add(1) {
+			if !skip(next) {
+				return next
+			}
+		}
+	}
+	return ""
+}
+
+func (a *sequentialAllocator) Release(ip IP) {}
+
+// randomAllocator picks a random address of the ranges, the following
+// addresses are tried in order if the picked one is skipped
+type randomAllocator struct {
+	rand *rand.Rand
+}
+
+func (a *randomAllocator) Allocate(iprl IPRangeList, skip func(IP) bool) IP {
+	sizes := make([]*big.Int, len(iprl))
+	total := big.NewInt(0)
+	for i, ipr := range iprl {
+		sizes[i] = big.NewInt(0).Sub(util.Ip2BigInt(string(ipr.End)), util.Ip2BigInt(string(ipr.Start)))
+		sizes[i].Add(sizes[i], big.NewInt(1))
+		total.Add(total, sizes[i])
+	}
+	if total.Sign() <= 0 {
+		return ""
+	}
+
+	offset := big.NewInt(0).Rand(a.rand, total)
+	for i, ipr := range iprl {
+		if offset.Cmp(sizes[i]) >= 0 {
+			offset.Sub(offset, sizes[i])
+			continue
+		}
+
+		// rotate the ranges to start from the picked address
+		start := IP(util.BigInt2Ip(big.NewInt(0).Add(util.Ip2BigInt(string(ipr.Start)), offset)))
+		rotated := make(IPRangeList, 0, len(iprl)+1)
+		rotated = append(rotated, &IPRange{Start: start, End: ipr.End})
+		rotated = append(rotated, iprl[i+1:]...)
+		rotated = append(rotated, iprl[:i]...)
+		if start != ipr.Start {
+			rotated = append(rotated, &IPRange{Start: ipr.Start, End: start.Sub(1)})
+		}
+		return (&sequentialAllocator{}).Allocate(rotated, skip)
+	}
+	return ""
+}
+
+func (a *randomAllocator) Release(ip IP) {}
+
+// lrrAllocator prefers addresses which have never been released,
+// and then the address released least recently
+type lrrAllocator struct {
+	seq      uint64
+	released map[IP]uint64
+}
+
+func (a *lrrAllocator) Allocate(iprl IPRangeList, skip func(IP) bool) IP {
+	ip := (&sequentialAllocator{}).Allocate(iprl, func(ip IP) bool {
+		if _, ok := a.released[ip]; ok {
+			return true
+		}
+		return skip(ip)
+	})
+	if ip == "" {
+		var oldest uint64
+		for releasedIP, seq := range a.released {
+			if (ip == "" || seq < oldest) && iprl.Contains(releasedIP) && !skip(releasedIP) {
+				ip, oldest = releasedIP, seq
+			}
+		}
+	}
+	if ip != "" {
+		delete(a.released, ip)
+	}
+	return ip
+}
+
+func (a *lrrAllocator) Release(ip IP) {
+	a.seq++
+	a.released[ip] = a.seq
+}
+
+func (subnet *Subnet) allocationStrategy() string {
+	if subnet.AllocationStrategy == "" {
+		return kubeovnv1.AllocationStrategySequential
+	}
+	return subnet.AllocationStrategy
+}
+
+// SetAllocationStrategy replaces the allocator of the subnet if the strategy is changed
+func (subnet *Subnet) SetAllocationStrategy(strategy string) error {
+	subnet.mutex.Lock()
+	defer subnet.mutex.Unlock()
+
+	if strategy == subnet.AllocationStrategy {
+		return nil
+	}
+	allocator, err := NewAllocator(strategy)
+	if err != nil {
+		return err
+	}
+	subnet.AllocationStrategy, subnet.Allocator = strategy, allocator
+	return nil
+}
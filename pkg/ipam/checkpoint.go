@@ -0,0 +1,316 @@
+package ipam
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+const CheckpointVersion = 1
+
+// Checkpoint is a snapshot of the IPAM state used to speed up controller startup
+type Checkpoint struct {
+	Version int                 `json:"version"`
+	Time    time.Time           `json:"time"`
+	Subnets []*SubnetCheckpoint `json:"subnets"`
+	// Objects records the uid and resource version of the pods, IP CRs and nodes whose
+	// addresses are in the checkpoint, only objects changed since then are replayed on restore
+	Objects map[string]string `json:"objects,omitempty"`
+}
+
+type SubnetCheckpoint struct {
+	Name             string              `json:"name"`
+	CIDR             string              `json:"cidr"`
+	V4FreeIPList     []string            `json:"v4Free,omitempty"`
+	V4ReleasedIPList []string            `json:"v4Released,omitempty"`
+	V4ReservedIPList []string            `json:"v4Reserved,omitempty"`
+	V4NicToIP        map[string]IP       `json:"v4NicToIP,omitempty"`
+	V4IPToPod        map[IP]string       `json:"v4IPToPod,omitempty"`
+	V6FreeIPList     []string            `json:"v6Free,omitempty"`
+	V6ReleasedIPList []string            `json:"v6Released,omitempty"`
+	V6ReservedIPList []string            `json:"v6Reserved,omitempty"`
+	V6NicToIP        map[string]IP       `json:"v6NicToIP,omitempty"`
+	V6IPToPod        map[IP]string       `json:"v6IPToPod,omitempty"`
+	NicToMac         map[string]string   `json:"nicToMac,omitempty"`
+	PodToNicList     map[string][]string `json:"podToNicList,omitempty"`
+	NicToOwner       map[string]string   `json:"nicToOwner,omitempty"`
+	ReleasedIPs      map[IP]*ReleasedIP  `json:"releasedIPs,omitempty"`
+}
+
+func encodeIPRangeList(iprl IPRangeList) []string {
+	result := make([]string, 0, len(iprl))
+	for _, ipr := range iprl {
+		if ipr.Start == ipr.End {
+			result = append(result, string(ipr.Start))
+		} else {
+			result = append(result, fmt.Sprintf("%s..%s", ipr.Start, ipr.End))
+		}
+	}
+	return result
+}
+
+func copyNicToIP(m map[string]IP) map[string]IP {
+	result := make(map[string]IP, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
+
+func copyIPToPod(m map[IP]string) map[IP]string {
+	result := make(map[IP]string, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
+
+func (subnet *Subnet) cidrString() string {
+	var cidrs []string
+	if subnet.V4CIDR != nil {
+		cidrs = append(cidrs, subnet.V4CIDR.String())
+		for _, cidr := range subnet.V4ExtraCIDRs {
+			cidrs = append(cidrs, cidr.String())
+		}
+	}
+	if subnet.V6CIDR != nil {
+		cidrs = append(cidrs, subnet.V6CIDR.String())
+		for _, cidr := range subnet.V6ExtraCIDRs {
+			cidrs = append(cidrs, cidr.String())
+		}
+	}
+	return strings.Join(cidrs, ",")
+}
+
+func (subnet *Subnet) checkpoint() *SubnetCheckpoint {
+	subnet.mutex.RLock()
+	defer subnet.mutex.RUnlock()
+
+	// free addresses of node blocks are saved as free addresses of the subnet,
+	// they are moved to the blocks again after the checkpoint is restored
+	v4Free, v6Free := subnet.V4FreeIPList, subnet.V6FreeIPList
+	for _, block := range subnet.NodeBlocks {
+		block.mutex.Lock()
+		v4Free = unionIPRangeList(v4Free, block.v4Free)
+		v6Free = unionIPRangeList(v6Free, block.v6Free)
+		block.mutex.Unlock()
+	}
+
+	cp := &SubnetCheckpoint{
+		Name:             subnet.Name,
+		CIDR:             subnet.cidrString(),
+		V4FreeIPList:     encodeIPRangeList(v4Free),
+		V4ReleasedIPList: encodeIPRangeList(subnet.V4ReleasedIPList),
+		V4ReservedIPList: encodeIPRangeList(subnet.V4ReservedIPList),
+		V4NicToIP:        copyNicToIP(subnet.V4NicToIP),
+		V4IPToPod:        copyIPToPod(subnet.V4IPToPod),
+		V6FreeIPList:     encodeIPRangeList(v6Free),
+		V6ReleasedIPList: encodeIPRangeList(subnet.V6ReleasedIPList),
+		V6ReservedIPList: encodeIPRangeList(subnet.V6ReservedIPList),
+		V6NicToIP:        copyNicToIP(subnet.V6NicToIP),
+		V6IPToPod:        copyIPToPod(subnet.V6IPToPod),
+		NicToMac:         make(map[string]string, len(subnet.NicToMac)),
+		PodToNicList:     make(map[string][]string, len(subnet.PodToNicList)),
+		NicToOwner:       make(map[string]string, len(subnet.NicToOwner)),
+		ReleasedIPs:      make(map[IP]*ReleasedIP, len(subnet.ReleasedIPs)),
+	}
+	for nic, mac := range subnet.NicToMac {
+		cp.NicToMac[nic] = mac
+	}
+	for pod, nics := range subnet.PodToNicList {
+		if len(nics) != 0 {
+			cp.PodToNicList[pod] = append([]string{}, nics...)
+		}
+	}
+	for nic, owner := range subnet.NicToOwner {
+		cp.NicToOwner[nic] = owner
+	}
+	for ip, r := range subnet.ReleasedIPs {
+		cp.ReleasedIPs[ip] = &ReleasedIP{Owner: r.Owner, Time: r.Time}
+	}
+	return cp
+}
+
+// restore loads the checkpoint into the subnet, it returns false if the
+// checkpoint does not match the current cidr and exclude ips of the subnet
+func (subnet *Subnet) restore(cp *SubnetCheckpoint) bool {
+	subnet.mutex.Lock()
+	defer subnet.mutex.Unlock()
+
+	if cp.CIDR != subnet.cidrString() ||
+		!reflect.DeepEqual(cp.V4ReservedIPList, encodeIPRangeList(subnet.V4ReservedIPList)) ||
+		!reflect.DeepEqual(cp.V6ReservedIPList, encodeIPRangeList(subnet.V6ReservedIPList)) {
+		return false
+	}
+
+	subnet.V4FreeIPList = convertExcludeIps(cp.V4FreeIPList)
+	subnet.V4ReleasedIPList = convertExcludeIps(cp.V4ReleasedIPList)
+	subnet.V4NicToIP = copyNicToIP(cp.V4NicToIP)
+	subnet.V4IPToPod = copyIPToPod(cp.V4IPToPod)
+	subnet.V6FreeIPList = convertExcludeIps(cp.V6FreeIPList)
+	subnet.V6ReleasedIPList = convertExcludeIps(cp.V6ReleasedIPList)
+	subnet.V6NicToIP = copyNicToIP(cp.V6NicToIP)
+	subnet.V6IPToPod = copyIPToPod(cp.V6IPToPod)
+	subnet.NicToMac = make(map[string]string, len(cp.NicToMac))
+	subnet.MacToPod = make(map[string]string, len(cp.NicToMac))
+	subnet.PodToNicList = make(map[string][]string, len(cp.PodToNicList))
+	for pod, nics := range cp.PodToNicList {
+		subnet.PodToNicList[pod] = append([]string{}, nics...)
+		for _, nic := range nics {
+			if mac, ok := cp.NicToMac[nic]; ok {
+				subnet.NicToMac[nic] = mac
+				subnet.MacToPod[mac] = pod
+			}
+		}
+	}
+	subnet.NicToOwner = make(map[string]string, len(cp.NicToOwner))
+	for nic, owner := range cp.NicToOwner {
+		subnet.NicToOwner[nic] = owner
+	}
+	subnet.ReleasedIPs = make(map[IP]*ReleasedIP, len(cp.ReleasedIPs))
+	if subnet.ReleasePolicy.enabled() {
+		for ip, r := range cp.ReleasedIPs {
+			subnet.ReleasedIPs[ip] = &ReleasedIP{Owner: r.Owner, Time: r.Time}
+		}
+	}
+	subnet.syncNodeBlocks()
+	return true
+}
+
+// releaseNicsExcept releases all addresses whose nic is not in the nics set
+func (subnet *Subnet) releaseNicsExcept(nics map[string]bool) int {
+	subnet.mutex.Lock()
+	defer subnet.mutex.Unlock()
+
+	var count int
+	for podName, nicList := range subnet.PodToNicList {
+		for _, nicName := range nicList {
+			if nics[nicName] {
+				continue
+			}
+			klog.Infof("release stale address of %s nic %s in subnet %s", podName, nicName, subnet.Name)
+			subnet.releaseAddr(podName, nicName)
+			subnet.popPodNic(podName, nicName)
+			count++
+		}
+	}
+	return count
+}
+
+// Checkpoint returns a snapshot of all subnets
+func (ipam *IPAM) Checkpoint() *Checkpoint {
+	ipam.mutex.RLock()
+	defer ipam.mutex.RUnlock()
+
+	cp := &Checkpoint{
+		Version: CheckpointVersion,
+		Time:    time.Now(),
+		Subnets: make([]*SubnetCheckpoint, 0, len(ipam.Subnets)),
+	}
+	for _, subnet := range ipam.Subnets {
+		cp.Subnets = append(cp.Subnets, subnet.checkpoint())
+	}
+	return cp
+}
+
+// RestoreCheckpoint loads the checkpoint into subnets which have been added
+// with the same cidr and exclude ips, names of the restored subnets are returned
+func (ipam *IPAM) RestoreCheckpoint(cp *Checkpoint) []string {
+	ipam.mutex.RLock()
+	defer ipam.mutex.RUnlock()
+
+	var restored []string
+	for _, s := range cp.Subnets {
+		subnet, ok := ipam.Subnets[s.Name]
+		if !ok {
+			continue
+		}
+		if !subnet.restore(s) {
+			klog.Infof("checkpoint of subnet %s does not match current spec, ignore it", s.Name)
+			continue
+		}
+		restored = append(restored, s.Name)
+	}
+	return restored
+}
+
+// ReleaseNicsExcept releases addresses of nics which are not in the nics set and returns the released count
+func (ipam *IPAM) ReleaseNicsExcept(subnetName string, nics map[string]bool) int {
+	ipam.mutex.RLock()
+	defer ipam.mutex.RUnlock()
+
+	subnet, ok := ipam.Subnets[subnetName]
+	if !ok {
+		return 0
+	}
+	return subnet.releaseNicsExcept(nics)
+}
+
+// NicAddressConflict returns true if the nic has been assigned addresses other than ip
+func (ipam *IPAM) NicAddressConflict(subnetName, nicName, ip string) bool {
+	ipam.mutex.RLock()
+	defer ipam.mutex.RUnlock()
+
+	subnet, ok := ipam.Subnets[subnetName]
+	if !ok {
+		return false
+	}
+
+	subnet.mutex.RLock()
+	defer subnet.mutex.RUnlock()
+	ips := strings.Split(ip, ",")
+	if v4IP, ok := subnet.V4NicToIP[nicName]; ok && !util.ContainsString(ips, string(v4IP)) {
+		return true
+	}
+	if v6IP, ok := subnet.V6NicToIP[nicName]; ok && !util.ContainsString(ips, string(v6IP)) {
+		return true
+	}
+	return false
+}
+
+// Marshal encodes the checkpoint into gzipped json
+func (cp *Checkpoint) Marshal() ([]byte, error) {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err = w.Write(data); err != nil {
+		return nil, err
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func UnmarshalCheckpoint(data []byte) (*Checkpoint, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	raw, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	cp := &Checkpoint{}
+	if err = json.Unmarshal(raw, cp); err != nil {
+		return nil, err
+	}
+	if cp.Version != CheckpointVersion {
+		return nil, fmt.Errorf("unsupported checkpoint version %d", cp.Version)
+	}
+	return cp, nil
+}
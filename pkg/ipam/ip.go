@@ -89,6 +89,27 @@ func splitIPRangeList(iprl IPRangeList, ip IP) (bool, IPRangeList) {
 	return split, newIPRangeList
 }
 
+// takeIPFromRangeList removes ip from the range containing it,
+// the remaining parts of the range are appended to the end of the list
+func takeIPFromRangeList(iprl IPRangeList, ip IP) IPRangeList {
+	for idx, ipr := range iprl {
+		if !ipr.IPExist(ip) {
+			continue
+		}
+		part1 := &IPRange{Start: ipr.Start, End: ip.Sub(1)}
+		part2 := &IPRange{Start: ip.Add(1), End: ipr.End}
+		iprl = append(iprl[:idx], iprl[idx+1:]...)
+		if !part1.Start.GreaterThan(part1.End) {
+			iprl = append(iprl, part1)
+		}
+		if !part2.Start.GreaterThan(part2.End) {
+			iprl = append(iprl, part2)
+		}
+		break
+	}
+	return iprl
+}
+
 func mergeIPRangeList(iprl IPRangeList, ip IP) (bool, IPRangeList) {
 	insertIPRangeList := []*IPRange{}
 	inserted := false
@@ -200,3 +221,48 @@ func splitRange(a, b *IPRange) IPRangeList {
 	}
 	return results
 }
+
+// Intersect returns the ranges contained by both iprl and b
+func (iprl IPRangeList) Intersect(b IPRangeList) IPRangeList {
+	result := IPRangeList{}
+	for _, x := range iprl {
+		for _, y := range b {
+			start, end := x.Start, x.End
+			if y.Start.GreaterThan(start) {
+				start = y.Start
+			}
+			if y.End.LessThan(end) {
+				end = y.End
+			}
+			if !start.GreaterThan(end) {
+				result = append(result, &IPRange{Start: start, End: end})
+			}
+		}
+	}
+	return result
+}
+
+// Exclude returns the ranges in iprl but not in b
+func (iprl IPRangeList) Exclude(b IPRangeList) IPRangeList {
+	result := iprl
+	for _, y := range b {
+		newResult := IPRangeList{}
+		for _, x := range result {
+			if iprl := splitRange(x, y); iprl != nil {
+				newResult = append(newResult, iprl...)
+			}
+		}
+		result = newResult
+	}
+	return result
+}
+
+func (iprl IPRangeList) Count() float64 {
+	count := big.NewInt(0)
+	for _, ipr := range iprl {
+		size := big.NewInt(0).Sub(util.Ip2BigInt(string(ipr.End)), util.Ip2BigInt(string(ipr.Start)))
+		count.Add(count, size.Add(size, big.NewInt(1)))
+	}
+	v, _ := new(big.Float).SetInt(count).Float64()
+	return v
+}
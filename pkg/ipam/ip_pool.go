@@ -0,0 +1,186 @@
+package ipam
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+type IPPool struct {
+	Name  string
+	V4IPs IPRangeList
+	V6IPs IPRangeList
+}
+
+// NewIPPool parses the addresses of an ip pool, each item can be a single ip,
+// a range like 10.0.0.10..10.0.0.20 or a cidr
+func NewIPPool(name string, ips []string) (*IPPool, error) {
+	pool := &IPPool{Name: name, V4IPs: IPRangeList{}, V6IPs: IPRangeList{}}
+	for _, s := range ips {
+		s = strings.TrimSpace(s)
+		var ipr *IPRange
+		switch {
+		case strings.Contains(s, ".."):
+			parts := strings.Split(s, "..")
+			if len(parts) != 2 || net.ParseIP(parts[0]) == nil || net.ParseIP(parts[1]) == nil ||
+				util.CheckProtocol(parts[0]) != util.CheckProtocol(parts[1]) {
+				return nil, fmt.Errorf("%s in ip pool %s is not a valid ip range", s, name)
+			}
+			ipr = &IPRange{Start: IP(parts[0]), End: IP(parts[1])}
+			if ipr.Start.GreaterThan(ipr.End) {
+				return nil, fmt.Errorf("%s in ip pool %s is not a valid ip range", s, name)
+			}
+		case strings.Contains(s, "/"):
+			_, cidr, err := net.ParseCIDR(s)
+			if err != nil {
+				return nil, fmt.Errorf("%s in ip pool %s is not a valid cidr", s, name)
+			}
+			ipr = &IPRange{Start: IP(cidr.IP.String()), End: IP(util.SubnetBroadcast(cidr.String()))}
+		default:
+			if net.ParseIP(s) == nil {
+				return nil, fmt.Errorf("%s in ip pool %s is not a valid address", s, name)
+			}
+			ipr = &IPRange{Start: IP(s), End: IP(s)}
+		}
+
+		if util.CheckProtocol(string(ipr.Start)) == kubeovnv1.ProtocolIPv4 {
+			pool.V4IPs = append(pool.V4IPs, ipr)
+		} else {
+			pool.V6IPs = append(pool.V6IPs, ipr)
+		}
+	}
+	return pool, nil
+}
+
+func (subnet *Subnet) AddOrUpdateIPPool(name string, ips []string) error {
+	pool, err := NewIPPool(name, ips)
+	if err != nil {
+		return err
+	}
+
+	subnet.mutex.Lock()
+	defer subnet.mutex.Unlock()
+
+	if err = subnet.checkRanges(name, pool.V4IPs, pool.V6IPs); err != nil {
+		return err
+	}
+	subnet.IPPools[name] = pool
+	return nil
+}
+
+func (subnet *Subnet) RemoveIPPool(name string) {
+	subnet.mutex.Lock()
+	defer subnet.mutex.Unlock()
+	delete(subnet.IPPools, name)
+}
+
+// IPPoolStatistics returns the available and using address count of an ip pool
+func (subnet *Subnet) IPPoolStatistics(name string) (v4Available, v4Using, v6Available, v6Using float64, err error) {
+	subnet.mutex.RLock()
+	defer subnet.mutex.RUnlock()
+
+	pool, ok := subnet.IPPools[name]
+	if !ok {
+		return 0, 0, 0, 0, ErrNoAvailable
+	}
+	v4Available, v4Using, v6Available, v6Using = subnet.rangeStatistics(pool.V4IPs, pool.V6IPs)
+	return
+}
+
+func (subnet *Subnet) rangeStatistics(v4IPs, v6IPs IPRangeList) (v4Available, v4Using, v6Available, v6Using float64) {
+	v4Available = subnet.V4FreeIPList.Intersect(v4IPs).Count() + subnet.V4ReleasedIPList.Intersect(v4IPs).Count()
+	v6Available = subnet.V6FreeIPList.Intersect(v6IPs).Count() + subnet.V6ReleasedIPList.Intersect(v6IPs).Count()
+	for ip := range subnet.V4IPToPod {
+		if v4IPs.Contains(ip) {
+			v4Using++
+		}
+	}
+	for ip := range subnet.V6IPToPod {
+		if v6IPs.Contains(ip) {
+			v6Using++
+		}
+	}
+	return
+}
+
+// checkPoolOwnership checks whether the static address is available to pods using the named pool,
+// addresses of a pool are only available to pods using it, and pods using a pool only get
+// addresses of the pool for the families covered by it
+func (subnet *Subnet) checkPoolOwnership(ip IP, poolName string) error {
+	v4 := net.ParseIP(string(ip)).To4() != nil
+	if poolName != "" {
+		if _, ok := subnet.IPPools[poolName]; !ok {
+			return ErrNoAvailable
+		}
+	}
+	for name, pool := range subnet.IPPools {
+		ips := pool.V4IPs
+		if !v4 {
+			ips = pool.V6IPs
+		}
+		if name == poolName {
+			if len(ips) != 0 && !ips.Contains(ip) {
+				klog.Errorf("address %s is out of ip pool %s", ip, name)
+				return ErrOutOfRange
+			}
+		} else if ips.Contains(ip) {
+			klog.Errorf("address %s belongs to ip pool %s", ip, name)
+			return ErrOutOfRange
+		}
+	}
+	return nil
+}
+
+// filterV4Pool returns the part of iprl which can be allocated from the pool,
+// addresses of all named pools and node blocks are excluded if no pool is specified
+// or the pool has no v4 address
+func (subnet *Subnet) filterV4Pool(iprl IPRangeList, poolName string) (IPRangeList, error) {
+	if pool, ok := subnet.IPPools[poolName]; ok && len(pool.V4IPs) == 0 {
+		poolName = ""
+	}
+	if poolName == "" {
+		if len(subnet.IPPools) == 0 && len(subnet.NodeBlocks) == 0 {
+			return iprl, nil
+		}
+		for _, pool := range subnet.IPPools {
+			iprl = iprl.Exclude(pool.V4IPs)
+		}
+		for _, block := range subnet.NodeBlocks {
+			iprl = iprl.Exclude(block.V4IPs)
+		}
+		return iprl, nil
+	}
+	pool, ok := subnet.IPPools[poolName]
+	if !ok {
+		return nil, ErrNoAvailable
+	}
+	return iprl.Intersect(pool.V4IPs), nil
+}
+
+func (subnet *Subnet) filterV6Pool(iprl IPRangeList, poolName string) (IPRangeList, error) {
+	if pool, ok := subnet.IPPools[poolName]; ok && len(pool.V6IPs) == 0 {
+		poolName = ""
+	}
+	if poolName == "" {
+		if len(subnet.IPPools) == 0 && len(subnet.NodeBlocks) == 0 {
+			return iprl, nil
+		}
+		for _, pool := range subnet.IPPools {
+			iprl = iprl.Exclude(pool.V6IPs)
+		}
+		for _, block := range subnet.NodeBlocks {
+			iprl = iprl.Exclude(block.V6IPs)
+		}
+		return iprl, nil
+	}
+	pool, ok := subnet.IPPools[poolName]
+	if !ok {
+		return nil, ErrNoAvailable
+	}
+	return iprl.Intersect(pool.V6IPs), nil
+}
@@ -2,7 +2,6 @@ package ipam
 
 import (
 	"errors"
-	"net"
 	"strings"
 	"sync"
 
@@ -38,6 +37,14 @@ func NewIPAM() *IPAM {
 }
 
 func (ipam *IPAM) GetRandomAddress(podName, nicName, subnetName string, skippedAddrs []string) (string, string, string, error) {
+	return ipam.GetRandomAddressFromPool(podName, nicName, subnetName, "", skippedAddrs)
+}
+
+func (ipam *IPAM) GetRandomAddressFromPool(podName, nicName, subnetName, poolName string, skippedAddrs []string) (string, string, string, error) {
+	return ipam.GetRandomAddressForOwner(podName, nicName, subnetName, poolName, "", skippedAddrs)
+}
+
+func (ipam *IPAM) GetRandomAddressForOwner(podName, nicName, subnetName, poolName, owner string, skippedAddrs []string) (string, string, string, error) {
 	ipam.mutex.RLock()
 	defer ipam.mutex.RUnlock()
 
@@ -46,7 +53,7 @@ func (ipam *IPAM) GetRandomAddress(podName, nicName, subnetName string, skippedA
 		return "", "", "", ErrNoAvailable
 	}
 
-	v4IP, v6IP, mac, err := subnet.GetRandomAddress(podName, nicName, skippedAddrs)
+	v4IP, v6IP, mac, err := subnet.GetRandomAddressForOwner(podName, nicName, poolName, owner, skippedAddrs)
 	klog.Infof("allocate v4 %s v6 %s mac %s for %s", v4IP, v6IP, mac, podName)
 	return string(v4IP), string(v6IP), mac, err
 }
@@ -64,6 +71,26 @@ func (ipam *IPAM) ReleaseIPAddressByPodNameAndNicName(podName, nicName, subnetNa
 }
 
 func (ipam *IPAM) GetStaticAddress(podName, nicName, ip, mac, subnetName string, checkConflict bool) (string, string, string, error) {
+	return ipam.GetStaticAddressForOwner(podName, nicName, ip, mac, subnetName, "", checkConflict)
+}
+
+// GetStaticAddressForOwner allocates the static addresses for the pod owned by owner,
+// the owner is recorded for the sticky reuse of the addresses after released
+func (ipam *IPAM) GetStaticAddressForOwner(podName, nicName, ip, mac, subnetName, owner string, checkConflict bool) (string, string, string, error) {
+	return ipam.getStaticAddress(podName, ip, mac, subnetName, func(subnet *Subnet, ip IP, mac string) (IP, string, error) {
+		return subnet.GetStaticAddressForOwner(podName, nicName, owner, ip, mac, false, checkConflict)
+	})
+}
+
+// GetStaticAddressFromPool allocates the static addresses for the pod using the named ip pool,
+// addresses of other pools are not available to the pod. The pod does not use any pool if poolName is empty
+func (ipam *IPAM) GetStaticAddressFromPool(podName, nicName, ip, mac, subnetName, poolName, owner string, checkConflict bool) (string, string, string, error) {
+	return ipam.getStaticAddress(podName, ip, mac, subnetName, func(subnet *Subnet, ip IP, mac string) (IP, string, error) {
+		return subnet.GetStaticAddressFromPool(podName, nicName, poolName, owner, ip, mac, checkConflict)
+	})
+}
+
+func (ipam *IPAM) getStaticAddress(podName, ip, mac, subnetName string, allocate func(subnet *Subnet, ip IP, mac string) (IP, string, error)) (string, string, string, error) {
 	ipam.mutex.RLock()
 	defer ipam.mutex.RUnlock()
 	if subnet, ok := ipam.Subnets[subnetName]; !ok {
@@ -73,7 +100,7 @@ func (ipam *IPAM) GetStaticAddress(podName, nicName, ip, mac, subnetName string,
 		var err error
 		var ipAddr IP
 		for _, ipStr := range strings.Split(ip, ",") {
-			ipAddr, mac, err = subnet.GetStaticAddress(podName, nicName, IP(ipStr), mac, false, checkConflict)
+			ipAddr, mac, err = allocate(subnet, IP(ipStr), mac)
 			if err != nil {
 				return "", "", "", err
 			}
@@ -112,10 +139,10 @@ func checkAndAppendIpsForDual(ips []IP, podName string, nicName string, subnet *
 	var err error
 	if util.CheckProtocol(string(ips[0])) == kubeovnv1.ProtocolIPv4 {
 		newIps = ips
-		_, ipAddr, _, err = subnet.getV6RandomAddress(podName, nicName, nil)
+		_, ipAddr, _, err = subnet.getV6RandomAddress(podName, nicName, "", podName, nil)
 		newIps = append(newIps, ipAddr)
 	} else if util.CheckProtocol(string(ips[0])) == kubeovnv1.ProtocolIPv6 {
-		ipAddr, _, _, err = subnet.getV4RandomAddress(podName, nicName, nil)
+		ipAddr, _, _, err = subnet.getV4RandomAddress(podName, nicName, "", podName, nil)
 		newIps = append(newIps, ipAddr)
 		newIps = append(newIps, ips...)
 	}
@@ -137,25 +164,11 @@ func (ipam *IPAM) AddOrUpdateSubnet(name, cidrStr string, excludeIps []string) e
 	ipam.mutex.Lock()
 	defer ipam.mutex.Unlock()
 
-	var v4cidrStr, v6cidrStr string
-	var cidrs []*net.IPNet
-	for _, cidrBlock := range strings.Split(cidrStr, ",") {
-		if _, cidr, err := net.ParseCIDR(cidrBlock); err != nil {
-			return ErrInvalidCIDR
-		} else {
-			cidrs = append(cidrs, cidr)
-		}
-	}
-	protocol := util.CheckProtocol(cidrStr)
-	switch protocol {
-	case kubeovnv1.ProtocolDual:
-		v4cidrStr = cidrs[0].String()
-		v6cidrStr = cidrs[1].String()
-	case kubeovnv1.ProtocolIPv4:
-		v4cidrStr = cidrs[0].String()
-	case kubeovnv1.ProtocolIPv6:
-		v6cidrStr = cidrs[0].String()
+	v4CIDRs, v6CIDRs, err := parseCIDRs(cidrStr)
+	if err != nil {
+		return err
 	}
+	protocol := cidrsProtocol(v4CIDRs, v6CIDRs)
 
 	// subnet.Spec.ExcludeIps contains both v4 and v6 addresses
 	v4ExcludeIps, v6ExcludeIps := util.SplitIpsByProtocol(excludeIps)
@@ -163,12 +176,9 @@ func (ipam *IPAM) AddOrUpdateSubnet(name, cidrStr string, excludeIps []string) e
 	if subnet, ok := ipam.Subnets[name]; ok {
 		subnet.Protocol = protocol
 		if protocol == kubeovnv1.ProtocolDual || protocol == kubeovnv1.ProtocolIPv4 {
-			_, cidr, _ := net.ParseCIDR(v4cidrStr)
-			subnet.V4CIDR = cidr
+			subnet.V4CIDR, subnet.V4ExtraCIDRs = v4CIDRs[0], v4CIDRs[1:]
 			subnet.V4ReservedIPList = convertExcludeIps(v4ExcludeIps)
-			firstIP, _ := util.FirstIP(v4cidrStr)
-			lastIP, _ := util.LastIP(v4cidrStr)
-			subnet.V4FreeIPList = IPRangeList{&IPRange{Start: IP(firstIP), End: IP(lastIP)}}
+			subnet.V4FreeIPList = cidrsToIPRangeList(v4CIDRs)
 			subnet.joinFreeWithReserve()
 			subnet.V4ReleasedIPList = IPRangeList{}
 			for nicName, ip := range subnet.V4NicToIP {
@@ -182,12 +192,9 @@ func (ipam *IPAM) AddOrUpdateSubnet(name, cidrStr string, excludeIps []string) e
 			}
 		}
 		if protocol == kubeovnv1.ProtocolDual || protocol == kubeovnv1.ProtocolIPv6 {
-			_, cidr, _ := net.ParseCIDR(v6cidrStr)
-			subnet.V6CIDR = cidr
+			subnet.V6CIDR, subnet.V6ExtraCIDRs = v6CIDRs[0], v6CIDRs[1:]
 			subnet.V6ReservedIPList = convertExcludeIps(v6ExcludeIps)
-			firstIP, _ := util.FirstIP(v6cidrStr)
-			lastIP, _ := util.LastIP(v6cidrStr)
-			subnet.V6FreeIPList = IPRangeList{&IPRange{Start: IP(firstIP), End: IP(lastIP)}}
+			subnet.V6FreeIPList = cidrsToIPRangeList(v6CIDRs)
 			subnet.joinFreeWithReserve()
 			subnet.V6ReleasedIPList = IPRangeList{}
 			for nicName, ip := range subnet.V6NicToIP {
@@ -200,6 +207,9 @@ func (ipam *IPAM) AddOrUpdateSubnet(name, cidrStr string, excludeIps []string) e
 				}
 			}
 		}
+		subnet.mutex.Lock()
+		subnet.syncNodeBlocks()
+		subnet.mutex.Unlock()
 		return nil
 	}
 
@@ -212,6 +222,172 @@ func (ipam *IPAM) AddOrUpdateSubnet(name, cidrStr string, excludeIps []string) e
 	return nil
 }
 
+func (ipam *IPAM) AddOrUpdateIPPool(subnetName, poolName string, ips []string) error {
+	ipam.mutex.RLock()
+	defer ipam.mutex.RUnlock()
+
+	subnet, ok := ipam.Subnets[subnetName]
+	if !ok {
+		return ErrNoAvailable
+	}
+	klog.Infof("add or update ip pool %s in subnet %s", poolName, subnetName)
+	if err := subnet.AddOrUpdateIPPool(poolName, ips); err != nil {
+		return err
+	}
+
+	// the ip pool may be moved from another subnet
+	for name, s := range ipam.Subnets {
+		if name != subnetName {
+			s.RemoveIPPool(poolName)
+		}
+	}
+	return nil
+}
+
+func (ipam *IPAM) RemoveIPPool(poolName string) {
+	ipam.mutex.RLock()
+	defer ipam.mutex.RUnlock()
+
+	klog.Infof("remove ip pool %s", poolName)
+	for _, subnet := range ipam.Subnets {
+		subnet.RemoveIPPool(poolName)
+	}
+}
+
+func (ipam *IPAM) IPPoolStatistics(subnetName, poolName string) (float64, float64, float64, float64, error) {
+	ipam.mutex.RLock()
+	defer ipam.mutex.RUnlock()
+
+	subnet, ok := ipam.Subnets[subnetName]
+	if !ok {
+		return 0, 0, 0, 0, ErrNoAvailable
+	}
+	return subnet.IPPoolStatistics(poolName)
+}
+
+func (ipam *IPAM) AddOrUpdateNodeBlock(subnetName, blockName, node string, ips []string) error {
+	ipam.mutex.RLock()
+	defer ipam.mutex.RUnlock()
+
+	subnet, ok := ipam.Subnets[subnetName]
+	if !ok {
+		return ErrNoAvailable
+	}
+	klog.Infof("add or update block %s of node %s in subnet %s", blockName, node, subnetName)
+	return subnet.AddOrUpdateNodeBlock(blockName, node, ips)
+}
+
+// LeaseNodeBlock leases a new block of size addresses to the node, ErrConflict
+// is returned if the block name has been used
+func (ipam *IPAM) LeaseNodeBlock(subnetName, blockName, node string, size int) ([]string, error) {
+	ipam.mutex.RLock()
+	defer ipam.mutex.RUnlock()
+
+	subnet, ok := ipam.Subnets[subnetName]
+	if !ok {
+		return nil, ErrNoAvailable
+	}
+	ips, err := subnet.LeaseNodeBlock(blockName, node, size)
+	if err != nil {
+		return nil, err
+	}
+	klog.Infof("lease block %s %v of subnet %s to node %s", blockName, ips, subnetName, node)
+	return ips, nil
+}
+
+// GetRandomAddressFromNodeBlocks allocates address from blocks of the node, the subnet is looked up
+// under the read lock of IPAM and addresses are taken under the locks of the blocks
+func (ipam *IPAM) GetRandomAddressFromNodeBlocks(podName, nicName, subnetName, node string, skippedAddrs []string) (string, string, string, error) {
+	ipam.mutex.RLock()
+	subnet, ok := ipam.Subnets[subnetName]
+	ipam.mutex.RUnlock()
+	if !ok {
+		return "", "", "", ErrNoAvailable
+	}
+
+	v4IP, v6IP, mac, err := subnet.GetRandomAddressFromNodeBlocks(podName, nicName, node, skippedAddrs)
+	return string(v4IP), string(v6IP), mac, err
+}
+
+func (ipam *IPAM) RemoveNodeBlock(blockName string) {
+	ipam.mutex.RLock()
+	defer ipam.mutex.RUnlock()
+
+	klog.Infof("remove node block %s", blockName)
+	for _, subnet := range ipam.Subnets {
+		subnet.RemoveNodeBlock(blockName)
+	}
+}
+
+func (ipam *IPAM) NodeBlockStatistics(subnetName, blockName string) (float64, float64, float64, float64, error) {
+	ipam.mutex.RLock()
+	defer ipam.mutex.RUnlock()
+
+	subnet, ok := ipam.Subnets[subnetName]
+	if !ok {
+		return 0, 0, 0, 0, ErrNoAvailable
+	}
+	return subnet.NodeBlockStatistics(blockName)
+}
+
+// NodeBlockIdle returns true if the block has no address in use and can be returned
+// without leaving less than minAvailable addresses to its node
+func (ipam *IPAM) NodeBlockIdle(subnetName, blockName string, minAvailable float64) bool {
+	ipam.mutex.RLock()
+	defer ipam.mutex.RUnlock()
+
+	subnet, ok := ipam.Subnets[subnetName]
+	if !ok {
+		return false
+	}
+	return subnet.NodeBlockIdle(blockName, minAvailable)
+}
+
+func (ipam *IPAM) NodeAvailableIPs(subnetName, node string) float64 {
+	ipam.mutex.RLock()
+	defer ipam.mutex.RUnlock()
+
+	subnet, ok := ipam.Subnets[subnetName]
+	if !ok {
+		return 0
+	}
+	return subnet.NodeAvailableIPs(node)
+}
+
+func (ipam *IPAM) SetReleasePolicy(subnetName string, policy ReleasePolicy) error {
+	ipam.mutex.RLock()
+	defer ipam.mutex.RUnlock()
+
+	subnet, ok := ipam.Subnets[subnetName]
+	if !ok {
+		return ErrNoAvailable
+	}
+	subnet.SetReleasePolicy(policy)
+	return nil
+}
+
+func (ipam *IPAM) SetAllocationStrategy(subnetName, strategy string) error {
+	ipam.mutex.RLock()
+	defer ipam.mutex.RUnlock()
+
+	subnet, ok := ipam.Subnets[subnetName]
+	if !ok {
+		return ErrNoAvailable
+	}
+	return subnet.SetAllocationStrategy(strategy)
+}
+
+func (ipam *IPAM) SetMacPolicy(subnetName, prefix, policy string) error {
+	ipam.mutex.RLock()
+	defer ipam.mutex.RUnlock()
+
+	subnet, ok := ipam.Subnets[subnetName]
+	if !ok {
+		return ErrNoAvailable
+	}
+	return subnet.SetMacPolicy(prefix, policy)
+}
+
 func (ipam *IPAM) DeleteSubnet(subnetName string) {
 	ipam.mutex.Lock()
 	defer ipam.mutex.Unlock()
@@ -242,6 +418,18 @@ func (ipam *IPAM) GetPodAddress(podName string) []*SubnetAddress {
 	return addresses
 }
 
+// GetSubnetAddresses returns a snapshot of free and allocated addresses of the subnet
+func (ipam *IPAM) GetSubnetAddresses(subnetName string) (*SubnetAddresses, error) {
+	ipam.mutex.RLock()
+	defer ipam.mutex.RUnlock()
+
+	subnet, ok := ipam.Subnets[subnetName]
+	if !ok {
+		return nil, ErrNoAvailable
+	}
+	return subnet.addresses(), nil
+}
+
 func (ipam *IPAM) ContainAddress(address string) bool {
 	ipam.mutex.RLock()
 	defer ipam.mutex.RUnlock()
@@ -274,3 +462,25 @@ func (ipam *IPAM) GetPodByIP(ip string, subnetName string) (podList []string) {
 		return subnet.GetPodByIP(ip)
 	}
 }
+
+// SubnetNics returns nics which have addresses in the subnet
+func (ipam *IPAM) SubnetNics(subnetName string) []string {
+	ipam.mutex.RLock()
+	defer ipam.mutex.RUnlock()
+
+	if subnet, ok := ipam.Subnets[subnetName]; ok {
+		return subnet.Nics()
+	}
+	return nil
+}
+
+// NamespaceNics returns nics of pods in the namespace which have addresses in the subnet
+func (ipam *IPAM) NamespaceNics(subnetName, namespace string) []string {
+	ipam.mutex.RLock()
+	defer ipam.mutex.RUnlock()
+
+	if subnet, ok := ipam.Subnets[subnetName]; ok {
+		return subnet.NamespaceNics(namespace)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package ipam
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func (subnet *Subnet) macPrefix() string {
+	if subnet.MacPrefix == "" {
+		return util.DefaultMacPrefix
+	}
+	return subnet.MacPrefix
+}
+
+// generateMac generates a mac address for the nic according to the mac policy of the subnet,
+// a random one is generated if the mac derived from ip is used by another pod
+func (subnet *Subnet) generateMac(podName, nicName string, ip IP) string {
+	prefix := subnet.macPrefix()
+	if subnet.MacPolicy == kubeovnv1.MacPolicyIPDerived && ip != "" {
+		mac := util.IPDerivedMac(prefix, string(ip))
+		if p, ok := subnet.MacToPod[mac]; !ok || p == podName {
+			subnet.MacToPod[mac] = podName
+			subnet.NicToMac[nicName] = mac
+			return mac
+		}
+		klog.Warningf("mac %s derived from ip %s is used by %s, generate a random one for %s", mac, ip, subnet.MacToPod[mac], podName)
+	}
+	for {
+		mac := util.GenerateMacWithPrefix(prefix)
+		if _, ok := subnet.MacToPod[mac]; !ok {
+			subnet.MacToPod[mac] = podName
+			subnet.NicToMac[nicName] = mac
+			return mac
+		}
+	}
+}
+
+// SetMacPolicy sets the prefix and policy used to generate mac addresses,
+// mac addresses already allocated are not changed
+func (subnet *Subnet) SetMacPolicy(prefix, policy string) error {
+	if prefix != "" {
+		if err := util.ValidateMacPrefix(prefix); err != nil {
+			return err
+		}
+	}
+	switch policy {
+	case "", kubeovnv1.MacPolicyRandom, kubeovnv1.MacPolicyIPDerived:
+	default:
+		return fmt.Errorf("unsupported mac policy %q", policy)
+	}
+
+	subnet.mutex.Lock()
+	defer subnet.mutex.Unlock()
+	subnet.MacPrefix, subnet.MacPolicy = strings.ToUpper(prefix), policy
+	return nil
+}
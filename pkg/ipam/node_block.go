@@ -0,0 +1,395 @@
+package ipam
+
+import (
+	"sort"
+	"sync"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+// NodeBlock is a set of addresses in the subnet leased to a node, the addresses of a block never
+// change and a new block replaces the old one when the block is updated
+type NodeBlock struct {
+	Name  string
+	Node  string
+	V4IPs IPRangeList
+	V6IPs IPRangeList
+
+	// free addresses of the block are owned by the block and taken under its lock rather than the
+	// lock of the subnet, they are moved out of the subnet when the block is leased and given back
+	// when the block is returned. The lock of the block may be taken with the lock of the subnet
+	// held but not vice versa
+	mutex  sync.Mutex
+	v4Free IPRangeList
+	v6Free IPRangeList
+}
+
+// firstAddress returns the first address in iprl which is not skipped
+func firstAddress(iprl IPRangeList, skippedAddrs []string) IP {
+	for _, ipr := range iprl {
+		for ip := ipr.Start; !ip.GreaterThan(ipr.End); ip = ip.Add(1) {
+			if !util.ContainsString(skippedAddrs, string(ip)) {
+				return ip
+			}
+		}
+	}
+	return ""
+}
+
+// take takes a free address of the protocol out of the block,
+// an empty address is returned if no address of the block is available
+func (block *NodeBlock) take(v4 bool, skippedAddrs []string) IP {
+	block.mutex.Lock()
+	defer block.mutex.Unlock()
+
+	free := &block.v6Free
+	if v4 {
+		free = &block.v4Free
+	}
+	ip := firstAddress(*free, skippedAddrs)
+	if ip != "" {
+		*free = takeIPFromRangeList(*free, ip)
+	}
+	return ip
+}
+
+// remove removes the address from the free addresses of the block, false is returned if it is not free
+func (block *NodeBlock) remove(ip IP, v4 bool) bool {
+	block.mutex.Lock()
+	defer block.mutex.Unlock()
+
+	free := &block.v6Free
+	if v4 {
+		free = &block.v4Free
+	}
+	split, newFree := splitIPRangeList(*free, ip)
+	if split {
+		*free = newFree
+	}
+	return split
+}
+
+// put gives the address back to the free addresses of the block
+func (block *NodeBlock) put(ip IP, v4 bool) {
+	block.mutex.Lock()
+	defer block.mutex.Unlock()
+
+	free := &block.v6Free
+	if v4 {
+		free = &block.v4Free
+	}
+	if merged, newFree := mergeIPRangeList(*free, ip); merged {
+		*free = newFree
+	}
+}
+
+// available returns the free address count of each protocol in the block
+func (block *NodeBlock) available() (v4Available, v6Available float64) {
+	block.mutex.Lock()
+	defer block.mutex.Unlock()
+	return block.v4Free.Count(), block.v6Free.Count()
+}
+
+// unionIPRangeList returns the ranges in either iprl or b
+func unionIPRangeList(iprl, b IPRangeList) IPRangeList {
+	result := append(IPRangeList{}, iprl...)
+	for _, ipr := range b {
+		for ip := ipr.Start; !ip.GreaterThan(ipr.End); ip = ip.Add(1) {
+			if merged, newResult := mergeIPRangeList(result, ip); merged {
+				result = newResult
+			}
+		}
+	}
+	return result
+}
+
+// leaseNodeBlock moves the free and released addresses of the subnet in the block to the block,
+// free addresses the block already has are dropped. It is called with the lock of the subnet held
+func (subnet *Subnet) leaseNodeBlock(block *NodeBlock) {
+	v4Free := append(subnet.V4FreeIPList.Intersect(block.V4IPs), subnet.V4ReleasedIPList.Intersect(block.V4IPs)...)
+	v6Free := append(subnet.V6FreeIPList.Intersect(block.V6IPs), subnet.V6ReleasedIPList.Intersect(block.V6IPs)...)
+	subnet.V4FreeIPList = subnet.V4FreeIPList.Exclude(block.V4IPs)
+	subnet.V4ReleasedIPList = subnet.V4ReleasedIPList.Exclude(block.V4IPs)
+	subnet.V6FreeIPList = subnet.V6FreeIPList.Exclude(block.V6IPs)
+	subnet.V6ReleasedIPList = subnet.V6ReleasedIPList.Exclude(block.V6IPs)
+
+	block.mutex.Lock()
+	defer block.mutex.Unlock()
+	block.v4Free, block.v6Free = v4Free, v6Free
+}
+
+// returnNodeBlock gives the free addresses of the block back to the subnet,
+// it is called with the lock of the subnet held
+func (subnet *Subnet) returnNodeBlock(block *NodeBlock) {
+	block.mutex.Lock()
+	v4Free, v6Free := block.v4Free, block.v6Free
+	block.v4Free, block.v6Free = nil, nil
+	block.mutex.Unlock()
+
+	subnet.V4FreeIPList = unionIPRangeList(subnet.V4FreeIPList, v4Free)
+	subnet.V6FreeIPList = unionIPRangeList(subnet.V6FreeIPList, v6Free)
+}
+
+// syncNodeBlocks leases all blocks again after the free and released addresses of the subnet are reset,
+// it is called with the lock of the subnet held
+func (subnet *Subnet) syncNodeBlocks() {
+	for _, block := range subnet.NodeBlocks {
+		subnet.leaseNodeBlock(block)
+	}
+}
+
+// nodeBlockOf returns the block containing the address, nil is returned if the address is out of all blocks
+func (subnet *Subnet) nodeBlockOf(ip IP, v4 bool) *NodeBlock {
+	for _, block := range subnet.NodeBlocks {
+		if (v4 && block.V4IPs.Contains(ip)) || (!v4 && block.V6IPs.Contains(ip)) {
+			return block
+		}
+	}
+	return nil
+}
+
+// putNodeBlockAddress gives the unused address taken from a block back to the block,
+// or to the subnet if the block has been returned. It is called with the lock of the subnet held
+func (subnet *Subnet) putNodeBlockAddress(ip IP, v4 bool) {
+	if block := subnet.nodeBlockOf(ip, v4); block != nil {
+		block.put(ip, v4)
+	} else if v4 {
+		subnet.V4FreeIPList = unionIPRangeList(subnet.V4FreeIPList, IPRangeList{&IPRange{Start: ip, End: ip}})
+	} else {
+		subnet.V6FreeIPList = unionIPRangeList(subnet.V6FreeIPList, IPRangeList{&IPRange{Start: ip, End: ip}})
+	}
+}
+
+// nodeBlocks returns blocks of the node sorted by name
+func (subnet *Subnet) nodeBlocks(node string) []*NodeBlock {
+	var blocks []*NodeBlock
+	for _, block := range subnet.NodeBlocks {
+		if block.Node == node {
+			blocks = append(blocks, block)
+		}
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].Name < blocks[j].Name })
+	return blocks
+}
+
+// GetRandomAddressFromNodeBlocks allocates addresses of the nic from blocks of the node,
+// ErrNoAvailable is returned if the blocks of the node are exhausted. Addresses are taken
+// under the locks of the blocks and the subnet is locked once to record the allocation
+func (subnet *Subnet) GetRandomAddressFromNodeBlocks(podName, nicName, node string, skippedAddrs []string) (IP, IP, string, error) {
+	subnet.mutex.RLock()
+	_, hasV4 := subnet.V4NicToIP[nicName]
+	_, hasV6 := subnet.V6NicToIP[nicName]
+	v4, v6 := subnet.V4CIDR != nil, subnet.V6CIDR != nil
+	blocks := subnet.nodeBlocks(node)
+	subnet.mutex.RUnlock()
+	if hasV4 || hasV6 {
+		// the nic has been allocated, e.g. the pod is handled again
+		return subnet.GetRandomAddress(podName, nicName, skippedAddrs)
+	}
+
+	var v4IP, v6IP IP
+	for _, block := range blocks {
+		if v4 && v4IP == "" {
+			v4IP = block.take(true, skippedAddrs)
+		}
+		if v6 && v6IP == "" {
+			v6IP = block.take(false, skippedAddrs)
+		}
+	}
+
+	subnet.mutex.Lock()
+	defer subnet.mutex.Unlock()
+	if (v4 && v4IP == "") || (v6 && v6IP == "") {
+		if v4IP != "" {
+			subnet.putNodeBlockAddress(v4IP, true)
+		}
+		if v6IP != "" {
+			subnet.putNodeBlockAddress(v6IP, false)
+		}
+		return "", "", "", ErrNoAvailable
+	}
+	if v4IP != "" {
+		subnet.assignV4Address(podName, nicName, podName, v4IP)
+	}
+	if v6IP != "" {
+		subnet.assignV6Address(podName, nicName, podName, v6IP)
+	}
+	klog.Infof("allocate v4 ip %s v6 ip %s for pod %s from ip blocks of node %s", v4IP, v6IP, podName, node)
+	return v4IP, v6IP, subnet.NicToMac[nicName], nil
+}
+
+// checkRanges returns an error if the ranges are out of the subnet or overlap
+// with ip pools or node blocks other than the one named name
+func (subnet *Subnet) checkRanges(name string, v4IPs, v6IPs IPRangeList) error {
+	for _, ipr := range v4IPs {
+		if !subnet.v4Contains(ipr.Start) || !subnet.v4Contains(ipr.End) {
+			return ErrOutOfRange
+		}
+	}
+	for _, ipr := range v6IPs {
+		if !subnet.v6Contains(ipr.Start) || !subnet.v6Contains(ipr.End) {
+			return ErrOutOfRange
+		}
+	}
+	for _, p := range subnet.IPPools {
+		if p.Name == name {
+			continue
+		}
+		if len(p.V4IPs.Intersect(v4IPs)) != 0 || len(p.V6IPs.Intersect(v6IPs)) != 0 {
+			return ErrConflict
+		}
+	}
+	for _, b := range subnet.NodeBlocks {
+		if b.Name == name {
+			continue
+		}
+		if len(b.V4IPs.Intersect(v4IPs)) != 0 || len(b.V6IPs.Intersect(v6IPs)) != 0 {
+			return ErrConflict
+		}
+	}
+	return nil
+}
+
+func (subnet *Subnet) AddOrUpdateNodeBlock(name, node string, ips []string) error {
+	pool, err := NewIPPool(name, ips)
+	if err != nil {
+		return err
+	}
+
+	subnet.mutex.Lock()
+	defer subnet.mutex.Unlock()
+
+	if err = subnet.checkRanges(name, pool.V4IPs, pool.V6IPs); err != nil {
+		return err
+	}
+	if old, ok := subnet.NodeBlocks[name]; ok {
+		subnet.returnNodeBlock(old)
+	}
+	block := &NodeBlock{Name: name, Node: node, V4IPs: pool.V4IPs, V6IPs: pool.V6IPs}
+	subnet.leaseNodeBlock(block)
+	subnet.NodeBlocks[name] = block
+	return nil
+}
+
+func (subnet *Subnet) RemoveNodeBlock(name string) {
+	subnet.mutex.Lock()
+	defer subnet.mutex.Unlock()
+	if block, ok := subnet.NodeBlocks[name]; ok {
+		subnet.returnNodeBlock(block)
+		delete(subnet.NodeBlocks, name)
+	}
+}
+
+// LeaseNodeBlock leases at most size free addresses of each protocol to the node,
+// addresses out of all ip pools and node blocks are used
+func (subnet *Subnet) LeaseNodeBlock(name, node string, size int) ([]string, error) {
+	subnet.mutex.Lock()
+	defer subnet.mutex.Unlock()
+
+	if _, ok := subnet.NodeBlocks[name]; ok {
+		return nil, ErrConflict
+	}
+
+	block := &NodeBlock{Name: name, Node: node}
+	if subnet.V4CIDR != nil {
+		free, _ := subnet.filterV4Pool(subnet.V4FreeIPList, "")
+		released, _ := subnet.filterV4Pool(subnet.V4ReleasedIPList, "")
+		block.V4IPs = takeRanges(append(append(IPRangeList{}, free...), released...), size)
+		if len(block.V4IPs) == 0 {
+			return nil, ErrNoAvailable
+		}
+	}
+	if subnet.V6CIDR != nil {
+		free, _ := subnet.filterV6Pool(subnet.V6FreeIPList, "")
+		released, _ := subnet.filterV6Pool(subnet.V6ReleasedIPList, "")
+		block.V6IPs = takeRanges(append(append(IPRangeList{}, free...), released...), size)
+		if len(block.V6IPs) == 0 {
+			return nil, ErrNoAvailable
+		}
+	}
+
+	subnet.leaseNodeBlock(block)
+	subnet.NodeBlocks[name] = block
+	return append(encodeIPRangeList(block.V4IPs), encodeIPRangeList(block.V6IPs)...), nil
+}
+
+// takeRanges returns the first size addresses of iprl
+func takeRanges(iprl IPRangeList, size int) IPRangeList {
+	result := IPRangeList{}
+	for _, ipr := range iprl {
+		if size <= 0 {
+			break
+		}
+		end := ipr.Start.Add(int64(size - 1))
+		if end.GreaterThan(ipr.End) {
+			end = ipr.End
+		}
+		result = append(result, &IPRange{Start: ipr.Start, End: end})
+		size -= int(IPRangeList{result[len(result)-1]}.Count())
+	}
+	return result
+}
+
+// NodeBlockStatistics returns the available and using address count of a node block
+func (subnet *Subnet) NodeBlockStatistics(name string) (v4Available, v4Using, v6Available, v6Using float64, err error) {
+	subnet.mutex.RLock()
+	defer subnet.mutex.RUnlock()
+
+	block, ok := subnet.NodeBlocks[name]
+	if !ok {
+		return 0, 0, 0, 0, ErrNoAvailable
+	}
+	_, v4Using, _, v6Using = subnet.rangeStatistics(block.V4IPs, block.V6IPs)
+	v4Available, v6Available = block.available()
+	return
+}
+
+// NodeBlockIdle returns true if no address of the block is in use and other blocks of its node
+// have at least minAvailable addresses of each protocol available, so the block can be returned
+func (subnet *Subnet) NodeBlockIdle(name string, minAvailable float64) bool {
+	subnet.mutex.RLock()
+	defer subnet.mutex.RUnlock()
+
+	block, ok := subnet.NodeBlocks[name]
+	if !ok {
+		return false
+	}
+	// an address taken from the block but not yet recorded is neither free nor in use
+	if v4Available, v6Available := block.available(); v4Available != block.V4IPs.Count() || v6Available != block.V6IPs.Count() {
+		return false
+	}
+
+	var v4Others, v6Others float64
+	for _, b := range subnet.nodeBlocks(block.Node) {
+		if b != block {
+			v4Available, v6Available := b.available()
+			v4Others += v4Available
+			v6Others += v6Available
+		}
+	}
+	return (subnet.V4CIDR == nil || v4Others >= minAvailable) && (subnet.V6CIDR == nil || v6Others >= minAvailable)
+}
+
+// NodeAvailableIPs returns the available address count in blocks of the node,
+// the smaller one is returned for dual stack subnets
+func (subnet *Subnet) NodeAvailableIPs(node string) float64 {
+	subnet.mutex.RLock()
+	defer subnet.mutex.RUnlock()
+
+	var v4Available, v6Available float64
+	for _, block := range subnet.nodeBlocks(node) {
+		v4, v6 := block.available()
+		v4Available += v4
+		v6Available += v6
+	}
+	switch {
+	case subnet.V4CIDR == nil:
+		return v6Available
+	case subnet.V6CIDR == nil || v4Available < v6Available:
+		return v4Available
+	default:
+		return v6Available
+	}
+}
@@ -0,0 +1,132 @@
+package ipam
+
+import (
+	"net"
+	"sort"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+// ReleasePolicy controls when released addresses can be allocated again
+type ReleasePolicy struct {
+	// ReuseTTL is how long a released address is held for its previous owner
+	ReuseTTL time.Duration
+	// Quarantine is how long a released address is not allocated to any other owner
+	Quarantine time.Duration
+}
+
+func (p ReleasePolicy) enabled() bool {
+	return p.ReuseTTL > 0 || p.Quarantine > 0
+}
+
+// ReleasedIP records the owner and time of a released address
+type ReleasedIP struct {
+	Owner string    `json:"owner"`
+	Time  time.Time `json:"time"`
+}
+
+// SetReleasePolicy updates the release policy of the subnet
+func (subnet *Subnet) SetReleasePolicy(policy ReleasePolicy) {
+	subnet.mutex.Lock()
+	defer subnet.mutex.Unlock()
+
+	subnet.ReleasePolicy = policy
+	if !policy.enabled() {
+		subnet.ReleasedIPs = map[IP]*ReleasedIP{}
+	}
+}
+
+// recordRelease must be called after ip of the nic is returned to the released list
+func (subnet *Subnet) recordRelease(ip IP, nicName string) {
+	if !subnet.ReleasePolicy.enabled() {
+		return
+	}
+	owner := subnet.NicToOwner[nicName]
+	if owner == "" {
+		return
+	}
+	subnet.ReleasedIPs[ip] = &ReleasedIP{Owner: owner, Time: time.Now()}
+}
+
+// unavailableAddresses returns released addresses which are held for other owners
+// or still in quarantine, expired records are removed
+func (subnet *Subnet) unavailableAddresses(owner string) map[IP]bool {
+	if len(subnet.ReleasedIPs) == 0 {
+		return nil
+	}
+
+	result := make(map[IP]bool, len(subnet.ReleasedIPs))
+	policy := subnet.ReleasePolicy
+	for ip, r := range subnet.ReleasedIPs {
+		elapsed := time.Since(r.Time)
+		if elapsed >= policy.ReuseTTL && elapsed >= policy.Quarantine {
+			delete(subnet.ReleasedIPs, ip)
+			continue
+		}
+		if r.Owner != owner {
+			result[ip] = true
+		}
+	}
+	return result
+}
+
+// reclaimAddress returns the most recently released address of the owner which
+// is still held for it, the address is removed from the free or released list
+func (subnet *Subnet) reclaimAddress(owner, poolName string, v4 bool, skippedAddrs []string) IP {
+	if owner == "" || subnet.ReleasePolicy.ReuseTTL <= 0 {
+		return ""
+	}
+
+	var candidates []IP
+	for ip, r := range subnet.ReleasedIPs {
+		if r.Owner != owner || time.Since(r.Time) >= subnet.ReleasePolicy.ReuseTTL {
+			continue
+		}
+		if (net.ParseIP(string(ip)).To4() != nil) != v4 {
+			continue
+		}
+		candidates = append(candidates, ip)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return subnet.ReleasedIPs[candidates[i]].Time.After(subnet.ReleasedIPs[candidates[j]].Time)
+	})
+
+	for _, ip := range candidates {
+		if util.ContainsString(skippedAddrs, string(ip)) {
+			continue
+		}
+		single := IPRangeList{&IPRange{Start: ip, End: ip}}
+		var iprl IPRangeList
+		var err error
+		if v4 {
+			iprl, err = subnet.filterV4Pool(single, poolName)
+		} else {
+			iprl, err = subnet.filterV6Pool(single, poolName)
+		}
+		if err != nil || len(iprl) == 0 {
+			continue
+		}
+
+		freeList, releasedList := &subnet.V4FreeIPList, &subnet.V4ReleasedIPList
+		if !v4 {
+			freeList, releasedList = &subnet.V6FreeIPList, &subnet.V6ReleasedIPList
+		}
+		if split, newList := splitIPRangeList(*freeList, ip); split {
+			*freeList = newList
+		} else if split, newList = splitIPRangeList(*releasedList, ip); split {
+			*releasedList = newList
+		} else {
+			// the address has been allocated by others
+			delete(subnet.ReleasedIPs, ip)
+			continue
+		}
+
+		klog.Infof("reclaim address %s released by %s", ip, owner)
+		delete(subnet.ReleasedIPs, ip)
+		return ip
+	}
+	return ""
+}
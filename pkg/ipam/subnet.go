@@ -17,12 +17,14 @@ type Subnet struct {
 	mutex            sync.RWMutex
 	Protocol         string
 	V4CIDR           *net.IPNet
+	V4ExtraCIDRs     []*net.IPNet
 	V4FreeIPList     IPRangeList
 	V4ReleasedIPList IPRangeList
 	V4ReservedIPList IPRangeList
 	V4NicToIP        map[string]IP
 	V4IPToPod        map[IP]string
 	V6CIDR           *net.IPNet
+	V6ExtraCIDRs     []*net.IPNet
 	V6FreeIPList     IPRangeList
 	V6ReleasedIPList IPRangeList
 	V6ReservedIPList IPRangeList
@@ -31,111 +33,168 @@ type Subnet struct {
 	NicToMac         map[string]string
 	MacToPod         map[string]string
 	PodToNicList     map[string][]string
+	IPPools          map[string]*IPPool
+	NodeBlocks       map[string]*NodeBlock
+	NicToOwner       map[string]string
+	ReleasedIPs      map[IP]*ReleasedIP
+	ReleasePolicy    ReleasePolicy
+
+	AllocationStrategy string
+	Allocator          Allocator
+
+	MacPrefix string
+	MacPolicy string
 }
 
 func NewSubnet(name, cidrStr string, excludeIps []string) (*Subnet, error) {
 	excludeIps = util.ExpandExcludeIPs(excludeIps, cidrStr)
 
-	var cidrs []*net.IPNet
+	v4CIDRs, v6CIDRs, err := parseCIDRs(cidrStr)
+	if err != nil {
+		return nil, err
+	}
+
+	// subnet.Spec.ExcludeIps contains both v4 and v6 addresses
+	v4ExcludeIps, v6ExcludeIps := util.SplitIpsByProtocol(excludeIps)
+
+	subnet := Subnet{
+		Name:             name,
+		mutex:            sync.RWMutex{},
+		Protocol:         cidrsProtocol(v4CIDRs, v6CIDRs),
+		V4ReleasedIPList: IPRangeList{},
+		V4NicToIP:        map[string]IP{},
+		V4IPToPod:        map[IP]string{},
+		V6ReleasedIPList: IPRangeList{},
+		V6NicToIP:        map[string]IP{},
+		V6IPToPod:        map[IP]string{},
+		MacToPod:         map[string]string{},
+		NicToMac:         map[string]string{},
+		PodToNicList:     map[string][]string{},
+		IPPools:          map[string]*IPPool{},
+		NodeBlocks:       map[string]*NodeBlock{},
+		NicToOwner:       map[string]string{},
+		ReleasedIPs:      map[IP]*ReleasedIP{},
+		Allocator:        &sequentialAllocator{},
+	}
+	if len(v4CIDRs) != 0 {
+		subnet.V4CIDR, subnet.V4ExtraCIDRs = v4CIDRs[0], v4CIDRs[1:]
+		subnet.V4FreeIPList = cidrsToIPRangeList(v4CIDRs)
+		subnet.V4ReservedIPList = convertExcludeIps(v4ExcludeIps)
+	}
+	if len(v6CIDRs) != 0 {
+		subnet.V6CIDR, subnet.V6ExtraCIDRs = v6CIDRs[0], v6CIDRs[1:]
+		subnet.V6FreeIPList = cidrsToIPRangeList(v6CIDRs)
+		subnet.V6ReservedIPList = convertExcludeIps(v6ExcludeIps)
+	}
+	subnet.joinFreeWithReserve()
+	return &subnet, nil
+}
+
+// parseCIDRs splits cidr blocks by family, the first block of each family is the primary one
+func parseCIDRs(cidrStr string) ([]*net.IPNet, []*net.IPNet, error) {
+	var v4CIDRs, v6CIDRs []*net.IPNet
 	for _, cidrBlock := range strings.Split(cidrStr, ",") {
-		if _, cidr, err := net.ParseCIDR(cidrBlock); err != nil {
-			return nil, ErrInvalidCIDR
+		_, cidr, err := net.ParseCIDR(cidrBlock)
+		if err != nil {
+			return nil, nil, ErrInvalidCIDR
+		}
+		if cidr.IP.To4() != nil {
+			v4CIDRs = append(v4CIDRs, cidr)
 		} else {
-			cidrs = append(cidrs, cidr)
+			v6CIDRs = append(v6CIDRs, cidr)
 		}
 	}
+	return v4CIDRs, v6CIDRs, nil
+}
 
-	// subnet.Spec.ExcludeIps contains both v4 and v6 addresses
-	v4ExcludeIps, v6ExcludeIps := util.SplitIpsByProtocol(excludeIps)
+func cidrsProtocol(v4CIDRs, v6CIDRs []*net.IPNet) string {
+	switch {
+	case len(v4CIDRs) != 0 && len(v6CIDRs) != 0:
+		return kubeovnv1.ProtocolDual
+	case len(v4CIDRs) != 0:
+		return kubeovnv1.ProtocolIPv4
+	default:
+		return kubeovnv1.ProtocolIPv6
+	}
+}
 
-	subnet := Subnet{}
-	protocol := util.CheckProtocol(cidrStr)
-	if protocol == kubeovnv1.ProtocolIPv4 {
-		firstIP, _ := util.FirstIP(cidrStr)
-		lastIP, _ := util.LastIP(cidrStr)
-
-		subnet = Subnet{
-			Name:             name,
-			mutex:            sync.RWMutex{},
-			Protocol:         protocol,
-			V4CIDR:           cidrs[0],
-			V4FreeIPList:     IPRangeList{&IPRange{Start: IP(firstIP), End: IP(lastIP)}},
-			V4ReleasedIPList: IPRangeList{},
-			V4ReservedIPList: convertExcludeIps(v4ExcludeIps),
-			V4NicToIP:        map[string]IP{},
-			V4IPToPod:        map[IP]string{},
-			V6NicToIP:        map[string]IP{},
-			V6IPToPod:        map[IP]string{},
-			MacToPod:         map[string]string{},
-			NicToMac:         map[string]string{},
-			PodToNicList:     map[string][]string{},
-		}
-		subnet.joinFreeWithReserve()
-	} else if protocol == kubeovnv1.ProtocolIPv6 {
-		firstIP, _ := util.FirstIP(cidrStr)
-		lastIP, _ := util.LastIP(cidrStr)
-
-		subnet = Subnet{
-			Name:             name,
-			mutex:            sync.RWMutex{},
-			Protocol:         protocol,
-			V6CIDR:           cidrs[0],
-			V6FreeIPList:     IPRangeList{&IPRange{Start: IP(firstIP), End: IP(lastIP)}},
-			V6ReleasedIPList: IPRangeList{},
-			V6ReservedIPList: convertExcludeIps(v6ExcludeIps),
-			V4NicToIP:        map[string]IP{},
-			V4IPToPod:        map[IP]string{},
-			V6NicToIP:        map[string]IP{},
-			V6IPToPod:        map[IP]string{},
-			MacToPod:         map[string]string{},
-			NicToMac:         map[string]string{},
-			PodToNicList:     map[string][]string{},
-		}
-		subnet.joinFreeWithReserve()
-	} else {
-		cidrBlocks := strings.Split(cidrStr, ",")
-		v4FirstIP, _ := util.FirstIP(cidrBlocks[0])
-		v4LastIP, _ := util.LastIP(cidrBlocks[0])
-		v6FirstIP, _ := util.FirstIP(cidrBlocks[1])
-		v6LastIP, _ := util.LastIP(cidrBlocks[1])
-
-		subnet = Subnet{
-			Name:             name,
-			mutex:            sync.RWMutex{},
-			Protocol:         protocol,
-			V4CIDR:           cidrs[0],
-			V4FreeIPList:     IPRangeList{&IPRange{Start: IP(v4FirstIP), End: IP(v4LastIP)}},
-			V4ReleasedIPList: IPRangeList{},
-			V4ReservedIPList: convertExcludeIps(v4ExcludeIps),
-			V4NicToIP:        map[string]IP{},
-			V4IPToPod:        map[IP]string{},
-			V6CIDR:           cidrs[1],
-			V6FreeIPList:     IPRangeList{&IPRange{Start: IP(v6FirstIP), End: IP(v6LastIP)}},
-			V6ReleasedIPList: IPRangeList{},
-			V6ReservedIPList: convertExcludeIps(v6ExcludeIps),
-			V6NicToIP:        map[string]IP{},
-			V6IPToPod:        map[IP]string{},
-			MacToPod:         map[string]string{},
-			NicToMac:         map[string]string{},
-			PodToNicList:     map[string][]string{},
-		}
-		subnet.joinFreeWithReserve()
+// cidrsToIPRangeList returns usable addresses of the cidr blocks,
+// addresses of the extra blocks are placed after the primary one
+func cidrsToIPRangeList(cidrs []*net.IPNet) IPRangeList {
+	iprl := make(IPRangeList, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		firstIP, _ := util.FirstIP(cidr.String())
+		lastIP, _ := util.LastIP(cidr.String())
+		if IP(firstIP).GreaterThan(IP(lastIP)) {
+			continue
+		}
+		iprl = append(iprl, &IPRange{Start: IP(firstIP), End: IP(lastIP)})
 	}
-	return &subnet, nil
+	return iprl
+}
+
+func (subnet *Subnet) v4Contains(ip IP) bool {
+	return cidrsContain(subnet.V4CIDR, subnet.V4ExtraCIDRs, ip)
+}
+
+func (subnet *Subnet) v6Contains(ip IP) bool {
+	return cidrsContain(subnet.V6CIDR, subnet.V6ExtraCIDRs, ip)
+}
+
+func cidrsContain(cidr *net.IPNet, extraCIDRs []*net.IPNet, ip IP) bool {
+	if cidr == nil {
+		return false
+	}
+	addr := net.ParseIP(string(ip))
+	if cidr.Contains(addr) {
+		return true
+	}
+	for _, c := range extraCIDRs {
+		if c.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// SubnetAddresses is a snapshot of addresses in a subnet
+type SubnetAddresses struct {
+	V4Free     []string          `json:"v4Free,omitempty"`
+	V4Released []string          `json:"v4Released,omitempty"`
+	V6Free     []string          `json:"v6Free,omitempty"`
+	V6Released []string          `json:"v6Released,omitempty"`
+	Allocated  map[string]string `json:"allocated,omitempty"`
+}
+
+func (subnet *Subnet) addresses() *SubnetAddresses {
+	subnet.mutex.RLock()
+	defer subnet.mutex.RUnlock()
+
+	addrs := &SubnetAddresses{
+		V4Free:     encodeIPRangeList(subnet.V4FreeIPList),
+		V4Released: encodeIPRangeList(subnet.V4ReleasedIPList),
+		V6Free:     encodeIPRangeList(subnet.V6FreeIPList),
+		V6Released: encodeIPRangeList(subnet.V6ReleasedIPList),
+		Allocated:  make(map[string]string, len(subnet.V4IPToPod)+len(subnet.V6IPToPod)),
+	}
+	for ip, pod := range subnet.V4IPToPod {
+		addrs.Allocated[string(ip)] = pod
+	}
+	for ip, pod := range subnet.V6IPToPod {
+		addrs.Allocated[string(ip)] = pod
+	}
+	return addrs
 }
 
 func (subnet *Subnet) GetRandomMac(podName, nicName string) string {
 	if mac, ok := subnet.NicToMac[nicName]; ok {
 		return mac
 	}
-	for {
-		mac := util.GenerateMac()
-		if _, ok := subnet.MacToPod[mac]; !ok {
-			subnet.MacToPod[mac] = podName
-			subnet.NicToMac[nicName] = mac
-			return mac
-		}
+	ip := subnet.V4NicToIP[nicName]
+	if ip == "" {
+		ip = subnet.V6NicToIP[nicName]
 	}
+	return subnet.generateMac(podName, nicName, ip)
 }
 
 func (subnet *Subnet) GetStaticMac(podName, nicName, mac string, checkConflict bool) error {
@@ -160,6 +219,22 @@ func (subnet *Subnet) popPodNic(podName, nicName string) {
 }
 
 func (subnet *Subnet) GetRandomAddress(podName, nicName string, skippedAddrs []string) (IP, IP, string, error) {
+	return subnet.GetRandomAddressFromPool(podName, nicName, "", skippedAddrs)
+}
+
+// GetRandomAddressFromPool allocates address from the named ip pool,
+// addresses out of all ip pools are used if poolName is empty
+func (subnet *Subnet) GetRandomAddressFromPool(podName, nicName, poolName string, skippedAddrs []string) (IP, IP, string, error) {
+	return subnet.GetRandomAddressForOwner(podName, nicName, poolName, "", skippedAddrs)
+}
+
+// GetRandomAddressForOwner allocates address for the pod owned by owner, addresses
+// released by the same owner are reused first according to the release policy.
+// The pod name is used as owner if owner is empty
+func (subnet *Subnet) GetRandomAddressForOwner(podName, nicName, poolName, owner string, skippedAddrs []string) (IP, IP, string, error) {
+	if owner == "" {
+		owner = podName
+	}
 	subnet.mutex.Lock()
 	defer func() {
 		subnet.pushPodNic(podName, nicName)
@@ -167,155 +242,197 @@ func (subnet *Subnet) GetRandomAddress(podName, nicName string, skippedAddrs []s
 	}()
 
 	if subnet.Protocol == kubeovnv1.ProtocolDual {
-		return subnet.getDualRandomAddress(podName, nicName, skippedAddrs)
+		return subnet.getDualRandomAddress(podName, nicName, poolName, owner, skippedAddrs)
 	} else if subnet.Protocol == kubeovnv1.ProtocolIPv4 {
-		return subnet.getV4RandomAddress(podName, nicName, skippedAddrs)
+		return subnet.getV4RandomAddress(podName, nicName, poolName, owner, skippedAddrs)
 	} else {
-		return subnet.getV6RandomAddress(podName, nicName, skippedAddrs)
+		return subnet.getV6RandomAddress(podName, nicName, poolName, owner, skippedAddrs)
 	}
 }
 
-func (subnet *Subnet) getDualRandomAddress(podName, nicName string, skippedAddrs []string) (IP, IP, string, error) {
-	v4IP, _, _, err := subnet.getV4RandomAddress(podName, nicName, skippedAddrs)
+func (subnet *Subnet) getDualRandomAddress(podName, nicName, poolName, owner string, skippedAddrs []string) (IP, IP, string, error) {
+	v4IP, _, _, err := subnet.getV4RandomAddress(podName, nicName, poolName, owner, skippedAddrs)
 	if err != nil {
 		return "", "", "", err
 	}
-	_, v6IP, mac, err := subnet.getV6RandomAddress(podName, nicName, skippedAddrs)
+	_, v6IP, mac, err := subnet.getV6RandomAddress(podName, nicName, poolName, owner, skippedAddrs)
 	if err != nil {
 		return "", "", "", err
 	}
 
 	// allocated IPv4 address may be released in getV6RandomAddress()
 	if subnet.V4NicToIP[nicName] != v4IP {
-		v4IP, _, _, _ = subnet.getV4RandomAddress(podName, nicName, skippedAddrs)
+		v4IP, _, _, _ = subnet.getV4RandomAddress(podName, nicName, poolName, owner, skippedAddrs)
 	}
 
 	return v4IP, v6IP, mac, nil
 }
 
-func (subnet *Subnet) getV4RandomAddress(podName, nicName string, skippedAddrs []string) (IP, IP, string, error) {
+func (subnet *Subnet) getV4RandomAddress(podName, nicName, poolName, owner string, skippedAddrs []string) (IP, IP, string, error) {
 	if ip, ok := subnet.V4NicToIP[nicName]; ok {
 		if !util.ContainsString(skippedAddrs, string(ip)) {
 			return ip, "", subnet.NicToMac[nicName], nil
 		}
 		subnet.releaseAddr(podName, nicName)
 	}
-	if len(subnet.V4FreeIPList) == 0 {
-		if len(subnet.V4ReleasedIPList) == 0 {
+	if ip := subnet.reclaimAddress(owner, poolName, true, skippedAddrs); ip != "" {
+		return subnet.assignV4Address(podName, nicName, owner, ip)
+	}
+
+	freeList, err := subnet.filterV4Pool(subnet.V4FreeIPList, poolName)
+	if err != nil {
+		return "", "", "", err
+	}
+	if len(freeList) == 0 {
+		releasedList, _ := subnet.filterV4Pool(subnet.V4ReleasedIPList, poolName)
+		if len(releasedList) == 0 {
 			return "", "", "", ErrNoAvailable
 		}
-		subnet.V4FreeIPList = subnet.V4ReleasedIPList
-		subnet.V4ReleasedIPList = IPRangeList{}
+		subnet.V4FreeIPList = append(subnet.V4FreeIPList, releasedList...)
+		subnet.V4ReleasedIPList = subnet.V4ReleasedIPList.Exclude(releasedList)
+		freeList = releasedList
 	}
 
-	var ip IP
-	var idx int
-	for i, ipr := range subnet.V4FreeIPList {
-		klog.Infof("allocate pod %s v4 ip in the range of start: %s, end: %s", podName, ipr.Start, ipr.End)
-		for next := ipr.Start; !next.GreaterThan(ipr.End); next = next.Add(1) {
-			if !util.ContainsString(skippedAddrs, string(next)) {
-				ip = next
-				break
-			} else {
-				klog.Infof("v4 ip %s is in skipped addrs %+v", skippedAddrs)
-			}
+	unavailable := subnet.unavailableAddresses(owner)
+	ip := subnet.Allocator.Allocate(freeList, func(ip IP) bool {
+		if unavailable[ip] {
+			return true
 		}
-		if ip != "" {
-			idx = i
-			break
+		if util.ContainsString(skippedAddrs, string(ip)) {
+			klog.Infof("v4 ip %s is in skipped addrs %+v", ip, skippedAddrs)
+			return true
 		}
-	}
+		return false
+	})
 	if ip == "" {
 		return "", "", "", ErrConflict
 	}
+	klog.Infof("allocate v4 ip %s for pod %s with %s strategy", ip, podName, subnet.allocationStrategy())
 
-	ipr := subnet.V4FreeIPList[idx]
-	part1 := &IPRange{Start: ipr.Start, End: ip.Sub(1)}
-	part2 := &IPRange{Start: ip.Add(1), End: ipr.End}
-	subnet.V4FreeIPList = append(subnet.V4FreeIPList[:idx], subnet.V4FreeIPList[idx+1:]...)
-	if !part1.Start.GreaterThan(part1.End) {
-		subnet.V4FreeIPList = append(subnet.V4FreeIPList, part1)
-	}
-	if !part2.Start.GreaterThan(part2.End) {
-		subnet.V4FreeIPList = append(subnet.V4FreeIPList, part2)
-	}
+	subnet.V4FreeIPList = takeIPFromRangeList(subnet.V4FreeIPList, ip)
+	return subnet.assignV4Address(podName, nicName, owner, ip)
+}
 
+func (subnet *Subnet) assignV4Address(podName, nicName, owner string, ip IP) (IP, IP, string, error) {
 	subnet.V4NicToIP[nicName] = ip
 	subnet.V4IPToPod[ip] = podName
+	subnet.NicToOwner[nicName] = owner
+	delete(subnet.ReleasedIPs, ip)
 	subnet.pushPodNic(podName, nicName)
 	return ip, "", subnet.GetRandomMac(podName, nicName), nil
 }
 
-func (subnet *Subnet) getV6RandomAddress(podName, nicName string, skippedAddrs []string) (IP, IP, string, error) {
+func (subnet *Subnet) getV6RandomAddress(podName, nicName, poolName, owner string, skippedAddrs []string) (IP, IP, string, error) {
 	if ip, ok := subnet.V6NicToIP[nicName]; ok {
 		if !util.ContainsString(skippedAddrs, string(ip)) {
 			return "", ip, subnet.NicToMac[nicName], nil
 		}
 		subnet.releaseAddr(podName, nicName)
 	}
-	if len(subnet.V6FreeIPList) == 0 {
-		if len(subnet.V6ReleasedIPList) == 0 {
+	if ip := subnet.reclaimAddress(owner, poolName, false, skippedAddrs); ip != "" {
+		return subnet.assignV6Address(podName, nicName, owner, ip)
+	}
+
+	freeList, err := subnet.filterV6Pool(subnet.V6FreeIPList, poolName)
+	if err != nil {
+		return "", "", "", err
+	}
+	if len(freeList) == 0 {
+		releasedList, _ := subnet.filterV6Pool(subnet.V6ReleasedIPList, poolName)
+		if len(releasedList) == 0 {
 			return "", "", "", ErrNoAvailable
 		}
-		subnet.V6FreeIPList = subnet.V6ReleasedIPList
-		subnet.V6ReleasedIPList = IPRangeList{}
+		subnet.V6FreeIPList = append(subnet.V6FreeIPList, releasedList...)
+		subnet.V6ReleasedIPList = subnet.V6ReleasedIPList.Exclude(releasedList)
+		freeList = releasedList
 	}
 
-	var ip IP
-	var idx int
-	for i, ipr := range subnet.V6FreeIPList {
-		klog.Infof("allocate pod %s v6 ip in the range of start: %s, end: %s", podName, ipr.Start, ipr.End)
-		for next := ipr.Start; !next.GreaterThan(ipr.End); next = next.Add(1) {
-			if !util.ContainsString(skippedAddrs, string(next)) {
-				ip = next
-				break
-			} else {
-				klog.Infof("v6 ip %s is in skipped addrs %+v", skippedAddrs)
-			}
+	unavailable := subnet.unavailableAddresses(owner)
+	ip := subnet.Allocator.Allocate(freeList, func(ip IP) bool {
+		if unavailable[ip] {
+			return true
 		}
-		if ip != "" {
-			idx = i
-			break
+		if util.ContainsString(skippedAddrs, string(ip)) {
+			klog.Infof("v6 ip %s is in skipped addrs %+v", ip, skippedAddrs)
+			return true
 		}
-	}
+		return false
+	})
 	if ip == "" {
 		return "", "", "", ErrConflict
 	}
+	klog.Infof("allocate v6 ip %s for pod %s with %s strategy", ip, podName, subnet.allocationStrategy())
 
-	ipr := subnet.V6FreeIPList[idx]
-	part1 := &IPRange{Start: ipr.Start, End: ip.Sub(1)}
-	part2 := &IPRange{Start: ip.Add(1), End: ipr.End}
-	subnet.V6FreeIPList = append(subnet.V6FreeIPList[:idx], subnet.V6FreeIPList[idx+1:]...)
-	if !part1.Start.GreaterThan(part1.End) {
-		subnet.V6FreeIPList = append(subnet.V6FreeIPList, part1)
-	}
-	if !part2.Start.GreaterThan(part2.End) {
-		subnet.V6FreeIPList = append(subnet.V6FreeIPList, part2)
-	}
+	subnet.V6FreeIPList = takeIPFromRangeList(subnet.V6FreeIPList, ip)
+	return subnet.assignV6Address(podName, nicName, owner, ip)
+}
 
+func (subnet *Subnet) assignV6Address(podName, nicName, owner string, ip IP) (IP, IP, string, error) {
 	subnet.V6NicToIP[nicName] = ip
 	subnet.V6IPToPod[ip] = podName
+	subnet.NicToOwner[nicName] = owner
+	delete(subnet.ReleasedIPs, ip)
 	subnet.pushPodNic(podName, nicName)
 	return "", ip, subnet.GetRandomMac(podName, nicName), nil
 }
 
 func (subnet *Subnet) GetStaticAddress(podName, nicName string, ip IP, mac string, force bool, checkConflict bool) (IP, string, error) {
+	return subnet.GetStaticAddressForOwner(podName, nicName, "", ip, mac, force, checkConflict)
+}
+
+// GetStaticAddressForOwner allocates the static address for the pod owned by owner, the owner
+// is recorded so that the address is held for it after released. The owner is not recorded if empty
+func (subnet *Subnet) GetStaticAddressForOwner(podName, nicName, owner string, ip IP, mac string, force bool, checkConflict bool) (IP, string, error) {
 	subnet.mutex.Lock()
 	defer func() {
 		subnet.pushPodNic(podName, nicName)
 		subnet.mutex.Unlock()
 	}()
 
+	ip, mac, err := subnet.getStaticAddress(podName, nicName, ip, mac, force, checkConflict)
+	if err == nil && owner != "" {
+		subnet.NicToOwner[nicName] = owner
+	}
+	return ip, mac, err
+}
+
+// GetStaticAddressFromPool allocates the static address for the pod using the named ip pool,
+// the pool ownership is not checked if the address has been assigned to the pod
+func (subnet *Subnet) GetStaticAddressFromPool(podName, nicName, poolName, owner string, ip IP, mac string, checkConflict bool) (IP, string, error) {
+	subnet.mutex.Lock()
+	defer func() {
+		subnet.pushPodNic(podName, nicName)
+		subnet.mutex.Unlock()
+	}()
+
+	existPod := subnet.V4IPToPod[ip]
+	if net.ParseIP(string(ip)).To4() == nil {
+		existPod = subnet.V6IPToPod[ip]
+	}
+	if !util.ContainsString(strings.Split(existPod, ","), podName) {
+		if err := subnet.checkPoolOwnership(ip, poolName); err != nil {
+			return ip, mac, err
+		}
+	}
+
+	ip, mac, err := subnet.getStaticAddress(podName, nicName, ip, mac, false, checkConflict)
+	if err == nil && owner != "" {
+		subnet.NicToOwner[nicName] = owner
+	}
+	return ip, mac, err
+}
+
+func (subnet *Subnet) getStaticAddress(podName, nicName string, ip IP, mac string, force bool, checkConflict bool) (IP, string, error) {
 	var v4, v6 bool
 	if net.ParseIP(string(ip)).To4() != nil {
 		v4 = subnet.V4CIDR != nil
 	} else {
 		v6 = subnet.V6CIDR != nil
 	}
-	if v4 && !subnet.V4CIDR.Contains(net.ParseIP(string(ip))) {
+	if v4 && !subnet.v4Contains(ip) {
 		return ip, mac, ErrOutOfRange
 	}
-	if v6 && !subnet.V6CIDR.Contains(net.ParseIP(string(ip))) {
+	if v6 && !subnet.v6Contains(ip) {
 		return ip, mac, ErrOutOfRange
 	}
 
@@ -323,7 +440,7 @@ func (subnet *Subnet) GetStaticAddress(podName, nicName string, ip IP, mac strin
 		if m, ok := subnet.NicToMac[nicName]; ok {
 			mac = m
 		} else {
-			mac = subnet.GetRandomMac(podName, nicName)
+			mac = subnet.generateMac(podName, nicName, ip)
 		}
 	} else {
 		if err := subnet.GetStaticMac(podName, nicName, mac, checkConflict); err != nil {
@@ -355,17 +472,25 @@ func (subnet *Subnet) GetStaticAddress(podName, nicName string, ip IP, mac strin
 
 		if split, newFreeList := splitIPRangeList(subnet.V4FreeIPList, ip); split {
 			subnet.V4FreeIPList = newFreeList
+			delete(subnet.ReleasedIPs, ip)
 			subnet.V4NicToIP[nicName] = ip
 			subnet.V4IPToPod[ip] = podName
 			return ip, mac, nil
 		} else {
 			if split, newReleasedList := splitIPRangeList(subnet.V4ReleasedIPList, ip); split {
 				subnet.V4ReleasedIPList = newReleasedList
+				delete(subnet.ReleasedIPs, ip)
 				subnet.V4NicToIP[nicName] = ip
 				subnet.V4IPToPod[ip] = podName
 				return ip, mac, nil
 			}
 		}
+		if block := subnet.nodeBlockOf(ip, true); block != nil && block.remove(ip, true) {
+			delete(subnet.ReleasedIPs, ip)
+			subnet.V4NicToIP[nicName] = ip
+			subnet.V4IPToPod[ip] = podName
+			return ip, mac, nil
+		}
 	} else if v6 {
 		if existPod, ok := subnet.V6IPToPod[ip]; ok {
 			pods := strings.Split(existPod, ",")
@@ -390,17 +515,25 @@ func (subnet *Subnet) GetStaticAddress(podName, nicName string, ip IP, mac strin
 
 		if split, newFreeList := splitIPRangeList(subnet.V6FreeIPList, ip); split {
 			subnet.V6FreeIPList = newFreeList
+			delete(subnet.ReleasedIPs, ip)
 			subnet.V6NicToIP[nicName] = ip
 			subnet.V6IPToPod[ip] = podName
 			return ip, mac, nil
 		} else {
 			if split, newReleasedList := splitIPRangeList(subnet.V6ReleasedIPList, ip); split {
 				subnet.V6ReleasedIPList = newReleasedList
+				delete(subnet.ReleasedIPs, ip)
 				subnet.V6NicToIP[nicName] = ip
 				subnet.V6IPToPod[ip] = podName
 				return ip, mac, nil
 			}
 		}
+		if block := subnet.nodeBlockOf(ip, false); block != nil && block.remove(ip, false) {
+			delete(subnet.ReleasedIPs, ip)
+			subnet.V6NicToIP[nicName] = ip
+			subnet.V6IPToPod[ip] = podName
+			return ip, mac, nil
+		}
 	}
 	return ip, mac, ErrNoAvailable
 }
@@ -422,7 +555,7 @@ func (subnet *Subnet) releaseAddr(podName, nicName string) {
 			}
 
 			// When CIDR changed, do not relocate ip to CIDR list
-			if !subnet.V4CIDR.Contains(net.ParseIP(string(ip))) {
+			if !subnet.v4Contains(ip) {
 				// Continue to release IPv6 address
 				klog.Infof("release v4 %s mac %s for %s, ignore ip", ip, mac, podName)
 				changed = true
@@ -433,9 +566,17 @@ func (subnet *Subnet) releaseAddr(podName, nicName string) {
 				changed = true
 			}
 
+			if block := subnet.nodeBlockOf(ip, true); !changed && block != nil {
+				block.put(ip, true)
+				klog.Infof("release v4 %s mac %s for %s, add ip to node block %s", ip, mac, podName, block.Name)
+				changed = true
+			}
+
 			if merged, newReleasedList := mergeIPRangeList(subnet.V4ReleasedIPList, ip); !changed && merged {
 				subnet.V4ReleasedIPList = newReleasedList
 				klog.Infof("release v4 %s mac %s for %s, add ip to released list", ip, mac, podName)
+				subnet.recordRelease(ip, nicName)
+				subnet.Allocator.Release(ip)
 			}
 		}
 	}
@@ -453,7 +594,7 @@ func (subnet *Subnet) releaseAddr(podName, nicName string) {
 			}
 			changed = false
 			// When CIDR changed, do not relocate ip to CIDR list
-			if !subnet.V6CIDR.Contains(net.ParseIP(string(ip))) {
+			if !subnet.v6Contains(ip) {
 				klog.Infof("release v6 %s mac %s for %s, ignore ip", ip, mac, podName)
 				changed = true
 			}
@@ -463,12 +604,25 @@ func (subnet *Subnet) releaseAddr(podName, nicName string) {
 				changed = true
 			}
 
+			if block := subnet.nodeBlockOf(ip, false); !changed && block != nil {
+				block.put(ip, false)
+				klog.Infof("release v6 %s mac %s for %s, add ip to node block %s", ip, mac, podName, block.Name)
+				changed = true
+			}
+
 			if merged, newReleasedList := mergeIPRangeList(subnet.V6ReleasedIPList, ip); !changed && merged {
 				subnet.V6ReleasedIPList = newReleasedList
 				klog.Infof("release v6 %s mac %s for %s, add ip to released list", ip, mac, podName)
+				subnet.recordRelease(ip, nicName)
+				subnet.Allocator.Release(ip)
 			}
 		}
 	}
+	if _, ok = subnet.V4NicToIP[nicName]; !ok {
+		if _, ok = subnet.V6NicToIP[nicName]; !ok {
+			delete(subnet.NicToOwner, nicName)
+		}
+	}
 }
 
 func (subnet *Subnet) ReleaseAddress(podName string) {
@@ -558,3 +712,30 @@ func (subnet *Subnet) GetPodByIP(ip string) (podList []string) {
 
 	return
 }
+
+// Nics returns nics which have addresses in the subnet
+func (subnet *Subnet) Nics() []string {
+	subnet.mutex.RLock()
+	defer subnet.mutex.RUnlock()
+
+	var nics []string
+	for _, nicList := range subnet.PodToNicList {
+		nics = append(nics, nicList...)
+	}
+	return nics
+}
+
+// NamespaceNics returns nics of pods in the namespace which have addresses in the subnet
+func (subnet *Subnet) NamespaceNics(namespace string) []string {
+	subnet.mutex.RLock()
+	defer subnet.mutex.RUnlock()
+
+	var nics []string
+	prefix := namespace + "/"
+	for podName, nicList := range subnet.PodToNicList {
+		if strings.HasPrefix(podName, prefix) {
+			nics = append(nics, nicList...)
+		}
+	}
+	return nics
+}
@@ -34,6 +34,7 @@ type LogicalRouterPort interface {
 	CreateLogicalRouterPort(lrName string, lrpName, mac string, networks []string) error
 	UpdateLogicalRouterPortRA(lrpName, ipv6RAConfigsStr string, enableIPv6RA bool) error
 	UpdateLogicalRouterPortOptions(lrpName string, options map[string]string) error
+	UpdateLogicalRouterPort(lrp *ovnnb.LogicalRouterPort, fields ...interface{}) error
 	DeleteLogicalRouterPort(lrpName string) error
 	DeleteLogicalRouterPorts(externalIDs map[string]string, filter func(lrp *ovnnb.LogicalRouterPort) bool) error
 	GetLogicalRouterPort(lrpName string, ignoreNotFound bool) (*ovnnb.LogicalRouterPort, error)
@@ -119,12 +120,29 @@ type AddressSet interface {
 }
 
 type LogicalRouterStaticRoute interface {
-	AddLogicalRouterStaticRoute(lrName, policy, ipPrefix string, nexthops ...string) error
+	AddLogicalRouterStaticRoute(lrName, routeTable, policy, ipPrefix string, nexthops ...string) error
 	ClearLogicalRouterStaticRoute(lrName string) error
-	DeleteLogicalRouterStaticRoute(lrName string, policy *string, ipPrefix, nextHop string) error
+	DeleteLogicalRouterStaticRoute(lrName string, routeTable, policy *string, ipPrefix, nextHop string) error
 	ListLogicalRouterStaticRoutesByOption(lrName, key, value string) ([]*ovnnb.LogicalRouterStaticRoute, error)
-	ListLogicalRouterStaticRoutes(lrName string, policy *string, ipPrefix string, externalIDs map[string]string) ([]*ovnnb.LogicalRouterStaticRoute, error)
-	LogicalRouterStaticRouteExists(lrName, policy, ipPrefix, nexthop string) (bool, error)
+	ListLogicalRouterStaticRoutes(lrName string, routeTable, policy *string, ipPrefix string, externalIDs map[string]string) ([]*ovnnb.LogicalRouterStaticRoute, error)
+	LogicalRouterStaticRouteExists(lrName, routeTable, policy, ipPrefix, nexthop string) (bool, error)
+	SetLogicalRouterStaticRouteBFD(lrName, routeTable, policy, ipPrefix, nexthop string, bfdUUID *string) error
+	RouteTableSupported() bool
+}
+
+type BFD interface {
+	CreateBFD(lrpName, dstIP string, minRx, minTx, detectMult int, externalIDs map[string]string) (*ovnnb.BFD, error)
+	ListBFD(lrpName, dstIP string) ([]ovnnb.BFD, error)
+	ListBFDByExternalIDs(externalIDs map[string]string) ([]ovnnb.BFD, error)
+	DeleteBFD(lrpName, dstIP string) error
+}
+
+type DNS interface {
+	CreateDNS(name string, records map[string]string) (*ovnnb.DNS, error)
+	GetDNS(name string, ignoreNotFound bool) (*ovnnb.DNS, error)
+	ListDNS(externalIDs map[string]string) ([]ovnnb.DNS, error)
+	DeleteDNS(name string) error
+	SetDNSLogicalSwitches(name string, lsNames ...string) error
 }
 
 type LogicalRouterPolicy interface {
@@ -152,12 +170,23 @@ type DHCPOptions interface {
 	DeleteDHCPOptions(lsName string, protocol string) error
 	DeleteDHCPOptionsByUUIDs(uuidList ...string) error
 	ListDHCPOptions(needVendorFilter bool, externalIDs map[string]string) ([]ovnnb.DHCPOptions, error)
+	UpdateLogicalSwitchPortDHCPOptions(lspName string, v4Options, v6Options map[string]string) error
+	DeleteLogicalSwitchPortDHCPOptions(lspName string) error
+}
+
+type QoS interface {
+	UpdateLogicalSwitchQoS(lsName, cidrBlock string, qos *kubeovnv1.SubnetQoS) error
+	CreateQoSRules(lsName string, rules ...*ovnnb.QoS) error
+	DeleteQoSRules(lsName string, externalIDs map[string]string) error
+	ListQoSRules(lsName string, externalIDs map[string]string) ([]ovnnb.QoS, error)
 }
 
 type OvnClient interface {
 	ACL
 	AddressSet
+	BFD
 	DHCPOptions
+	DNS
 	// GatewayChassis
 	LoadBalancer
 	LogicalRouterPolicy
@@ -169,6 +198,7 @@ type OvnClient interface {
 	NAT
 	NBGlobal
 	PortGroup
+	QoS
 	CreateGatewayLogicalSwitch(lsName, lrName, provider, ip, mac string, vlanID int, chassises ...string) error
 	CreateLogicalPatchPort(lsName, lrName, lspName, lrpName, ip, mac string, chassises ...string) error
 	RemoveLogicalPatchPort(lspName, lrpName string) error
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"k8s.io/klog/v2"
+
+	"github.com/kubeovn/kube-ovn/pkg/util"
 )
 
 func (c LegacyClient) ovnIcNbCommand(cmdArgs ...string) (string, error) {
@@ -44,3 +46,43 @@ func (c LegacyClient) GetTsSubnet(ts string) (string, error) {
 	}
 	return subnet, nil
 }
+
+// CreateTransitSwitch create transit switch in ovn-ic nb, ovn-ic creates the logical switch of it in every az
+func (c LegacyClient) CreateTransitSwitch(ts string) error {
+	if _, err := c.ovnIcNbCommand(MayExist, "ts-add", ts, "--",
+		"set", "Transit_Switch", ts, fmt.Sprintf("external_ids:vendor=%s", util.CniTypeName)); err != nil {
+		return fmt.Errorf("failed to create ts %s, %v", ts, err)
+	}
+	return nil
+}
+
+// GetTransitSwitchExternalID get the value of the key in external_ids of the transit switch,
+// an empty value is returned if the transit switch or the key does not exist
+func (c LegacyClient) GetTransitSwitchExternalID(ts, key string) (string, error) {
+	value, err := c.ovnIcNbCommand(IfExists, "get", "Transit_Switch", ts, fmt.Sprintf("external_ids:%q", key))
+	if err != nil {
+		return "", fmt.Errorf("failed to get external_ids:%s of ts %s, %v", key, ts, err)
+	}
+	return strings.Trim(value, `"`), nil
+}
+
+// SetTransitSwitchExternalID set the key in external_ids of the transit switch, the key is removed if value is empty
+func (c LegacyClient) SetTransitSwitchExternalID(ts, key, value string) error {
+	var err error
+	if value == "" {
+		_, err = c.ovnIcNbCommand(IfExists, "remove", "Transit_Switch", ts, "external_ids", fmt.Sprintf("%q", key))
+	} else {
+		_, err = c.ovnIcNbCommand("set", "Transit_Switch", ts, fmt.Sprintf("external_ids:%q=%q", key, value))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set external_ids:%s of ts %s, %v", key, ts, err)
+	}
+	return nil
+}
+
+func (c LegacyClient) DeleteTransitSwitch(ts string) error {
+	if _, err := c.ovnIcNbCommand(IfExists, "ts-del", ts); err != nil {
+		return fmt.Errorf("failed to delete ts %s, %v", ts, err)
+	}
+	return nil
+}
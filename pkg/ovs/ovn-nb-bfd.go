@@ -0,0 +1,139 @@
+package ovs
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/ovn-org/libovsdb/ovsdb"
+
+	ovsclient "github.com/kubeovn/kube-ovn/pkg/ovsdb/client"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+// CreateBFD create bfd session from the logical router port to dstIP with the external ids,
+// the intervals and external ids of the existing session are updated if they are different
+func (c *ovnClient) CreateBFD(lrpName, dstIP string, minRx, minTx, detectMult int, externalIDs map[string]string) (*ovnnb.BFD, error) {
+	bfdList, err := c.ListBFD(lrpName, dstIP)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := map[string]string{"vendor": util.CniTypeName}
+	for k, v := range externalIDs {
+		ids[k] = v
+	}
+
+	if len(bfdList) != 0 {
+		bfd := &bfdList[0]
+		if intPtrEqual(bfd.MinRx, minRx) && intPtrEqual(bfd.MinTx, minTx) && intPtrEqual(bfd.DetectMult, detectMult) && reflect.DeepEqual(bfd.ExternalIDs, ids) {
+			return bfd, nil
+		}
+
+		bfd.MinRx, bfd.MinTx, bfd.DetectMult, bfd.ExternalIDs = &minRx, &minTx, &detectMult, ids
+		op, err := c.Where(bfd).Update(bfd, &bfd.MinRx, &bfd.MinTx, &bfd.DetectMult, &bfd.ExternalIDs)
+		if err != nil {
+			return nil, fmt.Errorf("generate operations for updating bfd %s %s: %v", lrpName, dstIP, err)
+		}
+		if err = c.Transact("bfd-update", op); err != nil {
+			return nil, fmt.Errorf("update bfd %s %s: %v", lrpName, dstIP, err)
+		}
+		return bfd, nil
+	}
+
+	bfd := &ovnnb.BFD{
+		UUID:        ovsclient.NamedUUID(),
+		LogicalPort: lrpName,
+		DstIP:       dstIP,
+		MinRx:       &minRx,
+		MinTx:       &minTx,
+		DetectMult:  &detectMult,
+		ExternalIDs: ids,
+	}
+	op, err := c.ovnNbClient.Create(bfd)
+	if err != nil {
+		return nil, fmt.Errorf("generate operations for creating bfd %s %s: %v", lrpName, dstIP, err)
+	}
+	if err = c.Transact("bfd-add", op); err != nil {
+		return nil, fmt.Errorf("create bfd %s %s: %v", lrpName, dstIP, err)
+	}
+
+	if bfdList, err = c.ListBFD(lrpName, dstIP); err != nil {
+		return nil, err
+	}
+	if len(bfdList) == 0 {
+		return nil, fmt.Errorf("not found bfd %s %s after creation", lrpName, dstIP)
+	}
+
+	return &bfdList[0], nil
+}
+
+// ListBFD list bfd sessions of the logical router port, sessions to all destinations are listed when dstIP is empty
+func (c *ovnClient) ListBFD(lrpName, dstIP string) ([]ovnnb.BFD, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+
+	bfdList := make([]ovnnb.BFD, 0)
+	if err := c.WhereCache(func(bfd *ovnnb.BFD) bool {
+		return bfd.LogicalPort == lrpName && (dstIP == "" || bfd.DstIP == dstIP)
+	}).List(ctx, &bfdList); err != nil {
+		return nil, fmt.Errorf("list bfd of logical router port %s: %v", lrpName, err)
+	}
+
+	return bfdList, nil
+}
+
+// ListBFDByExternalIDs list bfd sessions created by kube-ovn which match the given externalIDs
+func (c *ovnClient) ListBFDByExternalIDs(externalIDs map[string]string) ([]ovnnb.BFD, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+
+	bfdList := make([]ovnnb.BFD, 0)
+	if err := c.WhereCache(func(bfd *ovnnb.BFD) bool {
+		if bfd.ExternalIDs["vendor"] != util.CniTypeName {
+			return false
+		}
+		for k, v := range externalIDs {
+			if bfd.ExternalIDs[k] != v {
+				return false
+			}
+		}
+		return true
+	}).List(ctx, &bfdList); err != nil {
+		return nil, fmt.Errorf("list bfd with external IDs %v: %v", externalIDs, err)
+	}
+
+	return bfdList, nil
+}
+
+// DeleteBFD delete bfd session from the logical router port to dstIP
+func (c *ovnClient) DeleteBFD(lrpName, dstIP string) error {
+	bfdList, err := c.ListBFD(lrpName, dstIP)
+	if err != nil {
+		return err
+	}
+
+	if len(bfdList) == 0 {
+		return nil
+	}
+
+	ops := make([]ovsdb.Operation, 0, len(bfdList))
+	for i := range bfdList {
+		op, err := c.Where(&bfdList[i]).Delete()
+		if err != nil {
+			return fmt.Errorf("generate operations for deleting bfd %s %s: %v", lrpName, bfdList[i].DstIP, err)
+		}
+		ops = append(ops, op...)
+	}
+
+	if err = c.Transact("bfd-del", ops); err != nil {
+		return fmt.Errorf("delete bfd %s %s: %v", lrpName, dstIP, err)
+	}
+
+	return nil
+}
+
+func intPtrEqual(p *int, v int) bool {
+	return p != nil && *p == v
+}
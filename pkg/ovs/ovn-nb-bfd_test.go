@@ -0,0 +1,137 @@
+package ovs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func (suite *OvnClientTestSuite) testCreateBFD() {
+	t := suite.T()
+	t.Parallel()
+
+	ovnClient := suite.ovnClient
+	lrpName := "test-create-bfd"
+	dstIP := "100.64.0.2"
+
+	t.Run("create bfd", func(t *testing.T) {
+		bfd, err := ovnClient.CreateBFD(lrpName, dstIP, 100, 100, 3, nil)
+		require.NoError(t, err)
+		require.NotEmpty(t, bfd.UUID)
+		require.Equal(t, lrpName, bfd.LogicalPort)
+		require.Equal(t, dstIP, bfd.DstIP)
+		require.Equal(t, 100, *bfd.MinRx)
+		require.Equal(t, 100, *bfd.MinTx)
+		require.Equal(t, 3, *bfd.DetectMult)
+	})
+
+	t.Run("create bfd repeatedly", func(t *testing.T) {
+		before, err := ovnClient.ListBFD(lrpName, dstIP)
+		require.NoError(t, err)
+		require.Len(t, before, 1)
+
+		bfd, err := ovnClient.CreateBFD(lrpName, dstIP, 100, 100, 3, nil)
+		require.NoError(t, err)
+		require.Equal(t, before[0].UUID, bfd.UUID)
+	})
+
+	t.Run("update intervals of existing bfd", func(t *testing.T) {
+		bfd, err := ovnClient.CreateBFD(lrpName, dstIP, 200, 300, 5, nil)
+		require.NoError(t, err)
+
+		bfdList, err := ovnClient.ListBFD(lrpName, dstIP)
+		require.NoError(t, err)
+		require.Len(t, bfdList, 1)
+		require.Equal(t, bfd.UUID, bfdList[0].UUID)
+		require.Equal(t, 200, *bfdList[0].MinRx)
+		require.Equal(t, 300, *bfdList[0].MinTx)
+		require.Equal(t, 5, *bfdList[0].DetectMult)
+	})
+
+	t.Run("update external ids of existing bfd", func(t *testing.T) {
+		bfd, err := ovnClient.CreateBFD(lrpName, dstIP, 200, 300, 5, map[string]string{logicalRouterKey: "test-create-bfd-lr"})
+		require.NoError(t, err)
+
+		bfdList, err := ovnClient.ListBFDByExternalIDs(map[string]string{logicalRouterKey: "test-create-bfd-lr"})
+		require.NoError(t, err)
+		require.Len(t, bfdList, 1)
+		require.Equal(t, bfd.UUID, bfdList[0].UUID)
+		require.Equal(t, util.CniTypeName, bfdList[0].ExternalIDs["vendor"])
+	})
+}
+
+func (suite *OvnClientTestSuite) testDeleteBFD() {
+	t := suite.T()
+	t.Parallel()
+
+	ovnClient := suite.ovnClient
+	lrpName := "test-del-bfd"
+
+	_, err := ovnClient.CreateBFD(lrpName, "100.64.0.3", 100, 100, 3, nil)
+	require.NoError(t, err)
+	_, err = ovnClient.CreateBFD(lrpName, "100.64.0.4", 100, 100, 3, nil)
+	require.NoError(t, err)
+
+	bfdList, err := ovnClient.ListBFD(lrpName, "")
+	require.NoError(t, err)
+	require.Len(t, bfdList, 2)
+
+	err = ovnClient.DeleteBFD(lrpName, "100.64.0.3")
+	require.NoError(t, err)
+
+	bfdList, err = ovnClient.ListBFD(lrpName, "")
+	require.NoError(t, err)
+	require.Len(t, bfdList, 1)
+	require.Equal(t, "100.64.0.4", bfdList[0].DstIP)
+
+	// delete non-existent bfd
+	err = ovnClient.DeleteBFD(lrpName, "100.64.0.3")
+	require.NoError(t, err)
+}
+
+func (suite *OvnClientTestSuite) testSetLogicalRouterStaticRouteBFD() {
+	t := suite.T()
+	t.Parallel()
+
+	ovnClient := suite.ovnClient
+	lrName := "test-route-bfd-lr"
+	policy := ovnnb.LogicalRouterStaticRoutePolicySrcIP
+	ipPrefix := "192.168.70.0/24"
+	nexthop := "100.64.0.5"
+
+	err := ovnClient.CreateLogicalRouter(lrName)
+	require.NoError(t, err)
+
+	err = ovnClient.AddLogicalRouterStaticRoute(lrName, util.MainRouteTable, policy, ipPrefix, nexthop)
+	require.NoError(t, err)
+
+	bfd, err := ovnClient.CreateBFD(lrName+"-join", nexthop, 100, 100, 3, nil)
+	require.NoError(t, err)
+
+	t.Run("set bfd", func(t *testing.T) {
+		err = ovnClient.SetLogicalRouterStaticRouteBFD(lrName, util.MainRouteTable, policy, ipPrefix, nexthop, &bfd.UUID)
+		require.NoError(t, err)
+
+		route, err := ovnClient.GetLogicalRouterStaticRoute(lrName, util.MainRouteTable, policy, ipPrefix, nexthop, false)
+		require.NoError(t, err)
+		require.NotNil(t, route.BFD)
+		require.Equal(t, bfd.UUID, *route.BFD)
+	})
+
+	t.Run("clear bfd", func(t *testing.T) {
+		err = ovnClient.SetLogicalRouterStaticRouteBFD(lrName, util.MainRouteTable, policy, ipPrefix, nexthop, nil)
+		require.NoError(t, err)
+
+		route, err := ovnClient.GetLogicalRouterStaticRoute(lrName, util.MainRouteTable, policy, ipPrefix, nexthop, false)
+		require.NoError(t, err)
+		require.Nil(t, route.BFD)
+	})
+
+	t.Run("route does not exist", func(t *testing.T) {
+		err = ovnClient.SetLogicalRouterStaticRouteBFD(lrName, util.MainRouteTable, policy, "192.168.71.0/24", nexthop, &bfd.UUID)
+		require.Error(t, err)
+	})
+}
@@ -3,6 +3,7 @@ package ovs
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strings"
 
 	"github.com/ovn-org/libovsdb/ovsdb"
@@ -25,13 +26,18 @@ func (c *ovnClient) CreateDHCPOptions(lsName, cidr, options string) error {
 		return err
 	}
 
+	return c.createDHCPOptions(dhcpOpt)
+}
+
+// createDHCPOptions create dhcp options
+func (c *ovnClient) createDHCPOptions(dhcpOpt *ovnnb.DHCPOptions) error {
 	op, err := c.ovnNbClient.Create(dhcpOpt)
 	if err != nil {
-		return fmt.Errorf("generate operations for creating dhcp options 'cidr %s options %s': %v", cidr, options, err)
+		return fmt.Errorf("generate operations for creating dhcp options 'cidr %s options %v': %v", dhcpOpt.Cidr, dhcpOpt.Options, err)
 	}
 
 	if err = c.Transact("dhcp-create", op); err != nil {
-		return fmt.Errorf("create dhcp options with cidr %q options %q: %v", cidr, options, err)
+		return fmt.Errorf("create dhcp options with cidr %q options %v: %v", dhcpOpt.Cidr, dhcpOpt.Options, err)
 	}
 
 	return nil
@@ -67,12 +73,12 @@ func (c *ovnClient) UpdateDHCPOptions(subnet *kubeovnv1.Subnet, nonRouter bool)
 		v4Gateway = gateways[0]
 	}
 
-	dhcpV4OptUUID, err := c.updateDHCPv4Options(lsName, v4CIDR, v4Gateway, subnet.Spec.DHCPv4Options, nonRouter)
+	dhcpV4OptUUID, err := c.updateDHCPv4Options(lsName, v4CIDR, v4Gateway, subnet.Spec.DHCPv4Options, subnet.Spec.DHCPv4ExtraOptions, nonRouter)
 	if err != nil {
 		return nil, fmt.Errorf("update IPv4 dhcp options for logical switch %s: %v", lsName, err)
 	}
 
-	dhcpV6OptUUID, err := c.updateDHCPv6Options(lsName, v6CIDR, subnet.Spec.DHCPv6Options)
+	dhcpV6OptUUID, err := c.updateDHCPv6Options(lsName, v6CIDR, subnet.Spec.DHCPv6Options, subnet.Spec.DHCPv6ExtraOptions)
 	if err != nil {
 		return nil, fmt.Errorf("update IPv6 dhcp options for logical switch %s: %v", lsName, err)
 	}
@@ -83,7 +89,7 @@ func (c *ovnClient) UpdateDHCPOptions(subnet *kubeovnv1.Subnet, nonRouter bool)
 	}, nil
 }
 
-func (c *ovnClient) updateDHCPv4Options(lsName, cidr, gateway, options string, nonRouter bool) (uuid string, err error) {
+func (c *ovnClient) updateDHCPv4Options(lsName, cidr, gateway, options string, extraOptions map[string]string, nonRouter bool) (uuid string, err error) {
 	protocol := util.CheckProtocol(cidr)
 	if protocol != kubeovnv1.ProtocolIPv4 {
 		return "", fmt.Errorf("cidr %s must be a valid ipv4 address", cidr)
@@ -111,15 +117,24 @@ func (c *ovnClient) updateDHCPv4Options(lsName, cidr, gateway, options string, n
 		options = fmt.Sprintf("lease_time=%d,server_id=%s,server_mac=%s,dns_server=\"%s\"", 3600, "169.254.0.254", mac, "{114.114.114.114, 8.8.8.8}")
 	}
 
+	opts := mergeDHCPOptions(parseDHCPOptions(options), extraOptions)
+	if nonRouter {
+		delete(opts, "router")
+	}
+
 	/* update */
 	if dhcpOpt != nil {
 		dhcpOpt.Cidr = cidr
-		dhcpOpt.Options = parseDHCPOptions(options)
+		dhcpOpt.Options = opts
 		return dhcpOpt.UUID, c.updateDHCPOptions(dhcpOpt, &dhcpOpt.Cidr, &dhcpOpt.Options)
 	}
 
 	/* create */
-	if err := c.CreateDHCPOptions(lsName, cidr, options); err != nil {
+	if dhcpOpt, err = newDHCPOptions(lsName, cidr, ""); err != nil {
+		return "", err
+	}
+	dhcpOpt.Options = opts
+	if err := c.createDHCPOptions(dhcpOpt); err != nil {
 		return "", fmt.Errorf("create dhcp options: %v", err)
 	}
 
@@ -131,7 +146,7 @@ func (c *ovnClient) updateDHCPv4Options(lsName, cidr, gateway, options string, n
 	return dhcpOpt.UUID, nil
 }
 
-func (c *ovnClient) updateDHCPv6Options(lsName, cidr, options string) (uuid string, err error) {
+func (c *ovnClient) updateDHCPv6Options(lsName, cidr, options string, extraOptions map[string]string) (uuid string, err error) {
 	protocol := util.CheckProtocol(cidr)
 	if protocol != kubeovnv1.ProtocolIPv6 {
 		return "", fmt.Errorf("cidr %s must be a valid ipv6 address", cidr)
@@ -151,15 +166,21 @@ func (c *ovnClient) updateDHCPv6Options(lsName, cidr, options string) (uuid stri
 			options = fmt.Sprintf("server_id=%s", mac)
 		}
 
+		opts := mergeDHCPOptions(parseDHCPOptions(options), extraOptions)
+
 		/* update */
 		if dhcpOpt != nil {
 			dhcpOpt.Cidr = cidr
-			dhcpOpt.Options = parseDHCPOptions(options)
+			dhcpOpt.Options = opts
 			return dhcpOpt.UUID, c.updateDHCPOptions(dhcpOpt, &dhcpOpt.Cidr, &dhcpOpt.Options)
 		}
 
 		/* create */
-		if err := c.CreateDHCPOptions(lsName, cidr, options); err != nil {
+		if dhcpOpt, err = newDHCPOptions(lsName, cidr, ""); err != nil {
+			return "", err
+		}
+		dhcpOpt.Options = opts
+		if err := c.createDHCPOptions(dhcpOpt); err != nil {
 			return "", fmt.Errorf("create dhcp options: %v", err)
 		}
 
@@ -260,20 +281,23 @@ func (c *ovnClient) GetDHCPOptions(lsName, protocol string, ignoreNotFound bool)
 		return nil, fmt.Errorf("get logical switch %s %s dhcp options: %v", lsName, protocol, err)
 	}
 
-	// not found
-	if len(dhcpOptList) == 0 {
-		if ignoreNotFound {
-			return nil, nil
+	// skip dhcp options dedicated to logical switch ports
+	for i := range dhcpOptList {
+		if len(dhcpOptList[i].ExternalIDs[logicalSwitchPortKey]) == 0 {
+			return &dhcpOptList[i], nil
 		}
-
-		return nil, fmt.Errorf("not found logical switch %s %s dhcp options: %v", lsName, protocol, err)
 	}
 
 	//	if len(dhcpOptList) > 1 {
 	//		return nil, fmt.Errorf("more than one %s dhcp options in logical switch %s", protocol, lsName)
 	//	}
 
-	return &dhcpOptList[0], nil
+	// not found
+	if ignoreNotFound {
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("not found logical switch %s %s dhcp options: %v", lsName, protocol, err)
 }
 
 // ListDHCPOptions list dhcp options which match the given externalIDs
@@ -290,6 +314,141 @@ func (c *ovnClient) ListDHCPOptions(needVendorFilter bool, externalIDs map[strin
 	return dhcpOptList, nil
 }
 
+// UpdateLogicalSwitchPortDHCPOptions point logical switch port to dedicated dhcp options which merge the given
+// options into the dhcp options of the subnet the port uses, the port is pointed back to the dhcp options of the subnet
+// and the dedicated dhcp options are deleted when the given options are empty
+func (c *ovnClient) UpdateLogicalSwitchPortDHCPOptions(lspName string, v4Options, v6Options map[string]string) error {
+	lsp, err := c.GetLogicalSwitchPort(lspName, false)
+	if err != nil {
+		return err
+	}
+
+	if err = c.updateLogicalSwitchPortDHCPOptions(lspName, kubeovnv1.ProtocolIPv4, lsp.Dhcpv4Options, v4Options); err != nil {
+		return fmt.Errorf("update IPv4 dhcp options of logical switch port %s: %v", lspName, err)
+	}
+
+	if err = c.updateLogicalSwitchPortDHCPOptions(lspName, kubeovnv1.ProtocolIPv6, lsp.Dhcpv6Options, v6Options); err != nil {
+		return fmt.Errorf("update IPv6 dhcp options of logical switch port %s: %v", lspName, err)
+	}
+
+	return nil
+}
+
+func (c *ovnClient) updateLogicalSwitchPortDHCPOptions(lspName, protocol string, uuid *string, options map[string]string) error {
+	portOpt, err := c.getLogicalSwitchPortDHCPOptions(lspName, protocol)
+	if err != nil {
+		return err
+	}
+
+	// dhcp is disabled for the port
+	if uuid == nil || len(*uuid) == 0 {
+		if portOpt != nil {
+			return c.DeleteDHCPOptionsByUUIDs(portOpt.UUID)
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+
+	// the port may point to the dhcp options of the subnet or the dedicated one
+	base := &ovnnb.DHCPOptions{UUID: *uuid}
+	if err = c.Get(ctx, base); err != nil {
+		return fmt.Errorf("get dhcp options by UUID %s: %v", *uuid, err)
+	}
+	if len(base.ExternalIDs[logicalSwitchPortKey]) != 0 {
+		if base, err = c.GetDHCPOptions(base.ExternalIDs[logicalSwitchKey], protocol, false); err != nil {
+			return err
+		}
+	}
+
+	if len(options) == 0 {
+		if *uuid != base.UUID {
+			if err = c.SetLogicalSwitchPortDHCPOptions(lspName, base.UUID, protocol); err != nil {
+				return err
+			}
+		}
+		if portOpt != nil {
+			return c.DeleteDHCPOptionsByUUIDs(portOpt.UUID)
+		}
+		return nil
+	}
+
+	opts := mergeDHCPOptions(base.Options, options)
+
+	/* create */
+	if portOpt == nil {
+		portOpt = &ovnnb.DHCPOptions{
+			Cidr: base.Cidr,
+			ExternalIDs: map[string]string{
+				logicalSwitchKey:     base.ExternalIDs[logicalSwitchKey],
+				logicalSwitchPortKey: lspName,
+				"protocol":           protocol,
+				"vendor":             util.CniTypeName,
+			},
+			Options: opts,
+		}
+		if err = c.createDHCPOptions(portOpt); err != nil {
+			return err
+		}
+		if portOpt, err = c.getLogicalSwitchPortDHCPOptions(lspName, protocol); err != nil {
+			return err
+		}
+		if portOpt == nil {
+			return fmt.Errorf("not found %s dhcp options of logical switch port %s", protocol, lspName)
+		}
+	} else if portOpt.Cidr != base.Cidr || portOpt.ExternalIDs[logicalSwitchKey] != base.ExternalIDs[logicalSwitchKey] ||
+		!reflect.DeepEqual(portOpt.Options, opts) {
+		/* update */
+		portOpt.Cidr = base.Cidr
+		portOpt.ExternalIDs[logicalSwitchKey] = base.ExternalIDs[logicalSwitchKey]
+		portOpt.Options = opts
+		if err = c.updateDHCPOptions(portOpt, &portOpt.Cidr, &portOpt.ExternalIDs, &portOpt.Options); err != nil {
+			return err
+		}
+	}
+
+	if *uuid != portOpt.UUID {
+		return c.SetLogicalSwitchPortDHCPOptions(lspName, portOpt.UUID, protocol)
+	}
+	return nil
+}
+
+// DeleteLogicalSwitchPortDHCPOptions delete dhcp options dedicated to logical switch port
+func (c *ovnClient) DeleteLogicalSwitchPortDHCPOptions(lspName string) error {
+	dhcpOptList, err := c.ListDHCPOptions(true, map[string]string{logicalSwitchPortKey: lspName})
+	if err != nil {
+		return err
+	}
+	if len(dhcpOptList) == 0 {
+		return nil
+	}
+
+	uuids := make([]string, 0, len(dhcpOptList))
+	for _, dhcpOpt := range dhcpOptList {
+		uuids = append(uuids, dhcpOpt.UUID)
+	}
+
+	return c.DeleteDHCPOptionsByUUIDs(uuids...)
+}
+
+// getLogicalSwitchPortDHCPOptions get dhcp options dedicated to logical switch port
+func (c *ovnClient) getLogicalSwitchPortDHCPOptions(lspName, protocol string) (*ovnnb.DHCPOptions, error) {
+	dhcpOptList, err := c.ListDHCPOptions(true, map[string]string{
+		logicalSwitchPortKey: lspName,
+		"protocol":           protocol,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get logical switch port %s %s dhcp options: %v", lspName, protocol, err)
+	}
+
+	if len(dhcpOptList) == 0 {
+		return nil, nil
+	}
+
+	return &dhcpOptList[0], nil
+}
+
 func (c *ovnClient) DHCPOptionsExists(lsName, cidr string) (bool, error) {
 	dhcpOpt, err := c.GetDHCPOptions(lsName, cidr, true)
 	return dhcpOpt != nil, err
@@ -317,6 +476,22 @@ func newDHCPOptions(lsName, cidr, options string) (*ovnnb.DHCPOptions, error) {
 	}, nil
 }
 
+// mergeDHCPOptions return dhcp options with the extra options overriding the ones with the same name
+func mergeDHCPOptions(options, extraOptions map[string]string) map[string]string {
+	if len(extraOptions) == 0 {
+		return options
+	}
+
+	merged := make(map[string]string, len(options)+len(extraOptions))
+	for k, v := range options {
+		merged[k] = v
+	}
+	for k, v := range extraOptions {
+		merged[k] = v
+	}
+	return merged
+}
+
 // dhcpOptionsFilter filter dhcp options which match the given externalIDs,
 // result should include all dhcp options when externalIDs is empty,
 // result should include all dhcp options which externalIDs[key] is not empty when externalIDs[key] is ""
@@ -76,7 +76,7 @@ func (suite *OvnClientTestSuite) test_updateDHCPv4Options() {
 
 	t.Run("create dhcp options", func(t *testing.T) {
 		t.Run("without options", func(t *testing.T) {
-			uuid, err := ovnClient.updateDHCPv4Options(lsName, cidr, gateway, "", false)
+			uuid, err := ovnClient.updateDHCPv4Options(lsName, cidr, gateway, "", nil, false)
 			require.NoError(t, err)
 
 			dhcpOpt, err := ovnClient.GetDHCPOptions(lsName, "IPv4", false)
@@ -98,7 +98,7 @@ func (suite *OvnClientTestSuite) test_updateDHCPv4Options() {
 		t.Run("with options", func(t *testing.T) {
 			lsName := "test-update-v4-dhcp-opt-ls-with-opt"
 			options := fmt.Sprintf("lease_time=%d,router=%s,server_id=%s,server_mac=%s", 7200, gateway, "169.254.0.1", "00:00:00:11:22:33")
-			uuid, err := ovnClient.updateDHCPv4Options(lsName, cidr, gateway, options, false)
+			uuid, err := ovnClient.updateDHCPv4Options(lsName, cidr, gateway, options, nil, false)
 			require.NoError(t, err)
 
 			dhcpOpt, err := ovnClient.GetDHCPOptions(lsName, "IPv4", false)
@@ -113,10 +113,30 @@ func (suite *OvnClientTestSuite) test_updateDHCPv4Options() {
 				"server_mac": "00:00:00:11:22:33",
 			}, dhcpOpt.Options)
 		})
+
+		t.Run("with extra options", func(t *testing.T) {
+			lsName := "test-update-v4-dhcp-opt-ls-with-extra-opt"
+			options := fmt.Sprintf("lease_time=%d,router=%s,server_id=%s,server_mac=%s", 7200, gateway, "169.254.0.1", "00:00:00:11:22:33")
+			extraOptions := map[string]string{"lease_time": "600", "mtu": "1400", "router": "192.168.30.254"}
+			uuid, err := ovnClient.updateDHCPv4Options(lsName, cidr, gateway, options, extraOptions, true)
+			require.NoError(t, err)
+
+			dhcpOpt, err := ovnClient.GetDHCPOptions(lsName, "IPv4", false)
+			require.NoError(t, err)
+
+			require.Equal(t, uuid, dhcpOpt.UUID)
+			require.Equal(t, map[string]string{
+				"lease_time": "600",
+				"mtu":        "1400",
+				"server_id":  "169.254.0.254",
+				"server_mac": dhcpOpt.Options["server_mac"],
+				"dns_server": "{114.114.114.114,8.8.8.8}",
+			}, dhcpOpt.Options)
+		})
 	})
 
 	t.Run("update dhcp options", func(t *testing.T) {
-		uuid, err := ovnClient.updateDHCPv4Options(lsName, cidr, gateway, "", false)
+		uuid, err := ovnClient.updateDHCPv4Options(lsName, cidr, gateway, "", nil, false)
 		require.NoError(t, err)
 
 		dhcpOpt, err := ovnClient.GetDHCPOptions(lsName, "IPv4", false)
@@ -145,7 +165,7 @@ func (suite *OvnClientTestSuite) test_updateDHCPv6Options() {
 
 	t.Run("create dhcp options", func(t *testing.T) {
 		t.Run("without options", func(t *testing.T) {
-			uuid, err := ovnClient.updateDHCPv6Options(lsName, cidr, "")
+			uuid, err := ovnClient.updateDHCPv6Options(lsName, cidr, "", nil)
 			require.NoError(t, err)
 
 			dhcpOpt, err := ovnClient.GetDHCPOptions(lsName, "IPv6", false)
@@ -163,7 +183,7 @@ func (suite *OvnClientTestSuite) test_updateDHCPv6Options() {
 		t.Run("with options", func(t *testing.T) {
 			lsName := "test-update-v6-dhcp-opt-ls-with-opt"
 			options := fmt.Sprintf("server_id=%s", "00:00:00:55:22:33")
-			uuid, err := ovnClient.updateDHCPv6Options(lsName, cidr, options)
+			uuid, err := ovnClient.updateDHCPv6Options(lsName, cidr, options, nil)
 			require.NoError(t, err)
 
 			dhcpOpt, err := ovnClient.GetDHCPOptions(lsName, "IPv6", false)
@@ -178,7 +198,7 @@ func (suite *OvnClientTestSuite) test_updateDHCPv6Options() {
 	})
 
 	t.Run("update dhcp options", func(t *testing.T) {
-		uuid, err := ovnClient.updateDHCPv6Options(lsName, cidr, "")
+		uuid, err := ovnClient.updateDHCPv6Options(lsName, cidr, "", nil)
 		require.NoError(t, err)
 
 		dhcpOpt, err := ovnClient.GetDHCPOptions(lsName, "IPv6", false)
@@ -192,6 +212,85 @@ func (suite *OvnClientTestSuite) test_updateDHCPv6Options() {
 	})
 }
 
+func (suite *OvnClientTestSuite) testUpdateLogicalSwitchPortDHCPOptions() {
+	t := suite.T()
+	t.Parallel()
+
+	ovnClient := suite.ovnClient
+	lsName := "test-update-lsp-dhcp-opt-ls"
+	lspName := "test-update-lsp-dhcp-opt-lsp"
+	cidr := "192.168.40.0/24"
+
+	err := ovnClient.CreateBareLogicalSwitch(lsName)
+	require.NoError(t, err)
+
+	err = ovnClient.CreateBareLogicalSwitchPort(lsName, lspName, "192.168.40.10", "00:00:00:AB:B4:65")
+	require.NoError(t, err)
+
+	uuid, err := ovnClient.updateDHCPv4Options(lsName, cidr, "192.168.40.1", "", nil, false)
+	require.NoError(t, err)
+
+	err = ovnClient.SetLogicalSwitchPortDHCPOptions(lspName, uuid, "IPv4")
+	require.NoError(t, err)
+
+	subnetOpt, err := ovnClient.GetDHCPOptions(lsName, "IPv4", false)
+	require.NoError(t, err)
+
+	t.Run("override dhcp options", func(t *testing.T) {
+		err = ovnClient.UpdateLogicalSwitchPortDHCPOptions(lspName, map[string]string{"mtu": "1300", "hostname": "\"vm1\""}, nil)
+		require.NoError(t, err)
+
+		portOpt, err := ovnClient.getLogicalSwitchPortDHCPOptions(lspName, "IPv4")
+		require.NoError(t, err)
+		require.NotNil(t, portOpt)
+		require.Equal(t, cidr, portOpt.Cidr)
+		require.Equal(t, lsName, portOpt.ExternalIDs[logicalSwitchKey])
+		require.Equal(t, "1300", portOpt.Options["mtu"])
+		require.Equal(t, "\"vm1\"", portOpt.Options["hostname"])
+		require.Equal(t, subnetOpt.Options["router"], portOpt.Options["router"])
+
+		lsp, err := ovnClient.GetLogicalSwitchPort(lspName, false)
+		require.NoError(t, err)
+		require.Equal(t, portOpt.UUID, *lsp.Dhcpv4Options)
+
+		// the dhcp options of the subnet are not affected
+		dhcpOpt, err := ovnClient.GetDHCPOptions(lsName, "IPv4", false)
+		require.NoError(t, err)
+		require.Equal(t, subnetOpt.UUID, dhcpOpt.UUID)
+		require.Empty(t, dhcpOpt.Options["mtu"])
+	})
+
+	t.Run("update overridden dhcp options", func(t *testing.T) {
+		before, err := ovnClient.getLogicalSwitchPortDHCPOptions(lspName, "IPv4")
+		require.NoError(t, err)
+
+		err = ovnClient.UpdateLogicalSwitchPortDHCPOptions(lspName, map[string]string{"mtu": "1200"}, nil)
+		require.NoError(t, err)
+
+		portOpt, err := ovnClient.getLogicalSwitchPortDHCPOptions(lspName, "IPv4")
+		require.NoError(t, err)
+		require.Equal(t, before.UUID, portOpt.UUID)
+		require.Equal(t, "1200", portOpt.Options["mtu"])
+		require.Empty(t, portOpt.Options["hostname"])
+	})
+
+	t.Run("delete dhcp options of port", func(t *testing.T) {
+		err = ovnClient.DeleteLogicalSwitchPort(lspName)
+		require.NoError(t, err)
+
+		err = ovnClient.DeleteLogicalSwitchPortDHCPOptions(lspName)
+		require.NoError(t, err)
+
+		portOpt, err := ovnClient.getLogicalSwitchPortDHCPOptions(lspName, "IPv4")
+		require.NoError(t, err)
+		require.Nil(t, portOpt)
+
+		dhcpOpt, err := ovnClient.GetDHCPOptions(lsName, "IPv4", false)
+		require.NoError(t, err)
+		require.Equal(t, subnetOpt.UUID, dhcpOpt.UUID)
+	})
+}
+
 func (suite *OvnClientTestSuite) testDeleteDHCPOptionsByUUIDs() {
 	t := suite.T()
 	t.Parallel()
@@ -0,0 +1,170 @@
+package ovs
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+
+	ovsclient "github.com/kubeovn/kube-ovn/pkg/ovsdb/client"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+// CreateDNS create dns with the records for the dns record named name,
+// the records of the existing dns are replaced if they are different
+func (c *ovnClient) CreateDNS(name string, records map[string]string) (*ovnnb.DNS, error) {
+	dns, err := c.GetDNS(name, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if dns != nil {
+		if reflect.DeepEqual(dns.Records, records) || (len(dns.Records) == 0 && len(records) == 0) {
+			return dns, nil
+		}
+
+		dns.Records = records
+		op, err := c.Where(dns).Update(dns, &dns.Records)
+		if err != nil {
+			return nil, fmt.Errorf("generate operations for updating dns %s: %v", name, err)
+		}
+		if err = c.Transact("dns-update", op); err != nil {
+			return nil, fmt.Errorf("update dns %s: %v", name, err)
+		}
+		return dns, nil
+	}
+
+	dns = &ovnnb.DNS{
+		UUID:    ovsclient.NamedUUID(),
+		Records: records,
+		ExternalIDs: map[string]string{
+			"vendor":     util.CniTypeName,
+			dnsRecordKey: name,
+		},
+	}
+	op, err := c.ovnNbClient.Create(dns)
+	if err != nil {
+		return nil, fmt.Errorf("generate operations for creating dns %s: %v", name, err)
+	}
+	if err = c.Transact("dns-add", op); err != nil {
+		return nil, fmt.Errorf("create dns %s: %v", name, err)
+	}
+
+	return c.GetDNS(name, false)
+}
+
+// GetDNS get dns of the dns record named name
+func (c *ovnClient) GetDNS(name string, ignoreNotFound bool) (*ovnnb.DNS, error) {
+	dnsList, err := c.ListDNS(map[string]string{dnsRecordKey: name})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(dnsList) == 0 {
+		if ignoreNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("not found dns %s", name)
+	}
+
+	if len(dnsList) > 1 {
+		return nil, fmt.Errorf("more than one dns with same name %s", name)
+	}
+
+	return &dnsList[0], nil
+}
+
+// ListDNS list dns created by kube-ovn which match the given externalIDs,
+// keys with empty values in externalIDs only require the keys to exist
+func (c *ovnClient) ListDNS(externalIDs map[string]string) ([]ovnnb.DNS, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+
+	dnsList := make([]ovnnb.DNS, 0)
+	if err := c.WhereCache(func(dns *ovnnb.DNS) bool {
+		if dns.ExternalIDs["vendor"] != util.CniTypeName {
+			return false
+		}
+		for k, v := range externalIDs {
+			if (len(v) == 0 && len(dns.ExternalIDs[k]) == 0) || (len(v) != 0 && dns.ExternalIDs[k] != v) {
+				return false
+			}
+		}
+		return true
+	}).List(ctx, &dnsList); err != nil {
+		return nil, fmt.Errorf("list dns with external IDs %v: %v", externalIDs, err)
+	}
+
+	return dnsList, nil
+}
+
+// DeleteDNS delete dns of the dns record named name, the dns is removed from logical switches by ovsdb
+// since logical switches refer to it weakly
+func (c *ovnClient) DeleteDNS(name string) error {
+	dns, err := c.GetDNS(name, true)
+	if err != nil {
+		return err
+	}
+
+	// not found, skip
+	if dns == nil {
+		return nil
+	}
+
+	op, err := c.Where(dns).Delete()
+	if err != nil {
+		return fmt.Errorf("generate operations for deleting dns %s: %v", name, err)
+	}
+	if err = c.Transact("dns-del", op); err != nil {
+		return fmt.Errorf("delete dns %s: %v", name, err)
+	}
+
+	return nil
+}
+
+// SetDNSLogicalSwitches set dns of the dns record named name to the logical switches,
+// and remove it from other logical switches
+func (c *ovnClient) SetDNSLogicalSwitches(name string, lsNames ...string) error {
+	dns, err := c.GetDNS(name, false)
+	if err != nil {
+		return err
+	}
+
+	lsList, err := c.ListLogicalSwitch(false, nil)
+	if err != nil {
+		return err
+	}
+
+	var ops []ovsdb.Operation
+	for _, ls := range lsList {
+		set := util.ContainsString(ls.DNSRecords, dns.UUID)
+		if set == util.ContainsString(lsNames, ls.Name) {
+			continue
+		}
+		op := ovsdb.MutateOperationInsert
+		if set {
+			op = ovsdb.MutateOperationDelete
+		}
+
+		lsOps, err := c.LogicalSwitchOp(ls.Name, func(ls *ovnnb.LogicalSwitch) *model.Mutation {
+			return &model.Mutation{
+				Field:   &ls.DNSRecords,
+				Value:   []string{dns.UUID},
+				Mutator: op,
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("generate operations for logical switch %s update dns %s: %v", ls.Name, name, err)
+		}
+		ops = append(ops, lsOps...)
+	}
+
+	if err = c.Transact("ls-dns-update", ops); err != nil {
+		return fmt.Errorf("set dns %s to logical switches %v: %v", name, lsNames, err)
+	}
+
+	return nil
+}
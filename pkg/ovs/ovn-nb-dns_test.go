@@ -0,0 +1,115 @@
+package ovs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func (suite *OvnClientTestSuite) testCreateDNS() {
+	t := suite.T()
+	t.Parallel()
+
+	ovnClient := suite.ovnClient
+	name := "test-create-dns"
+	records := map[string]string{"db.example.internal": "10.0.1.10 fd00::10"}
+
+	t.Run("create dns", func(t *testing.T) {
+		dns, err := ovnClient.CreateDNS(name, records)
+		require.NoError(t, err)
+		require.NotEmpty(t, dns.UUID)
+		require.Equal(t, records, dns.Records)
+		require.Equal(t, name, dns.ExternalIDs[dnsRecordKey])
+	})
+
+	t.Run("create dns repeatedly", func(t *testing.T) {
+		before, err := ovnClient.GetDNS(name, false)
+		require.NoError(t, err)
+
+		dns, err := ovnClient.CreateDNS(name, records)
+		require.NoError(t, err)
+		require.Equal(t, before.UUID, dns.UUID)
+	})
+
+	t.Run("update records of existing dns", func(t *testing.T) {
+		records := map[string]string{
+			"db.example.internal":    "10.0.1.11",
+			"11.1.0.10.in-addr.arpa": "db.example.internal",
+		}
+		dns, err := ovnClient.CreateDNS(name, records)
+		require.NoError(t, err)
+
+		dnsList, err := ovnClient.ListDNS(map[string]string{dnsRecordKey: name})
+		require.NoError(t, err)
+		require.Len(t, dnsList, 1)
+		require.Equal(t, dns.UUID, dnsList[0].UUID)
+		require.Equal(t, records, dnsList[0].Records)
+	})
+}
+
+func (suite *OvnClientTestSuite) testDeleteDNS() {
+	t := suite.T()
+	t.Parallel()
+
+	ovnClient := suite.ovnClient
+	name := "test-del-dns"
+
+	_, err := ovnClient.CreateDNS(name, map[string]string{"db.example.internal": "10.0.1.10"})
+	require.NoError(t, err)
+
+	err = ovnClient.DeleteDNS(name)
+	require.NoError(t, err)
+
+	dns, err := ovnClient.GetDNS(name, true)
+	require.NoError(t, err)
+	require.Nil(t, dns)
+
+	// delete non-existent dns
+	err = ovnClient.DeleteDNS(name)
+	require.NoError(t, err)
+}
+
+func (suite *OvnClientTestSuite) testSetDNSLogicalSwitches() {
+	t := suite.T()
+	t.Parallel()
+
+	ovnClient := suite.ovnClient
+	name := "test-set-dns-ls"
+	lsNames := []string{"test-set-dns-ls1", "test-set-dns-ls2", "test-set-dns-ls3"}
+
+	for _, lsName := range lsNames {
+		err := ovnClient.CreateBareLogicalSwitch(lsName)
+		require.NoError(t, err)
+	}
+	dns, err := ovnClient.CreateDNS(name, map[string]string{"db.example.internal": "10.0.1.10"})
+	require.NoError(t, err)
+
+	checkLogicalSwitches := func(expected ...string) {
+		for _, lsName := range lsNames {
+			ls, err := ovnClient.GetLogicalSwitch(lsName, false)
+			require.NoError(t, err)
+			if util.ContainsString(expected, lsName) {
+				require.Contains(t, ls.DNSRecords, dns.UUID)
+			} else {
+				require.NotContains(t, ls.DNSRecords, dns.UUID)
+			}
+		}
+	}
+
+	err = ovnClient.SetDNSLogicalSwitches(name, lsNames[0], lsNames[1])
+	require.NoError(t, err)
+	checkLogicalSwitches(lsNames[0], lsNames[1])
+
+	err = ovnClient.SetDNSLogicalSwitches(name, lsNames[1], lsNames[2])
+	require.NoError(t, err)
+	checkLogicalSwitches(lsNames[1], lsNames[2])
+
+	err = ovnClient.SetDNSLogicalSwitches(name)
+	require.NoError(t, err)
+	checkLogicalSwitches()
+
+	err = ovnClient.SetDNSLogicalSwitches("test-set-dns-non-existent", lsNames[0])
+	require.Error(t, err)
+}
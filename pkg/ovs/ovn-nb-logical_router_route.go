@@ -15,6 +15,59 @@ import (
 	"github.com/kubeovn/kube-ovn/pkg/util"
 )
 
+const (
+	staticRouteTable = "Logical_Router_Static_Route"
+	// routeTableColumn is the route table column of static routes added in the NB schema of OVN 21.12,
+	// it is kept out of the model since the client fails to connect to servers without any column of the model
+	routeTableColumn = "route_table"
+)
+
+// RouteTableSupported check whether static routes of the NB server have route tables
+func (c *ovnClient) RouteTableSupported() bool {
+	table, ok := c.Schema().Tables[staticRouteTable]
+	if !ok {
+		return false
+	}
+	_, ok = table.Columns[routeTableColumn]
+	return ok
+}
+
+// getStaticRouteTables get the route tables of static routes by uuid, routes in the main route table are not included
+func (c *ovnClient) getStaticRouteTables() (map[string]string, error) {
+	if !c.RouteTableSupported() {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+
+	op := ovsdb.Operation{
+		Op:      ovsdb.OperationSelect,
+		Table:   staticRouteTable,
+		Where:   []ovsdb.Condition{ovsdb.NewCondition(routeTableColumn, ovsdb.ConditionNotEqual, util.MainRouteTable)},
+		Columns: []string{"_uuid", routeTableColumn},
+	}
+	results, err := c.ovnNbClient.Transact(ctx, op)
+	if err != nil {
+		return nil, fmt.Errorf("select route tables of static routes: %v", err)
+	}
+	if len(results) != 1 || results[0].Error != "" {
+		return nil, fmt.Errorf("select route tables of static routes: %v", results)
+	}
+
+	tables := make(map[string]string, len(results[0].Rows))
+	for _, row := range results[0].Rows {
+		uuid, ok := row["_uuid"].(ovsdb.UUID)
+		if !ok {
+			continue
+		}
+		if table, ok := row[routeTableColumn].(string); ok {
+			tables[uuid.GoUUID] = table
+		}
+	}
+	return tables, nil
+}
+
 func (c *ovnClient) ListLogicalRouterStaticRoutesByOption(lrName, key, value string) ([]*ovnnb.LogicalRouterStaticRoute, error) {
 	//fnFilter := func(route *ovnnb.LogicalRouterStaticRoute) bool {
 	//	return len(route.Options) != 0 && route.Options[key] == value
@@ -24,6 +77,11 @@ func (c *ovnClient) ListLogicalRouterStaticRoutesByOption(lrName, key, value str
 
 // CreateLogicalRouterStaticRoutes create several logical router static route once
 func (c *ovnClient) CreateLogicalRouterStaticRoutes(lrName string, routes ...*ovnnb.LogicalRouterStaticRoute) error {
+	return c.createLogicalRouterStaticRoutes(lrName, util.MainRouteTable, routes...)
+}
+
+// createLogicalRouterStaticRoutes create several logical router static route in the route table once
+func (c *ovnClient) createLogicalRouterStaticRoutes(lrName, routeTable string, routes ...*ovnnb.LogicalRouterStaticRoute) error {
 	if len(routes) == 0 {
 		return nil
 	}
@@ -41,6 +99,11 @@ func (c *ovnClient) CreateLogicalRouterStaticRoutes(lrName string, routes ...*ov
 	if err != nil {
 		return fmt.Errorf("generate operations for creating static routes: %v", err)
 	}
+	if routeTable != util.MainRouteTable {
+		for i := range createRoutesOp {
+			createRoutesOp[i].Row[routeTableColumn] = routeTable
+		}
+	}
 
 	routeAddOp, err := c.LogicalRouterUpdateStaticRouteOp(lrName, routeUUIDs, ovsdb.MutateOperationInsert)
 	if err != nil {
@@ -58,13 +121,17 @@ func (c *ovnClient) CreateLogicalRouterStaticRoutes(lrName string, routes ...*ov
 	return nil
 }
 
-// AddLogicalRouterStaticRoute add a logical router static route
-func (c *ovnClient) AddLogicalRouterStaticRoute(lrName, policy, ipPrefix string, nexthops ...string) error {
+// AddLogicalRouterStaticRoute add a logical router static route to the route table,
+// routes with the same policy and ip prefix but other nexthops in the route table are removed
+func (c *ovnClient) AddLogicalRouterStaticRoute(lrName, routeTable, policy, ipPrefix string, nexthops ...string) error {
 	if len(policy) == 0 {
 		policy = ovnnb.LogicalRouterStaticRoutePolicyDstIP
 	}
+	if routeTable != util.MainRouteTable && !c.RouteTableSupported() {
+		return fmt.Errorf("failed to add static route %s to route table %s of logical router %s: route tables are not supported by the OVN NB schema, OVN 21.12 or later is required", ipPrefix, routeTable, lrName)
+	}
 
-	routes, err := c.ListLogicalRouterStaticRoutes(lrName, &policy, ipPrefix, nil)
+	routes, err := c.ListLogicalRouterStaticRoutes(lrName, &routeTable, &policy, ipPrefix, nil)
 	if err != nil {
 		return err
 	}
@@ -81,7 +148,7 @@ func (c *ovnClient) AddLogicalRouterStaticRoute(lrName, policy, ipPrefix string,
 	var toAdd []*ovnnb.LogicalRouterStaticRoute
 	for _, nexthop := range nexthops {
 		if !existing.Has(nexthop) {
-			route, err := c.newLogicalRouterStaticRoute(lrName, policy, ipPrefix, nexthop)
+			route, err := c.newLogicalRouterStaticRoute(lrName, routeTable, policy, ipPrefix, nexthop)
 			if err != nil {
 				return err
 			}
@@ -89,7 +156,7 @@ func (c *ovnClient) AddLogicalRouterStaticRoute(lrName, policy, ipPrefix string,
 		}
 	}
 
-	if err = c.CreateLogicalRouterStaticRoutes(lrName, toAdd...); err != nil {
+	if err = c.createLogicalRouterStaticRoutes(lrName, routeTable, toAdd...); err != nil {
 		return fmt.Errorf("add static routes to logical router %s: %v", lrName, err)
 	}
 	ops, err := c.LogicalRouterUpdateStaticRouteOp(lrName, toDel, ovsdb.MutateOperationDelete)
@@ -121,13 +188,29 @@ func (c *ovnClient) UpdateLogicalRouterStaticRoute(route *ovnnb.LogicalRouterSta
 	return nil
 }
 
-// DeleteLogicalRouterStaticRoute add a logical router static route
-func (c *ovnClient) DeleteLogicalRouterStaticRoute(lrName string, policy *string, ipPrefix, nexthop string) error {
+// SetLogicalRouterStaticRouteBFD set or clear bfd session which checks the nexthop of the static route
+func (c *ovnClient) SetLogicalRouterStaticRouteBFD(lrName, routeTable, policy, ipPrefix, nexthop string, bfdUUID *string) error {
+	route, err := c.GetLogicalRouterStaticRoute(lrName, routeTable, policy, ipPrefix, nexthop, false)
+	if err != nil {
+		return err
+	}
+
+	if (route.BFD == nil && bfdUUID == nil) || (route.BFD != nil && bfdUUID != nil && *route.BFD == *bfdUUID) {
+		return nil
+	}
+
+	route.BFD = bfdUUID
+	return c.UpdateLogicalRouterStaticRoute(route, &route.BFD)
+}
+
+// DeleteLogicalRouterStaticRoute delete logical router static routes,
+// routes in all route tables are deleted when routeTable is nil
+func (c *ovnClient) DeleteLogicalRouterStaticRoute(lrName string, routeTable, policy *string, ipPrefix, nexthop string) error {
 	if policy == nil || len(*policy) == 0 {
 		policy = &ovnnb.LogicalRouterStaticRoutePolicyDstIP
 	}
 
-	routes, err := c.ListLogicalRouterStaticRoutes(lrName, policy, ipPrefix, nil)
+	routes, err := c.ListLogicalRouterStaticRoutes(lrName, routeTable, policy, ipPrefix, nil)
 	if err != nil {
 		return err
 	}
@@ -190,17 +273,20 @@ func (c *ovnClient) GetLogicalRouterStaticRouteByUUID(uuid string) (*ovnnb.Logic
 }
 
 // GetLogicalRouterStaticRoute get logical router static route by some attribute,
-// a static route is uniquely identified by router(lrName), policy and ipPrefix when route is not ecmp
-// a static route is uniquely identified by router(lrName), policy, ipPrefix and nexthop when route is ecmp
-func (c *ovnClient) GetLogicalRouterStaticRoute(lrName, policy, ipPrefix, nexthop string, ignoreNotFound bool) (*ovnnb.LogicalRouterStaticRoute, error) {
+// a static route is uniquely identified by router(lrName), routeTable, policy and ipPrefix when route is not ecmp
+// a static route is uniquely identified by router(lrName), routeTable, policy, ipPrefix and nexthop when route is ecmp
+func (c *ovnClient) GetLogicalRouterStaticRoute(lrName, routeTable, policy, ipPrefix, nexthop string, ignoreNotFound bool) (*ovnnb.LogicalRouterStaticRoute, error) {
 	// this is necessary because may exist same static route in different logical router
 	if len(lrName) == 0 {
 		return nil, fmt.Errorf("the logical router name is required")
 	}
 
+	routeTables, err := c.getStaticRouteTables()
+	if err != nil {
+		return nil, err
+	}
 	fnFilter := func(route *ovnnb.LogicalRouterStaticRoute) bool {
-		//return route.RouteTable == routeTable && route.Policy != nil && *route.Policy == policy && route.IPPrefix == ipPrefix && route.Nexthop == nexthop
-		return route.Policy != nil && *route.Policy == policy && route.IPPrefix == ipPrefix && route.Nexthop == nexthop
+		return routeTables[route.UUID] == routeTable && route.Policy != nil && *route.Policy == policy && route.IPPrefix == ipPrefix && route.Nexthop == nexthop
 	}
 	routeList, err := c.listLogicalRouterStaticRoutesByFilter(lrName, fnFilter)
 	if err != nil {
@@ -222,8 +308,17 @@ func (c *ovnClient) GetLogicalRouterStaticRoute(lrName, policy, ipPrefix, nextho
 	return routeList[0], nil
 }
 
-// ListLogicalRouterStaticRoutes list route which match the given externalIDs
-func (c *ovnClient) ListLogicalRouterStaticRoutes(lrName string, policy *string, ipPrefix string, externalIDs map[string]string) ([]*ovnnb.LogicalRouterStaticRoute, error) {
+// ListLogicalRouterStaticRoutes list route which match the given externalIDs,
+// routes in all route tables are listed when routeTable is nil
+func (c *ovnClient) ListLogicalRouterStaticRoutes(lrName string, routeTable, policy *string, ipPrefix string, externalIDs map[string]string) ([]*ovnnb.LogicalRouterStaticRoute, error) {
+	var routeTables map[string]string
+	if routeTable != nil {
+		var err error
+		if routeTables, err = c.getStaticRouteTables(); err != nil {
+			return nil, err
+		}
+	}
+
 	fnFilter := func(route *ovnnb.LogicalRouterStaticRoute) bool {
 		if len(route.ExternalIDs) < len(externalIDs) {
 			return false
@@ -245,9 +340,9 @@ func (c *ovnClient) ListLogicalRouterStaticRoutes(lrName string, policy *string,
 			}
 		}
 
-		//if routeTable != nil && route.RouteTable != *routeTable {
-		//	return false
-		//}
+		if routeTable != nil && routeTables[route.UUID] != *routeTable {
+			return false
+		}
 		if policy != nil {
 			if route.Policy != nil {
 				if *route.Policy != *policy {
@@ -267,13 +362,13 @@ func (c *ovnClient) ListLogicalRouterStaticRoutes(lrName string, policy *string,
 	return c.listLogicalRouterStaticRoutesByFilter(lrName, fnFilter)
 }
 
-func (c *ovnClient) LogicalRouterStaticRouteExists(lrName, policy, ipPrefix, nexthop string) (bool, error) {
-	route, err := c.GetLogicalRouterStaticRoute(lrName, policy, ipPrefix, nexthop, true)
+func (c *ovnClient) LogicalRouterStaticRouteExists(lrName, routeTable, policy, ipPrefix, nexthop string) (bool, error) {
+	route, err := c.GetLogicalRouterStaticRoute(lrName, routeTable, policy, ipPrefix, nexthop, true)
 	return route != nil, err
 }
 
 // newLogicalRouterStaticRoute return logical router static route with basic information
-func (c *ovnClient) newLogicalRouterStaticRoute(lrName, policy, ipPrefix, nexthop string, options ...func(route *ovnnb.LogicalRouterStaticRoute)) (*ovnnb.LogicalRouterStaticRoute, error) {
+func (c *ovnClient) newLogicalRouterStaticRoute(lrName, routeTable, policy, ipPrefix, nexthop string, options ...func(route *ovnnb.LogicalRouterStaticRoute)) (*ovnnb.LogicalRouterStaticRoute, error) {
 	if len(lrName) == 0 {
 		return nil, fmt.Errorf("the logical router name is required")
 	}
@@ -282,7 +377,7 @@ func (c *ovnClient) newLogicalRouterStaticRoute(lrName, policy, ipPrefix, nextho
 		policy = ovnnb.LogicalRouterStaticRoutePolicyDstIP
 	}
 
-	exists, err := c.LogicalRouterStaticRouteExists(lrName, policy, ipPrefix, nexthop)
+	exists, err := c.LogicalRouterStaticRouteExists(lrName, routeTable, policy, ipPrefix, nexthop)
 	if err != nil {
 		return nil, fmt.Errorf("get logical router %s route: %v", lrName, err)
 	}
@@ -0,0 +1,301 @@
+package ovs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	ovsclient "github.com/kubeovn/kube-ovn/pkg/ovsdb/client"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+// UpdateLogicalSwitchQoS replace qos rules of logical switch with the aggregate limits of the subnet,
+// qos rules are deleted when qos is nil
+func (c *ovnClient) UpdateLogicalSwitchQoS(lsName, cidrBlock string, qos *kubeovnv1.SubnetQoS) error {
+	var rules []*ovnnb.QoS
+	if qos != nil {
+		if qos.EgressRate > 0 || qos.EgressDSCP > 0 {
+			rules = append(rules, newQoS(lsName, ovnnb.QoSDirectionFromLport, subnetQoSMatch(cidrBlock, true), qos.EgressRate, qos.EgressBurst, qos.EgressDSCP))
+		}
+		if qos.IngressRate > 0 {
+			rules = append(rules, newQoS(lsName, ovnnb.QoSDirectionToLport, subnetQoSMatch(cidrBlock, false), qos.IngressRate, qos.IngressBurst, 0))
+		}
+	}
+
+	existingRules, err := c.ListQoSRules(lsName, nil)
+	if err != nil {
+		return err
+	}
+	if qosRulesEqual(existingRules, rules) {
+		return nil
+	}
+
+	ops, err := c.DeleteQoSRulesOps(lsName, nil)
+	if err != nil {
+		return err
+	}
+
+	createOps, err := c.CreateQoSRulesOps(lsName, rules...)
+	if err != nil {
+		return err
+	}
+	ops = append(ops, createOps...)
+
+	if err = c.Transact("qos-update", ops); err != nil {
+		return fmt.Errorf("update qos rules of logical switch %s: %v", lsName, err)
+	}
+
+	return nil
+}
+
+// CreateQoSRules create several qos rules and attach them to logical switch
+func (c *ovnClient) CreateQoSRules(lsName string, rules ...*ovnnb.QoS) error {
+	ops, err := c.CreateQoSRulesOps(lsName, rules...)
+	if err != nil {
+		return err
+	}
+
+	if err = c.Transact("qos-add", ops); err != nil {
+		return fmt.Errorf("add qos rules to logical switch %s: %v", lsName, err)
+	}
+
+	return nil
+}
+
+// DeleteQoSRules delete qos rules of logical switch which match the given externalIDs
+func (c *ovnClient) DeleteQoSRules(lsName string, externalIDs map[string]string) error {
+	ops, err := c.DeleteQoSRulesOps(lsName, externalIDs)
+	if err != nil {
+		return err
+	}
+
+	if err = c.Transact("qos-del", ops); err != nil {
+		return fmt.Errorf("delete qos rules from logical switch %s: %v", lsName, err)
+	}
+
+	return nil
+}
+
+// ListQoSRules list qos rules of logical switch which match the given externalIDs
+func (c *ovnClient) ListQoSRules(lsName string, externalIDs map[string]string) ([]ovnnb.QoS, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+
+	ruleList := make([]ovnnb.QoS, 0)
+	if err := c.WhereCache(qosFilter(lsName, externalIDs)).List(ctx, &ruleList); err != nil {
+		return nil, fmt.Errorf("list qos rules of logical switch %s with external IDs %v: %v", lsName, externalIDs, err)
+	}
+
+	return ruleList, nil
+}
+
+// CreateQoSRulesOps return operations which create several qos rules and attach them to logical switch
+func (c *ovnClient) CreateQoSRulesOps(lsName string, rules ...*ovnnb.QoS) ([]ovsdb.Operation, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	models := make([]model.Model, 0, len(rules))
+	ruleUUIDs := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		if rule != nil {
+			models = append(models, model.Model(rule))
+			ruleUUIDs = append(ruleUUIDs, rule.UUID)
+		}
+	}
+
+	createOps, err := c.ovnNbClient.Create(models...)
+	if err != nil {
+		return nil, fmt.Errorf("generate operations for creating qos rules: %v", err)
+	}
+
+	addOps, err := c.logicalSwitchUpdateQoSOp(lsName, ruleUUIDs, ovsdb.MutateOperationInsert)
+	if err != nil {
+		return nil, fmt.Errorf("generate operations for adding qos rules to logical switch %s: %v", lsName, err)
+	}
+
+	ops := make([]ovsdb.Operation, 0, len(createOps)+len(addOps))
+	ops = append(ops, createOps...)
+	ops = append(ops, addOps...)
+
+	return ops, nil
+}
+
+// DeleteQoSRulesOps return operations which detach qos rules matching the given externalIDs from logical switch and delete them
+func (c *ovnClient) DeleteQoSRulesOps(lsName string, externalIDs map[string]string) ([]ovsdb.Operation, error) {
+	rules, err := c.ListQoSRules(lsName, externalIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	ruleUUIDs := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		ruleUUIDs = append(ruleUUIDs, rule.UUID)
+	}
+
+	removeOps, err := c.logicalSwitchUpdateQoSOp(lsName, ruleUUIDs, ovsdb.MutateOperationDelete)
+	if err != nil {
+		return nil, fmt.Errorf("generate operations for deleting qos rules from logical switch %s: %v", lsName, err)
+	}
+
+	delOps, err := c.WhereCache(qosFilter(lsName, externalIDs)).Delete()
+	if err != nil {
+		return nil, fmt.Errorf("generate operations for deleting qos rules of logical switch %s: %v", lsName, err)
+	}
+
+	ops := make([]ovsdb.Operation, 0, len(removeOps)+len(delOps))
+	ops = append(ops, removeOps...)
+	ops = append(ops, delOps...)
+
+	return ops, nil
+}
+
+// logicalSwitchUpdateQoSOp create operations which add or delete qos rules of logical switch
+func (c *ovnClient) logicalSwitchUpdateQoSOp(lsName string, ruleUUIDs []string, op ovsdb.Mutator) ([]ovsdb.Operation, error) {
+	if len(ruleUUIDs) == 0 {
+		return nil, nil
+	}
+
+	mutation := func(ls *ovnnb.LogicalSwitch) *model.Mutation {
+		return &model.Mutation{
+			Field:   &ls.QOSRules,
+			Value:   ruleUUIDs,
+			Mutator: op,
+		}
+	}
+
+	return c.LogicalSwitchOp(lsName, mutation)
+}
+
+// newQoS return qos rule with rate in Mbit/s and burst in Mbit,
+// rate and dscp are ignored when they are 0
+func newQoS(lsName, direction, match string, rate, burst, dscp int) *ovnnb.QoS {
+	qos := &ovnnb.QoS{
+		UUID:      ovsclient.NamedUUID(),
+		Direction: direction,
+		Match:     match,
+		Priority:  util.SubnetQoSPriority,
+		Action:    map[string]int{},
+		Bandwidth: map[string]int{},
+		ExternalIDs: map[string]string{
+			logicalSwitchKey: lsName,
+			"vendor":         util.CniTypeName,
+		},
+	}
+
+	// ovn rate is in kbps and burst is in kb
+	if rate > 0 {
+		qos.Bandwidth[ovnnb.QoSBandwidthRate] = rate * 1000
+		if burst > 0 {
+			qos.Bandwidth[ovnnb.QoSBandwidthBurst] = burst * 1000
+		}
+	}
+	if dscp > 0 {
+		qos.Action[ovnnb.QoSActionDSCP] = dscp
+	}
+
+	return qos
+}
+
+// subnetQoSMatch return match of traffic leaving the cidr blocks when egress is true,
+// otherwise match of traffic entering the cidr blocks, traffic inside the cidr blocks is excluded
+func subnetQoSMatch(cidrBlock string, egress bool) string {
+	var v4CIDRs, v6CIDRs []string
+	for _, cidr := range strings.Split(cidrBlock, ",") {
+		switch util.CheckProtocol(cidr) {
+		case kubeovnv1.ProtocolIPv4:
+			v4CIDRs = append(v4CIDRs, cidr)
+		case kubeovnv1.ProtocolIPv6:
+			v6CIDRs = append(v6CIDRs, cidr)
+		}
+	}
+
+	local, remote := "src", "dst"
+	if !egress {
+		local, remote = "dst", "src"
+	}
+
+	var matches []string
+	if len(v4CIDRs) != 0 {
+		set := fmt.Sprintf("{%s}", strings.Join(v4CIDRs, ", "))
+		matches = append(matches, fmt.Sprintf("ip4.%s == %s && ip4.%s != %s", local, set, remote, set))
+	}
+	if len(v6CIDRs) != 0 {
+		set := fmt.Sprintf("{%s}", strings.Join(v6CIDRs, ", "))
+		matches = append(matches, fmt.Sprintf("ip6.%s == %s && ip6.%s != %s", local, set, remote, set))
+	}
+
+	if len(matches) == 1 {
+		return matches[0]
+	}
+	return fmt.Sprintf("(%s)", strings.Join(matches, ") || ("))
+}
+
+// qosRulesEqual return whether the existing qos rules are the same as the expected ones regardless of uuid
+func qosRulesEqual(existing []ovnnb.QoS, expected []*ovnnb.QoS) bool {
+	if len(existing) != len(expected) {
+		return false
+	}
+
+	for _, rule := range expected {
+		found := false
+		for _, e := range existing {
+			if e.Direction == rule.Direction && e.Match == rule.Match && e.Priority == rule.Priority &&
+				intMapEqual(e.Bandwidth, rule.Bandwidth) && intMapEqual(e.Action, rule.Action) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// intMapEqual return whether the two maps have the same entries, a nil map equals an empty one
+func intMapEqual(a, b map[string]int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || w != v {
+			return false
+		}
+	}
+	return true
+}
+
+// qosFilter filter qos rules of logical switch which match the given externalIDs
+func qosFilter(lsName string, externalIDs map[string]string) func(qos *ovnnb.QoS) bool {
+	return func(qos *ovnnb.QoS) bool {
+		if len(qos.ExternalIDs) == 0 || qos.ExternalIDs["vendor"] != util.CniTypeName || qos.ExternalIDs[logicalSwitchKey] != lsName {
+			return false
+		}
+
+		for k, v := range externalIDs {
+			// if only key exist but not value in externalIDs, we should include this qos rule
+			if len(v) == 0 {
+				if len(qos.ExternalIDs[k]) == 0 {
+					return false
+				}
+			} else {
+				if qos.ExternalIDs[k] != v {
+					return false
+				}
+			}
+		}
+
+		return true
+	}
+}
@@ -0,0 +1,174 @@
+package ovs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func (suite *OvnClientTestSuite) testUpdateLogicalSwitchQoS() {
+	t := suite.T()
+	t.Parallel()
+
+	ovnClient := suite.ovnClient
+	lsName := "test_update_ls_qos"
+	cidrBlock := "192.168.30.0/24,fd00::c0a8:1e00/120"
+
+	err := ovnClient.CreateBareLogicalSwitch(lsName)
+	require.NoError(t, err)
+
+	t.Run("add ingress and egress qos rules", func(t *testing.T) {
+		err = ovnClient.UpdateLogicalSwitchQoS(lsName, cidrBlock, &kubeovnv1.SubnetQoS{
+			IngressRate: 100,
+			EgressRate:  50,
+			EgressBurst: 10,
+			EgressDSCP:  46,
+		})
+		require.NoError(t, err)
+
+		rules, err := ovnClient.ListQoSRules(lsName, nil)
+		require.NoError(t, err)
+		require.Len(t, rules, 2)
+
+		ls, err := ovnClient.GetLogicalSwitch(lsName, false)
+		require.NoError(t, err)
+		require.Len(t, ls.QOSRules, 2)
+
+		for _, rule := range rules {
+			require.Contains(t, ls.QOSRules, rule.UUID)
+			require.Equal(t, util.SubnetQoSPriority, rule.Priority)
+			switch rule.Direction {
+			case ovnnb.QoSDirectionFromLport:
+				require.Equal(t, subnetQoSMatch(cidrBlock, true), rule.Match)
+				require.Equal(t, map[string]int{ovnnb.QoSBandwidthRate: 50000, ovnnb.QoSBandwidthBurst: 10000}, rule.Bandwidth)
+				require.Equal(t, map[string]int{ovnnb.QoSActionDSCP: 46}, rule.Action)
+			case ovnnb.QoSDirectionToLport:
+				require.Equal(t, subnetQoSMatch(cidrBlock, false), rule.Match)
+				require.Equal(t, map[string]int{ovnnb.QoSBandwidthRate: 100000}, rule.Bandwidth)
+				require.Empty(t, rule.Action)
+			}
+		}
+	})
+
+	t.Run("keep qos rules when nothing changed", func(t *testing.T) {
+		before, err := ovnClient.ListQoSRules(lsName, nil)
+		require.NoError(t, err)
+
+		err = ovnClient.UpdateLogicalSwitchQoS(lsName, cidrBlock, &kubeovnv1.SubnetQoS{
+			IngressRate: 100,
+			EgressRate:  50,
+			EgressBurst: 10,
+			EgressDSCP:  46,
+		})
+		require.NoError(t, err)
+
+		after, err := ovnClient.ListQoSRules(lsName, nil)
+		require.NoError(t, err)
+		require.ElementsMatch(t, before, after)
+	})
+
+	t.Run("replace qos rules", func(t *testing.T) {
+		err = ovnClient.UpdateLogicalSwitchQoS(lsName, cidrBlock, &kubeovnv1.SubnetQoS{
+			EgressDSCP: 10,
+		})
+		require.NoError(t, err)
+
+		rules, err := ovnClient.ListQoSRules(lsName, nil)
+		require.NoError(t, err)
+		require.Len(t, rules, 1)
+		require.Equal(t, ovnnb.QoSDirectionFromLport, rules[0].Direction)
+		require.Empty(t, rules[0].Bandwidth)
+		require.Equal(t, map[string]int{ovnnb.QoSActionDSCP: 10}, rules[0].Action)
+
+		ls, err := ovnClient.GetLogicalSwitch(lsName, false)
+		require.NoError(t, err)
+		require.Equal(t, []string{rules[0].UUID}, ls.QOSRules)
+	})
+
+	t.Run("delete qos rules when qos is nil", func(t *testing.T) {
+		err = ovnClient.UpdateLogicalSwitchQoS(lsName, cidrBlock, nil)
+		require.NoError(t, err)
+
+		rules, err := ovnClient.ListQoSRules(lsName, nil)
+		require.NoError(t, err)
+		require.Empty(t, rules)
+
+		ls, err := ovnClient.GetLogicalSwitch(lsName, false)
+		require.NoError(t, err)
+		require.Empty(t, ls.QOSRules)
+	})
+}
+
+func (suite *OvnClientTestSuite) testDeleteQoSRules() {
+	t := suite.T()
+	t.Parallel()
+
+	ovnClient := suite.ovnClient
+	lsName := "test_del_ls_qos"
+
+	err := ovnClient.CreateBareLogicalSwitch(lsName)
+	require.NoError(t, err)
+
+	egress := newQoS(lsName, ovnnb.QoSDirectionFromLport, "ip4.src == 192.168.31.0/24", 10, 0, 0)
+	egress.ExternalIDs["direction"] = "egress"
+	ingress := newQoS(lsName, ovnnb.QoSDirectionToLport, "ip4.dst == 192.168.31.0/24", 10, 0, 0)
+	ingress.ExternalIDs["direction"] = "ingress"
+
+	err = ovnClient.CreateQoSRules(lsName, egress, ingress)
+	require.NoError(t, err)
+
+	err = ovnClient.DeleteQoSRules(lsName, map[string]string{"direction": "egress"})
+	require.NoError(t, err)
+
+	rules, err := ovnClient.ListQoSRules(lsName, nil)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	require.Equal(t, ovnnb.QoSDirectionToLport, rules[0].Direction)
+
+	ls, err := ovnClient.GetLogicalSwitch(lsName, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{rules[0].UUID}, ls.QOSRules)
+}
+
+func (suite *OvnClientTestSuite) test_newQoS() {
+	t := suite.T()
+	t.Parallel()
+
+	lsName := "test_new_qos"
+
+	t.Run("rate and burst are converted to kbps and kb", func(t *testing.T) {
+		qos := newQoS(lsName, ovnnb.QoSDirectionToLport, "ip4.dst == 10.0.0.0/24", 20, 5, 0)
+		require.NotEmpty(t, qos.UUID)
+		require.Equal(t, map[string]int{ovnnb.QoSBandwidthRate: 20000, ovnnb.QoSBandwidthBurst: 5000}, qos.Bandwidth)
+		require.Empty(t, qos.Action)
+		require.Equal(t, map[string]string{
+			logicalSwitchKey: lsName,
+			"vendor":         util.CniTypeName,
+		}, qos.ExternalIDs)
+	})
+
+	t.Run("burst is ignored without rate", func(t *testing.T) {
+		qos := newQoS(lsName, ovnnb.QoSDirectionFromLport, "ip4.src == 10.0.0.0/24", 0, 5, 8)
+		require.Empty(t, qos.Bandwidth)
+		require.Equal(t, map[string]int{ovnnb.QoSActionDSCP: 8}, qos.Action)
+	})
+}
+
+func (suite *OvnClientTestSuite) test_subnetQoSMatch() {
+	t := suite.T()
+	t.Parallel()
+
+	t.Run("ipv4", func(t *testing.T) {
+		require.Equal(t, "ip4.src == {10.0.0.0/24} && ip4.dst != {10.0.0.0/24}", subnetQoSMatch("10.0.0.0/24", true))
+		require.Equal(t, "ip4.dst == {10.0.0.0/24} && ip4.src != {10.0.0.0/24}", subnetQoSMatch("10.0.0.0/24", false))
+	})
+
+	t.Run("dual stack", func(t *testing.T) {
+		require.Equal(t, "(ip4.src == {10.0.0.0/24} && ip4.dst != {10.0.0.0/24}) || (ip6.src == {fd00::/120} && ip6.dst != {fd00::/120})",
+			subnetQoSMatch("10.0.0.0/24,fd00::/120", true))
+	})
+}
@@ -585,6 +585,10 @@ func (suite *OvnClientTestSuite) Test_updateDHCPv6Options() {
 	suite.test_updateDHCPv6Options()
 }
 
+func (suite *OvnClientTestSuite) Test_UpdateLogicalSwitchPortDHCPOptions() {
+	suite.testUpdateLogicalSwitchPortDHCPOptions()
+}
+
 func (suite *OvnClientTestSuite) Test_DeleteDHCPOptionsByUUIDs() {
 	suite.testDeleteDHCPOptionsByUUIDs()
 }
@@ -605,6 +609,49 @@ func (suite *OvnClientTestSuite) Test_dhcpOptionsFilter() {
 	suite.test_dhcpOptionsFilter()
 }
 
+/* qos unit test */
+func (suite *OvnClientTestSuite) Test_UpdateLogicalSwitchQoS() {
+	suite.testUpdateLogicalSwitchQoS()
+}
+
+func (suite *OvnClientTestSuite) Test_DeleteQoSRules() {
+	suite.testDeleteQoSRules()
+}
+
+func (suite *OvnClientTestSuite) Test_newQoS() {
+	suite.test_newQoS()
+}
+
+func (suite *OvnClientTestSuite) Test_subnetQoSMatch() {
+	suite.test_subnetQoSMatch()
+}
+
+/* bfd unit test */
+func (suite *OvnClientTestSuite) Test_CreateBFD() {
+	suite.testCreateBFD()
+}
+
+func (suite *OvnClientTestSuite) Test_DeleteBFD() {
+	suite.testDeleteBFD()
+}
+
+func (suite *OvnClientTestSuite) Test_SetLogicalRouterStaticRouteBFD() {
+	suite.testSetLogicalRouterStaticRouteBFD()
+}
+
+/* dns unit test */
+func (suite *OvnClientTestSuite) Test_CreateDNS() {
+	suite.testCreateDNS()
+}
+
+func (suite *OvnClientTestSuite) Test_DeleteDNS() {
+	suite.testDeleteDNS()
+}
+
+func (suite *OvnClientTestSuite) Test_SetDNSLogicalSwitches() {
+	suite.testSetDNSLogicalSwitches()
+}
+
 /* mixed operations unit test */
 func (suite *OvnClientTestSuite) Test_CreateGatewayLogicalSwitch() {
 	suite.testCreateGatewayLogicalSwitch()
@@ -722,7 +769,9 @@ func newNbClient(addr string, timeout int) (client.Client, error) {
 	monitorOpts := []client.MonitorOption{
 		client.WithTable(&ovnnb.ACL{}),
 		client.WithTable(&ovnnb.AddressSet{}),
+		client.WithTable(&ovnnb.BFD{}),
 		client.WithTable(&ovnnb.DHCPOptions{}),
+		client.WithTable(&ovnnb.DNS{}),
 		client.WithTable(&ovnnb.GatewayChassis{}),
 		client.WithTable(&ovnnb.LoadBalancer{}),
 		client.WithTable(&ovnnb.LogicalRouterPolicy{}),
@@ -734,6 +783,7 @@ func newNbClient(addr string, timeout int) (client.Client, error) {
 		client.WithTable(&ovnnb.NAT{}),
 		client.WithTable(&ovnnb.NBGlobal{}),
 		client.WithTable(&ovnnb.PortGroup{}),
+		client.WithTable(&ovnnb.QoS{}),
 	}
 	if _, err = c.Monitor(context.TODO(), c.NewMonitor(monitorOpts...)); err != nil {
 		return nil, err
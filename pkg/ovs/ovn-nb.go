@@ -16,11 +16,13 @@ import (
 const (
 	logicalRouterKey      = "lr"
 	logicalSwitchKey      = "ls"
+	logicalSwitchPortKey  = "lsp"
 	portGroupKey          = "pg"
 	aclParentKey          = "parent"
 	associatedSgKeyPrefix = "associated_sg_"
 	sgsKey                = "security_groups"
 	sgKey                 = "sg"
+	dnsRecordKey          = "dns_record"
 )
 
 // CreateGatewayLogicalSwitch create gateway switch connect external networks
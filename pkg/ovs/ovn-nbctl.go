@@ -141,6 +141,41 @@ func (c LegacyClient) DeleteICLogicalRouterPort(az string) error {
 	return nil
 }
 
+// CreateICPeerRouterPort connect the vpc router to the transit switch of a vpc peering across azs,
+// the lrp is named <az>-<ts> and the lsp is named <ts>-<az>, which is synced to the remote az by ovn-ic
+func (c LegacyClient) CreateICPeerRouterPort(router, ts, az, mac, networks string, chassises []string) error {
+	lrp, lsp := fmt.Sprintf("%s-%s", az, ts), fmt.Sprintf("%s-%s", ts, az)
+	args := []string{MayExist, "lrp-add", router, lrp, mac}
+	args = append(args, strings.Split(networks, ",")...)
+	args = append(args, "--", "set", "logical_router_port", lrp, fmt.Sprintf("external_ids:vendor=%s", util.CniTypeName))
+	if _, err := c.ovnNbCommand(args...); err != nil {
+		return fmt.Errorf("failed to create vpc peering lrp %s, %v", lrp, err)
+	}
+	if _, err := c.ovnNbCommand(MayExist, "lsp-add", ts, lsp, "--",
+		"lsp-set-addresses", lsp, "router", "--",
+		"lsp-set-type", lsp, "router", "--",
+		"lsp-set-options", lsp, fmt.Sprintf("router-port=%s", lrp), "--",
+		"set", "logical_switch_port", lsp, fmt.Sprintf("external_ids:vendor=%s", util.CniTypeName)); err != nil {
+		return fmt.Errorf("failed to create vpc peering lsp %s, %v", lsp, err)
+	}
+	for index, chassis := range chassises {
+		if _, err := c.ovnNbCommand("lrp-set-gateway-chassis", lrp, chassis, fmt.Sprintf("%d", 100-index)); err != nil {
+			return fmt.Errorf("failed to set gateway chassis, %v", err)
+		}
+	}
+	return nil
+}
+
+func (c LegacyClient) DeleteICPeerRouterPort(ts, az string) error {
+	if err := c.DeleteLogicalRouterPort(fmt.Sprintf("%s-%s", az, ts)); err != nil {
+		return fmt.Errorf("failed to delete vpc peering logical router port: %v", err)
+	}
+	if err := c.DeleteLogicalSwitchPort(fmt.Sprintf("%s-%s", ts, az)); err != nil {
+		return fmt.Errorf("failed to delete vpc peering logical switch port: %v", err)
+	}
+	return nil
+}
+
 /*
 	func (c LegacyClient) SetPortAddress(port, mac, ip string) error {
 		rets, err := c.ListLogicalEntity("logical_switch_port", fmt.Sprintf("name=%s", port))
@@ -466,27 +501,9 @@ func (c LegacyClient) UnsetLogicalRouterMulticast(lr string) (err error) {
 
 func (c LegacyClient) SetLogicalSwitchConfig(ls, lr, protocol, subnet, gateway string, excludeIps []string, needRouter bool) error {
 	var err error
-	cidrBlocks := strings.Split(subnet, ",")
-	mask := strings.Split(cidrBlocks[0], "/")[1]
-
-	var cmd []string
-	var networks string
-	switch protocol {
-	case kubeovnv1.ProtocolIPv4:
-		networks = fmt.Sprintf("%s/%s", gateway, mask)
-		cmd = []string{MayExist, "ls-add", ls}
-	case kubeovnv1.ProtocolIPv6:
-		gateway := strings.ReplaceAll(gateway, ":", "\\:")
-		networks = fmt.Sprintf("%s/%s", gateway, mask)
-		cmd = []string{MayExist, "ls-add", ls}
-	case kubeovnv1.ProtocolDual:
-		gws := strings.Split(gateway, ",")
-		v6Mask := strings.Split(cidrBlocks[1], "/")[1]
-		gwStr := gws[0] + "/" + mask + "," + gws[1] + "/" + v6Mask
-		networks = strings.ReplaceAll(strings.Join(strings.Split(gwStr, ","), " "), ":", "\\:")
-
-		cmd = []string{MayExist, "ls-add", ls}
-	}
+	// gateways are paired with cidr blocks in order, including the extra cidr blocks
+	networks := strings.ReplaceAll(strings.Join(strings.Split(util.GetIpAddrWithMask(gateway, subnet), ","), " "), ":", "\\:")
+	cmd := []string{MayExist, "ls-add", ls}
 	if needRouter {
 		cmd = append(cmd, []string{"--",
 			"set", "logical_router_port", fmt.Sprintf("%s-%s", lr, ls), fmt.Sprintf("networks=%s", networks)}...)
@@ -594,8 +611,9 @@ func (c LegacyClient) DestroyDns(uuid string) error {
 	return nil
 }
 
+// ListDns lists uuids of dns created by kube-ovn, dns of dns records are not included
 func (c LegacyClient) ListDns() ([]string, error) {
-	output, err := c.ovnNbCommand("--data=bare", "--no-heading", "--columns=_uuid",
+	output, err := c.ovnNbCommand("--data=bare", "--format=csv", "--no-heading", "--columns=_uuid,external_ids",
 		"find", "DNS", fmt.Sprintf("external_ids:vendor=%s", util.CniTypeName))
 	if err != nil {
 		return nil, fmt.Errorf("failed to list dns, %v", err)
@@ -603,16 +621,30 @@ func (c LegacyClient) ListDns() ([]string, error) {
 	lines := strings.Split(output, "\n")
 	result := make([]string, 0, len(lines))
 	for _, l := range lines {
-		l = strings.TrimSpace(l)
-		if len(l) > 0 {
-			result = append(result, l)
+		parts := strings.SplitN(strings.TrimSpace(l), ",", 2)
+		if len(parts[0]) == 0 {
+			continue
 		}
+		if len(parts) == 2 {
+			var owned bool
+			for _, id := range strings.Fields(strings.Trim(parts[1], `"`)) {
+				if strings.HasPrefix(id, dnsRecordKey+"=") {
+					owned = true
+					break
+				}
+			}
+			if owned {
+				continue
+			}
+		}
+		result = append(result, parts[0])
 	}
 	return result, nil
 }
 
 func (c LegacyClient) SetDnsRecordsToLogicalSwitch(logicalSwitch, dnsUuid string) error {
-	if _, err := c.ovnNbCommand("set", "Logical_Switch", logicalSwitch, fmt.Sprintf("dns_records=%s", dnsUuid)); err != nil {
+	// dns of dns records are set to the logical switch as well
+	if _, err := c.ovnNbCommand("add", "Logical_Switch", logicalSwitch, "dns_records", dnsUuid); err != nil {
 		return fmt.Errorf("failed to set dns to logical_switch, %v", err)
 	}
 	return nil
@@ -1091,9 +1123,10 @@ func (c LegacyClient) CreatePeerRouterPort(localRouter, remoteRouter, localRoute
 }
 
 type StaticRoute struct {
-	Policy  string
-	CIDR    string
-	NextHop string
+	Policy     string
+	CIDR       string
+	NextHop    string
+	RouteTable string
 }
 
 func (c LegacyClient) ListStaticRoute() ([]StaticRoute, error) {
@@ -1272,16 +1305,27 @@ func (c LegacyClient) GetStaticRouteList(router string) (routeList []*StaticRout
 	return parseLrRouteListOutput(output)
 }
 
+var routeTableRegexp = regexp.MustCompile(`^Route Table (.+):$`)
+
 var routeRegexp = regexp.MustCompile(`^\s*((\d+(\.\d+){3})|(([a-f0-9:]*:+)+[a-f0-9]?))(/\d+)?\s+((\d+(\.\d+){3})|(([a-f0-9:]*:+)+[a-f0-9]?))\s+(dst-ip|src-ip)(\s+.+)?$`)
 
 func parseLrRouteListOutput(output string) (routeList []*StaticRoute, err error) {
 	lines := strings.Split(output, "\n")
 	routeList = make([]*StaticRoute, 0, len(lines))
+	routeTable := util.MainRouteTable
 	for _, l := range lines {
 		if strings.Contains(l, "learned") {
 			continue
 		}
 
+		// routes are listed by route table since ovn 22.03
+		if sm := routeTableRegexp.FindStringSubmatch(strings.TrimSpace(l)); sm != nil {
+			if routeTable = sm[1]; routeTable == "<main>" {
+				routeTable = util.MainRouteTable
+			}
+			continue
+		}
+
 		if len(l) == 0 {
 			continue
 		}
@@ -1292,9 +1336,10 @@ func parseLrRouteListOutput(output string) (routeList []*StaticRoute, err error)
 
 		fields := strings.Fields(l)
 		routeList = append(routeList, &StaticRoute{
-			Policy:  fields[2],
-			CIDR:    fields[0],
-			NextHop: fields[1],
+			Policy:     fields[2],
+			CIDR:       fields[0],
+			NextHop:    fields[1],
+			RouteTable: routeTable,
 		})
 	}
 	return routeList, nil
@@ -1527,34 +1572,31 @@ func (c LegacyClient) ResetLogicalSwitchAcl(ls string) error {
 }
 
 // SetPrivateLogicalSwitch will drop all ingress traffic except allow subnets
-func (c LegacyClient) SetPrivateLogicalSwitch(ls, protocol, cidr string, allow []string) error {
+func (c LegacyClient) SetPrivateLogicalSwitch(ls, cidrBlock string, allow []string) error {
 	delArgs := []string{"acl-del", ls}
+	dropArgs := []string{"--", "--log", fmt.Sprintf("--name=%s", ls), fmt.Sprintf("--severity=%s", "warning"), "acl-add", ls, "to-lport", util.DefaultDropPriority, "ip", "drop"}
 	allowArgs := []string{}
-	var dropArgs []string
-	if protocol == kubeovnv1.ProtocolIPv4 {
-		dropArgs = []string{"--", "--log", fmt.Sprintf("--name=%s", ls), fmt.Sprintf("--severity=%s", "warning"), "acl-add", ls, "to-lport", util.DefaultDropPriority, "ip", "drop"}
-		allowArgs = append(allowArgs, "--", MayExist, "acl-add", ls, "to-lport", util.NodeAllowPriority, fmt.Sprintf("ip4.src==%s", c.NodeSwitchCIDR), "allow-related")
-		allowArgs = append(allowArgs, "--", MayExist, "acl-add", ls, "to-lport", util.SubnetAllowPriority, fmt.Sprintf(`ip4.src==%s && ip4.dst==%s`, cidr, cidr), "allow-related")
-	} else {
-		dropArgs = []string{"--", "--log", fmt.Sprintf("--name=%s", ls), fmt.Sprintf("--severity=%s", "warning"), "acl-add", ls, "to-lport", util.DefaultDropPriority, "ip", "drop"}
-		allowArgs = append(allowArgs, "--", MayExist, "acl-add", ls, "to-lport", util.NodeAllowPriority, fmt.Sprintf("ip6.src==%s", c.NodeSwitchCIDR), "allow-related")
-		allowArgs = append(allowArgs, "--", MayExist, "acl-add", ls, "to-lport", util.SubnetAllowPriority, fmt.Sprintf(`ip6.src==%s && ip6.dst==%s`, cidr, cidr), "allow-related")
-	}
-	ovnArgs := append(delArgs, dropArgs...)
+	nodeAllowed := make(map[string]bool)
+	for _, cidr := range strings.Split(cidrBlock, ",") {
+		protocol := util.CheckProtocol(cidr)
+		ipSuffix := "ip4"
+		if protocol == kubeovnv1.ProtocolIPv6 {
+			ipSuffix = "ip6"
+		}
+		if !nodeAllowed[protocol] {
+			nodeAllowed[protocol] = true
+			allowArgs = append(allowArgs, "--", MayExist, "acl-add", ls, "to-lport", util.NodeAllowPriority, fmt.Sprintf("%s.src==%s", ipSuffix, c.NodeSwitchCIDR), "allow-related")
+		}
+		allowArgs = append(allowArgs, "--", MayExist, "acl-add", ls, "to-lport", util.SubnetAllowPriority, fmt.Sprintf(`%s.src==%s && %s.dst==%s`, ipSuffix, cidr, ipSuffix, cidr), "allow-related")
 
-	for _, subnet := range allow {
-		if strings.TrimSpace(subnet) != "" {
-			var match string
-			switch protocol {
-			case kubeovnv1.ProtocolIPv4:
-				match = fmt.Sprintf("(ip4.src==%s && ip4.dst==%s) || (ip4.src==%s && ip4.dst==%s)", strings.TrimSpace(subnet), cidr, cidr, strings.TrimSpace(subnet))
-			case kubeovnv1.ProtocolIPv6:
-				match = fmt.Sprintf("(ip6.src==%s && ip6.dst==%s) || (ip6.src==%s && ip6.dst==%s)", strings.TrimSpace(subnet), cidr, cidr, strings.TrimSpace(subnet))
+		for _, subnet := range allow {
+			if strings.TrimSpace(subnet) != "" {
+				match := fmt.Sprintf("(%s.src==%s && %s.dst==%s) || (%s.src==%s && %s.dst==%s)", ipSuffix, strings.TrimSpace(subnet), ipSuffix, cidr, ipSuffix, cidr, ipSuffix, strings.TrimSpace(subnet))
+				allowArgs = append(allowArgs, "--", MayExist, "acl-add", ls, "to-lport", util.SubnetAllowPriority, match, "allow-related")
 			}
-
-			allowArgs = append(allowArgs, "--", MayExist, "acl-add", ls, "to-lport", util.SubnetAllowPriority, match, "allow-related")
 		}
 	}
+	ovnArgs := append(delArgs, dropArgs...)
 	ovnArgs = append(ovnArgs, allowArgs...)
 
 	_, err := c.ovnNbCommand(ovnArgs...)
@@ -28,6 +28,17 @@ func Test_parseLrRouteListOutput(t *testing.T) {
 	routeList, err = parseLrRouteListOutput(output)
 	ast.Nil(err)
 	ast.Equal(6, len(routeList))
+
+	output = `IPv4 Routes
+Route Table <main>:
+             10.17.0.0/16                10.0.1.254 dst-ip
+Route Table rtb-fw:
+                0.0.0.0/0                10.0.1.253 dst-ip`
+	routeList, err = parseLrRouteListOutput(output)
+	ast.Nil(err)
+	ast.Equal(2, len(routeList))
+	ast.Equal("", routeList[0].RouteTable)
+	ast.Equal("rtb-fw", routeList[1].RouteTable)
 }
 
 func Test_parseLrPolicyRouteListOutput(t *testing.T) {
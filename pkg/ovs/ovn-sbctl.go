@@ -131,3 +131,20 @@ func (c LegacyClient) GetAllChassis() ([]string, error) {
 	}
 	return result, nil
 }
+
+// GetBFDStatus get status of bfd sessions from the logical router port, keyed by destination ip
+func (c LegacyClient) GetBFDStatus(logicalPort string) (map[string]string, error) {
+	output, err := c.ovnSbCommand("--format=csv", "--no-heading", "--data=bare", "--columns=dst_ip,status", "find", "bfd", fmt.Sprintf("logical_port=%s", logicalPort))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find bfd of logical router port %s, %v", logicalPort, err)
+	}
+	result := make(map[string]string)
+	for _, l := range strings.Split(output, "\n") {
+		fields := strings.Split(strings.TrimSpace(l), ",")
+		if len(fields) != 2 {
+			continue
+		}
+		result[strings.TrimSpace(fields[0])] = strings.TrimSpace(fields[1])
+	}
+	return result, nil
+}
@@ -1,6 +1,7 @@
 package ovs
 
 import (
+	"crypto/sha1"
 	"fmt"
 	"os/exec"
 	"strconv"
@@ -9,6 +10,7 @@ import (
 
 	"k8s.io/klog/v2"
 
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
 	"github.com/kubeovn/kube-ovn/pkg/util"
 )
 
@@ -684,3 +686,117 @@ func ListExternalIds(table string) (map[string]string, error) {
 	}
 	return result, nil
 }
+
+// GetPortUUIDByIfaceID returns uuid of the port whose interface has the iface-id,
+// an empty string is returned if the port does not exist
+func GetPortUUIDByIfaceID(ifaceID string) (string, error) {
+	interfaceList, err := ovsFind("interface", "name", fmt.Sprintf("external-ids:iface-id=%s", ifaceID))
+	if err != nil || len(interfaceList) == 0 {
+		return "", err
+	}
+	portUUIDs, err := ovsFind("port", "_uuid", fmt.Sprintf("name=%s", interfaceList[0]))
+	if err != nil || len(portUUIDs) == 0 {
+		return "", err
+	}
+	return portUUIDs[0], nil
+}
+
+// mirrorSessionPortName returns name of the tunnel port to the collector of the mirror session,
+// the name is derived from the session name to fit in the limit of interface names
+func mirrorSessionPortName(session string) string {
+	return fmt.Sprintf("ms%x", sha1.Sum([]byte(session)))[:15]
+}
+
+func ovsUUIDSet(uuids []string) string {
+	return fmt.Sprintf("[%s]", strings.Join(uuids, ","))
+}
+
+// SetMirrorSession create or update the gre/erspan port to the collector and the mirror of the session,
+// packets sent by srcPorts and received by dstPorts are mirrored to the collector
+func SetMirrorSession(session string, dst kubeovnv1.MirrorDestination, snaplen int, srcPorts, dstPorts []string) error {
+	portName := mirrorSessionPortName(session)
+	options := []string{fmt.Sprintf("remote_ip=%q", dst.RemoteIP)}
+	if dst.Key != 0 {
+		options = append(options, fmt.Sprintf("key=\"%d\"", dst.Key))
+	}
+	if dst.Type == kubeovnv1.MirrorTypeERSPAN {
+		version := dst.ERSPANVersion
+		if version == 0 {
+			version = 1
+		}
+		options = append(options, fmt.Sprintf("erspan_ver=\"%d\"", version))
+	}
+
+	mirrors, err := ovsFind("mirror", "_uuid", fmt.Sprintf("external_ids:mirror-session=%s", session))
+	if err != nil {
+		return err
+	}
+
+	columns := []string{
+		"output_port=@p",
+		"select_src_port=" + ovsUUIDSet(srcPorts),
+		"select_dst_port=" + ovsUUIDSet(dstPorts),
+		"snaplen=[]",
+	}
+	if snaplen != 0 {
+		columns[3] = fmt.Sprintf("snaplen=%d", snaplen)
+	}
+
+	args := []string{MayExist, "add-port", "br-int", portName, "--",
+		"set", "interface", portName, "type=" + dst.Type, fmt.Sprintf("options={%s}", strings.Join(options, ",")), "--",
+		"set", "port", portName, "external_ids:vendor=" + util.CniTypeName, "external_ids:mirror-session=" + session, "--",
+		"--id=@p", "get", "port", portName, "--",
+	}
+	if len(mirrors) == 0 {
+		args = append(args, "--id=@m", "create", "mirror", "name="+session,
+			"external_ids:vendor="+util.CniTypeName, "external_ids:mirror-session="+session)
+		args = append(args, columns...)
+		args = append(args, "--", "add", "bridge", "br-int", "mirrors", "@m")
+	} else {
+		args = append(args, "set", "mirror", mirrors[0])
+		args = append(args, columns...)
+	}
+
+	if output, err := Exec(args...); err != nil {
+		return fmt.Errorf("failed to set mirror session %s: %v, %q", session, err, output)
+	}
+	return nil
+}
+
+// DeleteMirrorSession delete the mirror and the gre/erspan port of the session
+func DeleteMirrorSession(session string) error {
+	mirrors, err := ovsFind("mirror", "_uuid", fmt.Sprintf("external_ids:mirror-session=%s", session))
+	if err != nil {
+		return err
+	}
+
+	var args []string
+	for _, mirror := range mirrors {
+		args = append(args, "remove", "bridge", "br-int", "mirrors", mirror, "--")
+	}
+	args = append(args, IfExists, "del-port", "br-int", mirrorSessionPortName(session))
+	if output, err := Exec(args...); err != nil {
+		return fmt.Errorf("failed to delete mirror session %s: %v, %q", session, err, output)
+	}
+	return nil
+}
+
+// ListMirrorSessions returns names of the mirror sessions configured on the node
+func ListMirrorSessions() ([]string, error) {
+	output, err := Exec("--data=bare", "--format=csv", "--no-heading", "--columns=external_ids", "find", "port", "external_ids:mirror-session!=[]")
+	if err != nil {
+		klog.Errorf("failed to list mirror session ports, %v", err)
+		return nil, err
+	}
+
+	var sessions []string
+	for _, l := range strings.Split(output, "\n") {
+		for _, externalID := range strings.Fields(strings.Trim(strings.TrimSpace(l), "\"")) {
+			if strings.HasPrefix(externalID, "mirror-session=") {
+				sessions = append(sessions, strings.TrimPrefix(externalID, "mirror-session="))
+				break
+			}
+		}
+	}
+	return sessions, nil
+}
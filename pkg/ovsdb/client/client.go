@@ -102,7 +102,9 @@ func NewNbClient(addr string) (client.Client, error) {
 	monitor := c.NewMonitor(
 		client.WithTable(&ovnnb.ACL{}),
 		client.WithTable(&ovnnb.AddressSet{}),
+		client.WithTable(&ovnnb.BFD{}),
 		client.WithTable(&ovnnb.DHCPOptions{}),
+		client.WithTable(&ovnnb.DNS{}),
 		client.WithTable(&ovnnb.GatewayChassis{}),
 		client.WithTable(&ovnnb.LoadBalancer{}),
 		client.WithTable(&ovnnb.LogicalRouterPolicy{}),
@@ -114,6 +116,7 @@ func NewNbClient(addr string) (client.Client, error) {
 		client.WithTable(&ovnnb.NAT{}),
 		client.WithTable(&ovnnb.NBGlobal{}),
 		client.WithTable(&ovnnb.PortGroup{}),
+		client.WithTable(&ovnnb.QoS{}),
 	)
 	monitor.Method = ovsdb.ConditionalMonitorRPC
 	if _, err = c.Monitor(context.TODO(), monitor); err != nil {
@@ -15,6 +15,7 @@ var (
 // LogicalRouterStaticRoute defines an object in Logical_Router_Static_Route table
 type LogicalRouterStaticRoute struct {
 	UUID        string                          `ovsdb:"_uuid"`
+	BFD         *string                         `ovsdb:"bfd"`
 	ExternalIDs map[string]string               `ovsdb:"external_ids"`
 	IPPrefix    string                          `ovsdb:"ip_prefix"`
 	Nexthop     string                          `ovsdb:"nexthop"`
@@ -0,0 +1,172 @@
+package util
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+)
+
+// CompileAclRules compile acl rules of the subnet into acls of the logical switch
+func CompileAclRules(cidrBlock string, rules []kubeovnv1.AclRule) ([]kubeovnv1.Acl, error) {
+	var acls []kubeovnv1.Acl
+	for i, rule := range rules {
+		compiled, err := CompileAclRule(cidrBlock, rule)
+		if err != nil {
+			return nil, fmt.Errorf("aclRules[%d]: %v", i, err)
+		}
+		acls = append(acls, compiled...)
+	}
+	return acls, nil
+}
+
+// CompileAclRule compile an acl rule into one acl for each address family of the subnet cidr blocks,
+// ingress rules match traffic entering the subnet and egress rules match traffic leaving the subnet
+func CompileAclRule(cidrBlock string, rule kubeovnv1.AclRule) ([]kubeovnv1.Acl, error) {
+	var direction, local, remote string
+	switch rule.Direction {
+	case kubeovnv1.AclRuleIngress:
+		direction, local, remote = "to-lport", "dst", "src"
+	case kubeovnv1.AclRuleEgress:
+		direction, local, remote = "from-lport", "src", "dst"
+	default:
+		return nil, fmt.Errorf("%q is not a valid direction", rule.Direction)
+	}
+
+	var action string
+	switch rule.Action {
+	case kubeovnv1.AclRuleActionAllow:
+		action = "allow-related"
+	case kubeovnv1.AclRuleActionDrop, kubeovnv1.AclRuleActionReject:
+		action = rule.Action
+	default:
+		return nil, fmt.Errorf("%q is not a valid action", rule.Action)
+	}
+
+	if rule.Priority < 0 || rule.Priority > AclRuleMaxPriority {
+		return nil, fmt.Errorf("priority %d is not in range 0-%d", rule.Priority, AclRuleMaxPriority)
+	}
+
+	portMatch, err := aclRulePortMatch(rule.Protocol, rule.Ports)
+	if err != nil {
+		return nil, err
+	}
+
+	remoteCIDRs := map[string][]string{}
+	for _, cidr := range rule.CIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return nil, fmt.Errorf("%s is not a valid cidr", cidr)
+		}
+		protocol := CheckProtocol(cidr)
+		remoteCIDRs[protocol] = append(remoteCIDRs[protocol], cidr)
+	}
+
+	localCIDRs := map[string][]string{}
+	var protocols []string
+	for _, cidr := range strings.Split(cidrBlock, ",") {
+		protocol := CheckProtocol(cidr)
+		if len(localCIDRs[protocol]) == 0 {
+			protocols = append(protocols, protocol)
+		}
+		localCIDRs[protocol] = append(localCIDRs[protocol], cidr)
+	}
+	for protocol, cidrs := range remoteCIDRs {
+		if len(localCIDRs[protocol]) == 0 {
+			return nil, fmt.Errorf("cidrs %s do not match protocol of subnet cidr %s", strings.Join(cidrs, ","), cidrBlock)
+		}
+	}
+
+	acls := make([]kubeovnv1.Acl, 0, len(protocols))
+	for _, protocol := range protocols {
+		// rules with cidrs of the other address family only do not apply to this one
+		if len(rule.CIDRs) != 0 && len(remoteCIDRs[protocol]) == 0 {
+			continue
+		}
+
+		ipVersion := "ip4"
+		if protocol == kubeovnv1.ProtocolIPv6 {
+			ipVersion = "ip6"
+		}
+
+		matches := []string{fmt.Sprintf("%s.%s == {%s}", ipVersion, local, strings.Join(localCIDRs[protocol], ", "))}
+		if len(remoteCIDRs[protocol]) != 0 {
+			matches = append(matches, fmt.Sprintf("%s.%s == {%s}", ipVersion, remote, strings.Join(remoteCIDRs[protocol], ", ")))
+		}
+		switch rule.Protocol {
+		case "":
+		case "icmp":
+			if protocol == kubeovnv1.ProtocolIPv6 {
+				matches = append(matches, "icmp6")
+			} else {
+				matches = append(matches, "icmp4")
+			}
+		default:
+			matches = append(matches, rule.Protocol)
+		}
+		if portMatch != "" {
+			matches = append(matches, portMatch)
+		}
+
+		acls = append(acls, kubeovnv1.Acl{
+			Direction: direction,
+			Priority:  AclRuleBasePriority + rule.Priority,
+			Match:     strings.Join(matches, " && "),
+			Action:    action,
+		})
+	}
+
+	return acls, nil
+}
+
+// aclRulePortMatch return match of destination ports like "tcp.dst == {80, 443}",
+// port ranges are matched by "(tcp.dst >= 8000 && tcp.dst <= 8080)"
+func aclRulePortMatch(protocol string, ports []string) (string, error) {
+	switch protocol {
+	case "", "icmp":
+		if len(ports) != 0 {
+			return "", fmt.Errorf("ports are only supported by tcp, udp and sctp")
+		}
+		return "", nil
+	case "tcp", "udp", "sctp":
+	default:
+		return "", fmt.Errorf("%q is not a valid protocol", protocol)
+	}
+
+	var single, matches []string
+	for _, port := range ports {
+		fields := strings.Split(port, "-")
+		if len(fields) > 2 {
+			return "", fmt.Errorf("%s is not a valid port or port range", port)
+		}
+		values := make([]int, 0, len(fields))
+		for _, field := range fields {
+			value, err := strconv.Atoi(field)
+			if err != nil || value < 1 || value > 65535 {
+				return "", fmt.Errorf("%s is not a valid port or port range", port)
+			}
+			values = append(values, value)
+		}
+		if len(values) == 1 || values[0] == values[1] {
+			single = append(single, strconv.Itoa(values[0]))
+			continue
+		}
+		if values[0] > values[1] {
+			return "", fmt.Errorf("%s is not a valid port or port range", port)
+		}
+		matches = append(matches, fmt.Sprintf("(%s.dst >= %d && %s.dst <= %d)", protocol, values[0], protocol, values[1]))
+	}
+
+	if len(single) != 0 {
+		matches = append([]string{fmt.Sprintf("%s.dst == {%s}", protocol, strings.Join(single, ", "))}, matches...)
+	}
+	switch len(matches) {
+	case 0:
+		return "", nil
+	case 1:
+		return matches[0], nil
+	default:
+		return fmt.Sprintf("(%s)", strings.Join(matches, " || ")), nil
+	}
+}
@@ -70,6 +70,8 @@ const (
 
 	ExcludeIpsAnnotation = "ovn.kubernetes.io/exclude_ips"
 
+	SubnetMigrationSourceAnnotation = "ovn.kubernetes.io/migration_source"
+
 	IngressRateAnnotation = "ovn.kubernetes.io/ingress_rate"
 	EgressRateAnnotation  = "ovn.kubernetes.io/egress_rate"
 
@@ -86,6 +88,10 @@ const (
 	ExGatewayLabel     = "ovn.kubernetes.io/external-gw"
 	VpcNatGatewayLabel = "ovn.kubernetes.io/vpc-nat-gw"
 	VpcLbLabel         = "ovn.kubernetes.io/vpc_lb"
+	IPReservationLabel = "ovn.kubernetes.io/ip_reservation"
+
+	SubnetTemplateLabel          = "ovn.kubernetes.io/subnet-template"
+	SubnetTemplateNamespaceLabel = "ovn.kubernetes.io/subnet-template-namespace"
 
 	ProtocolTCP = "tcp"
 	ProtocolUDP = "udp"
@@ -113,6 +119,10 @@ const (
 	SubnetAllowPriority = "1001"
 	DefaultDropPriority = "1000"
 
+	// acls compiled from subnet acl rules take priorities from AclRuleBasePriority to AclRuleBasePriority+AclRuleMaxPriority
+	AclRuleBasePriority = 1100
+	AclRuleMaxPriority  = 899
+
 	GeneveHeaderLength = 100
 	TcpIpHeaderLength  = 40
 
@@ -125,8 +135,10 @@ const (
 	InterconnectionConfig  = "ovn-ic-config"
 	ExternalGatewayConfig  = "ovn-external-gw-config"
 	InterconnectionSwitch  = "ts"
+	VpcPeeringSwitchPrefix = "vpc-peering-"
 	ExternalGatewaySwitch  = "ovn-external"
 	VpcNatGatewayConfig    = "ovn-vpc-nat-gw-config"
+	IPAMCheckpointConfig   = "kube-ovn-ipam-checkpoint"
 	VpcExternalNet         = "ovn-vpc-external-network"
 	VpcLbNetworkAttachment = "ovn-vpc-lb"
 
@@ -135,11 +147,16 @@ const (
 	DefaultVpc    = "ovn-cluster"
 	DefaultSubnet = "ovn-default"
 
+	// MainRouteTable is the route table of routes applied to all router ports
+	MainRouteTable = ""
+
 	EcmpRouteType   = "ecmp"
 	NormalRouteType = "normal"
 
 	NodeRouterPolicyPriority = 30000
 
+	SubnetQoSPriority = 2000
+
 	PodNicAnnotation = "ovn.kubernetes.io/pod_nic_type"
 	VethType         = "veth-pair"
 	OffloadType      = "offload-port"
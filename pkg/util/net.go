@@ -16,9 +16,16 @@ import (
 	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
 )
 
+// DefaultMacPrefix is the prefix of generated mac addresses
+const DefaultMacPrefix = "00:00:00"
+
 // GenerateMac generates mac address.
 func GenerateMac() string {
-	prefix := "00:00:00"
+	return GenerateMacWithPrefix(DefaultMacPrefix)
+}
+
+// GenerateMacWithPrefix generates mac address starting with the 3 bytes prefix.
+func GenerateMacWithPrefix(prefix string) string {
 	b := make([]byte, 3)
 	_, err := rand.Read(b)
 	if err != nil {
@@ -29,6 +36,31 @@ func GenerateMac() string {
 	return mac
 }
 
+// IPDerivedMac returns the mac address consisting of the prefix and the last 3 bytes of the ip.
+func IPDerivedMac(prefix, ip string) string {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return ""
+	}
+	if v4 := addr.To4(); v4 != nil {
+		addr = v4
+	}
+	n := len(addr)
+	return fmt.Sprintf("%s:%02X:%02X:%02X", prefix, addr[n-3], addr[n-2], addr[n-1])
+}
+
+// ValidateMacPrefix checks whether the prefix is the first 3 bytes of a unicast mac address.
+func ValidateMacPrefix(prefix string) error {
+	mac, err := net.ParseMAC(prefix + ":00:00:00")
+	if err != nil || len(mac) != 6 {
+		return fmt.Errorf("%s is not a valid mac prefix", prefix)
+	}
+	if mac[0]&1 != 0 {
+		return fmt.Errorf("mac prefix %s is a multicast address", prefix)
+	}
+	return nil
+}
+
 func Ip2BigInt(ipStr string) *big.Int {
 	ipBigInt := big.NewInt(0)
 	if CheckProtocol(ipStr) == kubeovnv1.ProtocolIPv4 {
@@ -114,6 +146,56 @@ func CIDRConflict(a, b string) bool {
 	return false
 }
 
+// MaxCarvedBlockBits limits a supernet to be carved into at most 2^16 blocks
+const MaxCarvedBlockBits = 16
+
+// CarveCIDRBlock return the first block of the prefix length in the supernet which conflicts with none of the used cidrs
+func CarveCIDRBlock(supernet string, prefixLength int, used []string) (string, error) {
+	_, ipNet, err := net.ParseCIDR(supernet)
+	if err != nil {
+		return "", fmt.Errorf("%s is not a valid cidr", supernet)
+	}
+	ones, bits := ipNet.Mask.Size()
+	if prefixLength < ones || prefixLength > bits || prefixLength-ones > MaxCarvedBlockBits {
+		return "", fmt.Errorf("prefix length %d is not in range %d-%d", prefixLength, ones, int(math.Min(float64(bits), float64(ones+MaxCarvedBlockBits))))
+	}
+
+	base := big.NewInt(0).SetBytes(ipNet.IP)
+	step := big.NewInt(0).Lsh(big.NewInt(1), uint(bits-prefixLength))
+	for i := 0; i < 1<<uint(prefixLength-ones); i++ {
+		ipInt := big.NewInt(0).Add(base, big.NewInt(0).Mul(step, big.NewInt(int64(i))))
+		block := fmt.Sprintf("%s/%d", net.IP(ipInt.FillBytes(make([]byte, bits/8))), prefixLength)
+		conflict := false
+		for _, cidr := range used {
+			if CIDRConflict(block, cidr) {
+				conflict = true
+				break
+			}
+		}
+		if !conflict {
+			return block, nil
+		}
+	}
+	return "", fmt.Errorf("no free block of prefix length %d in %s", prefixLength, supernet)
+}
+
+// ReverseDNSName return the name of PTR records for the ip, e.g. 4.3.2.1.in-addr.arpa for 1.2.3.4
+func ReverseDNSName(ipStr string) (string, error) {
+	ip := net.ParseIP(ipStr)
+	if ip == nil {
+		return "", fmt.Errorf("%s is not a valid ip", ipStr)
+	}
+
+	if v4 := ip.To4(); v4 != nil {
+		return fmt.Sprintf("%d.%d.%d.%d.in-addr.arpa", v4[3], v4[2], v4[1], v4[0]), nil
+	}
+	labels := make([]string, 0, 2*net.IPv6len+1)
+	for i := net.IPv6len - 1; i >= 0; i-- {
+		labels = append(labels, fmt.Sprintf("%x", ip[i]&0xf), fmt.Sprintf("%x", ip[i]>>4))
+	}
+	return strings.Join(append(labels, "ip6.arpa"), "."), nil
+}
+
 func CIDRContainIP(cidrStr, ipStr string) bool {
 	var containFlag bool
 	for _, cidr := range strings.Split(cidrStr, ",") {
@@ -302,19 +384,29 @@ func GetIpAddrWithMask(ip, cidr string) string {
 	if cidr == CIDRNone {
 		return CIDRNone
 	}
-	var ipAddr string
-	if CheckProtocol(cidr) == kubeovnv1.ProtocolDual {
-		cidrBlocks := strings.Split(cidr, ",")
-		ips := strings.Split(ip, ",")
-		if len(cidrBlocks) == 2 && len(ips) == 2 {
-			v4IP := fmt.Sprintf("%s/%s", ips[0], strings.Split(cidrBlocks[0], "/")[1])
-			v6IP := fmt.Sprintf("%s/%s", ips[1], strings.Split(cidrBlocks[1], "/")[1])
-			ipAddr = v4IP + "," + v6IP
-		}
-	} else {
-		ipAddr = fmt.Sprintf("%s/%s", ip, strings.Split(cidr, "/")[1])
+	cidrBlocks := strings.Split(cidr, ",")
+	if len(cidrBlocks) == 1 {
+		return fmt.Sprintf("%s/%s", ip, strings.Split(cidr, "/")[1])
 	}
-	return ipAddr
+
+	// each ip is paired with the cidr block at the same index
+	ips := strings.Split(ip, ",")
+	if len(cidrBlocks) != len(ips) {
+		return ""
+	}
+	ipAddrs := make([]string, 0, len(ips))
+	for i := range ips {
+		ipAddrs = append(ipAddrs, fmt.Sprintf("%s/%s", ips[i], strings.Split(cidrBlocks[i], "/")[1]))
+	}
+	return strings.Join(ipAddrs, ",")
+}
+
+// JoinCIDRBlocks returns the cidr block of a subnet followed by its extra cidr blocks
+func JoinCIDRBlocks(cidrBlock string, extraCIDRBlocks []string) string {
+	if len(extraCIDRBlocks) == 0 {
+		return cidrBlock
+	}
+	return strings.Join(append([]string{cidrBlock}, extraCIDRBlocks...), ",")
 }
 
 func GetIpWithoutMask(ipStr string) string {
@@ -451,3 +543,13 @@ func GatewayContains(gatewayNodeStr, gateway string) bool {
 func JoinHostPort(host string, port int32) string {
 	return net.JoinHostPort(host, strconv.FormatInt(int64(port), 10))
 }
+
+// VpcPeeringTransitSwitch get the name of the transit switch connecting two vpcs in different azs,
+// the name is the same no matter in which az it is computed
+func VpcPeeringTransitSwitch(az, vpc, remoteAZ, remoteVpc string) string {
+	local, remote := fmt.Sprintf("%s.%s", az, vpc), fmt.Sprintf("%s.%s", remoteAZ, remoteVpc)
+	if local > remote {
+		local, remote = remote, local
+	}
+	return fmt.Sprintf("%s%s-%s", VpcPeeringSwitchPrefix, local, remote)
+}
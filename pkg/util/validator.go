@@ -2,12 +2,15 @@ package util
 
 import (
 	"fmt"
+	"math"
 	"net"
 	"os"
 	"strconv"
 	"strings"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
 )
@@ -38,6 +41,29 @@ func cidrConflict(cidr string) error {
 	return nil
 }
 
+func validateExtraCIDRBlocks(subnet kubeovnv1.Subnet) error {
+	protocol := CheckProtocol(subnet.Spec.CIDRBlock)
+	cidrBlocks := strings.Split(subnet.Spec.CIDRBlock, ",")
+	for _, cidr := range subnet.Spec.ExtraCIDRBlocks {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("extra cidr %s is not a valid cidr block", cidr)
+		}
+		if protocol != kubeovnv1.ProtocolDual && CheckProtocol(cidr) != protocol {
+			return fmt.Errorf("extra cidr %s does not match protocol %s of cidr %s", cidr, protocol, subnet.Spec.CIDRBlock)
+		}
+		if err := cidrConflict(cidr); err != nil {
+			return err
+		}
+		for _, c := range cidrBlocks {
+			if CIDRConflict(cidr, c) {
+				return fmt.Errorf("extra cidr %s conflicts with cidr %s", cidr, c)
+			}
+		}
+		cidrBlocks = append(cidrBlocks, cidr)
+	}
+	return nil
+}
+
 func ValidateSubnet(subnet kubeovnv1.Subnet) error {
 	if subnet.Spec.Gateway != "" && !CIDRContainIP(subnet.Spec.CIDRBlock, subnet.Spec.Gateway) {
 		return fmt.Errorf(" gateway %s is not in cidr %s", subnet.Spec.Gateway, subnet.Spec.CIDRBlock)
@@ -45,6 +71,60 @@ func ValidateSubnet(subnet kubeovnv1.Subnet) error {
 	if err := cidrConflict(subnet.Spec.CIDRBlock); err != nil {
 		return err
 	}
+	// an ipv4 subnet is upgraded to dual stack by appending the ipv6 cidr block
+	if CheckProtocol(subnet.Spec.CIDRBlock) == kubeovnv1.ProtocolDual &&
+		CheckProtocol(strings.Split(subnet.Spec.CIDRBlock, ",")[0]) != kubeovnv1.ProtocolIPv4 {
+		return fmt.Errorf("ipv4 cidr should be the first one of dual stack cidr blocks %s", subnet.Spec.CIDRBlock)
+	}
+	if err := validateExtraCIDRBlocks(subnet); err != nil {
+		return err
+	}
+	if subnet.Spec.IPReuseTTL < 0 || subnet.Spec.IPQuarantine < 0 {
+		return fmt.Errorf("ipReuseTTL and ipQuarantine must not be negative")
+	}
+	switch subnet.Spec.AllocationStrategy {
+	case "", kubeovnv1.AllocationStrategySequential, kubeovnv1.AllocationStrategyRandom, kubeovnv1.AllocationStrategyLeastRecentlyReleased:
+	default:
+		return fmt.Errorf("%s is not a valid allocation strategy", subnet.Spec.AllocationStrategy)
+	}
+	if subnet.Spec.NodeBlockSize < 0 {
+		return fmt.Errorf("nodeBlockSize must not be negative")
+	}
+	if subnet.Spec.MacPrefix != "" {
+		if err := ValidateMacPrefix(subnet.Spec.MacPrefix); err != nil {
+			return err
+		}
+	}
+	switch subnet.Spec.MacPolicy {
+	case "", kubeovnv1.MacPolicyRandom, kubeovnv1.MacPolicyIPDerived:
+	default:
+		return fmt.Errorf("%s is not a valid mac policy", subnet.Spec.MacPolicy)
+	}
+	if subnet.Spec.UtilizationThreshold < 0 || subnet.Spec.UtilizationThreshold > 100 {
+		return fmt.Errorf("utilizationThreshold must be between 0 and 100")
+	}
+	if migration := subnet.Spec.Migration; migration != nil {
+		if err := CheckCidrs(migration.CIDRBlock); err != nil {
+			return fmt.Errorf("migration cidr %s is not a valid cidrblock", migration.CIDRBlock)
+		}
+		if CheckProtocol(migration.CIDRBlock) != CheckProtocol(subnet.Spec.CIDRBlock) {
+			return fmt.Errorf("migration cidr %s does not match protocol of cidr %s", migration.CIDRBlock, subnet.Spec.CIDRBlock)
+		}
+		if err := cidrConflict(migration.CIDRBlock); err != nil {
+			return err
+		}
+		if migration.BatchSize < 0 || migration.BatchInterval < 0 {
+			return fmt.Errorf("migration batchSize and batchInterval must not be negative")
+		}
+	}
+	if qos := subnet.Spec.QoS; qos != nil {
+		if qos.IngressRate < 0 || qos.IngressBurst < 0 || qos.EgressRate < 0 || qos.EgressBurst < 0 {
+			return fmt.Errorf("qos rate and burst must not be negative")
+		}
+		if qos.EgressDSCP < 0 || qos.EgressDSCP > 63 {
+			return fmt.Errorf("qos egressDSCP %d is not in range 0-63", qos.EgressDSCP)
+		}
+	}
 	excludeIps := subnet.Spec.ExcludeIps
 	for _, ipr := range excludeIps {
 		ips := strings.Split(ipr, "..")
@@ -115,6 +195,167 @@ func ValidateSubnet(subnet kubeovnv1.Subnet) error {
 			}
 		}
 	}
+
+	if err := ValidateDHCPOptions(kubeovnv1.ProtocolIPv4, subnet.Spec.DHCPv4ExtraOptions); err != nil {
+		return err
+	}
+	if err := ValidateDHCPOptions(kubeovnv1.ProtocolIPv6, subnet.Spec.DHCPv6ExtraOptions); err != nil {
+		return err
+	}
+	for ns, quota := range subnet.Spec.NamespaceIPQuotas {
+		if quota < 0 {
+			return fmt.Errorf("ip quota %d of namespace %s is negative", quota, ns)
+		}
+	}
+	return nil
+}
+
+// dhcp option names supported by ovn, see DHCP_Options in ovn-nb(5)
+var (
+	dhcpV4OptionNames = []string{
+		"arp_cache_timeout", "bootfile_name", "bootfile_name_alt", "broadcast_address", "classless_static_route",
+		"default_ttl", "dns_server", "domain_name", "domain_search_list", "ethernet_encap", "hostname",
+		"ip_forward_enable", "lease_time", "log_server", "lpr_server", "mtu", "ms_classless_static_route",
+		"netbios_name_server", "netbios_node_type", "netmask", "next_server", "nis_server", "ntp_server",
+		"path_prefix", "policy_filter", "router", "router_discovery", "router_solicitation", "server_id",
+		"server_mac", "swap_server", "T1", "T2", "tcp_keepalive_interval", "tcp_ttl", "tftp_server",
+		"tftp_server_address", "wpad",
+	}
+	dhcpV6OptionNames = []string{
+		"bootfile_name", "bootfile_name_alt", "dhcpv6_stateless", "dns_server", "domain_search", "fqdn", "server_id",
+	}
+)
+
+// ValidateDHCPOptions check that options of the protocol are all supported by ovn and have a value
+func ValidateDHCPOptions(protocol string, options map[string]string) error {
+	names := dhcpV4OptionNames
+	if protocol == kubeovnv1.ProtocolIPv6 {
+		names = dhcpV6OptionNames
+	}
+
+	for name, value := range options {
+		if !ContainsString(names, name) {
+			return fmt.Errorf("%s dhcp option %s is not supported by ovn", protocol, name)
+		}
+		if strings.TrimSpace(value) == "" {
+			return fmt.Errorf("%s dhcp option %s has an empty value", protocol, name)
+		}
+	}
+	return nil
+}
+
+func ValidateMirrorSession(session *kubeovnv1.MirrorSession) error {
+	spec := session.Spec
+	if len(spec.Subnets) == 0 && len(spec.Namespaces) == 0 && spec.PodSelector == nil {
+		return fmt.Errorf("at least one of subnets, namespaces and podSelector should be specified")
+	}
+	if spec.PodSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(spec.PodSelector); err != nil {
+			return fmt.Errorf("invalid podSelector: %v", err)
+		}
+	}
+
+	switch spec.Direction {
+	case "", kubeovnv1.MirrorDirectionIngress, kubeovnv1.MirrorDirectionEgress, kubeovnv1.MirrorDirectionBoth:
+	default:
+		return fmt.Errorf("%q is not a valid direction", spec.Direction)
+	}
+	// ovs does not truncate packets to less than an ethernet header
+	if spec.Snaplen != 0 && (spec.Snaplen < 14 || spec.Snaplen > 65535) {
+		return fmt.Errorf("snaplen %d is not in range 14-65535", spec.Snaplen)
+	}
+
+	dst := spec.Destination
+	if net.ParseIP(dst.RemoteIP) == nil {
+		return fmt.Errorf("%q is not a valid remote ip", dst.RemoteIP)
+	}
+	switch dst.Type {
+	case kubeovnv1.MirrorTypeGRE:
+		if dst.ERSPANVersion != 0 {
+			return fmt.Errorf("erspanVersion is only supported by erspan")
+		}
+		if dst.Key < 0 || int64(dst.Key) > math.MaxUint32 {
+			return fmt.Errorf("gre key %d is not in range 0-%d", dst.Key, uint32(math.MaxUint32))
+		}
+	case kubeovnv1.MirrorTypeERSPAN:
+		if dst.ERSPANVersion != 0 && dst.ERSPANVersion != 1 && dst.ERSPANVersion != 2 {
+			return fmt.Errorf("erspanVersion %d is not 1 or 2", dst.ERSPANVersion)
+		}
+		if dst.Key < 0 || dst.Key > 1023 {
+			return fmt.Errorf("erspan session id %d is not in range 0-1023", dst.Key)
+		}
+	default:
+		return fmt.Errorf("%q is not a valid destination type", dst.Type)
+	}
+	return nil
+}
+
+func ValidateSubnetTemplate(template *kubeovnv1.SubnetTemplate) error {
+	spec := template.Spec
+	_, ipNet, err := net.ParseCIDR(spec.Supernet)
+	if err != nil || strings.Contains(spec.Supernet, ",") {
+		return fmt.Errorf("supernet %q is not a valid cidr", spec.Supernet)
+	}
+	ones, bits := ipNet.Mask.Size()
+	if spec.PrefixLength < ones || spec.PrefixLength > bits || spec.PrefixLength-ones > MaxCarvedBlockBits {
+		return fmt.Errorf("prefixLength %d is not in range %d-%d", spec.PrefixLength, ones, int(math.Min(float64(bits), float64(ones+MaxCarvedBlockBits))))
+	}
+	// a subnet for every namespace including the system ones is never wanted
+	if spec.NamespaceSelector == nil {
+		return fmt.Errorf("namespaceSelector should be specified")
+	}
+	if _, err := metav1.LabelSelectorAsSelector(spec.NamespaceSelector); err != nil {
+		return fmt.Errorf("invalid namespaceSelector: %v", err)
+	}
+	return nil
+}
+
+func ValidateDNSRecord(record *kubeovnv1.DNSRecord) error {
+	hostname := strings.TrimSuffix(record.Spec.Hostname, ".")
+	if errs := validation.IsDNS1123Subdomain(hostname); len(errs) != 0 {
+		return fmt.Errorf("hostname %q is invalid: %s", record.Spec.Hostname, strings.Join(errs, ", "))
+	}
+	if len(record.Spec.IPs) == 0 {
+		return fmt.Errorf("ips should be specified")
+	}
+	for _, ip := range record.Spec.IPs {
+		if net.ParseIP(ip) == nil {
+			return fmt.Errorf("%q is not a valid ip", ip)
+		}
+	}
+	return nil
+}
+
+// AddVpcNatGatewayQuotaUsage add eips and nat rules of the vpc nat gateway to the quota usage of its vpc
+func AddVpcNatGatewayQuotaUsage(usage *kubeovnv1.VpcQuotaUsage, gw *kubeovnv1.VpcNatGateway) {
+	usage.EIPs += len(gw.Spec.Eips)
+	usage.DnatRules += len(gw.Spec.DnatRules)
+	usage.SnatRules += len(gw.Spec.SnatRules)
+	usage.FloatingIPRules += len(gw.Spec.FloatingIpRules)
+}
+
+// ValidateVpcQuota check the quota usage of the vpc, a resource exceeding the quota is allowed
+// only if its usage does not grow compared with oldUsage, so that quotas can be lowered
+func ValidateVpcQuota(vpc string, quota *kubeovnv1.VpcQuota, usage, oldUsage kubeovnv1.VpcQuotaUsage) error {
+	if quota == nil {
+		return nil
+	}
+	for _, item := range []struct {
+		resource       string
+		max, used, old int
+	}{
+		{"subnets", quota.MaxSubnets, usage.Subnets, oldUsage.Subnets},
+		{"ips", quota.MaxIPs, usage.IPs, oldUsage.IPs},
+		{"eips", quota.MaxEIPs, usage.EIPs, oldUsage.EIPs},
+		{"dnat rules", quota.MaxDnatRules, usage.DnatRules, oldUsage.DnatRules},
+		{"snat rules", quota.MaxSnatRules, usage.SnatRules, oldUsage.SnatRules},
+		{"floating ip rules", quota.MaxFloatingIPRules, usage.FloatingIPRules, oldUsage.FloatingIPRules},
+		{"static routes", quota.MaxStaticRoutes, usage.StaticRoutes, oldUsage.StaticRoutes},
+	} {
+		if item.max > 0 && item.used > item.max && item.used > item.old {
+			return fmt.Errorf("%d %s exceed the quota %d of vpc %s", item.used, item.resource, item.max, vpc)
+		}
+	}
 	return nil
 }
 
@@ -158,7 +399,7 @@ func ValidatePodNetwork(annotations map[string]string) error {
 	}
 
 	ipPool := annotations[IpPoolAnnotation]
-	if ipPool != "" {
+	if ipPool != "" && !IsIPPoolName(ipPool) {
 		for _, ip := range strings.Split(ipPool, ",") {
 			if net.ParseIP(strings.TrimSpace(ip)) == nil {
 				errors = append(errors, fmt.Errorf("%s in %s is not a valid address", ip, IpPoolAnnotation))
@@ -183,6 +424,16 @@ func ValidatePodNetwork(annotations map[string]string) error {
 	return utilerrors.NewAggregate(errors)
 }
 
+// IsIPPoolName returns true if the ip pool annotation refers to an IPPool
+// resource instead of a list of addresses
+func IsIPPoolName(ipPool string) bool {
+	ipPool = strings.TrimSpace(ipPool)
+	if strings.Contains(ipPool, ",") || net.ParseIP(ipPool) != nil {
+		return false
+	}
+	return len(validation.IsDNS1123Subdomain(ipPool)) == 0
+}
+
 func ValidatePodCidr(cidr, ip string) error {
 	if cidr == CIDRNone {
 		return nil
@@ -211,8 +462,10 @@ func ValidateCidrConflict(subnet kubeovnv1.Subnet, subnetList []kubeovnv1.Subnet
 			continue
 		}
 
-		if CIDRConflict(sub.Spec.CIDRBlock, subnet.Spec.CIDRBlock) {
-			err := fmt.Errorf("subnet %s cidr %s is conflict with subnet %s cidr %s", subnet.Name, subnet.Spec.CIDRBlock, sub.Name, sub.Spec.CIDRBlock)
+		subCIDRs := JoinCIDRBlocks(sub.Spec.CIDRBlock, sub.Spec.ExtraCIDRBlocks)
+		subnetCIDRs := JoinCIDRBlocks(subnet.Spec.CIDRBlock, subnet.Spec.ExtraCIDRBlocks)
+		if CIDRConflict(subCIDRs, subnetCIDRs) {
+			err := fmt.Errorf("subnet %s cidr %s is conflict with subnet %s cidr %s", subnet.Name, subnetCIDRs, sub.Name, subCIDRs)
 			return err
 		}
 
@@ -0,0 +1,54 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	ctrlwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	ovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+var ipGVK = metav1.GroupVersionKind{Group: ovnv1.SchemeGroupVersion.Group, Version: ovnv1.SchemeGroupVersion.Version, Kind: "IP"}
+
+// validateIPDHCPOptions deny the ip if its dhcp options can not be applied to the logical switch port,
+// kube-ovn-controller ignores invalid dhcp options of ips
+func validateIPDHCPOptions(ip *ovnv1.IP) admission.Response {
+	if err := util.ValidateDHCPOptions(ovnv1.ProtocolIPv4, ip.Spec.DHCPv4Options); err != nil {
+		klog.Errorf("validate ip %s failed: %v", ip.Name, err)
+		return ctrlwebhook.Denied(err.Error())
+	}
+	if err := util.ValidateDHCPOptions(ovnv1.ProtocolIPv6, ip.Spec.DHCPv6Options); err != nil {
+		klog.Errorf("validate ip %s failed: %v", ip.Name, err)
+		return ctrlwebhook.Denied(err.Error())
+	}
+	return ctrlwebhook.Allowed("by pass")
+}
+
+func (v *ValidatingHook) IPCreateHook(ctx context.Context, req admission.Request) admission.Response {
+	o := ovnv1.IP{}
+	if err := v.decoder.Decode(req, &o); err != nil {
+		return ctrlwebhook.Errored(http.StatusBadRequest, err)
+	}
+	return validateIPDHCPOptions(&o)
+}
+
+func (v *ValidatingHook) IPUpdateHook(ctx context.Context, req admission.Request) admission.Response {
+	o := ovnv1.IP{}
+	if err := v.decoder.Decode(req, &o); err != nil {
+		return ctrlwebhook.Errored(http.StatusBadRequest, err)
+	}
+	oldO := ovnv1.IP{}
+	if err := v.decoder.DecodeRaw(req.OldObject, &oldO); err != nil {
+		return ctrlwebhook.Errored(http.StatusBadRequest, err)
+	}
+	if reflect.DeepEqual(o.Spec.DHCPv4Options, oldO.Spec.DHCPv4Options) && reflect.DeepEqual(o.Spec.DHCPv6Options, oldO.Spec.DHCPv6Options) {
+		return ctrlwebhook.Allowed("by pass")
+	}
+	return validateIPDHCPOptions(&o)
+}
@@ -0,0 +1,82 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	ovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ovs"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+// validateNamespaceIPQuota deny the pod if addresses of its namespace in the subnet of the pod reach the quota,
+// the quota is enforced again by kube-ovn-controller when the address is allocated
+func (v *ValidatingHook) validateNamespaceIPQuota(ctx context.Context, pod *corev1.Pod) admission.Response {
+	if pod.Spec.HostNetwork {
+		return ctrlwebhook.Allowed("by pass")
+	}
+
+	subnet, err := v.podSubnet(ctx, pod)
+	if err != nil {
+		return ctrlwebhook.Errored(http.StatusBadRequest, err)
+	}
+	if subnet == nil {
+		return ctrlwebhook.Allowed("by pass")
+	}
+	subnetName := subnet.Name
+	quota, ok := subnet.Spec.NamespaceIPQuotas[pod.Namespace]
+	if !ok {
+		return ctrlwebhook.Allowed("by pass")
+	}
+
+	ipList := &ovnv1.IPList{}
+	if err := v.cache.List(ctx, ipList, client.MatchingLabels{util.SubnetNameLabel: subnetName}); err != nil {
+		return ctrlwebhook.Errored(http.StatusBadRequest, err)
+	}
+	// the address kept for a recreated statefulset pod is not counted
+	portName := ovs.PodNameToPortName(pod.Name, pod.Namespace, util.OvnProvider)
+	var used int
+	for _, ip := range ipList.Items {
+		if ip.Spec.Namespace == pod.Namespace && ip.Name != portName {
+			used++
+		}
+	}
+	if used >= quota {
+		err := fmt.Errorf("namespace %s has used %d addresses of its quota %d in subnet %s", pod.Namespace, used, quota, subnetName)
+		klog.Errorf("validate pod %s/%s failed: %v", pod.Namespace, pod.Name, err)
+		return ctrlwebhook.Denied(err.Error())
+	}
+	return ctrlwebhook.Allowed("by pass")
+}
+
+// podSubnet get the subnet of the pod specified by the annotations of the pod or its namespace,
+// nil is returned if the subnet is not specified or not found
+func (v *ValidatingHook) podSubnet(ctx context.Context, pod *corev1.Pod) (*ovnv1.Subnet, error) {
+	subnetName := pod.Annotations[util.LogicalSwitchAnnotation]
+	if subnetName == "" {
+		ns := &corev1.Namespace{}
+		if err := v.cache.Get(ctx, client.ObjectKey{Name: pod.Namespace}, ns); err != nil {
+			return nil, err
+		}
+		if subnetName = ns.Annotations[util.LogicalSwitchAnnotation]; subnetName == "" {
+			return nil, nil
+		}
+	}
+
+	subnet := &ovnv1.Subnet{}
+	if err := v.cache.Get(ctx, client.ObjectKey{Name: subnetName}, subnet); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return subnet, nil
+}
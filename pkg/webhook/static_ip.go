@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"reflect"
 	"strings"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -73,6 +74,12 @@ func (v *ValidatingHook) PodCreateHook(ctx context.Context, req admission.Reques
 	if err := v.decoder.Decode(req, &o); err != nil {
 		return ctrlwebhook.Errored(http.StatusBadRequest, err)
 	}
+	if resp := v.validateNamespaceIPQuota(ctx, &o); !resp.Allowed {
+		return resp
+	}
+	if resp := v.validateVpcIPQuota(ctx, &o); !resp.Allowed {
+		return resp
+	}
 	poolAnno := o.GetAnnotations()[util.IpPoolAnnotation]
 	klog.V(3).Infof("%s %s@%s, ip_pool: %s", o.Kind, o.GetName(), o.GetNamespace(), poolAnno)
 	if poolAnno != "" {
@@ -95,6 +102,9 @@ func (v *ValidatingHook) SubnetCreateHook(ctx context.Context, req admission.Req
 	if err := util.ValidateSubnet(o); err != nil {
 		return ctrlwebhook.Denied(err.Error())
 	}
+	if _, err := util.CompileAclRules(util.JoinCIDRBlocks(o.Spec.CIDRBlock, o.Spec.ExtraCIDRBlocks), o.Spec.AclRules); err != nil {
+		return ctrlwebhook.Denied(err.Error())
+	}
 
 	subnetList := &ovnv1.SubnetList{}
 	if err := v.cache.List(ctx, subnetList); err != nil {
@@ -104,6 +114,31 @@ func (v *ValidatingHook) SubnetCreateHook(ctx context.Context, req admission.Req
 		return ctrlwebhook.Denied(err.Error())
 	}
 
+	return v.validateSubnetVpcQuota(ctx, &o)
+}
+
+func (v *ValidatingHook) SubnetUpdateHook(ctx context.Context, req admission.Request) admission.Response {
+	o := ovnv1.Subnet{}
+	if err := v.decoder.Decode(req, &o); err != nil {
+		return ctrlwebhook.Errored(http.StatusBadRequest, err)
+	}
+	oldSubnet := ovnv1.Subnet{}
+	if err := v.decoder.DecodeRaw(req.OldObject, &oldSubnet); err != nil {
+		return ctrlwebhook.Errored(http.StatusBadRequest, err)
+	}
+
+	// invalid acl rules are ignored by kube-ovn-controller, reject them here so the subnet keeps its rules
+	if !reflect.DeepEqual(o.Spec.AclRules, oldSubnet.Spec.AclRules) ||
+		o.Spec.CIDRBlock != oldSubnet.Spec.CIDRBlock ||
+		!reflect.DeepEqual(o.Spec.ExtraCIDRBlocks, oldSubnet.Spec.ExtraCIDRBlocks) {
+		if _, err := util.CompileAclRules(util.JoinCIDRBlocks(o.Spec.CIDRBlock, o.Spec.ExtraCIDRBlocks), o.Spec.AclRules); err != nil {
+			return ctrlwebhook.Denied(err.Error())
+		}
+	}
+
+	if subnetVpc(&o) != subnetVpc(&oldSubnet) {
+		return v.validateSubnetVpcQuota(ctx, &o)
+	}
 	return ctrlwebhook.Allowed("by pass")
 }
 
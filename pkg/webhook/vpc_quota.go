@@ -0,0 +1,234 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	ovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ovs"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+var (
+	vpcGVK           = metav1.GroupVersionKind{Group: ovnv1.SchemeGroupVersion.Group, Version: ovnv1.SchemeGroupVersion.Version, Kind: "Vpc"}
+	vpcNatGatewayGVK = metav1.GroupVersionKind{Group: ovnv1.SchemeGroupVersion.Group, Version: ovnv1.SchemeGroupVersion.Version, Kind: "VpcNatGateway"}
+)
+
+func subnetVpc(subnet *ovnv1.Subnet) string {
+	if subnet.Spec.Vpc == "" {
+		return util.DefaultVpc
+	}
+	return subnet.Spec.Vpc
+}
+
+// getVpcQuota get the vpc if it has a quota, nil is returned if the vpc is not found or has no quota
+func (v *ValidatingHook) getVpcQuota(ctx context.Context, name string) (*ovnv1.Vpc, error) {
+	vpc := &ovnv1.Vpc{}
+	if err := v.cache.Get(ctx, client.ObjectKey{Name: name}, vpc); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if vpc.Spec.Quota == nil {
+		return nil, nil
+	}
+	return vpc, nil
+}
+
+// vpcQuotaUsage count the resources in the vpc, eips and nat rules of the vpc nat gateway named
+// skipNatGateway are not counted
+func (v *ValidatingHook) vpcQuotaUsage(ctx context.Context, vpc *ovnv1.Vpc, skipNatGateway string) (ovnv1.VpcQuotaUsage, error) {
+	usage := ovnv1.VpcQuotaUsage{StaticRoutes: len(vpc.Spec.StaticRoutes)}
+
+	subnetList := &ovnv1.SubnetList{}
+	if err := v.cache.List(ctx, subnetList); err != nil {
+		return usage, err
+	}
+	subnets := make(map[string]bool)
+	for i := range subnetList.Items {
+		if subnetVpc(&subnetList.Items[i]) == vpc.Name {
+			subnets[subnetList.Items[i].Name] = true
+		}
+	}
+	usage.Subnets = len(subnets)
+
+	ipList := &ovnv1.IPList{}
+	if err := v.cache.List(ctx, ipList); err != nil {
+		return usage, err
+	}
+	for _, ip := range ipList.Items {
+		if subnets[ip.Spec.Subnet] {
+			usage.IPs++
+		}
+	}
+
+	gwList := &ovnv1.VpcNatGatewayList{}
+	if err := v.cache.List(ctx, gwList); err != nil {
+		return usage, err
+	}
+	for i := range gwList.Items {
+		if gwList.Items[i].Spec.Vpc == vpc.Name && gwList.Items[i].Name != skipNatGateway {
+			util.AddVpcNatGatewayQuotaUsage(&usage, &gwList.Items[i])
+		}
+	}
+	return usage, nil
+}
+
+// validateSubnetVpcQuota deny the subnet created in or moved to the vpc if subnets or ips of the vpc exceed the quota
+func (v *ValidatingHook) validateSubnetVpcQuota(ctx context.Context, subnet *ovnv1.Subnet) admission.Response {
+	vpc, err := v.getVpcQuota(ctx, subnetVpc(subnet))
+	if err != nil {
+		return ctrlwebhook.Errored(http.StatusBadRequest, err)
+	}
+	if vpc == nil {
+		return ctrlwebhook.Allowed("by pass")
+	}
+
+	usage, err := v.vpcQuotaUsage(ctx, vpc, "")
+	if err != nil {
+		return ctrlwebhook.Errored(http.StatusBadRequest, err)
+	}
+	newUsage := usage
+	newUsage.Subnets++
+	// addresses of a subnet moved to the vpc are counted in the vpc
+	ipList := &ovnv1.IPList{}
+	if err = v.cache.List(ctx, ipList, client.MatchingLabels{util.SubnetNameLabel: subnet.Name}); err != nil {
+		return ctrlwebhook.Errored(http.StatusBadRequest, err)
+	}
+	newUsage.IPs += len(ipList.Items)
+	if err = util.ValidateVpcQuota(vpc.Name, vpc.Spec.Quota, newUsage, usage); err != nil {
+		klog.Errorf("validate subnet %s failed: %v", subnet.Name, err)
+		return ctrlwebhook.Denied(err.Error())
+	}
+	return ctrlwebhook.Allowed("by pass")
+}
+
+// validateVpcIPQuota deny the pod if addresses allocated in the subnets of its vpc reach the quota,
+// the address kept for a recreated statefulset pod is not counted. It only fails fast, concurrent pods
+// may pass the check and the quota is enforced by kube-ovn-controller when the address is allocated
+func (v *ValidatingHook) validateVpcIPQuota(ctx context.Context, pod *corev1.Pod) admission.Response {
+	if pod.Spec.HostNetwork {
+		return ctrlwebhook.Allowed("by pass")
+	}
+
+	subnet, err := v.podSubnet(ctx, pod)
+	if err != nil {
+		return ctrlwebhook.Errored(http.StatusBadRequest, err)
+	}
+	if subnet == nil {
+		return ctrlwebhook.Allowed("by pass")
+	}
+	vpc, err := v.getVpcQuota(ctx, subnetVpc(subnet))
+	if err != nil {
+		return ctrlwebhook.Errored(http.StatusBadRequest, err)
+	}
+	if vpc == nil {
+		return ctrlwebhook.Allowed("by pass")
+	}
+
+	ip := &ovnv1.IP{}
+	if err = v.cache.Get(ctx, client.ObjectKey{Name: ovs.PodNameToPortName(pod.Name, pod.Namespace, util.OvnProvider)}, ip); err == nil {
+		return ctrlwebhook.Allowed("by pass")
+	} else if !k8serrors.IsNotFound(err) {
+		return ctrlwebhook.Errored(http.StatusBadRequest, err)
+	}
+
+	usage, err := v.vpcQuotaUsage(ctx, vpc, "")
+	if err != nil {
+		return ctrlwebhook.Errored(http.StatusBadRequest, err)
+	}
+	newUsage := usage
+	newUsage.IPs++
+	if err = util.ValidateVpcQuota(vpc.Name, vpc.Spec.Quota, newUsage, usage); err != nil {
+		klog.Errorf("validate pod %s/%s failed: %v", pod.Namespace, pod.Name, err)
+		return ctrlwebhook.Denied(err.Error())
+	}
+	return ctrlwebhook.Allowed("by pass")
+}
+
+// validateVpcNatGatewayQuota deny the vpc nat gateway if eips or nat rules of its vpc exceed the quota
+func (v *ValidatingHook) validateVpcNatGatewayQuota(ctx context.Context, gw, oldGw *ovnv1.VpcNatGateway) admission.Response {
+	vpc, err := v.getVpcQuota(ctx, gw.Spec.Vpc)
+	if err != nil {
+		return ctrlwebhook.Errored(http.StatusBadRequest, err)
+	}
+	if vpc == nil {
+		return ctrlwebhook.Allowed("by pass")
+	}
+
+	usage, err := v.vpcQuotaUsage(ctx, vpc, gw.Name)
+	if err != nil {
+		return ctrlwebhook.Errored(http.StatusBadRequest, err)
+	}
+	oldUsage, newUsage := usage, usage
+	if oldGw != nil && oldGw.Spec.Vpc == gw.Spec.Vpc {
+		util.AddVpcNatGatewayQuotaUsage(&oldUsage, oldGw)
+	}
+	util.AddVpcNatGatewayQuotaUsage(&newUsage, gw)
+	if err = util.ValidateVpcQuota(vpc.Name, vpc.Spec.Quota, newUsage, oldUsage); err != nil {
+		klog.Errorf("validate vpc nat gateway %s failed: %v", gw.Name, err)
+		return ctrlwebhook.Denied(err.Error())
+	}
+	return ctrlwebhook.Allowed("by pass")
+}
+
+// validateVpcStaticRouteQuota deny the vpc if its static routes exceed the quota
+func (v *ValidatingHook) validateVpcStaticRouteQuota(vpc, oldVpc *ovnv1.Vpc) admission.Response {
+	usage := ovnv1.VpcQuotaUsage{StaticRoutes: len(vpc.Spec.StaticRoutes)}
+	oldUsage := ovnv1.VpcQuotaUsage{}
+	if oldVpc != nil {
+		oldUsage.StaticRoutes = len(oldVpc.Spec.StaticRoutes)
+	}
+	if err := util.ValidateVpcQuota(vpc.Name, vpc.Spec.Quota, usage, oldUsage); err != nil {
+		klog.Errorf("validate vpc %s failed: %v", vpc.Name, err)
+		return ctrlwebhook.Denied(err.Error())
+	}
+	return ctrlwebhook.Allowed("by pass")
+}
+
+func (v *ValidatingHook) VpcCreateHook(ctx context.Context, req admission.Request) admission.Response {
+	o := ovnv1.Vpc{}
+	if err := v.decoder.Decode(req, &o); err != nil {
+		return ctrlwebhook.Errored(http.StatusBadRequest, err)
+	}
+	return v.validateVpcStaticRouteQuota(&o, nil)
+}
+
+func (v *ValidatingHook) VpcUpdateHook(ctx context.Context, req admission.Request) admission.Response {
+	o, oldO := ovnv1.Vpc{}, ovnv1.Vpc{}
+	if err := v.decoder.Decode(req, &o); err != nil {
+		return ctrlwebhook.Errored(http.StatusBadRequest, err)
+	}
+	if err := v.decoder.DecodeRaw(req.OldObject, &oldO); err != nil {
+		return ctrlwebhook.Errored(http.StatusBadRequest, err)
+	}
+	return v.validateVpcStaticRouteQuota(&o, &oldO)
+}
+
+func (v *ValidatingHook) VpcNatGatewayCreateHook(ctx context.Context, req admission.Request) admission.Response {
+	o := ovnv1.VpcNatGateway{}
+	if err := v.decoder.Decode(req, &o); err != nil {
+		return ctrlwebhook.Errored(http.StatusBadRequest, err)
+	}
+	return v.validateVpcNatGatewayQuota(ctx, &o, nil)
+}
+
+func (v *ValidatingHook) VpcNatGatewayUpdateHook(ctx context.Context, req admission.Request) admission.Response {
+	o, oldO := ovnv1.VpcNatGateway{}, ovnv1.VpcNatGateway{}
+	if err := v.decoder.Decode(req, &o); err != nil {
+		return ctrlwebhook.Errored(http.StatusBadRequest, err)
+	}
+	if err := v.decoder.DecodeRaw(req.OldObject, &oldO); err != nil {
+		return ctrlwebhook.Errored(http.StatusBadRequest, err)
+	}
+	return v.validateVpcNatGatewayQuota(ctx, &o, &oldO)
+}
@@ -16,6 +16,7 @@ import (
 
 var (
 	createHooks = make(map[metav1.GroupVersionKind]admission.HandlerFunc)
+	updateHooks = make(map[metav1.GroupVersionKind]admission.HandlerFunc)
 )
 
 type ValidatingHook struct {
@@ -42,6 +43,13 @@ func NewValidatingHook(c cache.Cache) (*ValidatingHook, error) {
 	createHooks[daemonSetGVK] = v.DaemonSetCreateHook
 	createHooks[podGVK] = v.PodCreateHook
 	createHooks[subnetGVK] = v.SubnetCreateHook
+	createHooks[ipGVK] = v.IPCreateHook
+	createHooks[vpcGVK] = v.VpcCreateHook
+	createHooks[vpcNatGatewayGVK] = v.VpcNatGatewayCreateHook
+	updateHooks[subnetGVK] = v.SubnetUpdateHook
+	updateHooks[ipGVK] = v.IPUpdateHook
+	updateHooks[vpcGVK] = v.VpcUpdateHook
+	updateHooks[vpcNatGatewayGVK] = v.VpcNatGatewayUpdateHook
 
 	return v, nil
 }
@@ -62,6 +70,12 @@ func (v *ValidatingHook) Handle(ctx context.Context, req admission.Request) (res
 			resp = createHooks[req.Kind](ctx, req)
 			return
 		}
+	case admissionv1.Update:
+		if updateHooks[req.Kind] != nil {
+			klog.Infof("handle update %s %s@%s", req.Kind, req.Name, req.Namespace)
+			resp = updateHooks[req.Kind](ctx, req)
+			return
+		}
 	}
 	resp = ctrlwebhook.Allowed("by pass")
 	return
@@ -2,10 +2,13 @@ package ipam
 
 import (
 	"fmt"
+	"sync"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
 	"github.com/kubeovn/kube-ovn/pkg/ipam"
 )
 
@@ -688,4 +691,698 @@ var _ = Describe("[IPAM]", func() {
 			})
 		})
 	})
+
+	Describe("[AllocationStrategy]", func() {
+		It("invalid strategy", func() {
+			im := ipam.NewIPAM()
+			err := im.AddOrUpdateSubnet(subnetName, "10.16.0.0/29", []string{"10.16.0.1"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(im.SetAllocationStrategy(subnetName, "unknown")).Should(HaveOccurred())
+			Expect(im.SetAllocationStrategy("unknown", kubeovnv1.AllocationStrategyRandom)).Should(MatchError(ipam.ErrNoAvailable))
+		})
+
+		It("random strategy", func() {
+			im := ipam.NewIPAM()
+			err := im.AddOrUpdateSubnet(subnetName, "10.16.0.0/29", []string{"10.16.0.1"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(im.SetAllocationStrategy(subnetName, kubeovnv1.AllocationStrategyRandom)).ShouldNot(HaveOccurred())
+
+			allocated := map[string]bool{}
+			for i := 1; i <= 5; i++ {
+				podName := fmt.Sprintf("pod%d.ns", i)
+				ip, _, _, err := im.GetRandomAddress(podName, podName, subnetName, nil)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(ip).To(BeElementOf("10.16.0.2", "10.16.0.3", "10.16.0.4", "10.16.0.5", "10.16.0.6"))
+				Expect(allocated).NotTo(HaveKey(ip))
+				allocated[ip] = true
+			}
+			_, _, _, err = im.GetRandomAddress("pod6.ns", "pod6.ns", subnetName, nil)
+			Expect(err).Should(MatchError(ipam.ErrNoAvailable))
+
+			err = im.AddOrUpdateSubnet(subnetName, "10.17.0.0/16,fd00::/64", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			sequential := true
+			for i := 1; i <= 10; i++ {
+				podName := fmt.Sprintf("pod-%d.ns", i)
+				ipv4, ipv6, _, err := im.GetRandomAddress(podName, podName, subnetName, nil)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(ipv4).To(HavePrefix("10.17."))
+				Expect(ipv6).To(HavePrefix("fd00::"))
+				if ipv4 != fmt.Sprintf("10.17.0.%d", i) {
+					sequential = false
+				}
+			}
+			Expect(sequential).To(BeFalse())
+		})
+
+		It("least recently released strategy", func() {
+			im := ipam.NewIPAM()
+			err := im.AddOrUpdateSubnet(subnetName, "10.16.0.0/29", []string{"10.16.0.1"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(im.SetAllocationStrategy(subnetName, kubeovnv1.AllocationStrategyLeastRecentlyReleased)).ShouldNot(HaveOccurred())
+
+			for i := 1; i <= 5; i++ {
+				podName := fmt.Sprintf("pod%d.ns", i)
+				ip, _, _, err := im.GetRandomAddress(podName, podName, subnetName, nil)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(ip).To(Equal(fmt.Sprintf("10.16.0.%d", i+1)))
+			}
+
+			im.ReleaseAddressByPod("pod3.ns")
+			im.ReleaseAddressByPod("pod1.ns")
+			ip, _, _, err := im.GetRandomAddress("pod6.ns", "pod6.ns", subnetName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.16.0.4"))
+
+			im.ReleaseAddressByPod("pod5.ns")
+			ip, _, _, err = im.GetRandomAddress("pod7.ns", "pod7.ns", subnetName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.16.0.2"))
+			ip, _, _, err = im.GetRandomAddress("pod8.ns", "pod8.ns", subnetName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.16.0.6"))
+
+			// the lowest released address is reused by the sequential strategy
+			Expect(im.SetAllocationStrategy(subnetName, kubeovnv1.AllocationStrategySequential)).ShouldNot(HaveOccurred())
+			im.ReleaseAddressByPod("pod4.ns")
+			im.ReleaseAddressByPod("pod2.ns")
+			ip, _, _, err = im.GetRandomAddress("pod9.ns", "pod9.ns", subnetName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.16.0.3"))
+		})
+	})
+
+	Describe("[ExtraCIDR]", func() {
+		It("allocate addresses in extra cidr blocks", func() {
+			im := ipam.NewIPAM()
+			cidrs := "10.16.0.0/30,10.17.0.0/30"
+			excludeIPs := []string{"10.16.0.1", "10.17.0.1"}
+			err := im.AddOrUpdateSubnet(subnetName, cidrs, excludeIPs)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(im.Subnets[subnetName].Protocol).To(Equal(kubeovnv1.ProtocolIPv4))
+
+			ip, _, _, err := im.GetRandomAddress("pod1.ns", "pod1.ns", subnetName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.16.0.2"))
+			ip, _, _, err = im.GetRandomAddress("pod2.ns", "pod2.ns", subnetName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.17.0.2"))
+			_, _, _, err = im.GetRandomAddress("pod3.ns", "pod3.ns", subnetName, nil)
+			Expect(err).Should(MatchError(ipam.ErrNoAvailable))
+			_, _, _, err = im.GetStaticAddress("pod3.ns", "pod3.ns", "10.17.0.1", "", subnetName, true)
+			Expect(err).ShouldNot(HaveOccurred())
+			_, _, _, err = im.GetStaticAddress("pod4.ns", "pod4.ns", "10.18.0.1", "", subnetName, true)
+			Expect(err).Should(MatchError(ipam.ErrOutOfRange))
+
+			// append a cidr block without disturbing existing allocations
+			err = im.AddOrUpdateSubnet(subnetName, cidrs+",10.18.0.0/30", append(excludeIPs, "10.18.0.1"))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(im.GetPodAddress("pod1.ns")[0].Ip).To(Equal("10.16.0.2"))
+			Expect(im.GetPodAddress("pod2.ns")[0].Ip).To(Equal("10.17.0.2"))
+			ip, _, _, err = im.GetRandomAddress("pod4.ns", "pod4.ns", subnetName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.18.0.2"))
+
+			im.ReleaseAddressByPod("pod2.ns")
+			ip, _, _, err = im.GetRandomAddress("pod5.ns", "pod5.ns", subnetName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.17.0.2"))
+		})
+
+		It("dual stack with extra cidr blocks", func() {
+			im := ipam.NewIPAM()
+			err := im.AddOrUpdateSubnet(subnetName, "10.16.0.0/30,fd00::/126,fd01::/126", []string{"10.16.0.1", "fd00::1", "fd01::1"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(im.Subnets[subnetName].Protocol).To(Equal(kubeovnv1.ProtocolDual))
+
+			ipv4, ipv6, _, err := im.GetRandomAddress("pod1.ns", "pod1.ns", subnetName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ipv4).To(Equal("10.16.0.2"))
+			Expect(ipv6).To(Equal("fd00::2"))
+
+			_, _, _, err = im.GetStaticAddress("pod2.ns", "pod2.ns", "fd01::2", "", subnetName, true)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(im.Checkpoint().Subnets[0].CIDR).To(Equal("10.16.0.0/30,fd00::/126,fd01::/126"))
+		})
+	})
+
+	Describe("[IPPool]", func() {
+		It("allocation in ip pool", func() {
+			im := ipam.NewIPAM()
+			err := im.AddOrUpdateSubnet(subnetName, "10.16.0.0/29", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			err = im.AddOrUpdateIPPool(subnetName, "pool1", []string{"10.16.0.2..10.16.0.3"})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			ip, _, _, err := im.GetRandomAddressFromPool("pod1.ns", "pod1.ns", subnetName, "pool1", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.16.0.2"))
+			ip, _, _, err = im.GetRandomAddressFromPool("pod2.ns", "pod2.ns", subnetName, "pool1", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.16.0.3"))
+			_, _, _, err = im.GetRandomAddressFromPool("pod3.ns", "pod3.ns", subnetName, "pool1", nil)
+			Expect(err).Should(MatchError(ipam.ErrNoAvailable))
+			_, _, _, err = im.GetRandomAddressFromPool("pod3.ns", "pod3.ns", subnetName, "pool2", nil)
+			Expect(err).Should(MatchError(ipam.ErrNoAvailable))
+
+			ip, _, _, err = im.GetRandomAddress("pod4.ns", "pod4.ns", subnetName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.16.0.1"))
+			ip, _, _, err = im.GetRandomAddress("pod5.ns", "pod5.ns", subnetName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.16.0.4"))
+
+			v4Available, v4Using, _, _, err := im.IPPoolStatistics(subnetName, "pool1")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(v4Available).To(Equal(float64(0)))
+			Expect(v4Using).To(Equal(float64(2)))
+
+			im.ReleaseAddressByPod("pod1.ns")
+			v4Available, v4Using, _, _, err = im.IPPoolStatistics(subnetName, "pool1")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(v4Available).To(Equal(float64(1)))
+			Expect(v4Using).To(Equal(float64(1)))
+
+			ip, _, _, err = im.GetRandomAddressFromPool("pod3.ns", "pod3.ns", subnetName, "pool1", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.16.0.2"))
+
+			im.RemoveIPPool("pool1")
+			im.ReleaseAddressByPod("pod2.ns")
+			ip, _, _, err = im.GetRandomAddress("pod6.ns", "pod6.ns", subnetName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.16.0.5"))
+		})
+
+		It("ip pool conflict", func() {
+			im := ipam.NewIPAM()
+			err := im.AddOrUpdateSubnet(subnetName, dualCIDR, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			err = im.AddOrUpdateIPPool("invalid_subnet", "pool1", []string{"10.16.0.2"})
+			Expect(err).Should(MatchError(ipam.ErrNoAvailable))
+			err = im.AddOrUpdateIPPool(subnetName, "pool1", []string{"10.16.0.2..10.16.0.1"})
+			Expect(err).Should(HaveOccurred())
+			err = im.AddOrUpdateIPPool(subnetName, "pool1", []string{"10.17.0.0/24"})
+			Expect(err).Should(MatchError(ipam.ErrOutOfRange))
+
+			err = im.AddOrUpdateIPPool(subnetName, "pool1", []string{"10.16.0.0/24", "fd00::10..fd00::20"})
+			Expect(err).ShouldNot(HaveOccurred())
+			err = im.AddOrUpdateIPPool(subnetName, "pool2", []string{"10.16.0.255..10.16.1.10"})
+			Expect(err).Should(MatchError(ipam.ErrConflict))
+			err = im.AddOrUpdateIPPool(subnetName, "pool2", []string{"fd00::20"})
+			Expect(err).Should(MatchError(ipam.ErrConflict))
+			err = im.AddOrUpdateIPPool(subnetName, "pool2", []string{"10.16.1.0/24", "fd00::21"})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			ipv4, ipv6, _, err := im.GetRandomAddressFromPool("pod1.ns", "pod1.ns", subnetName, "pool1", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ipv4).To(Equal("10.16.0.1"))
+			Expect(ipv6).To(Equal("fd00::10"))
+
+			ipv4, ipv6, _, err = im.GetRandomAddress("pod2.ns", "pod2.ns", subnetName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ipv4).To(Equal("10.16.2.0"))
+			Expect(ipv6).To(Equal("fd00::1"))
+		})
+
+		It("static address in ip pool", func() {
+			im := ipam.NewIPAM()
+			err := im.AddOrUpdateSubnet(subnetName, dualCIDR, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			err = im.AddOrUpdateIPPool(subnetName, "pool1", []string{"10.16.0.2..10.16.0.3"})
+			Expect(err).ShouldNot(HaveOccurred())
+			err = im.AddOrUpdateIPPool(subnetName, "pool2", []string{"10.16.0.4", "fd00::4"})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			// addresses of a pool are not available to pods using other pools or no pool
+			_, _, _, err = im.GetStaticAddressFromPool("pod1.ns", "pod1.ns", "10.16.0.2", "", subnetName, "", "", true)
+			Expect(err).Should(MatchError(ipam.ErrOutOfRange))
+			_, _, _, err = im.GetStaticAddressFromPool("pod1.ns", "pod1.ns", "10.16.0.2", "", subnetName, "pool2", "", true)
+			Expect(err).Should(MatchError(ipam.ErrOutOfRange))
+			_, _, _, err = im.GetStaticAddressFromPool("pod1.ns", "pod1.ns", "10.16.0.2", "", subnetName, "pool3", "", true)
+			Expect(err).Should(MatchError(ipam.ErrNoAvailable))
+			// pods using a pool only get addresses of the pool
+			_, _, _, err = im.GetStaticAddressFromPool("pod1.ns", "pod1.ns", "10.16.0.10", "", subnetName, "pool1", "", true)
+			Expect(err).Should(MatchError(ipam.ErrOutOfRange))
+			_, _, _, err = im.GetStaticAddressFromPool("pod1.ns", "pod1.ns", "fd00::10", "", subnetName, "pool2", "", true)
+			Expect(err).Should(MatchError(ipam.ErrOutOfRange))
+
+			// the subnet range is used for families not covered by the pool
+			ipv4, ipv6, _, err := im.GetStaticAddressFromPool("pod1.ns", "pod1.ns", "10.16.0.2,fd00::10", "", subnetName, "pool1", "", true)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ipv4).To(Equal("10.16.0.2"))
+			Expect(ipv6).To(Equal("fd00::10"))
+			_, _, _, err = im.GetStaticAddressFromPool("pod2.ns", "pod2.ns", "10.16.0.10,fd00::11", "", subnetName, "", "", true)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			// addresses assigned to the pod are kept after the pool is changed
+			err = im.AddOrUpdateIPPool(subnetName, "pool1", []string{"10.16.0.2..10.16.0.3", "fd00::10"})
+			Expect(err).ShouldNot(HaveOccurred())
+			_, _, _, err = im.GetStaticAddressFromPool("pod1.ns", "pod1.ns", "10.16.0.2,fd00::10", "", subnetName, "", "", true)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			// restored addresses are not checked against pools
+			_, _, _, err = im.GetStaticAddress("pod3.ns", "pod3.ns", "10.16.0.3", "", subnetName, true)
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+
+		It("fall back to subnet range for families not covered by ip pool", func() {
+			im := ipam.NewIPAM()
+			err := im.AddOrUpdateSubnet(subnetName, dualCIDR, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			err = im.AddOrUpdateIPPool(subnetName, "pool1", []string{"10.16.0.2..10.16.0.3"})
+			Expect(err).ShouldNot(HaveOccurred())
+			err = im.AddOrUpdateIPPool(subnetName, "pool2", []string{"fd00::1..fd00::2"})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			ipv4, ipv6, _, err := im.GetRandomAddressFromPool("pod1.ns", "pod1.ns", subnetName, "pool1", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ipv4).To(Equal("10.16.0.2"))
+			Expect(ipv6).To(Equal("fd00::3"))
+			ipv4, ipv6, _, err = im.GetRandomAddressFromPool("pod2.ns", "pod2.ns", subnetName, "pool2", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ipv4).To(Equal("10.16.0.1"))
+			Expect(ipv6).To(Equal("fd00::1"))
+		})
+	})
+
+	Describe("[NodeBlock]", func() {
+		It("allocation in node blocks", func() {
+			im := ipam.NewIPAM()
+			err := im.AddOrUpdateSubnet(subnetName, "10.16.0.0/28", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			err = im.AddOrUpdateIPPool(subnetName, "pool1", []string{"10.16.0.1..10.16.0.2"})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			ips, err := im.LeaseNodeBlock(subnetName, "block1", "node1", 2)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ips).To(Equal([]string{"10.16.0.3..10.16.0.4"}))
+			ips, err = im.LeaseNodeBlock(subnetName, "block2", "node2", 2)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ips).To(Equal([]string{"10.16.0.5..10.16.0.6"}))
+			_, err = im.LeaseNodeBlock(subnetName, "block2", "node2", 2)
+			Expect(err).Should(MatchError(ipam.ErrConflict))
+			Expect(im.NodeAvailableIPs(subnetName, "node1")).To(Equal(float64(2)))
+
+			ip, _, _, err := im.GetRandomAddressFromNodeBlocks("pod1.ns", "pod1.ns", subnetName, "node1", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.16.0.3"))
+			ip, _, _, err = im.GetRandomAddressFromNodeBlocks("pod2.ns", "pod2.ns", subnetName, "node1", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.16.0.4"))
+			_, _, _, err = im.GetRandomAddressFromNodeBlocks("pod3.ns", "pod3.ns", subnetName, "node1", nil)
+			Expect(err).Should(MatchError(ipam.ErrNoAvailable))
+			Expect(im.NodeAvailableIPs(subnetName, "node1")).To(Equal(float64(0)))
+
+			ip, _, _, err = im.GetRandomAddress("pod3.ns", "pod3.ns", subnetName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.16.0.7"))
+
+			v4Available, v4Using, _, _, err := im.NodeBlockStatistics(subnetName, "block1")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(v4Available).To(Equal(float64(0)))
+			Expect(v4Using).To(Equal(float64(2)))
+
+			// released addresses are reused by the block and addresses taken by static ones are skipped
+			im.ReleaseAddressByPod("pod1.ns")
+			_, _, _, err = im.GetStaticAddress("static.ns", "static.ns", "10.16.0.3", "", subnetName, true)
+			Expect(err).ShouldNot(HaveOccurred())
+			_, _, _, err = im.GetRandomAddressFromNodeBlocks("pod5.ns", "pod5.ns", subnetName, "node1", nil)
+			Expect(err).Should(MatchError(ipam.ErrNoAvailable))
+			Expect(im.GetPodAddress("pod5.ns")).To(BeEmpty())
+			im.ReleaseAddressByPod("pod2.ns")
+			ip, _, _, err = im.GetRandomAddressFromNodeBlocks("pod5.ns", "pod5.ns", subnetName, "node1", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.16.0.4"))
+
+			im.RemoveNodeBlock("block2")
+			ip, _, _, err = im.GetRandomAddress("pod4.ns", "pod4.ns", subnetName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.16.0.5"))
+		})
+
+		It("node block conflict", func() {
+			im := ipam.NewIPAM()
+			err := im.AddOrUpdateSubnet(subnetName, dualCIDR, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			err = im.AddOrUpdateIPPool(subnetName, "pool1", []string{"10.16.0.2..10.16.0.3"})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			err = im.AddOrUpdateNodeBlock(subnetName, "block1", "node1", []string{"10.16.0.3..10.16.0.4"})
+			Expect(err).Should(MatchError(ipam.ErrConflict))
+			err = im.AddOrUpdateNodeBlock(subnetName, "block1", "node1", []string{"10.17.0.1"})
+			Expect(err).Should(MatchError(ipam.ErrOutOfRange))
+			err = im.AddOrUpdateNodeBlock(subnetName, "block1", "node1", []string{"10.16.0.4..10.16.0.5", "fd00::4..fd00::5"})
+			Expect(err).ShouldNot(HaveOccurred())
+			err = im.AddOrUpdateIPPool(subnetName, "pool2", []string{"fd00::5"})
+			Expect(err).Should(MatchError(ipam.ErrConflict))
+
+			ipv4, ipv6, _, err := im.GetRandomAddressFromNodeBlocks("pod1.ns", "pod1.ns", subnetName, "node1", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ipv4).To(Equal("10.16.0.4"))
+			Expect(ipv6).To(Equal("fd00::4"))
+		})
+
+		It("node block checkpoint", func() {
+			im := ipam.NewIPAM()
+			err := im.AddOrUpdateSubnet(subnetName, "10.16.0.0/28", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			ips, err := im.LeaseNodeBlock(subnetName, "block1", "node1", 4)
+			Expect(err).ShouldNot(HaveOccurred())
+			_, _, _, err = im.GetRandomAddressFromNodeBlocks("pod1.ns", "pod1.ns", subnetName, "node1", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			cp := im.Checkpoint()
+
+			// free addresses of the block are moved to the block again after restored
+			restored := ipam.NewIPAM()
+			err = restored.AddOrUpdateSubnet(subnetName, "10.16.0.0/28", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			err = restored.AddOrUpdateNodeBlock(subnetName, "block1", "node1", ips)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(restored.RestoreCheckpoint(cp)).To(Equal([]string{subnetName}))
+			Expect(restored.NodeAvailableIPs(subnetName, "node1")).To(Equal(float64(3)))
+			ip, _, _, err := restored.GetRandomAddress("pod2.ns", "pod2.ns", subnetName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.16.0.5"))
+
+			restored.RemoveNodeBlock("block1")
+			ip, _, _, err = restored.GetRandomAddress("pod3.ns", "pod3.ns", subnetName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.16.0.2"))
+		})
+	})
+
+	Describe("[NodeBlockConcurrency]", func() {
+		It("concurrent allocation in node blocks", func() {
+			im := ipam.NewIPAM()
+			err := im.AddOrUpdateSubnet(subnetName, "10.16.0.0/24", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			for i := 0; i < 4; i++ {
+				_, err = im.LeaseNodeBlock(subnetName, fmt.Sprintf("block%d", i), fmt.Sprintf("node%d", i%2), 16)
+				Expect(err).ShouldNot(HaveOccurred())
+			}
+
+			var wg sync.WaitGroup
+			ips := make([]string, 64)
+			for i := range ips {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					defer GinkgoRecover()
+					pod := fmt.Sprintf("pod%d.ns", i)
+					ip, _, _, err := im.GetRandomAddressFromNodeBlocks(pod, pod, subnetName, fmt.Sprintf("node%d", i%2), nil)
+					Expect(err).ShouldNot(HaveOccurred())
+					ips[i] = ip
+				}(i)
+			}
+			wg.Wait()
+
+			used := map[string]bool{}
+			for _, ip := range ips {
+				Expect(used[ip]).To(BeFalse())
+				used[ip] = true
+			}
+			Expect(im.NodeAvailableIPs(subnetName, "node0")).To(Equal(float64(0)))
+			Expect(im.NodeAvailableIPs(subnetName, "node1")).To(Equal(float64(0)))
+		})
+	})
+
+	Describe("[MacPolicy]", func() {
+		It("ip derived mac", func() {
+			im := ipam.NewIPAM()
+			err := im.AddOrUpdateSubnet(subnetName, "10.16.0.0/16", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			err = im.SetMacPolicy(subnetName, "02:ab:cd", "invalid")
+			Expect(err).Should(HaveOccurred())
+			err = im.SetMacPolicy(subnetName, "02:ab:cd", kubeovnv1.MacPolicyIPDerived)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			ip, _, mac, err := im.GetRandomAddress("pod1.ns", "pod1.ns", subnetName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.16.0.1"))
+			Expect(mac).To(Equal("02:AB:CD:10:00:01"))
+
+			ip, _, mac, err = im.GetStaticAddress("pod2.ns", "pod2.ns", "10.16.1.2", "", subnetName, true)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.16.1.2"))
+			Expect(mac).To(Equal("02:AB:CD:10:01:02"))
+
+			// the derived mac is used by another pod
+			_, _, mac, err = im.GetStaticAddress("pod3.ns", "pod3.ns", "10.16.0.3", "02:AB:CD:10:00:04", subnetName, true)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(mac).To(Equal("02:AB:CD:10:00:04"))
+			_, _, mac, err = im.GetStaticAddress("pod4.ns", "pod4.ns", "10.16.0.4", "", subnetName, true)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(mac).To(HavePrefix("02:AB:CD:"))
+			Expect(mac).NotTo(Equal("02:AB:CD:10:00:04"))
+		})
+
+		It("random mac with prefix", func() {
+			im := ipam.NewIPAM()
+			err := im.AddOrUpdateSubnet(subnetName, ipv6CIDR, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			err = im.SetMacPolicy(subnetName, "01:00:5e", kubeovnv1.MacPolicyRandom)
+			Expect(err).Should(HaveOccurred())
+			err = im.SetMacPolicy(subnetName, "02:ab:cd", kubeovnv1.MacPolicyRandom)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			_, _, mac, err := im.GetRandomAddress("pod1.ns", "pod1.ns", subnetName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(mac).To(HavePrefix("02:AB:CD:"))
+		})
+	})
+
+	Describe("[Checkpoint]", func() {
+		It("restore from checkpoint", func() {
+			im := ipam.NewIPAM()
+			err := im.AddOrUpdateSubnet(subnetName, dualCIDR, dualExcludeIPs)
+			Expect(err).ShouldNot(HaveOccurred())
+			_, _, _, err = im.GetStaticAddress("pod1.ns", "pod1.ns", "10.16.0.2,fd00::2", "00:00:00:11:22:33", subnetName, true)
+			Expect(err).ShouldNot(HaveOccurred())
+			_, _, _, err = im.GetRandomAddress("pod2.ns", "pod2.ns", subnetName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			_, _, _, err = im.GetRandomAddress("pod3.ns", "pod3.ns", subnetName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			im.ReleaseAddressByPod("pod3.ns")
+
+			data, err := im.Checkpoint().Marshal()
+			Expect(err).ShouldNot(HaveOccurred())
+			cp, err := ipam.UnmarshalCheckpoint(data)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			restored := ipam.NewIPAM()
+			err = restored.AddOrUpdateSubnet(subnetName, dualCIDR, dualExcludeIPs)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(restored.RestoreCheckpoint(cp)).To(Equal([]string{subnetName}))
+
+			expected, actual := im.Subnets[subnetName], restored.Subnets[subnetName]
+			Expect(actual.V4FreeIPList).To(Equal(expected.V4FreeIPList))
+			Expect(actual.V4ReleasedIPList).To(Equal(expected.V4ReleasedIPList))
+			Expect(actual.V6FreeIPList).To(Equal(expected.V6FreeIPList))
+			Expect(actual.V6ReleasedIPList).To(Equal(expected.V6ReleasedIPList))
+			Expect(actual.V4NicToIP).To(Equal(expected.V4NicToIP))
+			Expect(actual.V4IPToPod).To(Equal(expected.V4IPToPod))
+			Expect(actual.V6NicToIP).To(Equal(expected.V6NicToIP))
+			Expect(actual.V6IPToPod).To(Equal(expected.V6IPToPod))
+			Expect(actual.NicToMac).To(Equal(expected.NicToMac))
+			Expect(actual.MacToPod).To(Equal(expected.MacToPod))
+
+			Expect(restored.NicAddressConflict(subnetName, "pod1.ns", "10.16.0.2,fd00::2")).To(BeFalse())
+			Expect(restored.NicAddressConflict(subnetName, "pod1.ns", "10.16.0.5,fd00::2")).To(BeTrue())
+			Expect(restored.NicAddressConflict(subnetName, "pod4.ns", "10.16.0.5")).To(BeFalse())
+
+			Expect(restored.ReleaseNicsExcept(subnetName, map[string]bool{"pod1.ns": true})).To(Equal(1))
+			Expect(restored.GetPodAddress("pod2.ns")).To(BeEmpty())
+			Expect(restored.GetPodAddress("pod1.ns")).To(HaveLen(2))
+		})
+
+		It("ignore mismatched checkpoint", func() {
+			im := ipam.NewIPAM()
+			err := im.AddOrUpdateSubnet(subnetName, ipv4CIDR, ipv4ExcludeIPs)
+			Expect(err).ShouldNot(HaveOccurred())
+			cp := im.Checkpoint()
+
+			restored := ipam.NewIPAM()
+			err = restored.AddOrUpdateSubnet(subnetName, ipv4CIDR, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(restored.RestoreCheckpoint(cp)).To(BeEmpty())
+
+			cp.Version = ipam.CheckpointVersion + 1
+			data, err := cp.Marshal()
+			Expect(err).ShouldNot(HaveOccurred())
+			_, err = ipam.UnmarshalCheckpoint(data)
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Describe("[ReleasePolicy]", func() {
+		It("reuse address released by the same owner", func() {
+			im := ipam.NewIPAM()
+			err := im.AddOrUpdateSubnet(subnetName, "10.16.0.0/29", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			err = im.SetReleasePolicy(subnetName, ipam.ReleasePolicy{ReuseTTL: time.Hour})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			ip, _, _, err := im.GetRandomAddressForOwner("pod1.ns", "pod1.ns", subnetName, "", "ns/Deployment/app", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.16.0.1"))
+			ip, _, _, err = im.GetRandomAddressForOwner("pod2.ns", "pod2.ns", subnetName, "", "ns/Deployment/app", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.16.0.2"))
+
+			im.ReleaseAddressByPod("pod1.ns")
+			ip, _, _, err = im.GetRandomAddressForOwner("pod3.ns", "pod3.ns", subnetName, "", "ns/Deployment/other", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.16.0.3"))
+			ip, _, _, err = im.GetRandomAddressForOwner("pod4.ns", "pod4.ns", subnetName, "", "ns/Deployment/app", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.16.0.1"))
+
+			// held addresses are not allocated to other owners even if the free list is exhausted
+			im.ReleaseAddressByPod("pod4.ns")
+			for _, pod := range []string{"pod5.ns", "pod6.ns", "pod7.ns"} {
+				_, _, _, err = im.GetRandomAddress(pod, pod, subnetName, nil)
+				Expect(err).ShouldNot(HaveOccurred())
+			}
+			_, _, _, err = im.GetRandomAddress("pod8.ns", "pod8.ns", subnetName, nil)
+			Expect(err).Should(HaveOccurred())
+
+			err = im.SetReleasePolicy(subnetName, ipam.ReleasePolicy{})
+			Expect(err).ShouldNot(HaveOccurred())
+			ip, _, _, err = im.GetRandomAddress("pod8.ns", "pod8.ns", subnetName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.16.0.1"))
+		})
+
+		It("reuse static address released by the same owner", func() {
+			im := ipam.NewIPAM()
+			err := im.AddOrUpdateSubnet(subnetName, "10.16.0.0/29", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			err = im.SetReleasePolicy(subnetName, ipam.ReleasePolicy{ReuseTTL: time.Hour})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			// addresses restored from annotations keep their owners
+			_, _, _, err = im.GetStaticAddressForOwner("pod1.ns", "pod1.ns", "10.16.0.5", "", subnetName, "ns/Deployment/app", false)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			im.ReleaseAddressByPod("pod1.ns")
+			ip, _, _, err := im.GetRandomAddressForOwner("pod2.ns", "pod2.ns", subnetName, "", "ns/Deployment/other", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.16.0.1"))
+			ip, _, _, err = im.GetRandomAddressForOwner("pod3.ns", "pod3.ns", subnetName, "", "ns/Deployment/app", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.16.0.5"))
+		})
+
+		It("quarantine released address", func() {
+			im := ipam.NewIPAM()
+			err := im.AddOrUpdateSubnet(subnetName, "fd00::/125", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			err = im.SetReleasePolicy(subnetName, ipam.ReleasePolicy{Quarantine: 100 * time.Millisecond})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			for i := 1; i <= 6; i++ {
+				pod := fmt.Sprintf("pod%d.ns", i)
+				_, ip, _, err := im.GetRandomAddress(pod, pod, subnetName, nil)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(ip).To(Equal(fmt.Sprintf("fd00::%d", i)))
+			}
+
+			im.ReleaseAddressByPod("pod3.ns")
+			_, _, _, err = im.GetRandomAddress("pod8.ns", "pod8.ns", subnetName, nil)
+			Expect(err).Should(HaveOccurred())
+
+			// the previous owner is not affected by quarantine
+			_, ip, _, err := im.GetRandomAddress("pod3.ns", "pod3.ns", subnetName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("fd00::3"))
+
+			im.ReleaseAddressByPod("pod3.ns")
+			time.Sleep(200 * time.Millisecond)
+			_, ip, _, err = im.GetRandomAddress("pod8.ns", "pod8.ns", subnetName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("fd00::3"))
+		})
+	})
+
+	Describe("[Migration]", func() {
+		It("keep addresses in the excluded source cidr", func() {
+			im := ipam.NewIPAM()
+			err := im.AddOrUpdateSubnet(subnetName, "10.16.0.0/24", []string{"10.16.0.1"})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			ip, _, _, err := im.GetRandomAddress("pod1.ns", "pod1.ns", subnetName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.16.0.2"))
+
+			// switch to the target cidr and keep the source cidr as an excluded extra cidr
+			err = im.AddOrUpdateSubnet(subnetName, "10.17.0.0/24,10.16.0.0/24", []string{"10.16.0.1", "10.17.0.1", "10.16.0.1..10.16.0.254"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(im.ContainAddress("10.16.0.2")).To(BeTrue())
+
+			ip, _, _, err = im.GetRandomAddress("pod1.ns", "pod1.ns", subnetName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.16.0.2"))
+
+			ip, _, _, err = im.GetRandomAddress("pod2.ns", "pod2.ns", subnetName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.17.0.2"))
+
+			// the released source address is not allocated again
+			im.ReleaseAddressByPod("pod1.ns")
+			ip, _, _, err = im.GetRandomAddress("pod1.ns", "pod1.ns", subnetName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.17.0.3"))
+		})
+	})
+
+	Describe("[DualStackUpgrade]", func() {
+		It("keep ipv4 address and allocate ipv6 address", func() {
+			im := ipam.NewIPAM()
+			err := im.AddOrUpdateSubnet(subnetName, ipv4CIDR, ipv4ExcludeIPs)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			ipv4, _, _, err := im.GetRandomAddress("pod1.ns", "pod1.ns", subnetName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ipv4).To(Equal("10.16.0.2"))
+
+			err = im.AddOrUpdateSubnet(subnetName, dualCIDR, dualExcludeIPs)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			ipv4, ipv6, _, err := im.GetRandomAddress("pod1.ns", "pod1.ns", subnetName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ipv4).To(Equal("10.16.0.2"))
+			Expect(ipv6).To(Equal("fd00::2"))
+
+			ipv4, ipv6, _, err = im.GetRandomAddress("pod2.ns", "pod2.ns", subnetName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ipv4).To(Equal("10.16.0.3"))
+			Expect(ipv6).NotTo(BeEmpty())
+			Expect(ipv6).NotTo(Equal("fd00::2"))
+		})
+	})
+
+	Describe("[NamespaceQuota]", func() {
+		It("list nics of namespace", func() {
+			im := ipam.NewIPAM()
+			err := im.AddOrUpdateSubnet(subnetName, "10.16.0.0/24", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(im.NamespaceNics(subnetName, "ns1")).To(BeEmpty())
+
+			for _, pod := range []string{"ns1/pod1", "ns1/pod2", "ns2/pod1"} {
+				_, _, _, err = im.GetRandomAddress(pod, pod+".nic", subnetName, nil)
+				Expect(err).ShouldNot(HaveOccurred())
+			}
+			Expect(im.NamespaceNics(subnetName, "ns1")).To(ConsistOf("ns1/pod1.nic", "ns1/pod2.nic"))
+			Expect(im.NamespaceNics(subnetName, "ns")).To(BeEmpty())
+			Expect(im.NamespaceNics("other", "ns1")).To(BeEmpty())
+			Expect(im.SubnetNics(subnetName)).To(ConsistOf("ns1/pod1.nic", "ns1/pod2.nic", "ns2/pod1.nic"))
+			Expect(im.SubnetNics("other")).To(BeEmpty())
+
+			im.ReleaseAddressByPod("ns1/pod1")
+			Expect(im.NamespaceNics(subnetName, "ns1")).To(ConsistOf("ns1/pod2.nic"))
+			Expect(im.SubnetNics(subnetName)).To(ConsistOf("ns1/pod2.nic", "ns2/pod1.nic"))
+		})
+	})
 })
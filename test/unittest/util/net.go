@@ -6,6 +6,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
 	"github.com/kubeovn/kube-ovn/pkg/util"
 )
 
@@ -143,4 +144,210 @@ var _ = Describe("[Net]", func() {
 			Expect(util.ExpandExcludeIPs(args[i].excludeIps, args[i].cidr)).To(Equal(wants[i]))
 		}
 	})
+
+	It("GetIpAddrWithMask", func() {
+		type arg struct {
+			ip   string
+			cidr string
+		}
+
+		args := []arg{
+			{"10.0.1.1", "10.0.1.0/24"},
+			{"fe00::101", "fe00::100/120"},
+			{"10.0.1.1,fe00::101", "10.0.1.0/24,fe00::100/120"},
+			{"10.0.1.1,fe00::101,10.0.2.1", "10.0.1.0/24,fe00::100/120,10.0.2.0/25"},
+			{"10.0.1.1", "10.0.1.0/24,fe00::100/120"},
+		}
+		wants := []string{
+			"10.0.1.1/24",
+			"fe00::101/120",
+			"10.0.1.1/24,fe00::101/120",
+			"10.0.1.1/24,fe00::101/120,10.0.2.1/25",
+			"",
+		}
+
+		for i := range args {
+			Expect(util.GetIpAddrWithMask(args[i].ip, args[i].cidr)).To(Equal(wants[i]))
+		}
+	})
+
+	It("IPDerivedMac", func() {
+		Expect(util.IPDerivedMac("02:AB:CD", "10.16.1.2")).To(Equal("02:AB:CD:10:01:02"))
+		Expect(util.IPDerivedMac("02:AB:CD", "fd00::1:a0b")).To(Equal("02:AB:CD:01:0A:0B"))
+		Expect(util.IPDerivedMac("02:AB:CD", "invalid")).To(Equal(""))
+
+		Expect(util.ValidateMacPrefix("02:ab:cd")).To(Succeed())
+		Expect(util.ValidateMacPrefix("01:00:5e")).NotTo(Succeed())
+		Expect(util.ValidateMacPrefix("02:ab")).NotTo(Succeed())
+	})
+
+	It("ValidateDHCPOptions", func() {
+		Expect(util.ValidateDHCPOptions(kubeovnv1.ProtocolIPv4, nil)).To(Succeed())
+		Expect(util.ValidateDHCPOptions(kubeovnv1.ProtocolIPv4, map[string]string{"dns_server": "{8.8.8.8}", "mtu": "1400"})).To(Succeed())
+		Expect(util.ValidateDHCPOptions(kubeovnv1.ProtocolIPv4, map[string]string{"domain_search": `"example.com"`})).NotTo(Succeed())
+		Expect(util.ValidateDHCPOptions(kubeovnv1.ProtocolIPv4, map[string]string{"mtu": " "})).NotTo(Succeed())
+		Expect(util.ValidateDHCPOptions(kubeovnv1.ProtocolIPv6, map[string]string{"domain_search": `"example.com"`})).To(Succeed())
+		Expect(util.ValidateDHCPOptions(kubeovnv1.ProtocolIPv6, map[string]string{"mtu": "1400"})).NotTo(Succeed())
+	})
+
+	It("CompileAclRule", func() {
+		acls, err := util.CompileAclRule("10.16.0.0/16", kubeovnv1.AclRule{
+			Direction: kubeovnv1.AclRuleIngress,
+			Priority:  10,
+			Action:    kubeovnv1.AclRuleActionAllow,
+			CIDRs:     []string{"10.17.0.0/16", "192.168.0.0/24"},
+			Protocol:  "tcp",
+			Ports:     []string{"80", "443", "8000-8080"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(acls).To(Equal([]kubeovnv1.Acl{{
+			Direction: "to-lport",
+			Priority:  1110,
+			Match:     "ip4.dst == {10.16.0.0/16} && ip4.src == {10.17.0.0/16, 192.168.0.0/24} && tcp && (tcp.dst == {80, 443} || (tcp.dst >= 8000 && tcp.dst <= 8080))",
+			Action:    "allow-related",
+		}}))
+
+		acls, err = util.CompileAclRule("10.16.0.0/16,fd00:10:16::/64", kubeovnv1.AclRule{
+			Direction: kubeovnv1.AclRuleEgress,
+			Action:    kubeovnv1.AclRuleActionDrop,
+			Protocol:  "icmp",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(acls).To(Equal([]kubeovnv1.Acl{
+			{Direction: "from-lport", Priority: 1100, Match: "ip4.src == {10.16.0.0/16} && icmp4", Action: "drop"},
+			{Direction: "from-lport", Priority: 1100, Match: "ip6.src == {fd00:10:16::/64} && icmp6", Action: "drop"},
+		}))
+
+		acls, err = util.CompileAclRule("10.16.0.0/16,fd00:10:16::/64", kubeovnv1.AclRule{
+			Direction: kubeovnv1.AclRuleIngress,
+			Action:    kubeovnv1.AclRuleActionReject,
+			CIDRs:     []string{"fd00:20::/64"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(acls).To(Equal([]kubeovnv1.Acl{
+			{Direction: "to-lport", Priority: 1100, Match: "ip6.dst == {fd00:10:16::/64} && ip6.src == {fd00:20::/64}", Action: "reject"},
+		}))
+
+		for _, rule := range []kubeovnv1.AclRule{
+			{Direction: "in", Action: kubeovnv1.AclRuleActionAllow},
+			{Direction: kubeovnv1.AclRuleIngress, Action: "accept"},
+			{Direction: kubeovnv1.AclRuleIngress, Action: kubeovnv1.AclRuleActionAllow, Priority: 900},
+			{Direction: kubeovnv1.AclRuleIngress, Action: kubeovnv1.AclRuleActionAllow, CIDRs: []string{"10.17.0.0"}},
+			{Direction: kubeovnv1.AclRuleIngress, Action: kubeovnv1.AclRuleActionAllow, CIDRs: []string{"fd00:20::/64"}},
+			{Direction: kubeovnv1.AclRuleIngress, Action: kubeovnv1.AclRuleActionAllow, Ports: []string{"80"}},
+			{Direction: kubeovnv1.AclRuleIngress, Action: kubeovnv1.AclRuleActionAllow, Protocol: "udp", Ports: []string{"8080-80"}},
+			{Direction: kubeovnv1.AclRuleIngress, Action: kubeovnv1.AclRuleActionAllow, Protocol: "udp", Ports: []string{"65536"}},
+		} {
+			_, err = util.CompileAclRule("10.16.0.0/16", rule)
+			Expect(err).To(HaveOccurred())
+		}
+	})
+
+	It("ValidateMirrorSession", func() {
+		session := &kubeovnv1.MirrorSession{Spec: kubeovnv1.MirrorSessionSpec{
+			Namespaces: []string{"default"},
+			Destination: kubeovnv1.MirrorDestination{
+				Type:     kubeovnv1.MirrorTypeERSPAN,
+				RemoteIP: "192.168.0.100",
+				Key:      10,
+			},
+		}}
+		Expect(util.ValidateMirrorSession(session)).To(Succeed())
+
+		for _, update := range []func(spec *kubeovnv1.MirrorSessionSpec){
+			func(spec *kubeovnv1.MirrorSessionSpec) { spec.Namespaces = nil },
+			func(spec *kubeovnv1.MirrorSessionSpec) { spec.Direction = "in" },
+			func(spec *kubeovnv1.MirrorSessionSpec) { spec.Snaplen = 10 },
+			func(spec *kubeovnv1.MirrorSessionSpec) { spec.Destination.Type = "vxlan" },
+			func(spec *kubeovnv1.MirrorSessionSpec) { spec.Destination.RemoteIP = "192.168.0" },
+			func(spec *kubeovnv1.MirrorSessionSpec) { spec.Destination.Key = 1024 },
+			func(spec *kubeovnv1.MirrorSessionSpec) { spec.Destination.ERSPANVersion = 3 },
+			func(spec *kubeovnv1.MirrorSessionSpec) {
+				spec.Destination.Type = kubeovnv1.MirrorTypeGRE
+				spec.Destination.ERSPANVersion = 2
+			},
+		} {
+			invalid := session.DeepCopy()
+			update(&invalid.Spec)
+			Expect(util.ValidateMirrorSession(invalid)).NotTo(Succeed())
+		}
+	})
+
+	It("CarveCIDRBlock", func() {
+		block, err := util.CarveCIDRBlock("10.100.0.0/16", 24, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(block).To(Equal("10.100.0.0/24"))
+
+		block, err = util.CarveCIDRBlock("10.100.0.0/16", 24, []string{"10.100.0.0/24", "10.100.1.128/25,fd00::/64", "10.100.2.0/23"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(block).To(Equal("10.100.4.0/24"))
+
+		block, err = util.CarveCIDRBlock("fd00:100::/48", 64, []string{"fd00:100::/63"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(block).To(Equal("fd00:100:0:2::/64"))
+
+		_, err = util.CarveCIDRBlock("10.100.0.0/24", 25, []string{"10.100.0.0/25", "10.100.0.128/25"})
+		Expect(err).To(HaveOccurred())
+		_, err = util.CarveCIDRBlock("10.100.0.0/16", 8, nil)
+		Expect(err).To(HaveOccurred())
+		_, err = util.CarveCIDRBlock("10.0.0.0/8", 30, nil)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("ReverseDNSName", func() {
+		name, err := util.ReverseDNSName("10.16.0.15")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(name).To(Equal("15.0.16.10.in-addr.arpa"))
+
+		name, err = util.ReverseDNSName("fd00::1a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(name).To(Equal("a.1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.d.f.ip6.arpa"))
+
+		_, err = util.ReverseDNSName("10.16.0")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("ValidateDNSRecord", func() {
+		record := &kubeovnv1.DNSRecord{Spec: kubeovnv1.DNSRecordSpec{
+			Vpc:      "vpc1",
+			Hostname: "db.example.internal",
+			IPs:      []string{"10.0.1.10", "fd00::10"},
+		}}
+		Expect(util.ValidateDNSRecord(record)).To(Succeed())
+
+		for _, update := range []func(spec *kubeovnv1.DNSRecordSpec){
+			func(spec *kubeovnv1.DNSRecordSpec) { spec.Hostname = "" },
+			func(spec *kubeovnv1.DNSRecordSpec) { spec.Hostname = "DB_1.example" },
+			func(spec *kubeovnv1.DNSRecordSpec) { spec.IPs = nil },
+			func(spec *kubeovnv1.DNSRecordSpec) { spec.IPs = []string{"10.0.1"} },
+		} {
+			invalid := record.DeepCopy()
+			update(&invalid.Spec)
+			Expect(util.ValidateDNSRecord(invalid)).NotTo(Succeed())
+		}
+	})
+
+	It("VpcPeeringTransitSwitch", func() {
+		ts := util.VpcPeeringTransitSwitch("az1", "vpc1", "az2", "vpc2")
+		Expect(ts).To(Equal("vpc-peering-az1.vpc1-az2.vpc2"))
+		Expect(util.VpcPeeringTransitSwitch("az2", "vpc2", "az1", "vpc1")).To(Equal(ts))
+		Expect(util.VpcPeeringTransitSwitch("az1", "vpc1", "az2", "vpc1")).NotTo(Equal(ts))
+	})
+
+	It("ValidateVpcQuota", func() {
+		quota := &kubeovnv1.VpcQuota{MaxSubnets: 2, MaxEIPs: 1}
+		Expect(util.ValidateVpcQuota("vpc1", nil, kubeovnv1.VpcQuotaUsage{Subnets: 3}, kubeovnv1.VpcQuotaUsage{})).To(Succeed())
+		Expect(util.ValidateVpcQuota("vpc1", quota, kubeovnv1.VpcQuotaUsage{Subnets: 2, IPs: 100}, kubeovnv1.VpcQuotaUsage{})).To(Succeed())
+		Expect(util.ValidateVpcQuota("vpc1", quota, kubeovnv1.VpcQuotaUsage{Subnets: 3}, kubeovnv1.VpcQuotaUsage{Subnets: 2})).NotTo(Succeed())
+		Expect(util.ValidateVpcQuota("vpc1", quota, kubeovnv1.VpcQuotaUsage{EIPs: 2}, kubeovnv1.VpcQuotaUsage{EIPs: 1})).NotTo(Succeed())
+		// usage over the lowered quota is allowed if it does not grow
+		Expect(util.ValidateVpcQuota("vpc1", quota, kubeovnv1.VpcQuotaUsage{EIPs: 2}, kubeovnv1.VpcQuotaUsage{EIPs: 3})).To(Succeed())
+
+		usage := kubeovnv1.VpcQuotaUsage{}
+		util.AddVpcNatGatewayQuotaUsage(&usage, &kubeovnv1.VpcNatGateway{Spec: kubeovnv1.VpcNatSpec{
+			Eips:      []*kubeovnv1.Eip{{EipCIDR: "172.18.0.10/24"}, {EipCIDR: "172.18.0.11/24"}},
+			SnatRules: []*kubeovnv1.SnatRule{{Eip: "172.18.0.10", InternalCIDR: "10.0.1.0/24"}},
+		}})
+		Expect(usage).To(Equal(kubeovnv1.VpcQuotaUsage{EIPs: 2, SnatRules: 1}))
+	})
 })